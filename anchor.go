@@ -0,0 +1,87 @@
+package geofence
+
+import "time"
+
+// AnchorWatch is the swing circle of a vessel at anchor: it can swing anywhere within the
+// radius around the anchor with the wind and tide, and is dragging its anchor once it is
+// further away. See Tracker.WatchAnchor for Drag events.
+type AnchorWatch struct {
+	anchor *Point
+	radius float64
+}
+
+// NewAnchorWatch returns the swing circle of swingRadiusMeters around the anchor, usually the
+// length of the anchor rode plus the vessel's length and some allowance for GPS error.
+func NewAnchorWatch(anchor *Point, swingRadiusMeters float64) *AnchorWatch {
+	return &AnchorWatch{anchor: anchor, radius: swingRadiusMeters}
+}
+
+// Anchor returns where the anchor was dropped.
+func (watch *AnchorWatch) Anchor() *Point {
+	return watch.anchor
+}
+
+// SwingRadius returns the radius of the swing circle in meters.
+func (watch *AnchorWatch) SwingRadius() float64 {
+	return watch.radius
+}
+
+// Dragging reports whether the point is outside the swing circle, measured as the great circle
+// distance from the anchor rather than against a polygon.
+func (watch *AnchorWatch) Dragging(point *Point) bool {
+	return watch.anchor.DistanceTo(point) > watch.radius
+}
+
+// Geofence returns a geodesic Geofence approximating the swing circle, e.g. to draw it on a map.
+func (watch *AnchorWatch) Geofence() *Geofence {
+	return NewGeodesicCircleGeofence(watch.anchor, watch.radius, 0)
+}
+
+// anchorState is an entity's anchor watch in a Tracker.
+type anchorState struct {
+	watch    *AnchorWatch
+	dragging bool
+	// outside counts consecutive updates outside the swing circle, towards WithHysteresis
+	outside int
+}
+
+// WatchAnchor watches the entity's updates against the anchor watch, and Update reports a Drag
+// event for the entity, with the zero key, when an update is outside the swing circle. As for
+// Exit, WithHysteresis requires that many consecutive updates outside first. A Drag is reported
+// once, and again only after the entity has been back inside the circle. A nil watch stops
+// watching the entity. Forget drops the watch too.
+func (tracker *Tracker[K]) WatchAnchor(entity string, watch *AnchorWatch) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	state, ok := tracker.entities[entity]
+	if !ok {
+		if watch == nil {
+			return
+		}
+		state = &trackedEntity[K]{inside: make(map[K]*visit), pending: make(map[K]*streak)}
+		tracker.entities[entity] = state
+	}
+	state.anchor = nil
+	if watch != nil {
+		state.anchor = &anchorState{watch: watch}
+	}
+}
+
+// checkAnchor returns the Drag event for the update, if the entity has dragged its anchor.
+func (tracker *Tracker[K]) checkAnchor(entity string, state *trackedEntity[K], point *Point, timestamp time.Time) []Event[K] {
+	watched := state.anchor
+	if watched == nil {
+		return nil
+	}
+	if !watched.watch.Dragging(point) {
+		watched.dragging, watched.outside = false, 0
+		return nil
+	}
+	watched.outside++
+	if watched.dragging || watched.outside < tracker.options.samples {
+		return nil
+	}
+	watched.dragging = true
+	return []Event[K]{{Type: Drag, Entity: entity, Point: point, Time: timestamp}}
+}
@@ -0,0 +1,52 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorWatch(t *testing.T) {
+	anchor := NewPoint(50.1, -5.05)
+	watch := NewAnchorWatch(anchor, 60)
+	assert.Equal(t, anchor, watch.Anchor())
+	assert.Equal(t, 60.0, watch.SwingRadius())
+	assert.False(t, watch.Dragging(anchor.PointAtDistanceAndBearing(0.059, 120)))
+	assert.True(t, watch.Dragging(anchor.PointAtDistanceAndBearing(0.061, 120)))
+	assert.True(t, watch.Geofence().Inside(anchor.PointAtDistanceAndBearing(0.05, 300)))
+	assert.False(t, watch.Geofence().Inside(anchor.PointAtDistanceAndBearing(0.07, 300)))
+}
+
+func TestTrackerWatchAnchor(t *testing.T) {
+	anchor := NewPoint(50.1, -5.05)
+	var handled []Event[string]
+	tracker := NewTracker(NewGeofenceGroup[string](), func(event Event[string]) {
+		handled = append(handled, event)
+	}, WithHysteresis(2))
+	tracker.WatchAnchor("yacht", NewAnchorWatch(anchor, 60))
+	start := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	swinging, dragged := anchor.PointAtDistanceAndBearing(0.04, 90), anchor.PointAtDistanceAndBearing(0.1, 90)
+	assert.Empty(t, tracker.Update("yacht", swinging, start))
+	assert.Empty(t, tracker.Update("yacht", dragged, start.Add(time.Minute)), "a single fix outside may be GPS noise")
+	assert.Empty(t, tracker.Update("yacht", swinging, start.Add(2*time.Minute)))
+	assert.Empty(t, tracker.Update("yacht", dragged, start.Add(3*time.Minute)))
+	events := tracker.Update("yacht", dragged, start.Add(4*time.Minute))
+	assert.Equal(t, []Event[string]{{Type: Drag, Entity: "yacht", Point: dragged, Time: start.Add(4 * time.Minute)}}, events)
+	assert.Equal(t, "DRAG", events[0].Type.String())
+	assert.Equal(t, events, handled)
+	assert.Empty(t, tracker.Update("yacht", dragged, start.Add(5*time.Minute)), "a drag is reported once")
+
+	// Back inside, e.g. after re-anchoring, the watch reports the next drag
+	tracker.Update("yacht", swinging, start.Add(6*time.Minute))
+	tracker.Update("yacht", dragged, start.Add(7*time.Minute))
+	assert.Len(t, tracker.Update("yacht", dragged, start.Add(8*time.Minute)), 1)
+
+	tracker.WatchAnchor("yacht", nil)
+	tracker.Update("yacht", swinging, start.Add(9*time.Minute))
+	tracker.Update("yacht", dragged, start.Add(10*time.Minute))
+	assert.Empty(t, tracker.Update("yacht", dragged, start.Add(11*time.Minute)))
+	tracker.WatchAnchor("dinghy", nil)
+	assert.Nil(t, tracker.Inside("dinghy"))
+}
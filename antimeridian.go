@@ -0,0 +1,91 @@
+package geofence
+
+// A fence crossing the ±180° meridian, say from 170° to -170°, would otherwise get a bounding
+// box and tile grid spanning the whole globe. Such fences shift negative longitudes by 360°
+// so the fence covers a continuous range, here 170° to 190°, and query points are shifted the same way.
+
+// crossesAntimeridian reports whether the ring's longitudes span more than 180°, which no
+// fence takes the long way round the globe, and shifting them gives a span under 180°.
+// Rings with longitudes beyond ±180° are taken to be planar coordinates and never wrap.
+func crossesAntimeridian(ring []*Point) bool {
+	if len(ring) == 0 {
+		return false
+	}
+	yVertices := getYVertices(ring)
+	minLng, maxLng := getMin(yVertices), getMax(yVertices)
+	if maxLng-minLng <= 180 || minLng < -180 || maxLng > 180 {
+		return false
+	}
+
+	for i, lng := range yVertices {
+		if lng < 0 {
+			yVertices[i] = lng + 360
+		}
+	}
+	return getMax(yVertices)-getMin(yVertices) < 180
+}
+
+// lngRanges returns the longitude ranges of query points the geofence's bounding box may contain.
+// A geofence crossing the antimeridian contains points in its shifted range, say 170° to 190°,
+// and the same range 360° west, -190° to -170°, for points given west of the antimeridian.
+func (geofence *Geofence) lngRanges() [][2]float64 {
+	if !geofence.wrapLng {
+		return [][2]float64{{geofence.minY, geofence.maxY}}
+	}
+	return [][2]float64{{geofence.minY, geofence.maxY}, {geofence.minY - 360, geofence.maxY - 360}}
+}
+
+// wrapPoint returns the point in the geofence's continuous longitude range, or projected for
+// a geofence built WithProjection.
+func (geofence *Geofence) wrapPoint(point *Point) *Point {
+	if geofence.projection != nil {
+		return geofence.projection.Project(point)
+	}
+	if geofence.wrapLng && point.Lng() < geofence.wrapFrom() {
+		return NewPoint(point.Lat(), point.Lng()+360)
+	}
+	return point
+}
+
+// wrapFrom returns the longitude below which wrapPoint shifts points by 360°. That is 0° unless
+// the geofence is a NewBBoxGeofence whose range starts elsewhere, e.g. from 10° east round to 5°,
+// which crosses both 0° and the antimeridian.
+func (geofence *Geofence) wrapFrom() float64 {
+	if geofence.rect && (geofence.minY < 0 || geofence.maxY >= 360) {
+		return geofence.minY
+	}
+	return 0
+}
+
+// unwrapPoint returns the point with its longitude back in [-180, 180], or unprojected.
+func (geofence *Geofence) unwrapPoint(point *Point) *Point {
+	if geofence.projection != nil {
+		return geofence.projection.Unproject(point)
+	}
+	if geofence.wrapLng && point.Lng() > 180 {
+		return NewPoint(point.Lat(), point.Lng()-360)
+	}
+	return point
+}
+
+func (geofence *Geofence) wrapRing(ring []*Point) []*Point {
+	if !geofence.wrapLng && geofence.projection == nil {
+		return ring
+	}
+	wrapped := make([]*Point, len(ring))
+	for i, point := range ring {
+		wrapped[i] = geofence.wrapPoint(point)
+	}
+	return wrapped
+}
+
+func (geofence *Geofence) unwrapRing(ring []*Point) []*Point {
+	if !geofence.wrapLng && geofence.projection == nil {
+		return ring
+	}
+	unwrapped := make([]*Point, len(ring))
+	for i, point := range ring {
+		unwrapped[i] = geofence.unwrapPoint(point)
+	}
+	return unwrapped
+}
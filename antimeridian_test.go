@@ -0,0 +1,31 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAntimeridian(t *testing.T) {
+	polygon := []*Point{NewPoint(-20, 170), NewPoint(-20, -170), NewPoint(-10, -170), NewPoint(-10, 170)}
+	for _, geofence := range []*Geofence{NewGeofence(polygon), NewGeodesicGeofence(polygon)} {
+		assert.True(t, geofence.Inside(NewPoint(-15, 175)))
+		assert.True(t, geofence.Inside(NewPoint(-15, 180)))
+		assert.True(t, geofence.Inside(NewPoint(-15, -180)))
+		assert.True(t, geofence.Inside(NewPoint(-15, -175)))
+		assert.False(t, geofence.Inside(NewPoint(-15, 0)))
+		assert.False(t, geofence.Inside(NewPoint(-15, 165)))
+		assert.False(t, geofence.Inside(NewPoint(-15, -165)))
+		assert.False(t, geofence.Inside(NewPoint(-25, 179)))
+	}
+
+	geofence := NewGeofence(polygon)
+	min, max := geofence.Bounds()
+	assert.Equal(t, NewPoint(-20, 170), min)
+	assert.Equal(t, NewPoint(-10, -170), max)
+	assert.InDelta(t, -5, geofence.DistanceToBoundary(NewPoint(-15, -175)), 1e-9)
+
+	data, err := geofence.ToGeoJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"Polygon","coordinates":[[[170,-20],[-170,-20],[-170,-10],[170,-10],[170,-20]]]}`, string(data))
+}
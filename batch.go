@@ -0,0 +1,80 @@
+package geofence
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// batchChunk is the number of points a goroutine takes at a time, small enough to share out
+// uneven work but large enough that the goroutine overhead does not outweigh the parallelism.
+const batchChunk = 1024
+
+// InsideBatch checks whether each of the points is inside the geofence, returning the
+// results in the same order as the points. Large batches are split into chunks that
+// are checked in parallel, using at most GOMAXPROCS goroutines.
+func (geofence *Geofence) InsideBatch(points []*Point) []bool {
+	return geofence.InsideBatchInto(points, nil)
+}
+
+// InsideBatchInto is InsideBatch writing the results into dst, which is grown only when it
+// is shorter than points, and returning dst resliced to len(points). Reusing dst across
+// calls, e.g. for each hour of a GPS trace, avoids allocating a result slice every time.
+func (geofence *Geofence) InsideBatchInto(points []*Point, dst []bool) []bool {
+	if cap(dst) < len(points) {
+		dst = make([]bool, len(points))
+	}
+	results := dst[:len(points)]
+	geofence.insideBatch(points, results, runtime.GOMAXPROCS(0))
+	return results
+}
+
+// InsideBatchParallel is InsideBatch using up to the given number of goroutines, or
+// GOMAXPROCS when workers is not positive. Batches of up to 1024 points are still checked
+// on the calling goroutine.
+func (geofence *Geofence) InsideBatchParallel(points []*Point, workers int) []bool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := make([]bool, len(points))
+	geofence.insideBatch(points, results, workers)
+	return results
+}
+
+// insideBatch fills in the results with a pool of workers taking chunks of points in turn,
+// so a worker that gets the slow points near the edges does not hold up the others.
+func (geofence *Geofence) insideBatch(points []*Point, results []bool, workers int) {
+	chunks := (len(points) + batchChunk - 1) / batchChunk
+	if workers > chunks {
+		workers = chunks
+	}
+	if workers <= 1 {
+		for i, point := range points {
+			results[i] = geofence.Inside(point)
+		}
+		return
+	}
+
+	var next int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			defer wg.Done()
+			for {
+				chunk := int(atomic.AddInt64(&next, 1) - 1)
+				if chunk >= chunks {
+					return
+				}
+				start, end := chunk*batchChunk, (chunk+1)*batchChunk
+				if end > len(points) {
+					end = len(points)
+				}
+				for i := start; i < end; i++ {
+					results[i] = geofence.Inside(points[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
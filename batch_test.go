@@ -0,0 +1,108 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsideBatch(t *testing.T) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	for _, size := range []int{0, 1, 100, 50000} {
+		points := make([]*Point, size)
+		for i := range points {
+			points[i] = randomPoint(200)
+		}
+
+		results := geofence.InsideBatch(points)
+		assert.Len(t, results, size)
+		for i, point := range points {
+			assert.Equal(t, geofence.Inside(point), results[i])
+		}
+	}
+}
+
+func TestInsideBatchInto(t *testing.T) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := make([]*Point, 500)
+	for i := range points {
+		points[i] = randomPoint(200)
+	}
+
+	dst := make([]bool, 0, 1000)
+	results := geofence.InsideBatchInto(points, dst)
+	assert.Len(t, results, 500)
+	assert.Equal(t, geofence.InsideBatch(points), results)
+	assert.Equal(t, &dst[:1][0], &results[0])
+
+	// Small batches run on the calling goroutine, so a reused dst means no allocations
+	bbox := NewBBoxGeofence(-100, -100, 100, 100)
+	allocs := testing.AllocsPerRun(10, func() {
+		bbox.InsideBatchInto(points, dst)
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	assert.Len(t, geofence.InsideBatchInto(points, nil), 500)
+}
+
+func TestInsideBatchParallel(t *testing.T) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := make([]*Point, 10*batchChunk+7)
+	for i := range points {
+		points[i] = randomPoint(200)
+	}
+
+	expected := geofence.InsideBatch(points)
+	for _, workers := range []int{-1, 0, 1, 3, 16, 100} {
+		assert.Equal(t, expected, geofence.InsideBatchParallel(points, workers), "workers %d", workers)
+	}
+	assert.Empty(t, geofence.InsideBatchParallel(nil, 4))
+}
+
+func batchBenchmarkPoints(geofence *Geofence) []*Point {
+	points := make([]*Point, 100000)
+	for i := range points {
+		points[i] = randomPointCustom(geofence.minX, geofence.maxX, geofence.minY, geofence.maxY, 1.2)
+	}
+	return points
+}
+
+func BenchmarkInsideSerial(b *testing.B) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := batchBenchmarkPoints(geofence)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, point := range points {
+			geofence.Inside(point)
+		}
+	}
+}
+
+func BenchmarkInsideBatch(b *testing.B) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := batchBenchmarkPoints(geofence)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geofence.InsideBatch(points)
+	}
+}
+
+func BenchmarkInsideBatchParallel16(b *testing.B) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := batchBenchmarkPoints(geofence)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geofence.InsideBatchParallel(points, 16)
+	}
+}
+
+func BenchmarkInsideBatchInto(b *testing.B) {
+	geofence := NewGeofence(randomPolygon(2000, 0.1))
+	points := batchBenchmarkPoints(geofence)
+	dst := make([]bool, len(points))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = geofence.InsideBatchInto(points, dst)
+	}
+}
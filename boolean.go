@@ -0,0 +1,123 @@
+package geofence
+
+// Union returns a geofence covering every point inside any of the fences, merging those that
+// overlap or touch and keeping the parts of holes that no other fence covers. Intersect returns
+// the points inside all of the fences, and Difference those inside the fence and none of the
+// exclusions. The results are new geofences with the options of the first fence with vertices,
+// with holes and several polygons as needed, and no vertices when nothing is left. The fences
+// are combined as for Buffer: geographic fences on a transverse Mercator plane about their
+// middle, following their edges as they are drawn, and WithPlanar or WithProjection fences on
+// their own plane, which the other fences should share.
+func Union(fences ...*Geofence) *Geofence {
+	return combine(fences, insideAny)
+}
+
+// Intersect returns a geofence covering the points inside all of the fences, see Union.
+func Intersect(fences ...*Geofence) *Geofence {
+	return combine(fences, insideAll)
+}
+
+// Difference returns a geofence covering the points inside the fence and outside all of the
+// exclusions, e.g. a coverage area less its no-fly zones, see Union.
+func Difference(fence *Geofence, exclusions ...*Geofence) *Geofence {
+	return combine(append([]*Geofence{fence}, exclusions...), insideFirstOnly)
+}
+
+func insideAny(inside []bool) bool {
+	for _, in := range inside {
+		if in {
+			return true
+		}
+	}
+	return false
+}
+
+func insideAll(inside []bool) bool {
+	for _, in := range inside {
+		if !in {
+			return false
+		}
+	}
+	return len(inside) > 0
+}
+
+// insideFirstOnly returns whether the point is inside the first fence and none of the others.
+func insideFirstOnly(inside []bool) bool {
+	return len(inside) > 0 && inside[0] && !insideAny(inside[1:])
+}
+
+// combine returns the geofence covering the points where keep is true of whether each fence
+// contains them.
+func combine(fences []*Geofence, keep func(inside []bool) bool) *Geofence {
+	plane, rings, template := overlayFences(fences, keep)
+	if rings == nil {
+		return NewGeofence(nil, template.rebuildOptions()...)
+	}
+	return plane.geofence(rings, template)
+}
+
+// overlayFences returns the plane the fences are combined on and the rings around the points
+// where keep is true of whether each fence contains them, along with the fence whose options
+// a result takes. The rings are nil when the fences have no vertices.
+func overlayFences(fences []*Geofence, keep func(inside []bool) bool) (overlayPlane, [][]*Point, *Geofence) {
+	var parts []*Geofence
+	for _, fence := range fences {
+		if fence != nil {
+			parts = append(parts, fence.polygons()...)
+		}
+	}
+	all := newMultiGeofence(parts)
+	template := all.template()
+	if all.empty() {
+		return overlayPlane{}, nil, template
+	}
+	plane := newOverlayPlane(all)
+
+	// Each fence on the plane, and its edges, along which the result's boundary lies
+	planes := make([]*Geofence, len(fences))
+	var segments []planeSegment
+	for i, fence := range fences {
+		var polygons [][][]*Point
+		if fence != nil {
+			polygons = plane.polygonRings(fence)
+		}
+		for _, rings := range polygons {
+			segments = append(segments, ringSegments(rings)...)
+		}
+		planes[i] = planeGeofence(polygons)
+	}
+
+	inside := make([]bool, len(planes))
+	rings := overlayRings(segments, func(x, y float64) bool {
+		point := NewPointXY(x, y)
+		for i, fence := range planes {
+			inside[i] = fence.Inside(point)
+		}
+		return keep(inside)
+	})
+	if rings == nil {
+		rings = [][]*Point{}
+	}
+	return plane, rings, template
+}
+
+// ringSegments returns the edges of the rings.
+func ringSegments(rings [][]*Point) []planeSegment {
+	var segments []planeSegment
+	for _, ring := range rings {
+		for i, a := range ring {
+			b := ring[(i+1)%len(ring)]
+			segments = append(segments, planeSegment{a.X(), a.Y(), b.X(), b.Y()})
+		}
+	}
+	return segments
+}
+
+// planeGeofence returns a planar geofence of the polygons' rings on the plane.
+func planeGeofence(polygons [][][]*Point) *Geofence {
+	parts := make([]*Geofence, len(polygons))
+	for i, rings := range polygons {
+		parts[i] = NewGeofenceWithHoles(rings[0], rings[1:], WithPlanar())
+	}
+	return newMultiGeofence(parts)
+}
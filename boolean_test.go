@@ -0,0 +1,82 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBooleanOperations(t *testing.T) {
+	rectangle := func(minX, minY, maxX, maxY float64) []*Point {
+		return []*Point{NewPointXY(minX, minY), NewPointXY(maxX, minY), NewPointXY(maxX, maxY), NewPointXY(minX, maxY)}
+	}
+	a := NewGeofence(rectangle(0, 0, 10, 10), WithPlanar())
+	b := NewGeofence(rectangle(5, 5, 15, 15), WithPlanar())
+
+	union := Union(a, b)
+	assert.InDelta(t, 175, union.Area(), 1e-9)
+	assert.Len(t, union.Vertices(), 8)
+	assert.True(t, union.planar)
+	assert.True(t, union.Inside(NewPointXY(12, 12)))
+	assert.False(t, union.Inside(NewPointXY(12, 2)))
+
+	intersection := Intersect(a, b)
+	assert.InDelta(t, 25, intersection.Area(), 1e-9)
+	assert.Len(t, intersection.Vertices(), 4)
+	assert.True(t, intersection.Inside(NewPointXY(7, 7)))
+	assert.False(t, intersection.Inside(NewPointXY(2, 2)))
+
+	difference := Difference(a, b)
+	assert.InDelta(t, 75, difference.Area(), 1e-9)
+	assert.Len(t, difference.Vertices(), 6)
+	assert.True(t, difference.Inside(NewPointXY(2, 2)))
+	assert.False(t, difference.Inside(NewPointXY(7, 7)))
+
+	// An exclusion inside the fence becomes a hole, and one across it splits it
+	holed := Difference(a, NewGeofence(rectangle(4, 4, 6, 6), WithPlanar()))
+	assert.Len(t, holed.Holes(), 1)
+	assert.InDelta(t, 96, holed.Area(), 1e-9)
+	assert.False(t, holed.Inside(NewPointXY(5, 5)))
+	split := Difference(a, NewGeofence(rectangle(4, -1, 6, 11), WithPlanar()))
+	assert.Len(t, split.polygons(), 2)
+	assert.InDelta(t, 80, split.Area(), 1e-9)
+
+	// Disjoint fences give several polygons or nothing, and fences sharing an edge merge
+	apart := NewGeofence(rectangle(20, 20, 25, 25), WithPlanar())
+	assert.Len(t, Union(a, apart).polygons(), 2)
+	assert.Empty(t, Intersect(a, apart).Vertices())
+	assert.Empty(t, Difference(a, a).Vertices())
+	beside := Union(a, NewGeofence(rectangle(10, 0, 20, 10), WithPlanar()))
+	assert.Len(t, beside.Vertices(), 4)
+	assert.InDelta(t, 200, beside.Area(), 1e-9)
+
+	// Holes are kept where nothing else covers them
+	ring := NewGeofenceWithHoles(rectangle(0, 0, 10, 10), [][]*Point{rectangle(2, 2, 8, 8)}, WithPlanar())
+	patched := Union(ring, NewGeofence(rectangle(1, 4, 9, 6), WithPlanar()))
+	assert.Len(t, patched.Holes(), 2)
+	assert.InDelta(t, 100-24, patched.Area(), 1e-9)
+	island := Union(ring, NewGeofence(rectangle(3, 3, 7, 7), WithPlanar()))
+	assert.Len(t, island.polygons(), 2)
+	assert.True(t, island.Inside(NewPointXY(5, 5)))
+	assert.False(t, island.Inside(NewPointXY(2.5, 5)))
+
+	// Several fences at once
+	assert.InDelta(t, 175+25, Union(a, b, apart).Area(), 1e-9)
+	assert.InDelta(t, 16, Intersect(a, b, NewGeofence(rectangle(6, 6, 20, 20), WithPlanar())).Area(), 1e-9)
+	assert.InDelta(t, 50, Difference(a, b, NewGeofence(rectangle(0, 0, 5, 5), WithPlanar())).Area(), 1e-9)
+	assert.Empty(t, Union().Vertices())
+
+	// Geographic fences, across the antimeridian
+	west := NewGeofence([]*Point{NewPoint(-1, 179), NewPoint(-1, -179.5), NewPoint(1, -179.5), NewPoint(1, 179)})
+	east := NewGeofence([]*Point{NewPoint(-1, 179.5), NewPoint(-1, -179), NewPoint(1, -179), NewPoint(1, 179.5)})
+	geographic := Union(west, east)
+	assert.False(t, geographic.planar)
+	assert.True(t, geographic.Inside(NewPoint(0, 179.2)))
+	assert.True(t, geographic.Inside(NewPoint(0, -179.2)))
+	assert.False(t, geographic.Inside(NewPoint(0, -178.8)))
+	assert.InDelta(t, west.Area()*4/3, geographic.Area(), west.Area()*1e-3)
+	overlap := Intersect(west, east)
+	assert.True(t, overlap.Inside(NewPoint(0, 180)))
+	assert.False(t, overlap.Inside(NewPoint(0, 179.3)))
+	assert.InDelta(t, west.Area()*2/3, overlap.Area(), west.Area()*1e-3)
+}
@@ -0,0 +1,63 @@
+package geofence
+
+import (
+	"math"
+)
+
+// Boundary decides whether points exactly on an edge or vertex of a geofence are inside it.
+type Boundary int
+
+const (
+	// Inclusive counts points on the boundary as inside. This is the default.
+	Inclusive Boundary = iota
+	// Exclusive counts points on the boundary as outside.
+	Exclusive
+)
+
+// geodesicBoundaryTolerance is how far, as a fraction of the Earth's radius, a point may be
+// from a great circle arc and still be on it. It is about 6 micrometers on the ground.
+const geodesicBoundaryTolerance = 1e-12
+
+// onBoundary returns whether the point lies exactly on an edge of the outer ring or a hole.
+// Planar edges are tested with exact arithmetic, so the answer never depends on rounding.
+func (geofence *Geofence) onBoundary(point *Point) bool {
+	for _, ring := range geofence.rings() {
+		if len(ring) < 2 {
+			continue
+		}
+		for i := 0; i < len(ring); i++ {
+			start, end := ring[i], ring[(i+1)%len(ring)]
+			if geofence.geodesic {
+				if onGeodesicSegment(point, start, end) {
+					return true
+				}
+			} else if onPlanarSegment(point, start, end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func onPlanarSegment(p, a, b *Point) bool {
+	if orientation(a, b, p) != 0 {
+		return false
+	}
+	return p.Lat() >= math.Min(a.Lat(), b.Lat()) && p.Lat() <= math.Max(a.Lat(), b.Lat()) &&
+		p.Lng() >= math.Min(a.Lng(), b.Lng()) && p.Lng() <= math.Max(a.Lng(), b.Lng())
+}
+
+func onGeodesicSegment(p, a, b *Point) bool {
+	pv, av, bv := toVector3(p), toVector3(a), toVector3(b)
+	if samePoint(p, a) || samePoint(p, b) {
+		return true
+	}
+
+	normal := av.cross(bv)
+	length := math.Sqrt(normal.dot(normal))
+	if length == 0 || math.Abs(pv.dot(normal))/length > geodesicBoundaryTolerance {
+		return false
+	}
+	// On the great circle, so check p lies between a and b rather than on the rest of it
+	return av.cross(pv).dot(normal) >= 0 && pv.cross(bv).dot(normal) >= 0
+}
@@ -0,0 +1,39 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundary(t *testing.T) {
+	west := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	east := []*Point{NewPoint(0, 10), NewPoint(0, 20), NewPoint(10, 20), NewPoint(10, 10)}
+
+	for _, options := range [][]Option{nil, {WithGeodesic()}, {WithGranularity(7)}} {
+		inclusive := NewGeofence(west, append(options, WithBoundary(Inclusive))...)
+		exclusive := NewGeofence(east, append(options, WithBoundary(Exclusive))...)
+
+		// Shared edge, shared vertices and a point on a tile corner of both fences
+		for _, point := range []*Point{NewPoint(5, 10), NewPoint(0, 10), NewPoint(10, 10), NewPoint(0.5, 10)} {
+			assert.True(t, inclusive.Inside(point), "%v", point)
+			assert.False(t, exclusive.Inside(point), "%v", point)
+		}
+
+		// Edges that are not shared follow the same rule. These all lie on the equator or
+		// a meridian, so they are on the boundary in geodesic mode as well.
+		assert.True(t, inclusive.Inside(NewPoint(0, 5)))
+		assert.True(t, inclusive.Inside(NewPoint(0, 0)))
+		assert.False(t, exclusive.Inside(NewPoint(0, 15)))
+		assert.False(t, exclusive.Inside(NewPoint(5, 20)))
+		assert.False(t, exclusive.Inside(NewPoint(10, 20)))
+
+		assert.True(t, inclusive.Inside(NewPoint(5, 9.9)))
+		assert.True(t, exclusive.Inside(NewPoint(5, 10.1)))
+	}
+
+	assert.True(t, NewGeofence(west).Inside(NewPoint(5, 10)))
+	geofence, err := newGeofence(west, nil, []Option{WithBoundary(Boundary(7))})
+	assert.EqualError(t, err, "unknown boundary 7")
+	assert.Equal(t, Inclusive, geofence.boundary)
+}
@@ -0,0 +1,210 @@
+package geofence
+
+import (
+	"math"
+)
+
+// A point in a TILE_EITHER tile used to need the exact check against every edge of the
+// geofence. Instead each such tile keeps the few edges that cross it and a reference point
+// in the tile whose containment is known. Any edge between the reference and a query point
+// in the same tile crosses the tile, so counting the bucket's edges crossed on the way from
+// one to the other tells whether the query point is inside, as for a ray cast.
+
+// tileBucket holds the edges crossing a TILE_EITHER tile, or a quarter of one, and a
+// reference point in it. With WithRefinement a bucket is split into quarters, and a quarter
+// no edge crosses is entirely TILE_IN or TILE_OUT.
+type tileBucket struct {
+	minX, minY, maxX, maxY float64
+	edges                  [][2]*Point
+	reference              *Point
+	inside                 bool
+	children               *[4]*tileBucket
+	tile                   byte
+}
+
+// bucketEdges builds the tile buckets of a planar geofence. Geodesic edges are arcs that
+// the tiling only approximates, so geodesic geofences keep the exact check. Counting crossings
+// only gives the even-odd rule, so WindingNumber geofences keep it too, as do geofences with
+// WithExactPredicates since the buckets count in float64.
+func (geofence *Geofence) bucketEdges() {
+	geofence.buckets = nil
+	if geofence.geodesic || geofence.containment == WindingNumber || geofence.exact || geofence.tileWidth == 0 || geofence.tileHeight == 0 {
+		return
+	}
+
+	buckets := make(map[int64]*tileBucket)
+	for _, ring := range geofence.rings() {
+		ring = closeRing(ring)
+		for i := 0; i+1 < len(ring); i++ {
+			a, b := ring[i], ring[i+1]
+			minTileX, maxTileX := project(math.Min(a.Lat(), b.Lat()), geofence.tileWidth), project(math.Max(a.Lat(), b.Lat()), geofence.tileWidth)
+			minTileY, maxTileY := project(math.Min(a.Lng(), b.Lng()), geofence.tileHeight), project(math.Max(a.Lng(), b.Lng()), geofence.tileHeight)
+			// Take the neighbouring tiles too, in case rounding put an end point in the wrong one
+			for tileX := math.Max(minTileX-1, geofence.minTileX); tileX <= math.Min(maxTileX+1, geofence.maxTileX); tileX++ {
+				for tileY := math.Max(minTileY-1, geofence.minTileY); tileY <= math.Min(maxTileY+1, geofence.maxTileY); tileY++ {
+					tileHash := geofence.tileHash(tileX, tileY)
+					if geofence.tiles[tileHash] != TILE_EITHER {
+						continue
+					}
+					bucket, ok := buckets[tileHash]
+					if !ok {
+						bucket = &tileBucket{
+							minX: tileX * geofence.tileWidth, maxX: (tileX + 1) * geofence.tileWidth,
+							minY: tileY * geofence.tileHeight, maxY: (tileY + 1) * geofence.tileHeight,
+						}
+					}
+					if bucket.crossedBy(a, b) {
+						bucket.edges = append(bucket.edges, [2]*Point{a, b})
+						buckets[tileHash] = bucket
+					}
+				}
+			}
+		}
+	}
+
+	for tileHash, bucket := range buckets {
+		if !bucket.setReference(geofence.polygon.Contains) {
+			delete(buckets, tileHash)
+			continue
+		}
+		bucket.refine(geofence.refinement)
+	}
+	geofence.buckets = buckets
+}
+
+// crossedBy returns whether the segment a-b passes through the bucket's area, grown slightly
+// so that edges along its sides are included. It clips the segment to the area (Liang-Barsky).
+func (bucket *tileBucket) crossedBy(a, b *Point) bool {
+	marginX, marginY := (bucket.maxX-bucket.minX)*1e-9, (bucket.maxY-bucket.minY)*1e-9
+	minX, maxX := bucket.minX-marginX, bucket.maxX+marginX
+	minY, maxY := bucket.minY-marginY, bucket.maxY+marginY
+
+	t0, t1 := 0.0, 1.0
+	dx, dy := b.Lat()-a.Lat(), b.Lng()-a.Lng()
+	for _, clip := range [4][2]float64{{-dx, a.Lat() - minX}, {dx, maxX - a.Lat()}, {-dy, a.Lng() - minY}, {dy, maxY - a.Lng()}} {
+		p, q := clip[0], clip[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		if t0 > t1 {
+			return false
+		}
+	}
+	return true
+}
+
+// setReference picks a reference point in the bucket's area that is on none of its edges,
+// trying the center first, and records whether contains reports it inside.
+func (bucket *tileBucket) setReference(contains func(point *Point) bool) bool {
+	width, height := bucket.maxX-bucket.minX, bucket.maxY-bucket.minY
+	for _, offset := range [][2]float64{{0.5, 0.5}, {0.31, 0.67}, {0.73, 0.29}, {0.43, 0.19}, {0.61, 0.83}} {
+		reference := NewPoint(bucket.minX+offset[0]*width, bucket.minY+offset[1]*height)
+		if bucket.onEdge(reference) {
+			continue
+		}
+		bucket.reference = reference
+		bucket.inside = contains(reference)
+		return true
+	}
+	return false
+}
+
+// refine splits the bucket into quarters, depth times over, each with the edges crossing it.
+// A quarter crossed by no edge is TILE_IN or TILE_OUT as a whole, and a quarter without a usable
+// reference point is left as it is.
+func (bucket *tileBucket) refine(depth int) {
+	if depth <= 0 || len(bucket.edges) == 0 {
+		return
+	}
+
+	midX, midY := (bucket.minX+bucket.maxX)/2, (bucket.minY+bucket.maxY)/2
+	var children [4]*tileBucket
+	for i := range children {
+		child := &tileBucket{minX: bucket.minX, maxX: midX, minY: bucket.minY, maxY: midY}
+		if i&1 != 0 {
+			child.minX, child.maxX = midX, bucket.maxX
+		}
+		if i&2 != 0 {
+			child.minY, child.maxY = midY, bucket.maxY
+		}
+		for _, edge := range bucket.edges {
+			if child.crossedBy(edge[0], edge[1]) {
+				child.edges = append(child.edges, edge)
+			}
+		}
+
+		// The parent's edges include every edge between its reference and the child's
+		if !child.setReference(bucket.contains) {
+			return
+		}
+		if len(child.edges) == 0 {
+			child.tile = TILE_OUT
+			if child.inside {
+				child.tile = TILE_IN
+			}
+		}
+		child.refine(depth - 1)
+		children[i] = child
+	}
+	bucket.children = &children
+}
+
+// leaf returns the smallest refined bucket around the point.
+func (bucket *tileBucket) leaf(point *Point) *tileBucket {
+	for bucket.children != nil {
+		i := 0
+		if point.Lat() >= (bucket.minX+bucket.maxX)/2 {
+			i |= 1
+		}
+		if point.Lng() >= (bucket.minY+bucket.maxY)/2 {
+			i |= 2
+		}
+		bucket = bucket.children[i]
+	}
+	return bucket
+}
+
+// onEdge returns whether the point lies on one of the bucket's edges.
+func (bucket *tileBucket) onEdge(point *Point) bool {
+	for _, edge := range bucket.edges {
+		if onPlanarSegment(point, edge[0], edge[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether the point, in the bucket's area and on none of its edges, is inside
+// the geofence. Each edge crossing the segment from the reference to the point flips the result.
+// A vertex exactly on the segment counts as above it, so a boundary passing through it
+// at a vertex is counted once and one touching it there is counted twice.
+func (bucket *tileBucket) contains(point *Point) bool {
+	inside := bucket.inside
+	direction := vectorDifference(point, bucket.reference)
+	for _, edge := range bucket.edges {
+		a, b := edge[0], edge[1]
+		aAbove := vectorCrossProduct(direction, vectorDifference(a, bucket.reference)) >= 0
+		bAbove := vectorCrossProduct(direction, vectorDifference(b, bucket.reference)) >= 0
+		if aAbove == bAbove {
+			continue
+		}
+		// The edge straddles the line through the reference and the point, check it
+		// passes between them rather than beyond either
+		edgeDirection := vectorDifference(b, a)
+		referenceSide := vectorCrossProduct(edgeDirection, vectorDifference(bucket.reference, a))
+		pointSide := vectorCrossProduct(edgeDirection, vectorDifference(point, a))
+		if (referenceSide > 0) != (pointSide > 0) {
+			inside = !inside
+		}
+	}
+	return inside
+}
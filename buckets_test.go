@@ -0,0 +1,113 @@
+package geofence
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coastline returns a jagged star shaped ring of n vertices around the origin.
+func coastline(n int) []*Point {
+	ring := make([]*Point, n)
+	for i := range ring {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		radius := 10 + 2*math.Sin(angle*7) + rand.Float64()
+		ring[i] = NewPoint(radius*math.Cos(angle), radius*math.Sin(angle))
+	}
+	return ring
+}
+
+func TestTileBuckets(t *testing.T) {
+	outer := coastline(10000)
+	hole := []*Point{NewPoint(-2, -2), NewPoint(-2, 2), NewPoint(2, 2), NewPoint(2, -2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+	polygon := NewPolygonWithHoles(outer, [][]*Point{hole})
+
+	assert.NotEmpty(t, geofence.buckets)
+	for tileHash, bucket := range geofence.buckets {
+		assert.Equal(t, byte(TILE_EITHER), geofence.tiles[tileHash])
+		assert.Less(t, len(bucket.edges), 2000)
+	}
+
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(-13, 13, -13, 13, 1)
+		assert.Equal(t, polygon.Contains(point), geofence.Inside(point))
+	}
+	assert.False(t, geofence.Inside(NewPoint(0, 0)))
+	assert.True(t, geofence.Inside(hole[0]))
+	assert.False(t, NewGeofenceWithHoles(outer, [][]*Point{hole}, WithBoundary(Exclusive)).Inside(hole[0]))
+
+	// Geodesic geofences keep the exact check
+	assert.Empty(t, NewGeodesicGeofence(outer[:100]).buckets)
+
+	data, err := geofence.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Len(t, decoded.buckets, len(geofence.buckets))
+}
+
+func TestWithRefinement(t *testing.T) {
+	outer := coastline(5000)
+	hole := []*Point{NewPoint(-2, -2), NewPoint(-2, 2), NewPoint(2, 2), NewPoint(2, -2)}
+	polygon := NewPolygonWithHoles(outer, [][]*Point{hole})
+	flat := NewGeofenceWithHoles(outer, [][]*Point{hole})
+	refined := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithRefinement(4))
+	assert.Equal(t, 4, refined.refinement)
+
+	// Count the points answered by an edge free quarter rather than by counting crossings
+	decided := 0
+	for i := 0; i < 20000; i++ {
+		point := randomPointCustom(-13, 13, -13, 13, 1)
+		assert.Equal(t, polygon.Contains(point), refined.Inside(point))
+		tileHash := refined.tileHash(project(point.Lat(), refined.tileWidth), project(point.Lng(), refined.tileHeight))
+		if bucket := refined.buckets[tileHash]; bucket != nil {
+			assert.LessOrEqual(t, len(bucket.leaf(point).edges), len(flat.buckets[tileHash].edges))
+			if bucket.leaf(point).tile != 0 {
+				decided++
+			}
+		}
+	}
+	assert.Greater(t, decided, 1000)
+	assert.True(t, refined.Inside(hole[0]))
+	assert.False(t, NewGeofenceWithHoles(outer, [][]*Point{hole}, WithRefinement(4), WithBoundary(Exclusive)).Inside(hole[0]))
+
+	_, err := newGeofence(outer, nil, []Option{WithRefinement(13)})
+	assert.EqualError(t, err, "refinement depth must be between 0 and 12, got 13")
+	_, err = newGeofence(outer, nil, []Option{WithRefinement(-1)})
+	assert.EqualError(t, err, "refinement depth must be between 0 and 12, got -1")
+}
+
+func TestTileBucketVertexOnSegment(t *testing.T) {
+	// A reference at the tile center lines up with vertices of both a crossing and a touching boundary
+	bucket := &tileBucket{
+		edges:     [][2]*Point{{NewPoint(1, -1), NewPoint(1, 0)}, {NewPoint(1, 0), NewPoint(1, 1)}, {NewPoint(2, -1), NewPoint(3, 0)}, {NewPoint(3, 0), NewPoint(4, -1)}},
+		reference: NewPoint(0, 0),
+		inside:    false,
+	}
+	assert.True(t, bucket.contains(NewPoint(2, 0)))
+	assert.True(t, bucket.contains(NewPoint(5, 0)))
+	assert.False(t, bucket.contains(NewPoint(0.5, 0)))
+}
+
+func benchmarkCoastline(b *testing.B, opts ...Option) {
+	geofence := NewGeofence(coastline(10000), opts...)
+	points := make([]*Point, 10000)
+	for i := range points {
+		points[i] = randomPointCustom(-13, 13, -13, 13, 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geofence.Inside(points[i%len(points)])
+	}
+}
+
+func BenchmarkCoastline10000(b *testing.B) {
+	benchmarkCoastline(b)
+}
+
+func BenchmarkCoastline10000Refined(b *testing.B) {
+	benchmarkCoastline(b, WithRefinement(4))
+}
@@ -0,0 +1,125 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// bufferSides is the number of sides of the polygon around each vertex standing in for the
+// circle of the buffer distance, besides those added where it meets the edges' rectangles.
+const bufferSides = 32
+
+// bufferJoinAngle is the largest turn of a ring, in radians, at which the rectangles of the
+// edges either side share their corners. Their union then falls short of the buffer distance by
+// at most distance*bufferJoinAngle²/8.
+const bufferJoinAngle = 1e-3
+
+// Buffer returns a geofence with the same options grown by meters all round, or shrunk when
+// meters is negative, e.g. a warning zone around a site or a safety margin inside it. Grown
+// corners are rounded, holes shrink as the geofence grows, and parts close enough merge into
+// one. A geofence shrunk to nothing returns a geofence without vertices. Geographic geofences
+// are buffered on a transverse Mercator plane about their centroid, so distances are true for
+// geofences up to a few hundred kilometers across. WithPlanar geofences take meters in grid
+// units, and WithProjection geofences on their plane. A distance of 0 returns the geofence
+// itself.
+func (geofence *Geofence) Buffer(meters float64) *Geofence {
+	if meters == 0 || math.IsNaN(meters) || geofence.empty() {
+		return geofence
+	}
+	plane := newOverlayPlane(geofence)
+	polygons := plane.polygonRings(geofence)
+	distance := math.Abs(meters)
+
+	// The geofence's plane rings, and the rectangles and circles within distance of its edges
+	var pieces [][]*Point
+	for _, rings := range polygons {
+		for _, ring := range rings {
+			pieces = append(pieces, bufferPieces(ring, distance)...)
+		}
+	}
+	original := planeGeofence(polygons)
+	near := NewMultiGeofence(pieces, WithPlanar(), WithGranularity(1))
+
+	// The boundary of the result lies along the pieces' edges
+	segments := ringSegments(pieces)
+	inside := func(x, y float64) bool {
+		point := NewPointXY(x, y)
+		if meters > 0 {
+			return original.Inside(point) || near.Inside(point)
+		}
+		return original.Inside(point) && !near.Inside(point)
+	}
+	return plane.geofence(overlayRings(segments, inside), geofence.template())
+}
+
+// bufferPieces returns the rectangles within distance of each edge of the ring and the
+// polygons inside the circles at its vertices. The circles have a vertex at each rectangle
+// corner they meet, so the pieces join without gaps. Where the ring barely turns, e.g. along a
+// densified edge, the rectangles either side share their corners on the mean of their normals,
+// as corners a hair apart leave slivers too thin to overlay.
+func bufferPieces(ring []*Point, distance float64) [][]*Point {
+	// The normal of the edge from each vertex, NaN for edges of no length
+	normals := make([]float64, len(ring))
+	for i, a := range ring {
+		b := ring[(i+1)%len(ring)]
+		normals[i] = math.NaN()
+		if a.X() != b.X() || a.Y() != b.Y() {
+			normals[i] = math.Atan2(b.Y()-a.Y(), b.X()-a.X()) + math.Pi/2
+		}
+	}
+	// The directions of the corners at each vertex of the rectangles of the edges before and after it
+	corners := make([][2]float64, len(ring))
+	for i := range ring {
+		before, after := normals[(i+len(ring)-1)%len(ring)], normals[i]
+		if turn := math.Remainder(after-before, 2*math.Pi); math.Abs(turn) < bufferJoinAngle {
+			before += turn / 2
+			after = before
+		}
+		corners[i] = [2]float64{before, after}
+	}
+	corner := func(point *Point, angle float64) *Point {
+		return NewPointXY(point.X()+distance*math.Cos(angle), point.Y()+distance*math.Sin(angle))
+	}
+
+	pieces := make([][]*Point, 0, 2*len(ring))
+	for i, a := range ring {
+		angles := make([]float64, 0, bufferSides+4)
+		for _, normal := range corners[i] {
+			if !math.IsNaN(normal) {
+				angles = append(angles, math.Mod(normal+2*math.Pi, 2*math.Pi), math.Mod(normal+3*math.Pi, 2*math.Pi))
+			}
+		}
+		// Sides of the circle ending a hair from a corner would leave a vertex all but on the rectangle's edge
+		for side, count := 0, len(angles); side < bufferSides; side++ {
+			angle, near := 2*math.Pi*float64(side)/bufferSides, false
+			for _, corner := range angles[:count] {
+				near = near || math.Abs(math.Remainder(angle-corner, 2*math.Pi)) < bufferJoinAngle
+			}
+			if !near {
+				angles = append(angles, angle)
+			}
+		}
+		sort.Float64s(angles)
+		circle := make([]*Point, 0, len(angles))
+		for j, angle := range angles {
+			if j > 0 && angle-angles[j-1] < 1e-9 {
+				continue
+			}
+			circle = append(circle, corner(a, angle))
+		}
+		pieces = append(pieces, circle)
+
+		if math.IsNaN(normals[i]) {
+			continue
+		}
+		next := (i + 1) % len(ring)
+		b, start, end := ring[next], corners[i][1], corners[next][0]
+		pieces = append(pieces, []*Point{
+			corner(a, start+math.Pi),
+			corner(b, end+math.Pi),
+			corner(b, end),
+			corner(a, start),
+		})
+	}
+	return pieces
+}
@@ -0,0 +1,99 @@
+package geofence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	planarSquare := func(min, max float64) []*Point {
+		return []*Point{NewPointXY(min, min), NewPointXY(max, min), NewPointXY(max, max), NewPointXY(min, max)}
+	}
+	// The rounded corners are polygons inside the circles
+	corners := bufferSides / 2 * math.Sin(2*math.Pi/bufferSides)
+
+	square := NewGeofence(planarSquare(0, 10), WithPlanar())
+	grown := square.Buffer(1)
+	assert.InDelta(t, 100+40+corners, grown.Area(), 1e-6)
+	assert.True(t, grown.Inside(NewPointXY(-0.9, 5)))
+	assert.True(t, grown.Inside(NewPointXY(10.5, 10.5)))
+	assert.False(t, grown.Inside(NewPointXY(-1.1, 5)))
+	assert.False(t, grown.Inside(NewPointXY(10.9, 10.9)))
+
+	shrunk := square.Buffer(-1)
+	assert.InDelta(t, 64, shrunk.Area(), 1e-6)
+	assert.Len(t, shrunk.Vertices(), 4)
+	assert.True(t, shrunk.Inside(NewPointXY(1.1, 5)))
+	assert.False(t, shrunk.Inside(NewPointXY(0.9, 5)))
+	assert.Empty(t, square.Buffer(-6).Vertices())
+	assert.Same(t, square, square.Buffer(0))
+
+	// Holes shrink as the geofence grows until they close
+	holed := NewGeofenceWithHoles(planarSquare(0, 10), [][]*Point{planarSquare(4, 6)}, WithPlanar())
+	grown = holed.Buffer(0.5)
+	if assert.Len(t, grown.Holes(), 1) {
+		assert.InDelta(t, 1, math.Abs(ringArea(grown.Holes()[0])), 1e-6)
+	}
+	assert.False(t, grown.Inside(NewPointXY(5, 5)))
+	assert.True(t, grown.Inside(NewPointXY(4.4, 5)))
+	assert.Empty(t, holed.Buffer(1.5).Holes())
+	assert.True(t, holed.Buffer(1.5).Inside(NewPointXY(5, 5)))
+	assert.Len(t, holed.Buffer(-1).Holes(), 1)
+
+	// Polygons close enough merge
+	pair := NewMultiGeofence([][]*Point{planarSquare(0, 10), {NewPointXY(12, 0), NewPointXY(22, 0), NewPointXY(22, 10), NewPointXY(12, 10)}}, WithPlanar())
+	assert.Len(t, pair.Buffer(0.5).polygons(), 2)
+	merged := pair.Buffer(1.5)
+	assert.Len(t, merged.polygons(), 1)
+	assert.True(t, merged.Inside(NewPointXY(11, 5)))
+	// A thin waist splits when shrunk
+	dumbbell := NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 4.5), NewPointXY(14, 4.5), NewPointXY(14, 0),
+		NewPointXY(24, 0), NewPointXY(24, 10), NewPointXY(14, 10), NewPointXY(14, 5.5), NewPointXY(10, 5.5), NewPointXY(10, 10), NewPointXY(0, 10)}, WithPlanar())
+	assert.Len(t, dumbbell.Buffer(-1).polygons(), 2)
+
+	// Geographic geofences are buffered in meters
+	london := NewCircleGeofence(NewPoint(51.5, -0.1), 1000, 64, WithGeodesic())
+	for _, meters := range []float64{200, -200} {
+		buffered := london.Buffer(meters)
+		assert.Equal(t, london.geodesic, buffered.geodesic)
+		for _, bearing := range []float64{0, 45, 90, 200} {
+			edge := NewPoint(51.5, -0.1).PointAtDistanceAndBearing((995+meters)/1000, bearing)
+			assert.True(t, buffered.Inside(edge), "%v at %v", meters, bearing)
+			edge = NewPoint(51.5, -0.1).PointAtDistanceAndBearing((1005+meters)/1000, bearing)
+			assert.False(t, buffered.Inside(edge), "%v at %v", meters, bearing)
+		}
+	}
+	across := NewGeodesicGeofence([]*Point{NewPoint(-1, 179.5), NewPoint(-1, -179.5), NewPoint(1, -179.5), NewPoint(1, 179.5)}).Buffer(10000)
+	assert.True(t, across.geodesic)
+	assert.True(t, across.Inside(NewPoint(1.05, 180)))
+	assert.True(t, across.Inside(NewPoint(0, -179.42)))
+	assert.False(t, across.Inside(NewPoint(0, -179.3)))
+	assert.False(t, across.Inside(NewPoint(0, 179.3)))
+	assert.Len(t, across.polygons(), 1)
+	assert.True(t, across.Inside(NewPoint(0, 180)))
+
+	// Large geofences are densified into nearly parallel edges, and still buffer into one piece
+	large := []*Geofence{
+		NewBBoxGeofence(10, 10, 11, 11),
+		NewBBoxGeofence(50, -3, 53, 0),
+		NewBBoxGeofence(60, 20, 62, 22, WithGeodesic()),
+		NewCircleGeofence(NewPoint(30, 30), 2, 64),
+	}
+	for _, geofence := range large {
+		for _, meters := range []float64{10, 1000, 10000} {
+			buffered := geofence.Buffer(meters)
+			assert.Len(t, buffered.polygons(), 1, "%v by %v", geofence.Centroid(), meters)
+			assert.True(t, buffered.Inside(geofence.Centroid()), "%v by %v", geofence.Centroid(), meters)
+			for _, vertex := range geofence.Vertices() {
+				assert.True(t, buffered.Inside(vertex), "%v by %v", vertex, meters)
+			}
+		}
+		assert.Greater(t, geofence.Buffer(1000).Area(), geofence.Area())
+		shrunk := geofence.Buffer(-1000)
+		assert.Len(t, shrunk.polygons(), 1)
+		assert.Less(t, shrunk.Area(), geofence.Area())
+		assert.True(t, shrunk.Inside(geofence.Centroid()))
+	}
+}
@@ -0,0 +1,145 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// Centroid returns the center of mass of the geofence, less its holes, computed on the plane
+// of its coordinates: lat/lng for geographic geofences, following the arcs of geodesic ones,
+// and the projected plane WithProjection. The centroid of a concave geofence can be outside
+// it, see InteriorPoint. The polygons of a NewMultiGeofence are weighted by their Area. A
+// geofence without area returns the mean of its vertices, and one without vertices nil.
+func (geofence *Geofence) Centroid() *Point {
+	parts := geofence.polygons()
+	if len(parts) == 1 {
+		if geofence.empty() {
+			return nil
+		}
+		return geofence.unwrapPoint(geofence.planarCentroid())
+	}
+
+	var sumX, sumY, weights float64
+	var sum vector3
+	var fallback *Point
+	for _, part := range parts {
+		if part.empty() {
+			continue
+		}
+		centroid := part.planarCentroid()
+		if fallback == nil {
+			fallback = part.unwrapPoint(centroid)
+		}
+		weight := part.Area()
+		if part.planar {
+			sumX, sumY = sumX+weight*centroid.X(), sumY+weight*centroid.Y()
+		} else {
+			// Geographic parts are averaged on the sphere, which works across the antimeridian
+			v := toVector3(part.unwrapPoint(centroid))
+			sum = vector3{sum.x + weight*v.x, sum.y + weight*v.y, sum.z + weight*v.z}
+		}
+		weights += weight
+	}
+	switch {
+	case weights == 0:
+		return fallback
+	case geofence.parts[0].planar:
+		return NewPointXY(sumX/weights, sumY/weights)
+	}
+	return sum.toPoint()
+}
+
+// planarCentroid returns the centroid of the single polygon geofence in its own coordinates.
+func (geofence *Geofence) planarCentroid() *Point {
+	var sumX, sumY, area float64
+	for i, ring := range geofence.rings() {
+		ring = geofence.tilingRing(ring)
+		ringX, ringY, ringArea := ringCentroid(ring)
+		if (ringArea < 0) != (i > 0) {
+			// The outer ring counts positive and holes negative, whichever way they wind
+			ringX, ringY, ringArea = -ringX, -ringY, -ringArea
+		}
+		sumX, sumY, area = sumX+ringX, sumY+ringY, area+ringArea
+	}
+	if area == 0 {
+		xs, ys := getXVertices(geofence.vertices), getYVertices(geofence.vertices)
+		meanX, meanY := 0.0, 0.0
+		for i := range xs {
+			meanX, meanY = meanX+xs[i], meanY+ys[i]
+		}
+		return NewPoint(meanX/float64(len(xs)), meanY/float64(len(ys)))
+	}
+	return NewPoint(sumX/(3*area), sumY/(3*area))
+}
+
+// ringCentroid returns the shoelace sums of the ring: x and y weighted by twice the signed area
+// of each triangle with the origin, and the signed area.
+func ringCentroid(ring []*Point) (sumX, sumY, area float64) {
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		cross := vectorCrossProduct(a, b)
+		sumX += (a.X() + b.X()) * cross
+		sumY += (a.Y() + b.Y()) * cross
+		area += cross / 2
+	}
+	return sumX / 2, sumY / 2, area
+}
+
+// InteriorPoint returns a point that is inside the geofence, even when it is concave or has
+// holes, e.g. to place a map label. It is the centroid when that is inside, or else the middle
+// of the widest stretch inside the geofence along a parallel near the middle of its bounds. For
+// a NewMultiGeofence the polygon with the largest Area is used. A geofence without area returns
+// a vertex, and one without vertices nil.
+func (geofence *Geofence) InteriorPoint() *Point {
+	var largest *Geofence
+	largestArea := -1.0
+	for _, part := range geofence.polygons() {
+		if area := part.Area(); !part.empty() && area > largestArea {
+			largest, largestArea = part, area
+		}
+	}
+	if largest == nil {
+		return nil
+	}
+	if centroid := largest.Centroid(); largest.Inside(centroid) && !largest.onBoundary(largest.wrapPoint(centroid)) {
+		return centroid
+	}
+
+	// Parallels between the middle and the edges of the bounds, in case one misses
+	for _, f := range []float64{0.5, 0.25, 0.75, 0.375, 0.625, 0.125, 0.875} {
+		x := largest.minX + f*(largest.maxX-largest.minX)
+		if point := largest.scanlinePoint(x); point != nil && largest.Inside(largest.unwrapPoint(point)) {
+			return largest.unwrapPoint(point)
+		}
+	}
+	return largest.unwrapPoint(largest.vertices[0])
+}
+
+// scanlinePoint returns the middle of the widest stretch inside the single polygon geofence at
+// x, from the crossings of its rings, or nil when there is none.
+func (geofence *Geofence) scanlinePoint(x float64) *Point {
+	var crossings []float64
+	for _, ring := range geofence.rings() {
+		ring = geofence.tilingRing(ring)
+		for i := 0; i < len(ring); i++ {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			if (a.X() <= x) == (b.X() <= x) {
+				continue
+			}
+			crossings = append(crossings, a.Y()+(x-a.X())/(b.X()-a.X())*(b.Y()-a.Y()))
+		}
+	}
+	sort.Float64s(crossings)
+
+	// By the even-odd rule the stretches between pairs of crossings are inside
+	best, width := math.NaN(), 0.0
+	for i := 0; i+1 < len(crossings); i += 2 {
+		if crossings[i+1]-crossings[i] > width {
+			best, width = (crossings[i]+crossings[i+1])/2, crossings[i+1]-crossings[i]
+		}
+	}
+	if math.IsNaN(best) {
+		return nil
+	}
+	return NewPoint(x, best)
+}
@@ -0,0 +1,62 @@
+package geofence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCentroid(t *testing.T) {
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)}
+	centroid := NewGeofence(square).Centroid()
+	assert.InDelta(t, 1, centroid.Lat(), 1e-9)
+	assert.InDelta(t, 1, centroid.Lng(), 1e-9)
+
+	// An L of three unit squares, wound the other way
+	l := []*Point{NewPoint(0, 0), NewPoint(2, 0), NewPoint(2, 1), NewPoint(1, 1), NewPoint(1, 2), NewPoint(0, 2)}
+	centroid = NewGeofence(l).Centroid()
+	assert.InDelta(t, 5.0/6, centroid.Lat(), 1e-9)
+	assert.InDelta(t, 5.0/6, centroid.Lng(), 1e-9)
+
+	// A hole off center pulls the centroid the other way
+	hole := []*Point{NewPoint(1.5, 1.5), NewPoint(1.5, 2), NewPoint(2, 2), NewPoint(2, 1.5)}
+	centroid = NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)}, [][]*Point{hole}).Centroid()
+	assert.Less(t, centroid.Lat(), 1.0)
+	assert.InDelta(t, centroid.Lat(), centroid.Lng(), 1e-9)
+
+	// Across the antimeridian, and on a flat grid
+	centroid = NewGeofence([]*Point{NewPoint(0, 179), NewPoint(0, -179), NewPoint(2, -179), NewPoint(2, 179)}).Centroid()
+	assert.InDelta(t, 1, centroid.Lat(), 1e-9)
+	assert.InDelta(t, 180, math.Abs(centroid.Lng()), 1e-9)
+	centroid = NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 4), NewPointXY(0, 4)}, WithPlanar()).Centroid()
+	assert.Equal(t, NewPointXY(5, 2), centroid)
+
+	// The polygons of a multi geofence are weighted by area
+	multi := NewMultiGeofence([][]*Point{square, {NewPoint(0, 10), NewPoint(0, 11), NewPoint(1, 11), NewPoint(1, 10)}})
+	centroid = multi.Centroid()
+	assert.InDelta(t, (4*1+1*10.5)/5.0, centroid.Lng(), 0.05)
+	assert.Nil(t, NewGeofence(nil).Centroid())
+	assert.Equal(t, NewPoint(0, 1), NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 2)}).Centroid())
+}
+
+func TestInteriorPoint(t *testing.T) {
+	// The centroid of a C is in its mouth
+	c := []*Point{NewPoint(0, 0), NewPoint(0, 3), NewPoint(1, 3), NewPoint(1, 1), NewPoint(2, 1), NewPoint(2, 3), NewPoint(3, 3), NewPoint(3, 0)}
+	for _, geofence := range []*Geofence{
+		NewGeofence(c),
+		NewGeodesicGeofence(c),
+		NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, [][]*Point{{NewPoint(1, 1), NewPoint(1, 9), NewPoint(9, 9), NewPoint(9, 1)}}),
+		NewGeofence([]*Point{NewPoint(0, 170), NewPoint(0, -170), NewPoint(1, -170), NewPoint(1, 175), NewPoint(2, 175), NewPoint(2, -170), NewPoint(3, -170), NewPoint(3, 170)}),
+		NewMultiGeofence([][]*Point{c, {NewPoint(10, 10), NewPoint(10, 11), NewPoint(11, 11)}}),
+	} {
+		assert.False(t, geofence.Inside(geofence.polygons()[0].Centroid()))
+		point := geofence.InteriorPoint()
+		assert.True(t, geofence.Inside(point), point)
+		assert.Less(t, geofence.DistanceToBoundaryMeters(point), -1000.0)
+	}
+
+	square := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 2), NewPoint(2, 2), NewPoint(2, 0)})
+	assert.Equal(t, square.Centroid(), square.InteriorPoint())
+	assert.Nil(t, NewGeofence(nil).InteriorPoint())
+}
@@ -0,0 +1,101 @@
+package geofence
+
+// Clone returns a deep copy of the geofence, with its own vertices, holes and tiles, that no
+// change to the points it was built from can reach. A geofence is read only once built, so a
+// clone is only needed when the points given to it may be changed later, see WithCopyPoints.
+func (geofence *Geofence) Clone() *Geofence {
+	return geofence.clone(make(map[*Point]*Point))
+}
+
+// clone returns a deep copy of the geofence, with the points copied once each through copies,
+// so points shared between its rings, polygon and tile buckets stay shared in the copy.
+func (geofence *Geofence) clone(copies map[*Point]*Point) *Geofence {
+	clone := *geofence
+	clone.vertices = clonePoints(geofence.vertices, copies)
+	clone.holes = cloneRings(geofence.holes, copies)
+	if geofence.polygon != nil {
+		clone.polygon = &Polygon{points: clonePoints(geofence.polygon.points, copies), holes: cloneRings(geofence.polygon.holes, copies)}
+	}
+	if geofence.parts != nil {
+		clone.parts = make([]*Geofence, len(geofence.parts))
+		for i, part := range geofence.parts {
+			clone.parts[i] = part.clone(copies)
+		}
+	}
+	if geofence.partTiles != nil {
+		clone.partTiles = make(map[int64][]int, len(geofence.partTiles))
+		for tileHash, parts := range geofence.partTiles {
+			clone.partTiles[tileHash] = append([]int{}, parts...)
+		}
+	}
+	if geofence.tiles != nil {
+		clone.tiles = make(map[int64]byte, len(geofence.tiles))
+		for tileHash, tile := range geofence.tiles {
+			clone.tiles[tileHash] = tile
+		}
+	}
+	if geofence.buckets != nil {
+		clone.buckets = make(map[int64]*tileBucket, len(geofence.buckets))
+		for tileHash, bucket := range geofence.buckets {
+			clone.buckets[tileHash] = bucket.clone(copies)
+		}
+	}
+	return &clone
+}
+
+// clone returns a deep copy of the bucket and its children.
+func (bucket *tileBucket) clone(copies map[*Point]*Point) *tileBucket {
+	if bucket == nil {
+		return nil
+	}
+	clone := *bucket
+	clone.edges = make([][2]*Point, len(bucket.edges))
+	for i, edge := range bucket.edges {
+		clone.edges[i] = [2]*Point{clonePoint(edge[0], copies), clonePoint(edge[1], copies)}
+	}
+	clone.reference = clonePoint(bucket.reference, copies)
+	if bucket.children != nil {
+		children := [4]*tileBucket{}
+		for i, child := range bucket.children {
+			children[i] = child.clone(copies)
+		}
+		clone.children = &children
+	}
+	return &clone
+}
+
+func cloneRings(rings [][]*Point, copies map[*Point]*Point) [][]*Point {
+	if rings == nil {
+		return nil
+	}
+	clones := make([][]*Point, len(rings))
+	for i, ring := range rings {
+		clones[i] = clonePoints(ring, copies)
+	}
+	return clones
+}
+
+func clonePoints(points []*Point, copies map[*Point]*Point) []*Point {
+	if points == nil {
+		return nil
+	}
+	clones := make([]*Point, len(points))
+	for i, point := range points {
+		clones[i] = clonePoint(point, copies)
+	}
+	return clones
+}
+
+// clonePoint returns the copy of the point, making it on first use.
+func clonePoint(point *Point, copies map[*Point]*Point) *Point {
+	if point == nil {
+		return nil
+	}
+	if clone, ok := copies[point]; ok {
+		return clone
+	}
+	clone := &Point{}
+	*clone = *point
+	copies[point] = clone
+	return clone
+}
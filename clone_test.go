@@ -0,0 +1,59 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	points := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(points, [][]*Point{hole}, WithGranularity(4))
+	clone := geofence.Clone()
+
+	assert.NotSame(t, geofence, clone)
+	assert.Equal(t, geofence.Vertices(), clone.Vertices())
+	assert.NotSame(t, geofence.Vertices()[0], clone.Vertices()[0])
+	assert.Equal(t, geofence.tiles, clone.tiles)
+	for _, point := range []*Point{NewPoint(1, 1), NewPoint(5, 5), NewPoint(11, 5), NewPoint(0, 5)} {
+		assert.Equal(t, geofence.Inside(point), clone.Inside(point))
+	}
+
+	// Changing the points the geofence was built from changes it, but not its clone
+	points[2] = NewPoint(1, 1)
+	assert.Equal(t, NewPoint(1, 1), geofence.Vertices()[2])
+	assert.Equal(t, NewPoint(10, 10), clone.Vertices()[2])
+	assert.NoError(t, hole[0].UnmarshalJSON([]byte(`{"lat":50,"lng":50}`)))
+	assert.Equal(t, NewPoint(4, 4), clone.Holes()[0][0])
+
+	// Multi polygon and geodesic geofences, whose tile buckets hold their own points
+	multi := NewMultiGeofence([][]*Point{{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)}, {NewPoint(5, 5), NewPoint(5, 6), NewPoint(6, 6)}})
+	multiClone := multi.Clone()
+	assert.Len(t, multiClone.polygons(), 2)
+	assert.NotSame(t, multi.parts[0], multiClone.parts[0])
+	assert.True(t, multiClone.Inside(NewPoint(5.2, 5.5)))
+	circle := NewGeodesicCircleGeofence(NewPoint(51.5, -0.1), 1000, 64)
+	circleClone := circle.Clone()
+	for _, bearing := range []float64{0, 90, 180, 270} {
+		for _, km := range []float64{0.98, 1.02} {
+			point := NewPoint(51.5, -0.1).PointAtDistanceAndBearing(km, bearing)
+			assert.Equal(t, circle.Inside(point), circleClone.Inside(point))
+		}
+	}
+	assert.Empty(t, NewGeofence(nil).Clone().Vertices())
+}
+
+func TestWithCopyPoints(t *testing.T) {
+	points := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofence(points, WithCopyPoints(), WithHoles(hole))
+
+	points[2] = NewPoint(1, 1)
+	assert.NoError(t, points[0].UnmarshalJSON([]byte(`{"lat":-5,"lng":-5}`)))
+	hole[0] = NewPoint(0, 0)
+	assert.Equal(t, []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, geofence.Vertices())
+	assert.Equal(t, NewPoint(4, 4), geofence.Holes()[0][0])
+	assert.True(t, geofence.Inside(NewPoint(8, 8)))
+	assert.False(t, geofence.Inside(NewPoint(5, 5)))
+}
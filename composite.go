@@ -0,0 +1,82 @@
+package geofence
+
+// Operators of a CompositeFence node.
+const (
+	compositeFence = iota
+	compositeAnd
+	compositeOr
+	compositeNot
+)
+
+// CompositeFence is a boolean expression over geofences, e.g. "inside region A and not inside
+// exclusion B, or inside region C" is Or(And(NewCompositeFence(a), Not(NewCompositeFence(b))),
+// NewCompositeFence(c)). A key of a GeofenceGroup is the special case
+// And(Or(whitelist...), Not(Or(blacklist...))). Unlike Union, Intersect and Difference, the
+// geofences are not combined into a new geometry, so any mix of geofences can be used and
+// nothing has to be rebuilt, at the cost of testing the point against each geofence the
+// expression needs.
+type CompositeFence struct {
+	operator int
+	geofence *Geofence
+	operands []*CompositeFence
+}
+
+// NewCompositeFence returns the expression that is true inside the geofence.
+func NewCompositeFence(geofence *Geofence) *CompositeFence {
+	return &CompositeFence{operator: compositeFence, geofence: geofence}
+}
+
+// And returns the expression that is true when all of the fences are, or everywhere when none
+// are given.
+func And(fences ...*CompositeFence) *CompositeFence {
+	return &CompositeFence{operator: compositeAnd, operands: append([]*CompositeFence(nil), fences...)}
+}
+
+// Or returns the expression that is true when any of the fences is, or nowhere when none are
+// given.
+func Or(fences ...*CompositeFence) *CompositeFence {
+	return &CompositeFence{operator: compositeOr, operands: append([]*CompositeFence(nil), fences...)}
+}
+
+// Not returns the expression that is true where the fence is not.
+func Not(fence *CompositeFence) *CompositeFence {
+	return &CompositeFence{operator: compositeNot, operands: []*CompositeFence{fence}}
+}
+
+// Inside reports whether the expression is true at the point. Operands are evaluated in order
+// and only until the result is known, so cheap or selective geofences are best given first.
+func (composite *CompositeFence) Inside(point *Point) bool {
+	switch composite.operator {
+	case compositeAnd:
+		for _, operand := range composite.operands {
+			if !operand.Inside(point) {
+				return false
+			}
+		}
+		return true
+	case compositeOr:
+		for _, operand := range composite.operands {
+			if operand.Inside(point) {
+				return true
+			}
+		}
+		return false
+	case compositeNot:
+		return !composite.operands[0].Inside(point)
+	default:
+		return composite.geofence.Inside(point)
+	}
+}
+
+// Geofences returns the geofences of the expression, in the order they appear, e.g. to draw
+// them on a map. A geofence used more than once is returned each time.
+func (composite *CompositeFence) Geofences() []*Geofence {
+	if composite.operator == compositeFence {
+		return []*Geofence{composite.geofence}
+	}
+	var geofences []*Geofence
+	for _, operand := range composite.operands {
+		geofences = append(geofences, operand.Geofences()...)
+	}
+	return geofences
+}
@@ -0,0 +1,31 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeFence(t *testing.T) {
+	regionA, exclusionB, regionC := square(0, 0, 10), square(4, 4, 2), square(20, 20, 10)
+	a, b, c := NewCompositeFence(regionA), NewCompositeFence(exclusionB), NewCompositeFence(regionC)
+	composite := Or(And(a, Not(b)), c)
+
+	assert.True(t, composite.Inside(NewPoint(1, 1)))
+	assert.False(t, composite.Inside(NewPoint(5, 5)), "inside the exclusion")
+	assert.True(t, composite.Inside(NewPoint(25, 25)))
+	assert.False(t, composite.Inside(NewPoint(15, 15)))
+	assert.Equal(t, []*Geofence{regionA, exclusionB, regionC}, composite.Geofences())
+
+	// The same as a group key with a whitelist and a blacklist
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{regionA, regionC}, []*Geofence{exclusionB})
+	asGroup := And(Or(a, c), Not(Or(b)))
+	for _, point := range []*Point{NewPoint(1, 1), NewPoint(5, 5), NewPoint(25, 25), NewPoint(15, 15), NewPoint(5, 25)} {
+		assert.Equal(t, group.GetValidKeys(point)[1], asGroup.Inside(point), "%v", point)
+	}
+
+	assert.True(t, And().Inside(NewPoint(50, 50)))
+	assert.False(t, Or().Inside(NewPoint(50, 50)))
+	assert.True(t, Not(Or()).Inside(NewPoint(50, 50)))
+}
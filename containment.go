@@ -0,0 +1,61 @@
+package geofence
+
+// Containment is the rule deciding which points a ring that crosses or overlaps itself contains.
+type Containment int
+
+const (
+	// RayCasting counts the edges crossed by a ray from the point, which is inside when the count
+	// is odd (the even-odd rule). Where a ring overlaps itself the overlap is outside. This is
+	// the default.
+	RayCasting Containment = iota
+	// WindingNumber counts how many times the ring winds around the point, which is inside when
+	// the count is not zero (the nonzero rule). Where a ring overlaps itself the overlap is inside,
+	// e.g. the middle of a five pointed star drawn in one stroke.
+	WindingNumber
+)
+
+// windingNumber returns how many times the ring winds around the point, positive when it does
+// so with increasing angle in the lat/lng plane. Each edge is counted when it crosses the line
+// of the point's lng to one side of the point, including its lower end and excluding its upper
+// end, so a vertex level with the point is counted once (Sunday's algorithm).
+func windingNumber(ring []*Point, point *Point) int {
+	winding := 0
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		side := (b.lat-a.lat)*(point.lng-a.lng) - (point.lat-a.lat)*(b.lng-a.lng)
+		if a.lng <= point.lng {
+			if b.lng > point.lng && side > 0 {
+				winding++
+			}
+		} else if b.lng <= point.lng && side < 0 {
+			winding--
+		}
+	}
+	return winding
+}
+
+// windingContains returns whether the geofence contains the point by the nonzero rule. As for
+// RayCasting, each hole that winds around the point flips the result.
+func (geofence *Geofence) windingContains(point *Point) bool {
+	inside := windingNumber(geofence.vertices, point) != 0
+	for _, hole := range geofence.holes {
+		if len(hole) >= 3 && windingNumber(hole, point) != 0 {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ringContainsAny returns whether the ring contains any of the points but the last, which
+// closes them, by the geofence's containment rule.
+func (geofence *Geofence) ringContainsAny(ring []*Point, points []*Point) bool {
+	if geofence.containment != WindingNumber {
+		return hasPointInPolygon(points, ring)
+	}
+	for _, point := range points[:len(points)-1] {
+		if windingNumber(ring, point) != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package geofence
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pentagram returns a five pointed star drawn in one stroke, winding twice around its middle.
+func pentagram() []*Point {
+	var star []*Point
+	for i := 0; i < 5; i++ {
+		angle := float64(i*2) * 2 * math.Pi / 5
+		star = append(star, NewPoint(10*math.Cos(angle), 10*math.Sin(angle)))
+	}
+	return star
+}
+
+func TestWithContainment(t *testing.T) {
+	star := pentagram()
+	rayCasting := NewGeofence(star)
+	winding := NewGeofence(star, WithContainment(WindingNumber))
+
+	middle, tip := NewPoint(0, 0), NewPoint(8, 0)
+	assert.False(t, rayCasting.Inside(middle))
+	assert.True(t, winding.Inside(middle))
+	assert.True(t, rayCasting.Inside(tip))
+	assert.True(t, winding.Inside(tip))
+	assert.Equal(t, 2, windingNumber(star, middle))
+
+	for i := 0; i < 10000; i++ {
+		point := NewPoint(rand.Float64()*24-12, rand.Float64()*24-12)
+		assert.Equal(t, windingNumber(star, point) != 0, winding.Inside(point))
+		assert.Equal(t, NewPolygon(star).Contains(point), rayCasting.Inside(point))
+	}
+
+	// The rules agree on simple rings, holes included
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	simple := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithContainment(WindingNumber))
+	polygon := NewPolygonWithHoles(outer, [][]*Point{hole})
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(0, 10, 0, 10, 1.2)
+		assert.Equal(t, polygon.Contains(point), simple.Inside(point))
+	}
+
+	data, err := winding.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.Inside(middle))
+
+	_, err = NewGeofenceChecked([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 0)}, WithContainment(Containment(5)))
+	assert.EqualError(t, err, "unknown containment 5")
+}
+
+func TestWindingNumber(t *testing.T) {
+	square := []*Point{NewPoint(0, 0), NewPoint(10, 0), NewPoint(10, 10), NewPoint(0, 10)}
+	assert.Equal(t, 1, windingNumber(square, NewPoint(5, 5)))
+	assert.Equal(t, -1, windingNumber([]*Point{square[3], square[2], square[1], square[0]}, NewPoint(5, 5)))
+	assert.Equal(t, 0, windingNumber(square, NewPoint(15, 5)))
+	// Level with a vertex, the vertex is counted once
+	assert.Equal(t, 1, windingNumber([]*Point{NewPoint(0, 0), NewPoint(10, 5), NewPoint(0, 10)}, NewPoint(1, 5)))
+	assert.Equal(t, 0, windingNumber([]*Point{NewPoint(0, 0), NewPoint(10, 5), NewPoint(0, 10)}, NewPoint(11, 5)))
+}
@@ -0,0 +1,114 @@
+package geofence
+
+import (
+	"math"
+)
+
+// coveringMargin is how far, in degrees, the rectangles are widened before testing them against
+// the edges of a geodesic geofence, more than the gap between a densified ring and its arcs.
+const coveringMargin = 0.001
+
+// rectClassifier sorts lat/lng rectangles, e.g. the cells of a covering, into those inside,
+// outside and on the boundary of a geofence. The rings are prepared once, as for tiling.
+type rectClassifier struct {
+	parts []coveringPart
+}
+
+type coveringPart struct {
+	geofence *Geofence
+	outer    []*Point
+	holes    [][]*Point
+}
+
+func newRectClassifier(geofence *Geofence) *rectClassifier {
+	classifier := &rectClassifier{}
+	for _, part := range geofence.polygons() {
+		if part.empty() {
+			continue
+		}
+		prepared := coveringPart{geofence: part, outer: closeRing(part.tilingRing(part.vertices))}
+		for _, hole := range part.holes {
+			prepared.holes = append(prepared.holes, closeRing(part.tilingRing(hole)))
+		}
+		classifier.parts = append(classifier.parts, prepared)
+	}
+	return classifier
+}
+
+// classify returns TILE_IN when the rectangle is inside the geofence, TILE_OUT when it is outside
+// and TILE_EITHER when it may be crossed by the boundary. Rectangles must not cross the antimeridian.
+func (classifier *rectClassifier) classify(minLat, minLng, maxLat, maxLng float64) byte {
+	state := byte(TILE_OUT)
+	for _, part := range classifier.parts {
+		shifts := []float64{0}
+		if part.geofence.wrapLng {
+			// The geofence's shifted longitudes may meet the rectangle either side of the antimeridian
+			shifts = append(shifts, 360)
+		}
+		for _, shift := range shifts {
+			switch part.classify(minLat, minLng+shift, maxLat, maxLng+shift) {
+			case TILE_IN:
+				return TILE_IN
+			case TILE_EITHER:
+				state = TILE_EITHER
+			}
+		}
+	}
+	return state
+}
+
+func (part *coveringPart) classify(minLat, minLng, maxLat, maxLng float64) byte {
+	geofence := part.geofence
+	if geofence.geodesic {
+		latMargin := coveringMargin
+		lngMargin := math.Min(coveringMargin/math.Max(math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat))*math.Pi/180.0), 1e-3), 1)
+		minLat, minLng, maxLat, maxLng = minLat-latMargin, minLng-lngMargin, maxLat+latMargin, maxLng+lngMargin
+	}
+
+	rect := part.rectRing(minLat, minLng, maxLat, maxLng)
+	if !part.overlapsBounds(rect) {
+		return TILE_OUT
+	}
+	if haveIntersectingEdges(rect, part.outer) || hasPointInPolygon(part.outer, rect) {
+		return TILE_EITHER
+	}
+	if !geofence.ringContainsAny(part.outer, rect[:2]) {
+		return TILE_OUT
+	}
+	for _, hole := range part.holes {
+		if haveIntersectingEdges(rect, hole) || hasPointInPolygon(hole, rect) {
+			return TILE_EITHER
+		}
+		if geofence.ringContainsAny(hole, rect[:2]) {
+			return TILE_OUT
+		}
+	}
+	return TILE_IN
+}
+
+// rectRing returns the closed ring of the rectangle in the geofence's coordinates. The edges of a
+// rectangle projected WithProjection are curves, so they are followed with extra points.
+func (part *coveringPart) rectRing(minLat, minLng, maxLat, maxLng float64) []*Point {
+	corners := []*Point{NewPoint(minLat, minLng), NewPoint(minLat, maxLng), NewPoint(maxLat, maxLng), NewPoint(maxLat, minLng)}
+	projection := part.geofence.projection
+	if projection == nil {
+		return closeRing(corners)
+	}
+	const steps = 4
+	ring := make([]*Point, 0, 4*steps+1)
+	for i, start := range corners {
+		end := corners[(i+1)%len(corners)]
+		for step := 0; step < steps; step++ {
+			f := float64(step) / steps
+			ring = append(ring, projection.Project(NewPoint(start.Lat()+f*(end.Lat()-start.Lat()), start.Lng()+f*(end.Lng()-start.Lng()))))
+		}
+	}
+	return closeRing(ring)
+}
+
+// overlapsBounds returns whether the bounding box of the ring overlaps the geofence's.
+func (part *coveringPart) overlapsBounds(ring []*Point) bool {
+	xs, ys := getXVertices(ring), getYVertices(ring)
+	geofence := part.geofence
+	return getMax(xs) >= geofence.minX && getMin(xs) <= geofence.maxX && getMax(ys) >= geofence.minY && getMin(ys) <= geofence.maxY
+}
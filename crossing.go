@@ -0,0 +1,123 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// Crosses returns whether the segment from a to b touches the boundary of the geofence, the
+// outer ring or a hole. This catches a path between two infrequent fixes passing through the
+// geofence even when neither fix is inside it. The segment is a great circle arc for a geodesic
+// geofence and a straight line otherwise.
+func (geofence *Geofence) Crosses(a, b *Point) bool {
+	return len(geofence.CrossingPoints(a, b)) > 0
+}
+
+// CrossingPoints returns where the segment from a to b meets the boundary of the geofence, in
+// order from a to b. Where the segment runs along an edge, the ends of the shared part are returned.
+func (geofence *Geofence) CrossingPoints(a, b *Point) []*Point {
+	type crossing struct {
+		point    *Point
+		distance float64
+	}
+	var crossings []crossing
+	for _, part := range geofence.polygons() {
+		start, end := part.wrapPoint(a), part.wrapPoint(b)
+		if part.wrapLng && math.Abs(end.Lng()-start.Lng()) > 180 {
+			// A segment crossing the antimeridian, e.g. 179° to -179°, is shorter the other way round
+			if start.Lng() > end.Lng() {
+				end = NewPoint(end.Lat(), end.Lng()+360)
+			} else {
+				start = NewPoint(start.Lat(), start.Lng()+360)
+			}
+		}
+		for _, ring := range part.rings() {
+			ring = closeRing(ring)
+			for i := 0; i+1 < len(ring); i++ {
+				var points []*Point
+				if part.geodesic {
+					points = geodesicIntersections(start, end, ring[i], ring[i+1])
+				} else {
+					points = planarIntersections(start, end, ring[i], ring[i+1])
+				}
+				for _, point := range points {
+					var distance float64
+					if part.geodesic {
+						distance = start.GreatCircleDistance(point)
+					} else {
+						distance = math.Hypot(point.Lat()-start.Lat(), point.Lng()-start.Lng())
+					}
+					crossings = append(crossings, crossing{part.unwrapPoint(point), distance})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(crossings, func(i, j int) bool {
+		return crossings[i].distance < crossings[j].distance
+	})
+	var points []*Point
+	for _, crossing := range crossings {
+		// A crossing through a vertex is found on both of its edges
+		if len(points) > 0 && samePoint(points[len(points)-1], crossing.point) {
+			continue
+		}
+		points = append(points, crossing.point)
+	}
+	return points
+}
+
+// planarIntersections returns the point where the straight segments a-b and c-d meet, or
+// the ends of the shared part when they are collinear and overlap.
+func planarIntersections(a, b, c, d *Point) []*Point {
+	if !segmentsIntersect(a, b, c, d) {
+		return nil
+	}
+	r := vectorDifference(b, a)
+	s := vectorDifference(d, c)
+	rCrossS := vectorCrossProduct(r, s)
+	if rCrossS != 0 {
+		t := vectorCrossProduct(vectorDifference(c, a), s) / rCrossS
+		return []*Point{NewPoint(a.Lat()+t*r.Lat(), a.Lng()+t*r.Lng())}
+	}
+
+	// Collinear, keep the end points of each segment that lie on the other
+	var points []*Point
+	for _, point := range []*Point{a, b} {
+		if onPlanarSegment(point, c, d) {
+			points = append(points, point)
+		}
+	}
+	for _, point := range []*Point{c, d} {
+		if onPlanarSegment(point, a, b) {
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// geodesicIntersections returns the point where the great circle arcs a-b and c-d meet.
+// Arcs on the same great circle are taken not to meet.
+func geodesicIntersections(a, b, c, d *Point) []*Point {
+	va, vb, vc, vd := toVector3(a), toVector3(b), toVector3(c), toVector3(d)
+	n1, n2 := va.cross(vb), vc.cross(vd)
+	line := n1.cross(n2)
+	length := math.Sqrt(line.dot(line))
+	if length < geodesicBoundaryTolerance {
+		return nil
+	}
+
+	// The great circles meet at two antipodal points, the arcs at most at one of them
+	for _, sign := range []float64{1, -1} {
+		p := vector3{sign * line.x / length, sign * line.y / length, sign * line.z / length}
+		if onArc(p, va, vb, n1) && onArc(p, vc, vd, n2) {
+			return []*Point{p.toPoint()}
+		}
+	}
+	return nil
+}
+
+// onArc returns whether p, on the great circle with normal n through a and b, lies between them.
+func onArc(p, a, b, n vector3) bool {
+	return a.cross(p).dot(n) >= -geodesicBoundaryTolerance && p.cross(b).dot(n) >= -geodesicBoundaryTolerance
+}
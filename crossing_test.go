@@ -0,0 +1,45 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossingPoints(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+
+	// Neither end is inside, but the path runs straight through
+	a, b := NewPoint(5, -5), NewPoint(5, 15)
+	assert.False(t, geofence.Inside(a))
+	assert.False(t, geofence.Inside(b))
+	assert.True(t, geofence.Crosses(a, b))
+	assert.Equal(t, []*Point{NewPoint(5, 0), NewPoint(5, 4), NewPoint(5, 6), NewPoint(5, 10)}, geofence.CrossingPoints(a, b))
+	assert.Equal(t, []*Point{NewPoint(5, 10), NewPoint(5, 6), NewPoint(5, 4), NewPoint(5, 0)}, geofence.CrossingPoints(b, a))
+
+	assert.False(t, geofence.Crosses(NewPoint(-5, -5), NewPoint(-5, 15)))
+	assert.False(t, geofence.Crosses(NewPoint(1, 1), NewPoint(2, 2)))
+	assert.Empty(t, geofence.CrossingPoints(NewPoint(1, 1), NewPoint(2, 2)))
+
+	// Through a corner, and along an edge
+	assert.Equal(t, []*Point{NewPoint(0, 0), NewPoint(4, 4)}, geofence.CrossingPoints(NewPoint(-1, -1), NewPoint(5, 5)))
+	assert.Equal(t, []*Point{NewPoint(0, 2), NewPoint(0, 8)}, geofence.CrossingPoints(NewPoint(0, 2), NewPoint(0, 8)))
+}
+
+func TestCrossingPointsGeodesic(t *testing.T) {
+	geofence := NewGeodesicGeofence([]*Point{NewPoint(-10, -10), NewPoint(-10, 10), NewPoint(10, 10), NewPoint(10, -10)})
+	points := geofence.CrossingPoints(NewPoint(0, -20), NewPoint(0, 20))
+	assert.Len(t, points, 2)
+	assert.InDelta(t, 0, points[0].Lat(), 1e-9)
+	assert.InDelta(t, -10, points[0].Lng(), 1e-9)
+	assert.InDelta(t, 0, points[1].Lat(), 1e-9)
+	assert.InDelta(t, 10, points[1].Lng(), 1e-9)
+	assert.False(t, geofence.Crosses(NewPoint(20, -20), NewPoint(20, 20)))
+
+	// The antimeridian, and a multi polygon
+	antimeridian := NewMultiGeofence([][]*Point{{NewPoint(0, 170), NewPoint(0, -170), NewPoint(10, -170), NewPoint(10, 170)}})
+	points = antimeridian.CrossingPoints(NewPoint(5, 160), NewPoint(5, -160))
+	assert.Equal(t, []*Point{NewPoint(5, 170), NewPoint(5, -170)}, points)
+}
@@ -0,0 +1,86 @@
+package geofence
+
+import (
+	"math"
+)
+
+// delaunayTriangle is a counterclockwise triangle of vertex indices, with its circumcircle.
+type delaunayTriangle struct {
+	a, b, c    int
+	cx, cy, r2 float64
+}
+
+// delaunayEdge is a directed edge between vertex indices.
+type delaunayEdge struct {
+	a, b int
+}
+
+// delaunay returns the counterclockwise triangles of the Delaunay triangulation of the distinct
+// points, by the Bowyer-Watson algorithm: each point in turn replaces the triangles whose
+// circumcircle contains it with a fan of triangles around it. Fewer than three points, or
+// points all on a line, give no triangles.
+func delaunay(xs, ys []float64) []delaunayTriangle {
+	n := len(xs)
+	if n < 3 {
+		return nil
+	}
+	minX, maxX, minY, maxY := getMin(xs), getMax(xs), getMin(ys), getMax(ys)
+	size := math.Max(maxX-minX, maxY-minY)
+	if size == 0 {
+		return nil
+	}
+
+	// A triangle around every point, whose vertices are removed at the end
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+	xs = append(append([]float64{}, xs...), midX-20*size, midX, midX+20*size)
+	ys = append(append([]float64{}, ys...), midY-20*size, midY+20*size, midY-20*size)
+	triangles := []delaunayTriangle{newDelaunayTriangle(xs, ys, n, n+2, n+1)}
+
+	for p := 0; p < n; p++ {
+		// Remove the triangles whose circumcircle contains p, keeping the edges around the cavity
+		edges := make(map[delaunayEdge]bool)
+		kept := triangles[:0]
+		for _, triangle := range triangles {
+			dx, dy := xs[p]-triangle.cx, ys[p]-triangle.cy
+			if dx*dx+dy*dy >= triangle.r2 {
+				kept = append(kept, triangle)
+				continue
+			}
+			for _, edge := range []delaunayEdge{{triangle.a, triangle.b}, {triangle.b, triangle.c}, {triangle.c, triangle.a}} {
+				if reverse := (delaunayEdge{edge.b, edge.a}); edges[reverse] {
+					// Shared by two removed triangles, so inside the cavity
+					delete(edges, reverse)
+					continue
+				}
+				edges[edge] = true
+			}
+		}
+		triangles = kept
+		for edge := range edges {
+			triangles = append(triangles, newDelaunayTriangle(xs, ys, edge.a, edge.b, p))
+		}
+	}
+
+	result := triangles[:0]
+	for _, triangle := range triangles {
+		if triangle.a < n && triangle.b < n && triangle.c < n && !math.IsInf(triangle.r2, 1) {
+			result = append(result, triangle)
+		}
+	}
+	return result
+}
+
+// newDelaunayTriangle returns the triangle a, b, c, which must be counterclockwise, with its
+// circumcircle. A triangle with no area has an infinite circumcircle.
+func newDelaunayTriangle(xs, ys []float64, a, b, c int) delaunayTriangle {
+	ax, ay := xs[a], ys[a]
+	bx, by := xs[b]-ax, ys[b]-ay
+	cx, cy := xs[c]-ax, ys[c]-ay
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		return delaunayTriangle{a: a, b: b, c: c, r2: math.Inf(1)}
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux, uy := (cy*b2-by*c2)/d, (bx*c2-cx*b2)/d
+	return delaunayTriangle{a: a, b: b, c: c, cx: ax + ux, cy: ay + uy, r2: ux*ux + uy*uy}
+}
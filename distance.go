@@ -0,0 +1,270 @@
+package geofence
+
+import (
+	"math"
+)
+
+// DistanceToBoundary returns the distance from the point to the nearest edge of the
+// geofence, including the edges of its holes. The distance is negative when the point
+// is inside the geofence and positive when it is outside. Geodesic geofences return
+// meters along the Earth's surface, WithProjection geofences meters on the plane, and other
+// planar geofences return coordinate units.
+func (geofence *Geofence) DistanceToBoundary(point *Point) float64 {
+	distance := math.Inf(1)
+	for _, part := range geofence.polygons() {
+		distance = math.Min(distance, part.edgeDistance(part.wrapPoint(point)))
+	}
+
+	if geofence.Inside(point) {
+		return -distance
+	}
+	return distance
+}
+
+// DistanceToBoundaryMeters is DistanceToBoundary in meters for every kind of geofence, negative
+// inside and positive outside, e.g. for "you are 200m from the restricted zone" warnings. The
+// edges of a geofence that is neither geodesic nor projected are straight in lat/lng, so the
+// nearest point on each is found on a plane scaled to the point's latitude, and the distance to
+// it measured along the Earth's surface. WithPlanar geofences are taken to be in meters.
+func (geofence *Geofence) DistanceToBoundaryMeters(point *Point) float64 {
+	distance := math.Inf(1)
+	for _, part := range geofence.polygons() {
+		distance = math.Min(distance, part.edgeMeters(part.wrapPoint(point)))
+	}
+
+	if geofence.Inside(point) {
+		return -distance
+	}
+	return distance
+}
+
+// InsideWithin checks whether the point is inside the geofence or no more than marginMeters
+// outside it, so a fix whose GPS accuracy circle reaches the fence counts as inside. A negative
+// margin instead requires the point to be at least that deep inside, e.g. -50 for "certainly
+// inside". Distances are as for DistanceToBoundaryMeters.
+func (geofence *Geofence) InsideWithin(point *Point, marginMeters float64) bool {
+	inside := geofence.Inside(point)
+	if marginMeters == 0 || inside == (marginMeters > 0) {
+		return inside
+	}
+	distance := math.Inf(1)
+	for _, part := range geofence.polygons() {
+		distance = math.Min(distance, part.edgeMeters(part.wrapPoint(point)))
+	}
+	if marginMeters > 0 {
+		// Outside, but perhaps within the margin
+		return distance <= marginMeters
+	}
+	// Inside, but perhaps not deep enough
+	return distance >= -marginMeters
+}
+
+// boundsMeters returns a lower bound of DistanceToBoundaryMeters for points outside the
+// geofence's bounding box, from the distance to the box, and 0 for points inside it. Projected
+// geofences, whose bounding box is on the plane, always return 0.
+func (geofence *Geofence) boundsMeters(point *Point) float64 {
+	if len(geofence.parts) > 0 {
+		distance := math.Inf(1)
+		for _, part := range geofence.parts {
+			if !part.empty() {
+				distance = math.Min(distance, part.boundsMeters(point))
+			}
+		}
+		return distance
+	}
+	if geofence.empty() {
+		return math.Inf(1)
+	}
+	if geofence.projection != nil {
+		return 0
+	}
+
+	if geofence.planar {
+		point = geofence.wrapPoint(point)
+		dX := math.Max(0, math.Max(geofence.minX-point.Lat(), point.Lat()-geofence.maxX))
+		dY := math.Max(0, math.Max(geofence.minY-point.Lng(), point.Lng()-geofence.maxY))
+		return math.Hypot(dX, dY)
+	}
+	return boxMeters(point, geofence.minX, geofence.maxX, geofence.minY, geofence.maxY)
+}
+
+// boxMeters returns a lower bound of the distance in meters from the point to the lat/lng box,
+// and 0 for points inside it. The box's longitudes may run past ±180°, e.g. 170 to 190 for a
+// box crossing the antimeridian.
+func boxMeters(point *Point, minLat, maxLat, minLng, maxLng float64) float64 {
+	dX := math.Max(0, math.Max(minLat-point.Lat(), point.Lat()-maxLat))
+	dY := 0.0
+	if lng := point.Lng(); !(lng >= minLng && lng <= maxLng) && !(lng+360 >= minLng && lng+360 <= maxLng) && !(lng-360 >= minLng && lng-360 <= maxLng) {
+		// The box may be nearer the other way round the globe
+		dY = math.Min(math.Mod(minLng-lng+720, 360), math.Mod(lng-maxLng+720, 360))
+	}
+
+	// hav(d) = hav(dLat) + cos(lat1)cos(lat2)hav(dLng), with each term at its least over the box
+	radians := math.Pi / 180.0
+	cosLat := math.Cos(point.Lat()*radians) * math.Min(math.Cos(minLat*radians), math.Cos(maxLat*radians))
+	h := math.Pow(math.Sin(dX*radians/2), 2) + math.Max(0, cosLat)*math.Pow(math.Sin(dY*radians/2), 2)
+	return 2 * math.Asin(math.Sqrt(math.Min(1, h))) * EARTH_RADIUS * 1000
+}
+
+// edgeMeters returns the unsigned distance in meters from the wrapped point to the nearest
+// edge of a single polygon geofence.
+func (geofence *Geofence) edgeMeters(point *Point) float64 {
+	if geofence.geodesic || geofence.planar || geofence.projection != nil {
+		return geofence.edgeDistance(point)
+	}
+	distance := math.Inf(1)
+	for _, ring := range geofence.rings() {
+		for i := 0; i < len(ring); i++ {
+			distance = math.Min(distance, localSegmentDistance(point, ring[i], ring[(i+1)%len(ring)]))
+		}
+	}
+	return distance
+}
+
+// localSegmentDistance returns the distance in meters from p to the segment a-b, which is
+// straight in lat/lng.
+func localSegmentDistance(p, a, b *Point) float64 {
+	return p.DistanceTo(localNearestPoint(p, a, b))
+}
+
+// localNearestPoint returns the point of the segment a-b, which is straight in lat/lng, nearest
+// to p. Degrees of lng are shortened by the cosine of p's latitude, which is accurate for
+// segments up to a few hundred kilometers from p.
+func localNearestPoint(p, a, b *Point) *Point {
+	scale := math.Cos(p.Lat() * math.Pi / 180.0)
+	abX, abY := b.Lat()-a.Lat(), (b.Lng()-a.Lng())*scale
+	apX, apY := p.Lat()-a.Lat(), (p.Lng()-a.Lng())*scale
+	t := 0.0
+	if lengthSquared := abX*abX + abY*abY; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, (apX*abX+apY*abY)/lengthSquared))
+	}
+	return NewPoint(a.Lat()+t*(b.Lat()-a.Lat()), a.Lng()+t*(b.Lng()-a.Lng()))
+}
+
+// NearestBoundaryPoint returns the point on the edges of the geofence, including the edges of
+// its holes, nearest to the given point, e.g. the closest exit from a zone or a noisy fix snapped
+// onto a corridor. Edges follow the geofence's mode: great circles for geodesic geofences,
+// straight lines on the plane for planar and projected ones and straight lines in lat/lng
+// otherwise. A geofence without vertices returns nil.
+func (geofence *Geofence) NearestBoundaryPoint(point *Point) *Point {
+	var nearest *Point
+	best := math.Inf(1)
+	for _, part := range geofence.polygons() {
+		wrapped := part.wrapPoint(point)
+		for _, ring := range part.rings() {
+			for i := 0; i < len(ring); i++ {
+				candidate, distance := part.nearestSegmentPoint(wrapped, ring[i], ring[(i+1)%len(ring)])
+				if distance < best {
+					nearest, best = part.unwrapPoint(candidate), distance
+				}
+			}
+		}
+	}
+	return nearest
+}
+
+// nearestSegmentPoint returns the point of the edge a-b nearest to the wrapped point p, and its
+// distance in meters, or in coordinate units for planar and projected geofences.
+func (geofence *Geofence) nearestSegmentPoint(p, a, b *Point) (*Point, float64) {
+	var nearest *Point
+	switch {
+	case geofence.planar || geofence.projection != nil:
+		nearest = planarNearestPoint(p, a, b)
+		return nearest, math.Hypot(p.X()-nearest.X(), p.Y()-nearest.Y())
+	case geofence.geodesic:
+		nearest = geodesicNearestPoint(p, a, b)
+	default:
+		nearest = localNearestPoint(p, a, b)
+	}
+	return nearest, p.DistanceTo(nearest)
+}
+
+// planarNearestPoint returns the point of the segment a-b nearest to p on a flat plane.
+func planarNearestPoint(p, a, b *Point) *Point {
+	ab := vectorDifference(b, a)
+	ap := vectorDifference(p, a)
+	t := 0.0
+	if lengthSquared := ab.Lat()*ab.Lat() + ab.Lng()*ab.Lng(); lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, (ap.Lat()*ab.Lat()+ap.Lng()*ab.Lng())/lengthSquared))
+	}
+	return NewPoint(a.Lat()+t*ab.Lat(), a.Lng()+t*ab.Lng())
+}
+
+// geodesicNearestPoint returns the point of the great circle arc a-b nearest to p, which is
+// p's foot on the arc's great circle when that lies between a and b, or else the nearer end.
+func geodesicNearestPoint(p, a, b *Point) *Point {
+	va, vb, vp := toVector3(a), toVector3(b), toVector3(p)
+	n := va.cross(vb)
+	if n.dot(n) > 0 {
+		// Remove p's component along the normal of the great circle
+		d := vp.dot(n) / n.dot(n)
+		foot := vector3{vp.x - d*n.x, vp.y - d*n.y, vp.z - d*n.z}
+		if foot.dot(foot) > 0 && va.cross(foot).dot(n) >= 0 && foot.cross(vb).dot(n) >= 0 {
+			return foot.toPoint()
+		}
+	}
+	if p.DistanceTo(a) <= p.DistanceTo(b) {
+		return NewPoint(a.Lat(), a.Lng())
+	}
+	return NewPoint(b.Lat(), b.Lng())
+}
+
+// edgeDistance returns the unsigned distance from the wrapped point to the nearest edge
+// of a single polygon geofence.
+func (geofence *Geofence) edgeDistance(point *Point) float64 {
+	distance := math.Inf(1)
+	for _, ring := range geofence.rings() {
+		for i := 0; i < len(ring); i++ {
+			start, end := ring[i], ring[(i+1)%len(ring)]
+			if geofence.geodesic {
+				distance = math.Min(distance, geodesicSegmentDistance(point, start, end))
+			} else {
+				distance = math.Min(distance, planarSegmentDistance(point, start, end))
+			}
+		}
+	}
+	return distance
+}
+
+// rings returns the outer ring followed by the holes.
+func (geofence *Geofence) rings() [][]*Point {
+	return append([][]*Point{geofence.vertices}, geofence.holes...)
+}
+
+// planarSegmentDistance returns the euclidean distance from p to the segment a-b.
+func planarSegmentDistance(p, a, b *Point) float64 {
+	ab := vectorDifference(b, a)
+	ap := vectorDifference(p, a)
+	lengthSquared := ab.Lat()*ab.Lat() + ab.Lng()*ab.Lng()
+	if lengthSquared == 0 {
+		return math.Hypot(ap.Lat(), ap.Lng())
+	}
+
+	// Project p onto the segment, clamped to its end points
+	t := math.Max(0, math.Min(1, (ap.Lat()*ab.Lat()+ap.Lng()*ab.Lng())/lengthSquared))
+	return math.Hypot(ap.Lat()-t*ab.Lat(), ap.Lng()-t*ab.Lng())
+}
+
+// geodesicSegmentDistance returns the distance in meters from p to the great circle arc a-b,
+// using the cross-track and along-track distances.
+// Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
+func geodesicSegmentDistance(p, a, b *Point) float64 {
+	d13 := a.GreatCircleDistance(p) / EARTH_RADIUS
+	d12 := a.GreatCircleDistance(b) / EARTH_RADIUS
+	if d12 == 0 {
+		return d13 * EARTH_RADIUS * 1000
+	}
+
+	theta := (a.BearingTo(p) - a.BearingTo(b)) * math.Pi / 180.0
+	if math.Cos(theta) < 0 {
+		// p is behind the start of the arc
+		return d13 * EARTH_RADIUS * 1000
+	}
+
+	dxt := math.Asin(math.Sin(d13) * math.Sin(theta))
+	dat := math.Acos(math.Max(-1, math.Min(1, math.Cos(d13)/math.Cos(dxt))))
+	if dat > d12 {
+		return b.GreatCircleDistance(p) * 1000
+	}
+	return math.Abs(dxt) * EARTH_RADIUS * 1000
+}
@@ -0,0 +1,114 @@
+package geofence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceToBoundary(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+
+	assert.InDelta(t, -1, geofence.DistanceToBoundary(NewPoint(1, 5)), 1e-9)
+	assert.InDelta(t, -2, geofence.DistanceToBoundary(NewPoint(2, 5)), 1e-9)
+	assert.InDelta(t, 1, geofence.DistanceToBoundary(NewPoint(5, 5)), 1e-9)
+	assert.InDelta(t, 3, geofence.DistanceToBoundary(NewPoint(5, 13)), 1e-9)
+	assert.InDelta(t, 5, geofence.DistanceToBoundary(NewPoint(13, 14)), 1e-9)
+	assert.Equal(t, 0.0, geofence.DistanceToBoundary(NewPoint(0, 5)))
+}
+
+func TestDistanceToBoundaryGeodesic(t *testing.T) {
+	geofence := NewGeodesicGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)})
+
+	// A tenth of a degree along the equator or a meridian is about 11.1km
+	assert.InDelta(t, -11119.5, geofence.DistanceToBoundary(NewPoint(0.1, 0.5)), 1)
+	assert.InDelta(t, 11119.5, geofence.DistanceToBoundary(NewPoint(-0.1, 0.5)), 1)
+	assert.InDelta(t, NewPoint(1, 1).GreatCircleDistance(NewPoint(1.1, 1.1))*1000, geofence.DistanceToBoundary(NewPoint(1.1, 1.1)), 1e-6)
+	assert.InDelta(t, 0, geofence.DistanceToBoundary(NewPoint(0, 0.5)), 1e-6)
+}
+
+func TestDistanceToBoundaryMeters(t *testing.T) {
+	// A degree square at 60°N, where a degree of lng is about half as long as a degree of lat
+	geofence := NewGeofence([]*Point{NewPoint(60, 10), NewPoint(60, 11), NewPoint(61, 11), NewPoint(61, 10)})
+	lngDegree := NewPoint(60.5, 10).DistanceTo(NewPoint(60.5, 11))
+	assert.InDelta(t, 54750, lngDegree, 100)
+
+	assert.InDelta(t, -0.1*lngDegree, geofence.DistanceToBoundaryMeters(NewPoint(60.5, 10.9)), 10)
+	assert.InDelta(t, 0.1*lngDegree, geofence.DistanceToBoundaryMeters(NewPoint(60.5, 11.1)), 10)
+	assert.InDelta(t, -11119.5, geofence.DistanceToBoundaryMeters(NewPoint(60.9, 10.5)), 1)
+	assert.InDelta(t, NewPoint(61, 11).DistanceTo(NewPoint(61.1, 11.1)), geofence.DistanceToBoundaryMeters(NewPoint(61.1, 11.1)), 1e-6)
+	assert.InDelta(t, 0, geofence.DistanceToBoundaryMeters(NewPoint(60, 10.5)), 1e-6)
+
+	// Geodesic geofences already return meters
+	geodesic := NewGeodesicGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)})
+	assert.Equal(t, geodesic.DistanceToBoundary(NewPoint(0.1, 0.5)), geodesic.DistanceToBoundaryMeters(NewPoint(0.1, 0.5)))
+
+	// Across the antimeridian
+	fiji := NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)})
+	assert.InDelta(t, -NewPoint(-17, -179).DistanceTo(NewPoint(-17, -178)), fiji.DistanceToBoundaryMeters(NewPoint(-17, -179)), 100)
+
+	// Multi-polygon geofences use the nearest polygon
+	multi := NewMultiGeofence([][]*Point{
+		{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)},
+		{NewPoint(0, 3), NewPoint(0, 4), NewPoint(1, 4), NewPoint(1, 3)},
+	})
+	assert.InDelta(t, NewPoint(0.5, 2.5).DistanceTo(NewPoint(0.5, 3)), multi.DistanceToBoundaryMeters(NewPoint(0.5, 2.5)), 1)
+}
+
+func TestInsideWithin(t *testing.T) {
+	geofence := NewGeofence([]*Point{NewPoint(60, 10), NewPoint(60, 11), NewPoint(61, 11), NewPoint(61, 10)})
+	// About 5.5km inside and outside the east edge
+	inside, outside := NewPoint(60.5, 10.9), NewPoint(60.5, 11.1)
+
+	assert.True(t, geofence.InsideWithin(inside, 0))
+	assert.False(t, geofence.InsideWithin(outside, 0))
+	assert.True(t, geofence.InsideWithin(outside, 6000))
+	assert.False(t, geofence.InsideWithin(outside, 5000))
+	assert.True(t, geofence.InsideWithin(inside, 1))
+
+	assert.True(t, geofence.InsideWithin(inside, -5000))
+	assert.False(t, geofence.InsideWithin(inside, -6000))
+	assert.False(t, geofence.InsideWithin(outside, -1))
+}
+
+func TestNearestBoundaryPoint(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+
+	assertPoint := func(expected, actual *Point, delta float64) {
+		t.Helper()
+		assert.InDelta(t, expected.Lat(), actual.Lat(), delta)
+		assert.InDelta(t, expected.Lng(), actual.Lng(), delta)
+	}
+	assertPoint(NewPoint(0, 1.5), geofence.NearestBoundaryPoint(NewPoint(1, 1.5)), 1e-9)
+	assertPoint(NewPoint(4, 5), geofence.NearestBoundaryPoint(NewPoint(3, 5)), 1e-9)
+	assertPoint(NewPoint(10, 10), geofence.NearestBoundaryPoint(NewPoint(12, 13)), 1e-9)
+	assertPoint(NewPoint(0, 5), geofence.NearestBoundaryPoint(NewPoint(0, 5)), 1e-9)
+	assert.Nil(t, NewGeofence(nil).NearestBoundaryPoint(NewPoint(0, 0)))
+
+	// The nearest point is at the distance given by DistanceToBoundaryMeters
+	for _, point := range []*Point{NewPoint(1, 1.5), NewPoint(5, 5.2), NewPoint(-3, 4), NewPoint(11, 2)} {
+		assert.InDelta(t, math.Abs(geofence.DistanceToBoundaryMeters(point)), point.DistanceTo(geofence.NearestBoundaryPoint(point)), 1e-6)
+	}
+
+	// Geodesic edges bulge towards the pole, so the nearest point of the north edge of a
+	// geodesic fence between two points at 60°N is north of 60°
+	geodesic := NewGeodesicGeofence([]*Point{NewPoint(50, 0), NewPoint(50, 20), NewPoint(60, 20), NewPoint(60, 0)})
+	nearest := geodesic.NearestBoundaryPoint(NewPoint(62, 10))
+	assert.Greater(t, nearest.Lat(), 60.3)
+	assert.InDelta(t, 10, nearest.Lng(), 1e-6)
+	assert.InDelta(t, geodesic.DistanceToBoundary(NewPoint(62, 10)), NewPoint(62, 10).DistanceTo(nearest), 1e-3)
+	assertPoint(NewPoint(60, 20), geodesic.NearestBoundaryPoint(NewPoint(65, 30)), 1e-9)
+
+	// Points are returned in the range -180 to 180 across the antimeridian
+	fiji := NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)})
+	assertPoint(NewPoint(-17, -178), fiji.NearestBoundaryPoint(NewPoint(-17, -179)), 1e-9)
+
+	// Planar geofences use the grid
+	shed := NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 40), NewPointXY(0, 40)}, WithPlanar())
+	assertPoint(NewPointXY(10, 20), shed.NearestBoundaryPoint(NewPointXY(8, 20)), 1e-9)
+}
@@ -0,0 +1,227 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kinds of token in a typed coordinate.
+const (
+	dmsNumber = iota
+	dmsDegrees
+	dmsMinutes
+	dmsSeconds
+	dmsHemisphere
+	dmsSeparator
+)
+
+type dmsToken struct {
+	kind   int
+	text   string
+	number float64
+}
+
+// ParsePoint parses a point typed by a person, with the latitude and longitude in decimal
+// degrees, degrees and decimal minutes, or degrees, minutes and seconds, e.g.
+// "51°30'26\"N 0°7'39\"W", "N 51 30.433, W 0 7.65", "51.5072N 0.1275W" or "51.5072, -0.1275".
+// Hemisphere letters may come before or after each coordinate, and put the longitude first when
+// it is given first; without them the latitude comes first and negative values are south or
+// west. Degrees, minutes and seconds may be marked with °, ' and " or their typographic
+// variants, or left unmarked and separated by spaces. Decimals must use a point, since a comma
+// separates the coordinates.
+func ParsePoint(text string) (*Point, error) {
+	tokens, err := dmsTokens(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse point %q: %v", text, err)
+	}
+	first, second, err := splitDMSTokens(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse point %q: %v", text, err)
+	}
+	lat, latAxis, err := parseDMSCoordinate(first)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse point %q: %v", text, err)
+	}
+	lng, lngAxis, err := parseDMSCoordinate(second)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse point %q: %v", text, err)
+	}
+	if latAxis == 'E' || lngAxis == 'N' {
+		lat, lng, latAxis, lngAxis = lng, lat, lngAxis, latAxis
+	}
+	if latAxis == 'E' || lngAxis == 'N' {
+		return nil, fmt.Errorf("unable to parse point %q: both coordinates are in the same direction", text)
+	}
+	if math.Abs(lat) > 90 || math.Abs(lng) > 180 {
+		return nil, fmt.Errorf("unable to parse point %q: latitude %v or longitude %v is out of range", text, lat, lng)
+	}
+	return NewPoint(lat, lng), nil
+}
+
+// dmsTokens splits the text into numbers, unit marks, hemisphere letters and separators.
+func dmsTokens(text string) ([]dmsToken, error) {
+	var tokens []dmsToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == ',' || r == ';' || r == '/':
+			tokens = append(tokens, dmsToken{kind: dmsSeparator})
+		case r == '°' || r == 'º' || r == '˚':
+			tokens = append(tokens, dmsToken{kind: dmsDegrees})
+		case r == '\'' || r == '′' || r == '’':
+			// Two apostrophes are often typed for seconds
+			if i+1 < len(runes) && runes[i+1] == r {
+				tokens = append(tokens, dmsToken{kind: dmsSeconds})
+				i++
+			} else {
+				tokens = append(tokens, dmsToken{kind: dmsMinutes})
+			}
+		case r == '"' || r == '″' || r == '”':
+			tokens = append(tokens, dmsToken{kind: dmsSeconds})
+		case strings.ContainsRune("NSEWnsew", r):
+			tokens = append(tokens, dmsToken{kind: dmsHemisphere, text: strings.ToUpper(string(r))})
+		case r == '-' || r == '+' || r == '.' || (r >= '0' && r <= '9'):
+			start := i
+			for i+1 < len(runes) && (runes[i+1] == '.' || (runes[i+1] >= '0' && runes[i+1] <= '9')) {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(runes[start:i+1]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(runes[start:i+1]))
+			}
+			tokens = append(tokens, dmsToken{kind: dmsNumber, text: string(runes[start : i+1]), number: number})
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// splitDMSTokens returns the tokens of each coordinate: either side of a separator, after a
+// hemisphere letter that ends the first coordinate, before one that starts the second, or after
+// the seconds of the first, and failing all those half the numbers each.
+func splitDMSTokens(tokens []dmsToken) ([]dmsToken, []dmsToken, error) {
+	var separators []int
+	for i, token := range tokens {
+		if token.kind == dmsSeparator {
+			separators = append(separators, i)
+		}
+	}
+	switch {
+	case len(separators) > 1:
+		return nil, nil, fmt.Errorf("expected one separator between the coordinates")
+	case len(separators) == 1:
+		return tokens[:separators[0]], tokens[separators[0]+1:], nil
+	}
+
+	seenNumber := false
+	for i, token := range tokens {
+		switch {
+		case token.kind == dmsNumber:
+			seenNumber = true
+		case token.kind == dmsHemisphere && seenNumber:
+			// A suffix closes the first coordinate, unless the text is prefixed, e.g. "N 51 W 0"
+			if tokens[0].kind == dmsHemisphere {
+				return tokens[:i], tokens[i:], nil
+			}
+			return tokens[:i+1], tokens[i+1:], nil
+		case token.kind == dmsSeconds && i+1 < len(tokens) && tokens[i+1].kind != dmsHemisphere:
+			return tokens[:i+1], tokens[i+1:], nil
+		case token.kind == dmsDegrees && i > 0 && countDMSKind(tokens[:i], dmsDegrees) == 1:
+			// A second degrees mark starts the second coordinate with its number
+			return tokens[:i-1], tokens[i-1:], nil
+		}
+	}
+
+	// Unmarked numbers are split evenly, e.g. "51 30 26 0 7 39"
+	numbers := countDMSKind(tokens, dmsNumber)
+	if numbers%2 == 1 || countDMSKind(tokens, dmsDegrees)+countDMSKind(tokens, dmsMinutes) > 0 {
+		return nil, nil, fmt.Errorf("unable to tell the latitude from the longitude")
+	}
+	for i, count := 0, 0; i < len(tokens); i++ {
+		if tokens[i].kind == dmsNumber {
+			if count == numbers/2 {
+				return tokens[:i], tokens[i:], nil
+			}
+			count++
+		}
+	}
+	return nil, nil, fmt.Errorf("expected a latitude and a longitude")
+}
+
+func countDMSKind(tokens []dmsToken, kind int) int {
+	count := 0
+	for _, token := range tokens {
+		if token.kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// parseDMSCoordinate returns the value in degrees of one coordinate's tokens, and 'N' or 'E' for
+// the axis its hemisphere letter gives, if any.
+func parseDMSCoordinate(tokens []dmsToken) (value float64, axis byte, err error) {
+	var parts []float64
+	var units []int
+	sign := 1.0
+	for i, token := range tokens {
+		switch token.kind {
+		case dmsHemisphere:
+			if axis != 0 {
+				return 0, 0, fmt.Errorf("coordinate has more than one hemisphere letter")
+			}
+			axis = 'N'
+			if token.text == "E" || token.text == "W" {
+				axis = 'E'
+			}
+			if token.text == "S" || token.text == "W" {
+				sign = -1
+			}
+		case dmsNumber:
+			if len(parts) == 3 {
+				return 0, 0, fmt.Errorf("coordinate has more than degrees, minutes and seconds")
+			}
+			if len(parts) > 0 && (strings.ContainsAny(token.text, "+-") || parts[len(parts)-1] != math.Trunc(parts[len(parts)-1])) {
+				return 0, 0, fmt.Errorf("invalid %q after %v", token.text, parts[len(parts)-1])
+			}
+			parts = append(parts, token.number)
+			units = append(units, dmsDegrees+len(parts)-1)
+		default:
+			// A mark names the unit of the number before it, in order
+			if i == 0 || tokens[i-1].kind != dmsNumber || token.kind < units[len(units)-1] {
+				return 0, 0, fmt.Errorf("misplaced degrees, minutes or seconds mark")
+			}
+			units[len(units)-1] = token.kind
+		}
+	}
+	if len(parts) == 0 {
+		return 0, 0, fmt.Errorf("coordinate has no degrees")
+	}
+	for i := 1; i < len(units); i++ {
+		if units[i] <= units[i-1] {
+			return 0, 0, fmt.Errorf("misplaced degrees, minutes or seconds mark")
+		}
+	}
+
+	negative := math.Signbit(parts[0])
+	if negative && sign < 0 {
+		return 0, 0, fmt.Errorf("coordinate has both a minus sign and an S or W")
+	}
+	for i, part := range parts {
+		scale := math.Pow(60, float64(units[i]-dmsDegrees))
+		if units[i] != dmsDegrees && part >= 60 {
+			return 0, 0, fmt.Errorf("minutes and seconds must be less than 60, got %v", part)
+		}
+		value += math.Abs(part) / scale
+	}
+	if negative {
+		sign = -1
+	}
+	return sign * value, axis, nil
+}
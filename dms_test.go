@@ -0,0 +1,63 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePoint(t *testing.T) {
+	london := 51.0 + 30.0/60 + 26.0/3600
+	londonLng := -(7.0/60 + 39.0/3600)
+	for text, want := range map[string][2]float64{
+		`51°30'26"N 0°7'39"W`:        {london, londonLng},
+		`51° 30' 26" N, 0° 7' 39" W`: {london, londonLng},
+		`51º30′26″N 000º07′39″W`:     {london, londonLng},
+		`51°30'26''N 0°7'39''W`:      {london, londonLng},
+		`N51°30'26" W0°7'39"`:        {london, londonLng},
+		`0°7'39"W 51°30'26"N`:        {london, londonLng},
+		`51 30 26 N 0 7 39 W`:        {london, londonLng},
+		`51 30 26 0 7 39`:            {london, -londonLng},
+		`51°30'26" -0°7'39"`:         {london, londonLng},
+		`N 51 30.5, W 0 7.5`:         {51.5 + 0.5/60, -0.125},
+		`51°30.5'N 0°7.5'W`:          {51.5 + 0.5/60, -0.125},
+		`51.5072N 0.1275W`:           {51.5072, -0.1275},
+		`51.5072, -0.1275`:           {51.5072, -0.1275},
+		` -33.8568 151.2153 `:        {-33.8568, 151.2153},
+		`33.8568s; 151.2153e`:        {-33.8568, 151.2153},
+		`151.2153 E / 33.8568 S`:     {-33.8568, 151.2153},
+		`+40.7128,-74.0060`:          {40.7128, -74.006},
+		`40°42'46.1"N 74°00'21.6"W`:  {40 + 42.0/60 + 46.1/3600, -(74 + 21.6/3600)},
+		`90 N 180 E`:                 {90, 180},
+	} {
+		point, err := ParsePoint(text)
+		if assert.NoError(t, err, text) {
+			assert.InDelta(t, want[0], point.Lat(), 1e-9, text)
+			assert.InDelta(t, want[1], point.Lng(), 1e-9, text)
+		}
+	}
+
+	for _, bad := range []string{
+		``,
+		`51.5`,
+		`51.5, 0.1, 2`,
+		`51 30 0`,
+		`51°30'26"N 0°7'39"N`,
+		`51°30'26"E 0°7'39"W`,
+		`51°61'N 0°7'W`,
+		`51°30'70"N 0°7'W`,
+		`-51°30'S 0°7'W`,
+		`51.5°30'N 0°7'W`,
+		`51°30'-5"N 0°7'W`,
+		`'30°51 N 0 W`,
+		`51NN 0W`,
+		`91 N 0 E`,
+		`0 N 181 E`,
+		`51.5.1, 0`,
+		`51.5; 0.1x`,
+		`51 30 26 1 N 0 W`,
+	} {
+		_, err := ParsePoint(bad)
+		assert.Error(t, err, bad)
+	}
+}
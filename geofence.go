@@ -1,70 +1,220 @@
 package geofence
 
+import (
+	"math"
+)
+
 // Geofence is a struct for efficient search whether a point is in polygon
 type Geofence struct {
-	vertices    []*Point
-	tiles       map[float64]byte
-	granularity int64
-	minX        float64
-	maxX        float64
-	minY        float64
-	maxY        float64
-	tileWidth   float64
-	tileHeight  float64
-	minTileX    float64
-	maxTileX    float64
-	minTileY    float64
-	maxTileY    float64
+	vertices          []*Point
+	holes             [][]*Point
+	geodesic          bool
+	wrapLng           bool
+	boundary          Boundary
+	containment       Containment
+	exact             bool
+	densifyStep       float64
+	simplifyTolerance float64
+	copyPoints        bool
+	order             CoordinateOrder
+	planar            bool
+	projection        Projection
+	localProj         bool
+	rect              bool
+	altitudeRange     bool
+	minAltitude       float64
+	maxAltitude       float64
+	parts             []*Geofence
+	partTiles         map[int64][]int
+	polygon           *Polygon
+	tiles             map[int64]byte
+	buckets           map[int64]*tileBucket
+	granularityX      int64
+	granularityY      int64
+	autoGrid          bool
+	tileMeters        float64
+	refinement        int
+	minX              float64
+	maxX              float64
+	minY              float64
+	maxY              float64
+	tileWidth         float64
+	tileHeight        float64
+	minTileX          float64
+	maxTileX          float64
+	minTileY          float64
+	maxTileY          float64
 }
 
 const (
-	TILE_IN  = 0x01
-	TILE_OUT = 0x02
-	TILE_EITHER   = 0x03
+	TILE_IN     = 0x01
+	TILE_OUT    = 0x02
+	TILE_EITHER = 0x03
 )
 
 const defaultGranularity = 20
 
-// NewGeofence is the construct for Geofence, vertices: {{(1,2),(2,3)}, {(1,0)}}.
-// 1st array contains polygon vertices. 2nd array contains holes.
-func NewGeofence(points []*Point, args ...interface{}) *Geofence {
-	geofence := &Geofence{}
-	if len(args) > 0 {
-		geofence.granularity = args[0].(int64)
-	} else {
-		geofence.granularity = defaultGranularity
+// NewGeofence is the construct for Geofence, points: {(1,2),(2,3),(1,0)}.
+// Options such as WithGranularity configure the tile grid. An invalid option is
+// ignored and its default is kept.
+func NewGeofence(points []*Point, opts ...Option) *Geofence {
+	geofence, _ := newGeofence(points, nil, opts)
+	return geofence
+}
+
+// NewGeofenceWithHoles is the construct for a Geofence with holes, outer: {(1,2),(2,3),(1,0)},
+// holes: {{(1.2,2),(1.5,2.2),(1.3,1.8)}}. Points inside a hole are outside the geofence,
+// and a hole nested inside another hole is inside the geofence again.
+func NewGeofenceWithHoles(outer []*Point, holes [][]*Point, opts ...Option) *Geofence {
+	geofence, _ := newGeofence(outer, holes, opts)
+	return geofence
+}
+
+// NewGeodesicGeofence is the construct for a Geofence over real lat/lng coordinates, where
+// each edge is the great circle arc between its vertices rather than a straight line in the
+// lat/lng plane. It is shorthand for NewGeofence(points, WithGeodesic()).
+func NewGeodesicGeofence(points []*Point, opts ...Option) *Geofence {
+	return NewGeofence(points, append([]Option{WithGeodesic()}, opts...)...)
+}
+
+// newGeofence builds the geofence and returns it along with the first option error, if any.
+func newGeofence(outer []*Point, holes [][]*Point, opts []Option) (*Geofence, error) {
+	geofence := &Geofence{granularityX: defaultGranularity, granularityY: defaultGranularity}
+	err := geofence.applyOptions(opts)
+	holes = append(append([][]*Point{}, holes...), geofence.holes...)
+	geofence.holes = nil
+	if geofence.copyPoints {
+		copies := make(map[*Point]*Point)
+		outer, holes = clonePoints(outer, copies), cloneRings(holes, copies)
+	}
+	if geofence.order != 0 && !geofence.planar {
+		outer, holes = geofence.orderRings(outer, holes)
+		if rangeErr := validateGeographic(outer, holes); err == nil {
+			err = rangeErr
+		}
+	}
+	if geofence.simplifyTolerance > 0 {
+		outer, holes = geofence.simplifyRings(outer, holes)
+	}
+	if geofence.densifyStep > 0 && !geofence.geodesic && !geofence.planar {
+		outer = densifyGeodesic(outer, geofence.densifyStep)
+		for i, hole := range holes {
+			holes[i] = densifyGeodesic(hole, geofence.densifyStep)
+		}
+	}
+	if geofence.localProj && len(outer) > 0 {
+		yVertices := getYVertices(outer)
+		geofence.projection = TransverseMercator{CentralMeridian: (getMin(yVertices) + getMax(yVertices)) / 2}
+	}
+	geofence.wrapLng = !geofence.planar && geofence.projection == nil && crossesAntimeridian(outer)
+	geofence.vertices = geofence.wrapRing(outer)
+	for _, hole := range holes {
+		geofence.holes = append(geofence.holes, geofence.wrapRing(hole))
 	}
-	geofence.vertices = points
-	geofence.tiles = make(map[float64]byte)
+	geofence.tiles = make(map[int64]byte)
 
 	geofence.setInclusionTiles()
-	return geofence
+	return geofence, err
 }
 
-// Inside checks whether a given point is inside the geofence
+// Inside checks whether a given point is inside the geofence. Points exactly on an edge
+// or vertex are inside unless the geofence was built with WithBoundary(Exclusive).
+// Inside only reads the geofence, so it is safe for concurrent use once construction has completed.
 func (geofence *Geofence) Inside(point *Point) bool {
+	if geofence.altitudeRange && !geofence.insideAltitude(point) {
+		return false
+	}
+	if len(geofence.parts) > 0 {
+		return geofence.insideParts(point)
+	}
+	point = geofence.wrapPoint(point)
+
 	// Bbox check first
 	if point.Lat() < geofence.minX || point.Lat() > geofence.maxX || point.Lng() < geofence.minY || point.Lng() > geofence.maxY {
 		return false
 	}
+	if geofence.rect {
+		return geofence.boundary == Inclusive || (point.Lat() > geofence.minX && point.Lat() < geofence.maxX && point.Lng() > geofence.minY && point.Lng() < geofence.maxY)
+	}
 
-	tileHash := (project(point.Lng(), geofence.tileHeight)-geofence.minTileY)*float64(geofence.granularity) + (project(point.Lat(), geofence.tileWidth) - geofence.minTileX)
+	tileHash := geofence.tileHash(project(point.Lat(), geofence.tileWidth), project(point.Lng(), geofence.tileHeight))
 	intersects := geofence.tiles[tileHash]
 
 	if intersects == TILE_IN {
 		return true
 	} else if intersects == TILE_EITHER {
-		polygon := NewPolygon(geofence.vertices)
-		inside := polygon.Contains(point)
-		return inside
+		if bucket := geofence.buckets[tileHash]; bucket != nil {
+			bucket = bucket.leaf(point)
+			if bucket.tile != 0 {
+				return bucket.tile == TILE_IN
+			}
+			if bucket.onEdge(point) {
+				return geofence.boundary == Inclusive
+			}
+			return bucket.contains(point)
+		}
+		if geofence.onBoundary(point) {
+			return geofence.boundary == Inclusive
+		}
+		return geofence.contains(point)
 	} else {
 		return false
 	}
 }
 
+// insideAltitude returns whether the point is within the altitude range, as it is when the
+// point has no altitude.
+func (geofence *Geofence) insideAltitude(point *Point) bool {
+	altitude, ok := point.Altitude()
+	if !ok {
+		return true
+	}
+	if geofence.boundary == Exclusive {
+		return altitude > geofence.minAltitude && altitude < geofence.maxAltitude
+	}
+	return altitude >= geofence.minAltitude && altitude <= geofence.maxAltitude
+}
+
+// contains runs the exact point in polygon check, used for tiles crossed by an edge.
+func (geofence *Geofence) contains(point *Point) bool {
+	if !geofence.geodesic {
+		if geofence.exact {
+			return geofence.exactContains(point)
+		}
+		if geofence.containment == WindingNumber {
+			return geofence.windingContains(point)
+		}
+		return geofence.polygon.Contains(point)
+	}
+
+	inside := sphericalRingContains(geofence.vertices, point)
+	for _, hole := range geofence.holes {
+		if sphericalRingContains(hole, point) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// tilingRing returns the ring used to classify tiles. Geodesic edges bulge away from the
+// straight line between their vertices, so they are densified to follow the arc, and a
+// geodesic ring around a pole is replaced by the cap between it and the pole.
+func (geofence *Geofence) tilingRing(ring []*Point) []*Point {
+	if geofence.geodesic {
+		if pole := enclosedPole(ring); pole != 0 {
+			return polarRing(densifyGeodesic(ring, maxGeodesicStep), pole)
+		}
+		return geofence.wrapRing(densifyGeodesic(ring, maxGeodesicStep))
+	}
+	return ring
+}
+
 func (geofence *Geofence) setInclusionTiles() {
-	xVertices := geofence.getXVertices()
-	yVertices := geofence.getYVertices()
+	geofence.polygon = NewPolygonWithHoles(geofence.vertices, geofence.holes)
+
+	outer := geofence.tilingRing(geofence.vertices)
+	xVertices := getXVertices(outer)
+	yVertices := getYVertices(outer)
 
 	geofence.minX = getMin(xVertices)
 	geofence.minY = getMin(yVertices)
@@ -73,50 +223,104 @@ func (geofence *Geofence) setInclusionTiles() {
 
 	xRange := geofence.maxX - geofence.minX
 	yRange := geofence.maxY - geofence.minY
-	geofence.tileWidth = xRange / float64(geofence.granularity)
-	geofence.tileHeight = yRange / float64(geofence.granularity)
+	if geofence.tileMeters > 0 && xRange > 0 && yRange > 0 {
+		geofence.tileWidth, geofence.tileHeight = geofence.metricTileSize(xRange, yRange)
+	} else {
+		if geofence.autoGrid && xRange > 0 && yRange > 0 {
+			geofence.granularityX, geofence.granularityY = geofence.autoGranularity(outer, xRange, yRange)
+		}
+		geofence.tileWidth = xRange / float64(geofence.granularityX)
+		geofence.tileHeight = yRange / float64(geofence.granularityY)
+	}
 
 	geofence.minTileX = project(geofence.minX, geofence.tileWidth)
 	geofence.minTileY = project(geofence.minY, geofence.tileHeight)
 	geofence.maxTileX = project(geofence.maxX, geofence.tileWidth)
 	geofence.maxTileY = project(geofence.maxY, geofence.tileHeight)
 
-	geofence.setExclusionTiles(geofence.vertices, true)
+	// A degenerate fence has no area to tile, and zero sized tiles would never end the loops
+	if geofence.tileWidth == 0 || geofence.tileHeight == 0 {
+		return
+	}
+
+	geofence.setExclusionTiles(outer, true)
+	for _, hole := range geofence.holes {
+		geofence.setExclusionTiles(geofence.tilingRing(hole), false)
+	}
+	if geofence.geodesic || geofence.exact {
+		geofence.widenEitherTiles()
+	}
+	geofence.bucketEdges()
 }
 
+// setExclusionTiles marks the tiles crossed by the ring as TILE_EITHER. Tiles fully inside
+// the ring become TILE_IN when inclusive, otherwise (holes) they flip between TILE_IN and TILE_OUT.
 func (geofence *Geofence) setExclusionTiles(vertices []*Point, inclusive bool) {
-	var tileHash float64
+	var tileHash int64
 	var bBoxPoly []*Point
+	vertices = closeRing(vertices)
 	for tileX := geofence.minTileX; tileX <= geofence.maxTileX; tileX++ {
 		for tileY := geofence.minTileY; tileY <= geofence.maxTileY; tileY++ {
-			tileHash = (tileY-geofence.minTileY)*float64(geofence.granularity) + (tileX - geofence.minTileX)
+			tileHash = geofence.tileHash(tileX, tileY)
 			bBoxPoly = []*Point{NewPoint(tileX*geofence.tileWidth, tileY*geofence.tileHeight), NewPoint((tileX+1)*geofence.tileWidth, tileY*geofence.tileHeight), NewPoint((tileX+1)*geofence.tileWidth, (tileY+1)*geofence.tileHeight), NewPoint(tileX*geofence.tileWidth, (tileY+1)*geofence.tileHeight), NewPoint(tileX*geofence.tileWidth, tileY*geofence.tileHeight)}
 
 			if haveIntersectingEdges(bBoxPoly, vertices) || hasPointInPolygon(vertices, bBoxPoly) {
 				geofence.tiles[tileHash] = TILE_EITHER
-			} else if hasPointInPolygon(bBoxPoly, vertices) {
+			} else if geofence.ringContainsAny(vertices, bBoxPoly) {
 				if inclusive {
 					geofence.tiles[tileHash] = TILE_IN
-				} else {
+				} else if geofence.tiles[tileHash] == TILE_IN {
 					geofence.tiles[tileHash] = TILE_OUT
+				} else if geofence.tiles[tileHash] != TILE_EITHER {
+					geofence.tiles[tileHash] = TILE_IN
 				}
 			} // else all points are outside the poly
 		}
 	}
 }
 
-func (geofence *Geofence) getXVertices() []float64 {
-	xVertices := make([]float64, len(geofence.vertices))
-	for i := 0; i < len(geofence.vertices); i++ {
-		xVertices[i] = geofence.vertices[i].Lat()
+// widenEitherTiles marks the neighbours of every TILE_EITHER tile as TILE_EITHER too, so that
+// the small gap between a densified ring and its great circle arcs never decides a result,
+// nor, with WithExactPredicates, an edge that rounding hid from the tile it grazes.
+func (geofence *Geofence) widenEitherTiles() {
+	var either []int64
+	for tileHash, tile := range geofence.tiles {
+		if tile == TILE_EITHER {
+			either = append(either, tileHash)
+		}
+	}
+
+	stride := int64(geofence.maxTileX - geofence.minTileX + 1)
+	for _, tileHash := range either {
+		tileY := float64(tileHash/stride) + geofence.minTileY
+		tileX := float64(tileHash%stride) + geofence.minTileX
+		for x := math.Max(tileX-1, geofence.minTileX); x <= math.Min(tileX+1, geofence.maxTileX); x++ {
+			for y := math.Max(tileY-1, geofence.minTileY); y <= math.Min(tileY+1, geofence.maxTileY); y++ {
+				geofence.tiles[geofence.tileHash(x, y)] = TILE_EITHER
+			}
+		}
+	}
+}
+
+// tileHash returns the tiles map key for the tile at the projected tileX, tileY, numbering the
+// tiles row by row from 0. Rows are one tile wider than the granularity, since maxTileX is
+// inclusive. The tile coordinates are whole numbers, so the conversions are exact.
+func (geofence *Geofence) tileHash(tileX, tileY float64) int64 {
+	return int64(tileY-geofence.minTileY)*int64(geofence.maxTileX-geofence.minTileX+1) + int64(tileX-geofence.minTileX)
+}
+
+func getXVertices(vertices []*Point) []float64 {
+	xVertices := make([]float64, len(vertices))
+	for i := 0; i < len(vertices); i++ {
+		xVertices[i] = vertices[i].Lat()
 	}
 	return xVertices
 }
 
-func (geofence *Geofence) getYVertices() []float64 {
-	yVertices := make([]float64, len(geofence.vertices))
-	for i := 0; i < len(geofence.vertices); i++ {
-		yVertices[i] = geofence.vertices[i].Lng()
+func getYVertices(vertices []*Point) []float64 {
+	yVertices := make([]float64, len(vertices))
+	for i := 0; i < len(vertices); i++ {
+		yVertices[i] = vertices[i].Lng()
 	}
 	return yVertices
 }
@@ -146,3 +350,133 @@ func getMax(slice []float64) float64 {
 	}
 	return max
 }
+
+// Vertices returns the outer ring of the geofence, nil for a NewMultiGeofence.
+func (geofence *Geofence) Vertices() []*Point {
+	return geofence.unwrapRing(geofence.vertices)
+}
+
+// Holes returns the holes of the geofence, nil for a geofence without holes or a NewMultiGeofence.
+func (geofence *Geofence) Holes() [][]*Point {
+	if len(geofence.holes) == 0 {
+		return nil
+	}
+	holes := make([][]*Point, len(geofence.holes))
+	for i, hole := range geofence.holes {
+		holes[i] = geofence.unwrapRing(hole)
+	}
+	return holes
+}
+
+// Bounds returns the southwest and northeast corners of the geofence's bounding box, as
+// Points with the minimum and maximum Lat and Lng of the outer ring, or the union of the
+// polygons of a NewMultiGeofence. Holes never extend the bounds. A geofence crossing the
+// antimeridian has a southwest corner east of its northeast corner, e.g. Lng 170 and -170. A
+// geofence built WithProjection returns the corners of its bounding box on the plane. A
+// geofence without vertices returns nil, nil.
+func (geofence *Geofence) Bounds() (min, max *Point) {
+	if geofence.empty() {
+		return nil, nil
+	}
+	return geofence.unwrapPoint(NewPoint(geofence.minX, geofence.minY)), geofence.unwrapPoint(NewPoint(geofence.maxX, geofence.maxY))
+}
+
+// BBox returns the bounding box given by Bounds as plain numbers, e.g. for a coarse prefilter
+// in a database query. As with Bounds, minLng is greater than maxLng for a geofence crossing
+// the antimeridian. A geofence without vertices returns zeros.
+func (geofence *Geofence) BBox() (minLat, minLng, maxLat, maxLng float64) {
+	min, max := geofence.Bounds()
+	if min == nil {
+		return 0, 0, 0, 0
+	}
+	return min.Lat(), min.Lng(), max.Lat(), max.Lng()
+}
+
+// TileStats returns how many tiles of the grid are TILE_IN, TILE_OUT and TILE_EITHER. Only points
+// in TILE_EITHER tiles need more than a map lookup, so a large share of them suggests a finer
+// granularity. The tiles of a NewMultiGeofence's polygons are summed. A NewBBoxGeofence and a
+// geofence without area have no tiles.
+func (geofence *Geofence) TileStats() (in, out, either int) {
+	if len(geofence.parts) > 0 {
+		for _, part := range geofence.parts {
+			partIn, partOut, partEither := part.TileStats()
+			in, out, either = in+partIn, out+partOut, either+partEither
+		}
+		return in, out, either
+	}
+	if geofence.rect || geofence.tileWidth == 0 || geofence.tileHeight == 0 {
+		return 0, 0, 0
+	}
+
+	for _, tile := range geofence.tiles {
+		switch tile {
+		case TILE_IN:
+			in++
+		case TILE_EITHER:
+			either++
+		}
+	}
+	total := int((geofence.maxTileX - geofence.minTileX + 1) * (geofence.maxTileY - geofence.minTileY + 1))
+	return in, total - in - either, either
+}
+
+const (
+	// autoEitherFraction is the share of TILE_EITHER tiles WithAutoGranularity aims for.
+	autoEitherFraction = 0.25
+	minAutoGranularity = 4
+	maxAutoGranularity = 256
+	// autoTilingBudget caps tiles × vertices, the edge tests tiling costs.
+	autoTilingBudget = 2e7
+)
+
+// autoGranularity returns the granularities for WithAutoGranularity. The tiles are square in
+// degrees, so a long thin geofence gets many tiles along its length and few across it. An edge
+// spanning dx by dy crosses about (|dx|+|dy|)/side tiles, so summing over the edges estimates
+// the TILE_EITHER tiles as length/side, out of xRange × yRange / side² tiles.
+func (geofence *Geofence) autoGranularity(outer []*Point, xRange, yRange float64) (int64, int64) {
+	length := 0.0
+	vertices := 0
+	for _, ring := range append([][]*Point{outer}, geofence.holes...) {
+		ring = closeRing(ring)
+		for i := 0; i+1 < len(ring); i++ {
+			length += math.Abs(ring[i+1].Lat()-ring[i].Lat()) + math.Abs(ring[i+1].Lng()-ring[i].Lng())
+		}
+		vertices += len(ring)
+	}
+
+	side := autoEitherFraction * xRange * yRange / length
+	x, y := math.Ceil(xRange/side), math.Ceil(yRange/side)
+	// Shrink both axes alike to keep the tile count within the caps
+	limit := math.Min(maxAutoGranularity*maxAutoGranularity, autoTilingBudget/float64(vertices))
+	if x*y > limit {
+		scale := math.Sqrt(limit / (x * y))
+		x, y = math.Floor(x*scale), math.Floor(y*scale)
+	}
+	return int64(math.Max(x, minAutoGranularity)), int64(math.Max(y, minAutoGranularity))
+}
+
+// maxMetricTiles caps the tiles along each axis for WithTileSizeMeters, so a small tile size on
+// a large geofence cannot make tiling take forever.
+const maxMetricTiles = 1024
+
+// metricTileSize returns the tile width and height in degrees for WithTileSizeMeters, using the
+// lengths of a degree of latitude and longitude on the WGS84 ellipsoid at the middle latitude of
+// the bounding box. Tiles are never smaller than maxMetricTiles across the bounding box. The
+// coordinates of a WithPlanar or WithProjection geofence are already meters.
+func (geofence *Geofence) metricTileSize(xRange, yRange float64) (width, height float64) {
+	if geofence.planar || geofence.projection != nil {
+		return math.Max(geofence.tileMeters, xRange/maxMetricTiles), math.Max(geofence.tileMeters, yRange/maxMetricTiles)
+	}
+	lat := (geofence.minX + geofence.maxX) / 2 * math.Pi / 180.0
+	latDegree := 111132.92 - 559.82*math.Cos(2*lat) + 1.175*math.Cos(4*lat)
+	lngDegree := 111412.84*math.Cos(lat) - 93.5*math.Cos(3*lat)
+
+	width = math.Max(geofence.tileMeters/latDegree, xRange/maxMetricTiles)
+	height = yRange
+	// A degree of longitude shrinks to nothing at the poles, one tile then spans the geofence
+	if lngDegree > 1 {
+		height = math.Min(geofence.tileMeters/lngDegree, yRange)
+	}
+	height = math.Max(height, yRange/maxMetricTiles)
+	return width, height
+}
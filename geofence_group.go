@@ -0,0 +1,560 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// GeofenceGroup maps keys to whitelist and blacklist geofences. A key is valid for a point
+// when the point is inside one of its whitelist geofences, or it has no whitelist, and the
+// point is inside none of its blacklist geofences.
+// Keys can be any comparable type, e.g. GeofenceGroup[string] for device IDs.
+// A GeofenceGroup is safe for concurrent use.
+type GeofenceGroup[K comparable] struct {
+	mu      sync.RWMutex
+	entries map[K]*groupEntry
+	// index is rebuilt by the first GetValidKeys after the entries change
+	index   *groupIndex[K]
+	dirty   bool
+	options groupOptions
+	// nextExpiry is the earliest expiry of a geofence added by AddWithExpiry, zero for none
+	nextExpiry time.Time
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// GroupOption configures a GeofenceGroup, e.g. NewGeofenceGroup[string](WithS2Index(12)).
+type GroupOption func(options *groupOptions) error
+
+type groupOptions struct {
+	s2Level          int
+	geohashPrecision int
+}
+
+// WithS2Index indexes the group's whitelist geofences by S2 cells rather than an R-tree of their
+// bounding boxes. Each geofence is covered by up to maxCoveringCells cells no smaller than the
+// level, and a point only checks the geofences whose covering holds one of its cells, so large
+// or oddly shaped geofences are skipped for points in their bounding box but outside them. Level
+// 12 cells are about 2km across. WithPlanar geofences have no S2 cells and are always checked.
+// It replaces WithGeohashIndex, whichever comes last wins.
+func WithS2Index(level int) GroupOption {
+	return func(options *groupOptions) error {
+		if level < 1 || level > S2MaxLevel {
+			return fmt.Errorf("S2 index level must be from 1 to %d, got %d", S2MaxLevel, level)
+		}
+		options.s2Level, options.geohashPrecision = level, 0
+		return nil
+	}
+}
+
+// WithGeohashIndex indexes the group's whitelist geofences by geohash, as WithS2Index does by S2
+// cell, covering each geofence with up to maxGeohashCells geohashes no longer than the precision.
+// Precision 5 cells are about 5km across. It replaces WithS2Index, whichever comes last wins.
+func WithGeohashIndex(precision int) GroupOption {
+	return func(options *groupOptions) error {
+		if precision < 1 || precision > GeohashMaxPrecision {
+			return fmt.Errorf("geohash index precision must be from 1 to %d, got %d", GeohashMaxPrecision, precision)
+		}
+		options.s2Level, options.geohashPrecision = 0, precision
+		return nil
+	}
+}
+
+type groupEntry struct {
+	whitelist []*Geofence
+	blacklist []*Geofence
+	// whitelistExpires and blacklistExpires hold when each geofence expires, zero for never, or
+	// are nil when none of the list's geofences expire
+	whitelistExpires []time.Time
+	blacklistExpires []time.Time
+	// metadata is set by SetMetadata
+	metadata any
+	// priority is set by SetPriority
+	priority int
+}
+
+// NewGeofenceGroup returns an empty GeofenceGroup, e.g. NewGeofenceGroup[string]().
+// As with NewGeofence, an invalid option is ignored.
+func NewGeofenceGroup[K comparable](opts ...GroupOption) *GeofenceGroup[K] {
+	group := &GeofenceGroup[K]{entries: make(map[K]*groupEntry)}
+	for _, opt := range opts {
+		opt(&group.options)
+	}
+	return group
+}
+
+// Add appends the whitelist and blacklist geofences to the key, creating it if needed.
+func (group *GeofenceGroup[K]) Add(key K, whitelist []*Geofence, blacklist []*Geofence) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		entry = &groupEntry{}
+		group.entries[key] = entry
+	}
+	entry.whitelist, entry.whitelistExpires = appendExpiring(entry.whitelist, entry.whitelistExpires, whitelist, time.Time{})
+	entry.blacklist, entry.blacklistExpires = appendExpiring(entry.blacklist, entry.blacklistExpires, blacklist, time.Time{})
+	group.dirty = true
+}
+
+// AddWithExpiry appends the whitelist and blacklist geofences to the key as Add does, until the
+// expiry time, e.g. for a road closure or an event zone. From then on GetValidKeys and the
+// group's other methods behave as though the geofences had been removed, and they are dropped
+// from the group. A key whose whitelist geofences have all expired is removed with them, rather
+// than becoming valid everywhere. A zero expiry never expires, as with Add.
+func (group *GeofenceGroup[K]) AddWithExpiry(key K, whitelist []*Geofence, blacklist []*Geofence, expires time.Time) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		entry = &groupEntry{}
+		group.entries[key] = entry
+	}
+	entry.whitelist, entry.whitelistExpires = appendExpiring(entry.whitelist, entry.whitelistExpires, whitelist, expires)
+	entry.blacklist, entry.blacklistExpires = appendExpiring(entry.blacklist, entry.blacklistExpires, blacklist, expires)
+	if !expires.IsZero() && len(whitelist)+len(blacklist) > 0 && (group.nextExpiry.IsZero() || expires.Before(group.nextExpiry)) {
+		group.nextExpiry = expires
+	}
+	group.dirty = true
+}
+
+// appendExpiring appends the added geofences to the list, and their expiry to the list's expiry
+// times if any of them expire.
+func appendExpiring(list []*Geofence, expires []time.Time, added []*Geofence, expiry time.Time) ([]*Geofence, []time.Time) {
+	if expires == nil && !expiry.IsZero() && len(added) > 0 {
+		expires = make([]time.Time, len(list), len(list)+len(added))
+	}
+	if expires != nil {
+		for range added {
+			expires = append(expires, expiry)
+		}
+	}
+	return append(list, added...), expires
+}
+
+// Update replaces the whitelist and blacklist geofences of the key, creating it if needed. The
+// key's metadata and priority are kept.
+func (group *GeofenceGroup[K]) Update(key K, whitelist []*Geofence, blacklist []*Geofence) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	entry := &groupEntry{
+		whitelist: append([]*Geofence(nil), whitelist...),
+		blacklist: append([]*Geofence(nil), blacklist...),
+	}
+	if existing, ok := group.entries[key]; ok {
+		entry.metadata, entry.priority = existing.metadata, existing.priority
+	}
+	group.entries[key] = entry
+	group.dirty = true
+}
+
+// SetMetadata attaches the metadata to the key, e.g. a label, speed limit or owner, replacing any
+// it had, so it comes back with GetValidKeysWithMetadata and Evaluate rather than being kept in
+// a separate map. The key must have been added first, and its metadata is removed with it.
+func (group *GeofenceGroup[K]) SetMetadata(key K, metadata any) error {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		return fmt.Errorf("geofence group has no key %v", key)
+	}
+	entry.metadata = metadata
+	return nil
+}
+
+// Metadata returns the metadata attached to the key by SetMetadata, and whether the key exists.
+func (group *GeofenceGroup[K]) Metadata(key K) (any, bool) {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return entry.metadata, true
+}
+
+// Remove deletes the key and its geofences. Removing a missing key does nothing.
+func (group *GeofenceGroup[K]) Remove(key K) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	delete(group.entries, key)
+	group.dirty = true
+}
+
+// Keys returns the keys in the group, in no particular order.
+func (group *GeofenceGroup[K]) Keys() []K {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	keys := make([]K, 0, len(group.entries))
+	for key := range group.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// GetValidKeys returns the set of keys that are valid for the point.
+// Only keys whose whitelist geofences have a bounding box around the point are checked.
+func (group *GeofenceGroup[K]) GetValidKeys(point *Point) map[K]bool {
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	return group.validKeys(point)
+}
+
+// SetPriority sets the key's priority for Resolve, where higher priorities win. Keys start at 0.
+// The key must have been added first.
+func (group *GeofenceGroup[K]) SetPriority(key K, priority int) error {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		return fmt.Errorf("geofence group has no key %v", key)
+	}
+	entry.priority = priority
+	return nil
+}
+
+// Priority returns the key's priority set by SetPriority, and whether the key exists.
+func (group *GeofenceGroup[K]) Priority(key K) (int, bool) {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	entry, ok := group.entries[key]
+	if !ok {
+		return 0, false
+	}
+	return entry.priority, true
+}
+
+// Resolution is how Resolve picks one key among the valid keys for a point.
+type Resolution int
+
+const (
+	// ResolveByPriority picks the key with the highest priority, see SetPriority, and among
+	// keys of the same priority the innermost.
+	ResolveByPriority Resolution = iota + 1
+	// ResolveInnermost picks the key whose smallest whitelist geofence containing the point has
+	// the least area, e.g. a zone within a site, and among keys of the same area the highest
+	// priority. Keys without a whitelist are outermost.
+	ResolveInnermost
+)
+
+// Resolve returns the single key that wins among the keys valid for the point, as GetValidKeys
+// returns them, e.g. the one congestion charge zone to bill or the most specific of nested
+// site and zone keys, and false when no key is valid. Keys that tie on both priority and area
+// are resolved in the order of SortKeys, so the same key always wins.
+func (group *GeofenceGroup[K]) Resolve(point *Point, resolution Resolution) (K, bool) {
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	validKeys := group.validKeys(point)
+	if len(validKeys) == 0 {
+		var none K
+		return none, false
+	}
+	keys := make([]K, 0, len(validKeys))
+	for key := range validKeys {
+		keys = append(keys, key)
+	}
+	SortKeys(keys)
+
+	areas := make(map[K]float64, len(keys))
+	for _, key := range keys {
+		areas[key] = group.entries[key].innermostArea(point)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := group.entries[keys[i]], group.entries[keys[j]]
+		if resolution == ResolveInnermost && areas[keys[i]] != areas[keys[j]] {
+			return areas[keys[i]] < areas[keys[j]]
+		}
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return areas[keys[i]] < areas[keys[j]]
+	})
+	return keys[0], true
+}
+
+// innermostArea returns the area of the smallest whitelist geofence containing the point, or
+// +Inf when the entry has no whitelist.
+func (entry *groupEntry) innermostArea(point *Point) float64 {
+	area := math.Inf(1)
+	for _, geofence := range entry.whitelist {
+		if geofence.Inside(point) {
+			area = math.Min(area, geofence.Area())
+		}
+	}
+	return area
+}
+
+// GetValidKeysWithMetadata returns the keys that are valid for the point as GetValidKeys does,
+// each with its metadata, nil for keys without any.
+func (group *GeofenceGroup[K]) GetValidKeysWithMetadata(point *Point) map[K]any {
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	validKeys := group.validKeys(point)
+	metadata := make(map[K]any, len(validKeys))
+	for key := range validKeys {
+		metadata[key] = group.entries[key].metadata
+	}
+	return metadata
+}
+
+// GetInvalidKeys returns the set of keys that are not valid for the point, e.g. the users to
+// deny access to, the complement of GetValidKeys.
+func (group *GeofenceGroup[K]) GetInvalidKeys(point *Point) map[K]bool {
+	_, invalidKeys := group.Partition(point)
+	return invalidKeys
+}
+
+// Partition returns both the valid and the invalid keys for the point, as one consistent view of
+// the group even while other goroutines change it. Every key of the group is in one of the sets.
+// Only the keys GetValidKeys would check are tested, the rest are invalid without testing.
+func (group *GeofenceGroup[K]) Partition(point *Point) (validKeys, invalidKeys map[K]bool) {
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	validKeys = group.validKeys(point)
+	invalidKeys = make(map[K]bool, len(group.entries)-len(validKeys))
+	for key := range group.entries {
+		if !validKeys[key] {
+			invalidKeys[key] = true
+		}
+	}
+	return validKeys, invalidKeys
+}
+
+// validKeys returns the keys that are valid for the point. The group must be read locked with
+// rLockIndexed.
+func (group *GeofenceGroup[K]) validKeys(point *Point) map[K]bool {
+	validKeys := make(map[K]bool)
+	checked := make(map[K]bool)
+	group.index.candidates(point, func(key K) {
+		if checked[key] {
+			return
+		}
+		checked[key] = true
+		if group.entries[key].valid(point) {
+			validKeys[key] = true
+		}
+	})
+	return validKeys
+}
+
+// GetValidKeysSlice returns the keys that are valid for the point as GetValidKeys does, sorted
+// so that logs and tests see the same order every time, see SortKeys.
+func (group *GeofenceGroup[K]) GetValidKeysSlice(point *Point) []K {
+	validKeys := group.GetValidKeys(point)
+	keys := make([]K, 0, len(validKeys))
+	for key := range validKeys {
+		keys = append(keys, key)
+	}
+	SortKeys(keys)
+	return keys
+}
+
+// SortKeys sorts group keys in place: keys whose underlying type is a string, integer or float
+// in their natural order and, since comparable types need not be ordered, any other keys by
+// their fmt %v form, e.g. structs field by field as text.
+func SortKeys[K comparable](keys []K) {
+	if len(keys) < 2 {
+		return
+	}
+	switch reflect.TypeOf((*K)(nil)).Elem().Kind() {
+	case reflect.String:
+		sort.SliceStable(keys, func(i, j int) bool {
+			return reflect.ValueOf(keys[i]).String() < reflect.ValueOf(keys[j]).String()
+		})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sort.SliceStable(keys, func(i, j int) bool {
+			return reflect.ValueOf(keys[i]).Int() < reflect.ValueOf(keys[j]).Int()
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sort.SliceStable(keys, func(i, j int) bool {
+			return reflect.ValueOf(keys[i]).Uint() < reflect.ValueOf(keys[j]).Uint()
+		})
+	case reflect.Float32, reflect.Float64:
+		sort.SliceStable(keys, func(i, j int) bool {
+			return reflect.ValueOf(keys[i]).Float() < reflect.ValueOf(keys[j]).Float()
+		})
+	default:
+		text := make(map[K]string, len(keys))
+		for _, key := range keys {
+			text[key] = fmt.Sprintf("%v", key)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return text[keys[i]] < text[keys[j]]
+		})
+	}
+}
+
+// KeyMatch explains whether a key of a GeofenceGroup is valid for a point, see Evaluate. The
+// indexes are of the geofences in the key's whitelist and blacklist, in the order they were added.
+type KeyMatch struct {
+	Valid bool
+	// Whitelist is the first whitelist geofence containing the point, nil when none does or the
+	// key has no whitelist
+	Whitelist      *Geofence
+	WhitelistIndex int
+	// Blacklist is the first blacklist geofence containing the point, which makes the key
+	// invalid, nil when none does
+	Blacklist      *Geofence
+	BlacklistIndex int
+	// Metadata is the key's metadata, see SetMetadata
+	Metadata any
+}
+
+// Evaluate returns for every key of the group why it is or is not valid for the point: the
+// whitelist geofence it matched and the blacklist geofence that vetoed it, e.g. to find out why
+// a driver was flagged. Valid is the same as for GetValidKeys, but every geofence of every key
+// is checked, so Evaluate is for debugging rather than for each update.
+func (group *GeofenceGroup[K]) Evaluate(point *Point) map[K]KeyMatch {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	matches := make(map[K]KeyMatch, len(group.entries))
+	for key, entry := range group.entries {
+		match := KeyMatch{WhitelistIndex: -1, BlacklistIndex: -1, Metadata: entry.metadata}
+		for i, geofence := range entry.whitelist {
+			if geofence.Inside(point) {
+				match.Whitelist, match.WhitelistIndex = geofence, i
+				break
+			}
+		}
+		for i, geofence := range entry.blacklist {
+			if geofence.Inside(point) {
+				match.Blacklist, match.BlacklistIndex = geofence, i
+				break
+			}
+		}
+		match.Valid = (len(entry.whitelist) == 0 || match.Whitelist != nil) && match.Blacklist == nil
+		matches[key] = match
+	}
+	return matches
+}
+
+// rLockIndexed read locks the group once its expired geofences are removed and its index is up
+// to date with the entries.
+func (group *GeofenceGroup[K]) rLockIndexed() {
+	group.mu.RLock()
+	for group.dirty || group.index == nil || group.expired() {
+		group.mu.RUnlock()
+		group.mu.Lock()
+		group.removeExpired()
+		if group.dirty || group.index == nil {
+			group.index = newGroupIndex(group.entries, group.options)
+			group.dirty = false
+		}
+		group.mu.Unlock()
+		group.mu.RLock()
+	}
+}
+
+// rLockUnexpired read locks the group once its expired geofences are removed.
+func (group *GeofenceGroup[K]) rLockUnexpired() {
+	group.mu.RLock()
+	for group.expired() {
+		group.mu.RUnlock()
+		group.mu.Lock()
+		group.removeExpired()
+		group.mu.Unlock()
+		group.mu.RLock()
+	}
+}
+
+// expired returns whether a geofence added by AddWithExpiry has expired and not been removed.
+func (group *GeofenceGroup[K]) expired() bool {
+	return !group.nextExpiry.IsZero() && !group.clock().Before(group.nextExpiry)
+}
+
+func (group *GeofenceGroup[K]) clock() time.Time {
+	if group.now != nil {
+		return group.now()
+	}
+	return time.Now()
+}
+
+// removeExpired drops the expired geofences, and the keys left without a whitelist by them,
+// and finds the next expiry. The group must be write locked.
+func (group *GeofenceGroup[K]) removeExpired() {
+	if !group.expired() {
+		return
+	}
+	now := group.clock()
+	group.nextExpiry = time.Time{}
+	for key, entry := range group.entries {
+		whitelisted := len(entry.whitelist) > 0
+		entry.whitelist, entry.whitelistExpires = removeExpiredGeofences(entry.whitelist, entry.whitelistExpires, now)
+		entry.blacklist, entry.blacklistExpires = removeExpiredGeofences(entry.blacklist, entry.blacklistExpires, now)
+		if whitelisted && len(entry.whitelist) == 0 {
+			delete(group.entries, key)
+			continue
+		}
+		group.scheduleExpiry(entry)
+	}
+	group.dirty = true
+}
+
+// scheduleExpiry brings the group's next expiry forward to the earliest of the entry's.
+func (group *GeofenceGroup[K]) scheduleExpiry(entry *groupEntry) {
+	for _, expires := range [][]time.Time{entry.whitelistExpires, entry.blacklistExpires} {
+		for _, expiry := range expires {
+			if !expiry.IsZero() && (group.nextExpiry.IsZero() || expiry.Before(group.nextExpiry)) {
+				group.nextExpiry = expiry
+			}
+		}
+	}
+}
+
+// removeExpiredGeofences returns the geofences of the list that have not expired by now, with
+// their expiry times, or nil expiry times once none are left to expire.
+func removeExpiredGeofences(list []*Geofence, expires []time.Time, now time.Time) ([]*Geofence, []time.Time) {
+	if expires == nil {
+		return list, nil
+	}
+	var kept []*Geofence
+	var keptExpires []time.Time
+	expiring := false
+	for i, geofence := range list {
+		if !expires[i].IsZero() && !now.Before(expires[i]) {
+			continue
+		}
+		kept = append(kept, geofence)
+		keptExpires = append(keptExpires, expires[i])
+		expiring = expiring || !expires[i].IsZero()
+	}
+	if !expiring {
+		keptExpires = nil
+	}
+	return kept, keptExpires
+}
+
+func (entry *groupEntry) valid(point *Point) bool {
+	for _, geofence := range entry.blacklist {
+		if geofence.Inside(point) {
+			return false
+		}
+	}
+	if len(entry.whitelist) == 0 {
+		return true
+	}
+	for _, geofence := range entry.whitelist {
+		if geofence.Inside(point) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,609 @@
+package geofence
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func square(minLat, minLng, size float64) *Geofence {
+	return NewGeofence([]*Point{
+		NewPoint(minLat, minLng),
+		NewPoint(minLat, minLng+size),
+		NewPoint(minLat+size, minLng+size),
+		NewPoint(minLat+size, minLng),
+	})
+}
+
+func TestGeofenceGroup(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	group.Add(2, []*Geofence{square(0, 0, 10)}, []*Geofence{square(2, 2, 2)})
+	group.Add(3, []*Geofence{square(20, 20, 10), square(5, 5, 10)}, nil)
+	group.Add(4, nil, []*Geofence{square(0, 0, 5)})
+
+	assert.Equal(t, map[int]bool{1: true, 2: true}, group.GetValidKeys(NewPoint(1, 1)))
+	assert.Equal(t, map[int]bool{1: true}, group.GetValidKeys(NewPoint(3, 3)))
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 4: true}, group.GetValidKeys(NewPoint(7, 7)))
+	assert.Equal(t, map[int]bool{3: true, 4: true}, group.GetValidKeys(NewPoint(25, 25)))
+}
+
+func TestGeofenceGroupStringKeys(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("truck-1", []*Geofence{square(0, 0, 10)}, nil)
+	group.Add("truck-2", []*Geofence{square(20, 20, 10)}, nil)
+	group.Add("van-7", []*Geofence{square(0, 0, 10)}, []*Geofence{square(2, 2, 2)})
+
+	assert.Equal(t, map[string]bool{"truck-1": true, "van-7": true}, group.GetValidKeys(NewPoint(1, 1)))
+	assert.Equal(t, map[string]bool{"truck-1": true}, group.GetValidKeys(NewPoint(3, 3)))
+	group.Remove("truck-1")
+	assert.ElementsMatch(t, []string{"truck-2", "van-7"}, group.Keys())
+}
+
+func TestGeofenceGroupRemoveUpdate(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	group.Add(2, []*Geofence{square(0, 0, 10)}, nil)
+	group.Add(3, []*Geofence{square(0, 0, 10)}, nil)
+	point := NewPoint(5, 5)
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, group.GetValidKeys(point))
+
+	group.Remove(2)
+	assert.Equal(t, map[int]bool{1: true, 3: true}, group.GetValidKeys(point))
+	group.Remove(2)
+
+	group.Update(3, []*Geofence{square(20, 20, 10)}, nil)
+	assert.Equal(t, map[int]bool{1: true}, group.GetValidKeys(point))
+	assert.Equal(t, map[int]bool{3: true}, group.GetValidKeys(NewPoint(25, 25)))
+
+	group.Update(5, []*Geofence{square(0, 0, 10)}, []*Geofence{square(4, 4, 2)})
+	assert.Equal(t, map[int]bool{1: true}, group.GetValidKeys(point))
+	assert.Equal(t, map[int]bool{1: true, 5: true}, group.GetValidKeys(NewPoint(1, 1)))
+	assert.ElementsMatch(t, []int{1, 3, 5}, group.Keys())
+	assert.Empty(t, NewGeofenceGroup[int]().Keys())
+}
+
+func TestGeofenceGroupGetValidKeysSlice(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	for _, key := range []int{12, 3, -4, 100, 7} {
+		group.Add(key, []*Geofence{square(0, 0, 10)}, nil)
+	}
+	group.Add(8, []*Geofence{square(20, 20, 10)}, nil)
+	assert.Equal(t, []int{-4, 3, 7, 12, 100}, group.GetValidKeysSlice(NewPoint(5, 5)))
+	assert.Empty(t, group.GetValidKeysSlice(NewPoint(50, 50)))
+
+	type deviceID string
+	devices := []deviceID{"van-2", "truck-10", "truck-1"}
+	SortKeys(devices)
+	assert.Equal(t, []deviceID{"truck-1", "truck-10", "van-2"}, devices)
+
+	floats := []float64{2.5, -1, 0.25}
+	SortKeys(floats)
+	assert.Equal(t, []float64{-1, 0.25, 2.5}, floats)
+
+	type zone struct {
+		Site  string
+		Level int
+	}
+	zones := []zone{{"b", 1}, {"a", 2}, {"a", 1}}
+	SortKeys(zones)
+	assert.Equal(t, []zone{{"a", 1}, {"a", 2}, {"b", 1}}, zones)
+}
+
+func TestGeofenceGroupPartition(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	group.Add(2, []*Geofence{square(0, 0, 10)}, []*Geofence{square(2, 2, 2)})
+	group.Add(3, []*Geofence{square(20, 20, 10)}, nil)
+	group.Add(4, nil, []*Geofence{square(0, 0, 5)})
+
+	valid, invalid := group.Partition(NewPoint(3, 3))
+	assert.Equal(t, map[int]bool{1: true}, valid)
+	assert.Equal(t, map[int]bool{2: true, 3: true, 4: true}, invalid)
+	assert.Equal(t, invalid, group.GetInvalidKeys(NewPoint(3, 3)))
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, group.GetInvalidKeys(NewPoint(50, 50)))
+
+	valid, invalid = NewGeofenceGroup[int]().Partition(NewPoint(3, 3))
+	assert.Empty(t, valid)
+	assert.Empty(t, invalid)
+}
+
+func TestGeofenceGroupMetadata(t *testing.T) {
+	type zone struct {
+		Label      string
+		SpeedLimit int
+	}
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	group.Add(2, []*Geofence{square(0, 0, 10)}, []*Geofence{square(2, 2, 2)})
+	assert.NoError(t, group.SetMetadata(1, zone{"school", 20}))
+	assert.Error(t, group.SetMetadata(3, zone{"missing", 0}))
+
+	metadata, ok := group.Metadata(1)
+	assert.True(t, ok)
+	assert.Equal(t, zone{"school", 20}, metadata)
+	metadata, ok = group.Metadata(2)
+	assert.True(t, ok)
+	assert.Nil(t, metadata)
+	_, ok = group.Metadata(3)
+	assert.False(t, ok)
+
+	assert.Equal(t, map[int]any{1: zone{"school", 20}, 2: nil}, group.GetValidKeysWithMetadata(NewPoint(1, 1)))
+	assert.Equal(t, map[int]any{1: zone{"school", 20}}, group.GetValidKeysWithMetadata(NewPoint(3, 3)))
+	assert.Equal(t, zone{"school", 20}, group.Evaluate(NewPoint(50, 50))[1].Metadata)
+
+	// Update keeps the metadata, Remove drops it
+	group.Update(1, []*Geofence{square(20, 20, 10)}, nil)
+	assert.Equal(t, map[int]any{1: zone{"school", 20}}, group.GetValidKeysWithMetadata(NewPoint(25, 25)))
+	group.Remove(1)
+	group.Add(1, []*Geofence{square(20, 20, 10)}, nil)
+	metadata, _ = group.Metadata(1)
+	assert.Nil(t, metadata)
+}
+
+func TestGeofenceGroupResolve(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("site", []*Geofence{square(0, 0, 10)}, nil)
+	group.Add("zone", []*Geofence{square(20, 20, 10), square(2, 2, 3)}, nil)
+	group.Add("charge", []*Geofence{square(1, 1, 8)}, nil)
+	group.Add("anywhere", nil, []*Geofence{square(40, 40, 1)})
+	assert.NoError(t, group.SetPriority("charge", 5))
+	assert.Error(t, group.SetPriority("missing", 1))
+	priority, ok := group.Priority("charge")
+	assert.True(t, ok)
+	assert.Equal(t, 5, priority)
+
+	inZone := NewPoint(3, 3)
+	key, ok := group.Resolve(inZone, ResolveInnermost)
+	assert.True(t, ok)
+	assert.Equal(t, "zone", key)
+	key, _ = group.Resolve(inZone, ResolveByPriority)
+	assert.Equal(t, "charge", key)
+
+	// Without priorities the innermost wins ties, and keys without a whitelist are outermost
+	assert.NoError(t, group.SetPriority("charge", 0))
+	key, _ = group.Resolve(inZone, ResolveByPriority)
+	assert.Equal(t, "zone", key)
+	key, _ = group.Resolve(NewPoint(9.5, 9.5), ResolveInnermost)
+	assert.Equal(t, "site", key)
+	key, _ = group.Resolve(NewPoint(50, 50), ResolveInnermost)
+	assert.Equal(t, "anywhere", key)
+	_, ok = group.Resolve(NewPoint(40.5, 40.5), ResolveByPriority)
+	assert.False(t, ok)
+
+	// Keys that tie on priority and area resolve in key order
+	ties := NewGeofenceGroup[int]()
+	ties.Add(3, []*Geofence{square(0, 0, 10)}, nil)
+	ties.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	for i := 0; i < 10; i++ {
+		key, _ := ties.Resolve(NewPoint(5, 5), ResolveInnermost)
+		assert.Equal(t, 1, key)
+	}
+}
+
+func TestGeofenceGroupEvaluate(t *testing.T) {
+	depot, yard, closed := square(0, 0, 10), square(20, 20, 10), square(4, 4, 2)
+	group := NewGeofenceGroup[string]()
+	group.Add("driver", []*Geofence{yard, depot}, []*Geofence{closed})
+	group.Add("anywhere", nil, []*Geofence{yard})
+
+	matches := group.Evaluate(NewPoint(1, 1))
+	assert.Equal(t, map[string]KeyMatch{
+		"driver":   {Valid: true, Whitelist: depot, WhitelistIndex: 1, BlacklistIndex: -1},
+		"anywhere": {Valid: true, WhitelistIndex: -1, BlacklistIndex: -1},
+	}, matches)
+
+	matches = group.Evaluate(NewPoint(5, 5))
+	assert.Equal(t, KeyMatch{Whitelist: depot, WhitelistIndex: 1, Blacklist: closed, BlacklistIndex: 0}, matches["driver"])
+
+	matches = group.Evaluate(NewPoint(25, 25))
+	assert.Equal(t, KeyMatch{Valid: true, Whitelist: yard, WhitelistIndex: 0, BlacklistIndex: -1}, matches["driver"])
+	assert.Equal(t, KeyMatch{WhitelistIndex: -1, Blacklist: yard, BlacklistIndex: 0}, matches["anywhere"])
+	assert.Equal(t, KeyMatch{WhitelistIndex: -1, BlacklistIndex: -1}, group.Evaluate(NewPoint(50, 50))["driver"])
+
+	for _, point := range []*Point{NewPoint(1, 1), NewPoint(5, 5), NewPoint(25, 25), NewPoint(50, 50)} {
+		valid := map[string]bool{}
+		for key, match := range group.Evaluate(point) {
+			if match.Valid {
+				valid[key] = true
+			}
+		}
+		assert.Equal(t, group.GetValidKeys(point), valid, "%v", point)
+	}
+}
+
+func TestGeofenceGroupExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	group := NewGeofenceGroup[string]()
+	group.now = func() time.Time { return now }
+
+	group.Add("route", []*Geofence{square(0, 0, 10)}, nil)
+	group.AddWithExpiry("route", nil, []*Geofence{square(4, 4, 2)}, now.Add(time.Hour))
+	group.AddWithExpiry("incident", []*Geofence{square(0, 0, 3)}, nil, now.Add(2*time.Hour))
+	group.AddWithExpiry("forever", []*Geofence{square(0, 0, 10)}, nil, time.Time{})
+
+	closed, stillOpen := NewPoint(5, 5), NewPoint(1, 1)
+	assert.Equal(t, map[string]bool{"forever": true}, group.GetValidKeys(closed))
+	assert.Equal(t, map[string]bool{"route": true, "incident": true, "forever": true}, group.GetValidKeys(stillOpen))
+
+	// The road closure expires and the route is valid through it again
+	now = now.Add(time.Hour)
+	assert.Equal(t, map[string]bool{"route": true, "forever": true}, group.GetValidKeys(closed))
+	assert.Nil(t, group.entries["route"].blacklistExpires)
+	assert.ElementsMatch(t, []string{"route", "incident", "forever"}, group.Keys())
+
+	// A key whose whitelist has expired is removed, rather than becoming valid everywhere
+	now = now.Add(time.Hour)
+	assert.ElementsMatch(t, []string{"route", "forever"}, group.Keys())
+	assert.Equal(t, map[string]bool{"route": true, "forever": true}, group.GetValidKeys(stillOpen))
+	assert.True(t, group.nextExpiry.IsZero())
+	assert.Len(t, group.Nearest(NewPoint(50, 50), 5), 2)
+
+	// An expiry in the past takes effect straight away, and only for the geofences it was given with
+	group.AddWithExpiry("forever", []*Geofence{square(20, 20, 10)}, nil, now.Add(-time.Minute))
+	assert.Equal(t, map[string]bool{"route": true, "forever": true}, group.GetValidKeys(stillOpen))
+	assert.Empty(t, group.GetValidKeys(NewPoint(25, 25)))
+	assert.Len(t, group.entries["forever"].whitelist, 1)
+}
+
+func TestGeofenceGroupConcurrent(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	fence := square(0, 0, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(key int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				group.Add(key, []*Geofence{fence}, nil)
+				group.Remove(key)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				group.GetValidKeys(NewPoint(5, 5))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Empty(t, group.GetValidKeys(NewPoint(5, 5)))
+}
+
+func TestGeofenceGroupConcurrentQueries(t *testing.T) {
+	// An admin goroutine reshapes the fences while queries see the steady key throughout
+	group := NewGeofenceGroup[int]()
+	group.Add(100, []*Geofence{square(0, 0, 100)}, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 200; j++ {
+			key := j % 10
+			group.Update(key, []*Geofence{square(float64(key*10), 0, 10)}, []*Geofence{square(float64(key*10), 0, 1)})
+			if j%3 == 0 {
+				group.Remove(key)
+			} else {
+				group.Add(key, []*Geofence{square(50, 50, 10)}, nil)
+			}
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				validKeys := group.GetValidKeys(randomPointCustom(0, 100, 0, 100, 1))
+				assert.True(t, validKeys[100])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// bruteForceValidKeys is GetValidKeys without the index.
+func bruteForceValidKeys(group *GeofenceGroup[int], point *Point) map[int]bool {
+	validKeys := make(map[int]bool)
+	for key, entry := range group.entries {
+		if entry.valid(point) {
+			validKeys[key] = true
+		}
+	}
+	return validKeys
+}
+
+func randomGroup(fences int) *GeofenceGroup[int] {
+	group := NewGeofenceGroup[int]()
+	for key := 0; key < fences; key++ {
+		center := randomPoint(1000)
+		var whitelist, blacklist []*Geofence
+		if key%50 != 0 {
+			whitelist = append(whitelist, NewCircleGeofence(center, 1+rand.Float64()*10, 8))
+		}
+		if key%7 == 0 {
+			blacklist = append(blacklist, NewCircleGeofence(center, 2, 8))
+		}
+		if key%500 == 0 {
+			whitelist = append(whitelist, NewCircleGeofence(center, 400, 8))
+		}
+		group.Add(key, whitelist, blacklist)
+	}
+	return group
+}
+
+func TestGeofenceGroupIndex(t *testing.T) {
+	group := randomGroup(500)
+	group.Add(1000, []*Geofence{NewGeofence([]*Point{NewPoint(-20, 170), NewPoint(-20, -170), NewPoint(-10, -170), NewPoint(-10, 170)})}, nil)
+
+	for i := 0; i < 20000; i++ {
+		if i == 10000 {
+			for key := 0; key < 500; key += 3 {
+				group.Remove(key)
+			}
+			group.Update(1, []*Geofence{square(-5, -5, 10)}, nil)
+		}
+		point := randomPoint(1000)
+		assert.Equal(t, bruteForceValidKeys(group, point), group.GetValidKeys(point))
+	}
+	assert.True(t, group.GetValidKeys(NewPoint(-15, -175))[1000])
+	assert.True(t, group.GetValidKeys(NewPoint(-15, 175))[1000])
+	assert.False(t, group.GetValidKeys(NewPoint(-15, 0))[1000])
+}
+
+func TestGeofenceGroupIndexAntimeridian(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("fiji", []*Geofence{NewGeofence([]*Point{NewPoint(-20, 175), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 175)})}, nil)
+	group.Add("samoa", []*Geofence{NewGeofence([]*Point{NewPoint(-15, -173), NewPoint(-15, -171), NewPoint(-13, -171), NewPoint(-13, -173)})}, nil)
+
+	assert.Equal(t, map[string]bool{"fiji": true}, group.GetValidKeys(NewPoint(-17, 179)))
+	// The fiji fence is indexed either side of the antimeridian rather than checked for every point
+	assert.Empty(t, group.index.always)
+	assert.Equal(t, map[string]bool{"fiji": true}, group.GetValidKeys(NewPoint(-17, -179)))
+	assert.Equal(t, map[string]bool{"fiji": true}, group.GetValidKeys(NewPoint(-17, 180)))
+	assert.Equal(t, map[string]bool{"samoa": true}, group.GetValidKeys(NewPoint(-14, -172)))
+	assert.Empty(t, group.GetValidKeys(NewPoint(-17, 170)))
+}
+
+func TestGeofenceGroupIndexMixedSizes(t *testing.T) {
+	// Thousands of small fences with a few continent sized ones
+	group := NewGeofenceGroup[int]()
+	for key := 0; key < 5000; key++ {
+		radius := 0.01 + rand.Float64()*0.1
+		if key%1000 == 0 {
+			radius = 40
+		}
+		group.Add(key, []*Geofence{NewCircleGeofence(NewPoint(rand.Float64()*120-60, rand.Float64()*360-180), radius, 8, WithGranularity(4))}, nil)
+	}
+	for i := 0; i < 500; i++ {
+		point := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+		assert.Equal(t, bruteForceValidKeys(group, point), group.GetValidKeys(point))
+	}
+	// Every fence is in the tree, however large
+	assert.Empty(t, group.index.always)
+
+	checked := 0
+	group.index.candidates(NewPoint(0, 0), func(int) { checked++ })
+	assert.Less(t, checked, 20)
+}
+
+func TestGeofenceGroupCellIndex(t *testing.T) {
+	group := NewGeofenceGroup[int](WithS2Index(12))
+	plain := NewGeofenceGroup[int]()
+	for key := 0; key < 1000; key++ {
+		center := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+		var whitelist, blacklist []*Geofence
+		if key%50 != 0 {
+			whitelist = append(whitelist, NewCircleGeofence(center, 0.1+rand.Float64()*2, 8, WithGranularity(4)))
+		}
+		if key%7 == 0 {
+			blacklist = append(blacklist, NewCircleGeofence(center, 0.1, 8))
+		}
+		group.Add(key, whitelist, blacklist)
+		plain.Add(key, whitelist, blacklist)
+	}
+	fiji := NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)})
+	group.Add(5000, []*Geofence{fiji}, nil)
+	plain.Add(5000, []*Geofence{fiji}, nil)
+
+	for i := 0; i < 2000; i++ {
+		point := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+		assert.Equal(t, plain.GetValidKeys(point), group.GetValidKeys(point))
+	}
+	assert.True(t, group.GetValidKeys(NewPoint(-17, -179))[5000])
+	assert.True(t, group.GetValidKeys(NewPoint(-17, 179))[5000])
+	assert.Len(t, group.index.always, 20)
+	assert.Nil(t, group.index.root)
+
+	// A geohash index gives the same keys again
+	geohash := NewGeofenceGroup[int](WithGeohashIndex(5))
+	for _, key := range plain.Keys() {
+		entry := plain.entries[key]
+		geohash.Add(key, entry.whitelist, entry.blacklist)
+	}
+	for i := 0; i < 2000; i++ {
+		point := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+		assert.Equal(t, plain.GetValidKeys(point), geohash.GetValidKeys(point))
+	}
+	assert.True(t, geohash.GetValidKeys(NewPoint(-17, -179))[5000])
+	assert.NotNil(t, geohash.index.geohashCells)
+
+	// An invalid level is ignored, and the last index option wins
+	assert.Equal(t, 0, NewGeofenceGroup[int](WithS2Index(31)).options.s2Level)
+	assert.Equal(t, groupOptions{geohashPrecision: 6}, NewGeofenceGroup[int](WithS2Index(10), WithGeohashIndex(6)).options)
+	assert.EqualError(t, WithGeohashIndex(13)(&groupOptions{}), "geohash index precision must be from 1 to 12, got 13")
+	assert.EqualError(t, WithS2Index(0)(&groupOptions{}), "S2 index level must be from 1 to 30, got 0")
+}
+
+func TestGeofenceGroupNearest(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("depot", []*Geofence{square(0, 0, 1)}, nil)
+	group.Add("yard", []*Geofence{square(0, 3, 1), square(5, 5, 1)}, nil)
+	group.Add("port", []*Geofence{square(10, 10, 1)}, nil)
+	group.Add("anywhere", nil, []*Geofence{square(0, 0, 1)})
+
+	nearest := group.Nearest(NewPoint(0.5, 1.9), 2)
+	assert.Len(t, nearest, 2)
+	assert.Equal(t, "depot", nearest[0].Key)
+	assert.Equal(t, "yard", nearest[1].Key)
+	assert.InDelta(t, NewPoint(0.5, 1.9).DistanceTo(NewPoint(0.5, 1)), nearest[0].Meters, 1)
+	assert.InDelta(t, NewPoint(0.5, 1.9).DistanceTo(NewPoint(0.5, 3)), nearest[1].Meters, 1)
+
+	// Fences containing the point come first, and each key is listed once
+	nearest = group.Nearest(NewPoint(5.5, 5.5), 5)
+	assert.Len(t, nearest, 3)
+	assert.Equal(t, "yard", nearest[0].Key)
+	assert.Less(t, nearest[0].Meters, 0.0)
+	assert.Equal(t, "port", nearest[1].Key)
+	assert.Equal(t, "depot", nearest[2].Key)
+
+	assert.Nil(t, group.Nearest(NewPoint(0, 0), 0))
+	assert.Empty(t, NewGeofenceGroup[int]().Nearest(NewPoint(0, 0), 3))
+}
+
+func TestGeofenceGroupNearestRandom(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	for key := 0; key < 500; key++ {
+		center := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+		group.Add(key, []*Geofence{NewCircleGeofence(center, 0.1+rand.Float64()*2, 8)}, nil)
+	}
+	for i := 0; i < 50; i++ {
+		point := NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+		nearest := group.Nearest(point, 5)
+		assert.Len(t, nearest, 5)
+
+		// Compare with every key measured
+		best := map[int]float64{}
+		for key, entry := range group.entries {
+			for _, geofence := range entry.whitelist {
+				distance := geofence.DistanceToBoundaryMeters(point)
+				if current, ok := best[key]; !ok || distance < current {
+					best[key] = distance
+				}
+			}
+		}
+		count := 0
+		for _, distance := range best {
+			if distance < nearest[4].Meters {
+				count++
+			}
+		}
+		assert.LessOrEqual(t, count, 4)
+		for _, keyDistance := range nearest {
+			assert.Equal(t, best[keyDistance.Key], keyDistance.Meters)
+		}
+	}
+}
+
+func TestGeofenceGroupNearestIndexed(t *testing.T) {
+	for _, opts := range [][]GroupOption{nil, {WithS2Index(8)}, {WithGeohashIndex(4)}} {
+		group := NewGeofenceGroup[int](opts...)
+		for key := 0; key < 300; key++ {
+			center := NewPoint(rand.Float64()*120-60, rand.Float64()*360-180)
+			group.Add(key, []*Geofence{NewCircleGeofence(center, 0.1+rand.Float64()*2, 8)}, nil)
+		}
+		// Across the antimeridian, and in units the tree does not hold
+		group.Add(300, []*Geofence{NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)})}, nil)
+		group.Add(301, []*Geofence{NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(0, 1), NewPointXY(1, 1), NewPointXY(1, 0)}, WithPlanar())}, nil)
+
+		points := []*Point{NewPoint(-17, 179.5), NewPoint(-17, -179.5), NewPoint(0.5, 0.5)}
+		for i := 0; i < 30; i++ {
+			points = append(points, NewPoint(rand.Float64()*180-90, rand.Float64()*360-180))
+		}
+		for _, point := range points {
+			best := map[int]float64{}
+			for key, entry := range group.entries {
+				best[key] = entry.whitelist[0].DistanceToBoundaryMeters(point)
+			}
+			nearest := group.Nearest(point, 5)
+			assert.Len(t, nearest, 5)
+			for i, keyDistance := range nearest {
+				assert.Equal(t, best[keyDistance.Key], keyDistance.Meters)
+				if i > 0 {
+					assert.LessOrEqual(t, nearest[i-1].Meters, keyDistance.Meters)
+				}
+			}
+			count := 0
+			for _, distance := range best {
+				if distance < nearest[4].Meters {
+					count++
+				}
+			}
+			assert.LessOrEqual(t, count, 4)
+
+			meters := nearest[4].Meters
+			within := group.WithinDistance(point, meters)
+			for _, key := range within {
+				assert.LessOrEqual(t, best[key], meters)
+			}
+			for key, distance := range best {
+				if distance <= meters {
+					assert.Contains(t, within, key)
+				}
+			}
+		}
+	}
+
+	// The walk stops once the bounding boxes are further than the nearest geofences
+	group := NewGeofenceGroup[int]()
+	for key := 0; key < 1000; key++ {
+		group.Add(key, []*Geofence{square(float64(key/40)*3-40, float64(key%40)*3-60, 1)}, nil)
+	}
+	group.rLockIndexed()
+	measured := 0
+	group.index.visitNearest(NewPoint(-0.5, 0.5), func() float64 { return 200000 }, func(key int, geofence *Geofence) {
+		measured++
+	})
+	group.mu.RUnlock()
+	assert.Equal(t, 1, measured)
+	assert.Len(t, group.WithinDistance(NewPoint(-0.5, 0.5), 200000), 1)
+}
+
+func TestGeofenceGroupWithinDistance(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("depot", []*Geofence{square(0, 0, 1)}, nil)
+	group.Add("yard", []*Geofence{square(0, 3, 1), square(5, 5, 1)}, nil)
+	group.Add("port", []*Geofence{square(10, 10, 1)}, nil)
+	group.Add("anywhere", nil, nil)
+
+	// About 100km from the depot and 122km from the yard
+	point := NewPoint(0.5, 1.9)
+	assert.Empty(t, group.WithinDistance(point, 90000))
+	assert.Equal(t, []string{"depot"}, group.WithinDistance(point, 110000))
+	assert.Equal(t, []string{"depot", "yard"}, group.WithinDistance(point, 125000))
+	assert.Equal(t, []string{"yard", "depot"}, group.WithinDistance(NewPoint(0.5, 2.1), 125000))
+
+	// Fences containing the point are within any distance
+	assert.Equal(t, []string{"yard"}, group.WithinDistance(NewPoint(5.5, 5.5), 0))
+	assert.Equal(t, []string{"yard", "port", "depot"}, group.WithinDistance(NewPoint(5.5, 5.5), 1e7))
+}
+
+func TestBoundsMeters(t *testing.T) {
+	fences := []*Geofence{
+		square(60, 10, 1),
+		square(-30, 100, 5),
+		NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)}),
+		NewMultiGeofence([][]*Point{square(0, 0, 1).Vertices(), square(0, 170, 1).Vertices()}),
+	}
+	for _, geofence := range fences {
+		for i := 0; i < 500; i++ {
+			point := NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+			assert.LessOrEqual(t, geofence.boundsMeters(point), math.Abs(geofence.DistanceToBoundaryMeters(point))+1e-6, point)
+		}
+	}
+	assert.Equal(t, 0.0, square(60, 10, 1).boundsMeters(NewPoint(60.5, 10.5)))
+	assert.InDelta(t, 111195, square(60, 10, 1).boundsMeters(NewPoint(62, 10.5)), 1)
+}
+
+func BenchmarkGeofenceGroup5000(b *testing.B) {
+	group := randomGroup(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		group.GetValidKeys(randomPoint(1000))
+	}
+}
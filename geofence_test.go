@@ -1,6 +1,7 @@
 package geofence
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 
@@ -10,7 +11,7 @@ import (
 func TestCorrectness(t *testing.T) {
 	polygon := randomPolygon(2000, 0.1)
 	geoPoly := NewPolygon(polygon)
-	geofence := NewGeofence(polygon, int64(20))
+	geofence := NewGeofence(polygon, WithGranularity(20))
 
 	for i := 0; i < 100000; i++ {
 		point := randomPoint(200)
@@ -18,11 +19,334 @@ func TestCorrectness(t *testing.T) {
 	}
 }
 
-/*===================================================
+func TestPolygonContainsLevelWithVertex(t *testing.T) {
+	// Points at the same lat as a vertex, beside its edges rather than on them
+	below := NewPolygon([]*Point{NewPoint(49, 1.5), NewPoint(50, 0.5), NewPoint(51, 1.5), NewPoint(51, 0), NewPoint(49, 0)})
+	assert.False(t, below.Contains(NewPoint(50, 1.4)))
+	assert.True(t, below.Contains(NewPoint(50, 0.4)))
+	above := NewPolygon([]*Point{NewPoint(49, 0.5), NewPoint(50, 1.5), NewPoint(51, 0.5), NewPoint(51, 2), NewPoint(49, 2)})
+	assert.False(t, above.Contains(NewPoint(50, 0.6)))
+	assert.True(t, above.Contains(NewPoint(50, 1.6)))
+}
+
+func TestWithGranularity(t *testing.T) {
+	polygon := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	assert.Equal(t, int64(defaultGranularity), NewGeofence(polygon).granularityX)
+	assert.Equal(t, int64(defaultGranularity), NewGeofence(polygon).granularityY)
+	assert.Equal(t, int64(40), NewGeofence(polygon, WithGranularity(40)).granularityX)
+	assert.Equal(t, int64(40), NewGeofence(polygon, WithGranularity(40)).granularityY)
+
+	geofence, err := newGeofence(polygon, nil, []Option{WithGranularity(0)})
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+	assert.Equal(t, int64(defaultGranularity), geofence.granularityX)
+	assert.NotPanics(t, func() {
+		assert.True(t, NewGeofence(polygon, WithGranularity(-1)).Inside(NewPoint(5, 5)))
+	})
+}
+
+func TestWithGranularityXY(t *testing.T) {
+	river := []*Point{NewPoint(0, 0), NewPoint(0, 20), NewPoint(1, 20), NewPoint(1, 0)}
+	geofence := NewGeofence(river, WithGranularityXY(4, 80))
+	assert.Equal(t, int64(4), geofence.granularityX)
+	assert.Equal(t, int64(80), geofence.granularityY)
+	assert.Equal(t, 0.25, geofence.tileWidth)
+	assert.Equal(t, 0.25, geofence.tileHeight)
+	assert.True(t, geofence.Inside(NewPoint(0.5, 10)))
+	assert.False(t, geofence.Inside(NewPoint(1.5, 10)))
+
+	_, err := NewGeofenceChecked(river, WithGranularityXY(4, 0))
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+}
+
+func TestWithAutoGranularity(t *testing.T) {
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	assert.Equal(t, int64(16), NewGeofence(square, WithAutoGranularity()).granularityX)
+	assert.Equal(t, int64(16), NewGeofence(square, WithAutoGranularity()).granularityY)
+	assert.Equal(t, int64(40), NewGeofence(square, WithAutoGranularity(), WithGranularity(40)).granularityX)
+	assert.Equal(t, int64(16), NewGeofence(square, WithGranularity(40), WithAutoGranularity()).granularityX)
+
+	// A thin band across the bounding box is mostly boundary at the default granularity
+	band := []*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(9, 10), NewPoint(10, 10), NewPoint(10, 9), NewPoint(1, 0)}
+	eitherFraction := func(geofence *Geofence) float64 {
+		either := 0
+		for _, tile := range geofence.tiles {
+			if tile == TILE_EITHER {
+				either++
+			}
+		}
+		return float64(either) / float64(geofence.granularityX*geofence.granularityY)
+	}
+	assert.Greater(t, eitherFraction(NewGeofence(band, WithGranularity(4))), 0.5)
+	assert.Less(t, eitherFraction(NewGeofence(band, WithAutoGranularity())), 0.3)
+
+	// A zigzag road, long and winding, is divided along its length into about square tiles
+	var road []*Point
+	for i := 0; i <= 200; i++ {
+		road = append(road, NewPoint(float64(i), float64(i%2)))
+	}
+	for i := 200; i >= 0; i-- {
+		road = append(road, NewPoint(float64(i), float64(i%2)+0.5))
+	}
+	zigzag := NewGeofence(road, WithAutoGranularity())
+	assert.Greater(t, zigzag.granularityX, int64(1000))
+	assert.Equal(t, int64(17), zigzag.granularityY)
+	assert.InDelta(t, 1, zigzag.tileWidth/zigzag.tileHeight, 0.1)
+	for i := 0; i < 1000; i++ {
+		point := NewPoint(rand.Float64()*200, rand.Float64()*1.5)
+		assert.Equal(t, zigzag.polygon.Contains(point), zigzag.Inside(point))
+	}
+
+	// A winding boundary in a square gets as fine a grid as the tiling budget allows
+	coast := NewGeofence(randomPolygon(2000, 0.1), WithAutoGranularity())
+	assert.Equal(t, int64(math.Floor(math.Sqrt(autoTilingBudget/1001))), coast.granularityX)
+	for i := 0; i < 1000; i++ {
+		point := randomPoint(200)
+		assert.Equal(t, coast.polygon.Contains(point), coast.Inside(point))
+	}
+}
+
+func TestWithTileSizeMeters(t *testing.T) {
+	// About 1.1km on each side at latitude 60, where a degree of longitude is half as long
+	square := []*Point{NewPoint(60, 0), NewPoint(60, 0.02), NewPoint(60.01, 0.02), NewPoint(60.01, 0)}
+	geofence := NewGeofence(square, WithTileSizeMeters(50))
+	assert.InDelta(t, 50/111412.0, geofence.tileWidth, 1e-7)
+	assert.InDelta(t, 50/55800.0, geofence.tileHeight, 1e-6)
+	assert.InDelta(t, 23, geofence.maxTileX-geofence.minTileX+1, 1)
+	assert.InDelta(t, 23, geofence.maxTileY-geofence.minTileY+1, 1)
+	for i := 0; i < 1000; i++ {
+		point := NewPoint(60+rand.Float64()*0.012-0.001, rand.Float64()*0.024-0.002)
+		assert.Equal(t, geofence.polygon.Contains(point), geofence.Inside(point))
+	}
+
+	// The last of WithGranularity, WithAutoGranularity and WithTileSizeMeters wins
+	assert.InDelta(t, 0.01/20, NewGeofence(square, WithTileSizeMeters(50), WithGranularity(20)).tileWidth, 1e-12)
+	assert.Equal(t, geofence.tileWidth, NewGeofence(square, WithAutoGranularity(), WithTileSizeMeters(50)).tileWidth)
+	assert.InDelta(t, 0.01/12, NewGeofence(square, WithTileSizeMeters(50), WithAutoGranularity()).tileWidth, 1e-12)
+
+	// Tiny tiles on a large geofence are capped, as is the tile height near the pole
+	large := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, WithTileSizeMeters(1))
+	assert.Equal(t, 10.0/maxMetricTiles, large.tileWidth)
+	assert.Equal(t, 10.0/maxMetricTiles, large.tileHeight)
+	polar := NewGeofence([]*Point{NewPoint(89.99, 0), NewPoint(89.99, 90), NewPoint(90, 90), NewPoint(90, 0)}, WithTileSizeMeters(1000))
+	assert.Equal(t, 90.0, polar.tileHeight)
+	assert.True(t, polar.Inside(NewPoint(89.995, 45)))
+
+	for _, meters := range []float64{0, -5, math.NaN(), math.Inf(1)} {
+		_, err := NewGeofenceChecked(square, WithTileSizeMeters(meters))
+		assert.Error(t, err)
+	}
+}
+
+func TestWithHoles(t *testing.T) {
+	polygon := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 4), NewPoint(4, 4), NewPoint(4, 2)}
+	other := []*Point{NewPoint(6, 6), NewPoint(6, 8), NewPoint(8, 8), NewPoint(8, 6)}
+
+	geofence := NewGeofenceWithHoles(polygon, [][]*Point{hole}, WithHoles(other), WithBoundaryInclusive(false))
+	assert.Equal(t, [][]*Point{hole, other}, geofence.Holes())
+	assert.False(t, geofence.Inside(NewPoint(3, 3)))
+	assert.False(t, geofence.Inside(NewPoint(7, 7)))
+	assert.True(t, geofence.Inside(NewPoint(5, 5)))
+	assert.False(t, geofence.Inside(NewPoint(0, 5)))
+	assert.True(t, NewGeofence(polygon, WithBoundaryInclusive(true)).Inside(NewPoint(0, 5)))
+
+	bbox := NewBBoxGeofence(0, 0, 10, 10, WithHoles(hole))
+	assert.False(t, bbox.rect)
+	assert.False(t, bbox.Inside(NewPoint(3, 3)))
+	assert.True(t, bbox.Inside(NewPoint(5, 5)))
+}
+
+func TestBounds(t *testing.T) {
+	outer := []*Point{NewPoint(41.6, -87.9), NewPoint(42.1, -87.9), NewPoint(42.1, -87.4), NewPoint(41.6, -87.4)}
+	hole := []*Point{NewPoint(41.8, -87.7), NewPoint(41.9, -87.7), NewPoint(41.9, -87.6)}
+	min, max := NewGeofenceWithHoles(outer, [][]*Point{hole}).Bounds()
+	assert.Equal(t, NewPoint(41.6, -87.9), min)
+	assert.Equal(t, NewPoint(42.1, -87.4), max)
+
+	single := NewGeofence([]*Point{NewPoint(1, 2)})
+	min, max = single.Bounds()
+	assert.Equal(t, NewPoint(1, 2), min)
+	assert.Equal(t, NewPoint(1, 2), max)
+	assert.False(t, single.Inside(NewPoint(1, 2)))
+
+	line := NewGeofence([]*Point{NewPoint(1, 2), NewPoint(3, 2)})
+	min, max = line.Bounds()
+	assert.Equal(t, NewPoint(1, 2), min)
+	assert.Equal(t, NewPoint(3, 2), max)
+
+	min, max = NewGeofence(nil).Bounds()
+	assert.Nil(t, min)
+	assert.Nil(t, max)
+}
+
+func TestBBox(t *testing.T) {
+	outer := []*Point{NewPoint(41.6, -87.9), NewPoint(42.1, -87.9), NewPoint(42.1, -87.4), NewPoint(41.6, -87.4)}
+	minLat, minLng, maxLat, maxLng := NewGeofence(outer).BBox()
+	assert.Equal(t, []float64{41.6, -87.9, 42.1, -87.4}, []float64{minLat, minLng, maxLat, maxLng})
+
+	minLat, minLng, maxLat, maxLng = NewBBoxGeofence(-10, 170, 10, -170).BBox()
+	assert.Equal(t, []float64{-10, 170, 10, -170}, []float64{minLat, minLng, maxLat, maxLng})
+
+	minLat, minLng, maxLat, maxLng = NewGeofence(nil).BBox()
+	assert.Equal(t, []float64{0, 0, 0, 0}, []float64{minLat, minLng, maxLat, maxLng})
+}
+
+func TestTileStats(t *testing.T) {
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2.5, 2.5), NewPoint(2.5, 7.5), NewPoint(7.5, 7.5), NewPoint(7.5, 2.5)}
+	geofence := NewGeofenceWithHoles(square, [][]*Point{hole}, WithGranularity(10))
+	in, out, either := geofence.TileStats()
+	// The grid runs to the tile holding the max corner, so it is 11 x 11
+	assert.Equal(t, 121, in+out+either)
+	// Tiles touching an edge are TILE_EITHER: rows and columns 0, 9 and 10 for the outer ring,
+	// and the border of the 6 x 6 block from 2 to 7 for the hole
+	assert.Equal(t, (121-8*8)+(6*6-4*4), either)
+	assert.Equal(t, 8*8-6*6, in)
+	assert.Equal(t, 4*4, out)
+
+	in, out, either = NewGeofence(square, WithGranularity(20)).TileStats()
+	assert.Equal(t, 441, in+out+either)
+	assert.Greater(t, in, either)
+
+	multi := NewMultiGeofence([][]*Point{square, hole})
+	multiIn, multiOut, multiEither := multi.TileStats()
+	holeIn, holeOut, holeEither := NewGeofence(hole).TileStats()
+	squareIn, squareOut, squareEither := NewGeofence(square).TileStats()
+	assert.Equal(t, []int{squareIn + holeIn, squareOut + holeOut, squareEither + holeEither}, []int{multiIn, multiOut, multiEither})
+
+	in, out, either = NewBBoxGeofence(0, 0, 10, 10).TileStats()
+	assert.Equal(t, []int{0, 0, 0}, []int{in, out, either})
+	in, out, either = NewGeofence(nil).TileStats()
+	assert.Equal(t, []int{0, 0, 0}, []int{in, out, either})
+}
+
+func TestTileHash(t *testing.T) {
+	// Offset coordinates with thin tiles, where float keys would be fragile
+	var polygon []*Point
+	for _, point := range randomPolygon(0.002, 1) {
+		polygon = append(polygon, NewPoint(point.Lat()+51.5, point.Lng()-0.12))
+	}
+	for _, geofence := range []*Geofence{NewGeofence(polygon, WithGranularity(37)), NewGeodesicGeofence(polygon)} {
+		tilesX := int64(geofence.maxTileX - geofence.minTileX + 1)
+		tilesY := int64(geofence.maxTileY - geofence.minTileY + 1)
+		seen := make(map[int64]bool)
+		for tileX := geofence.minTileX; tileX <= geofence.maxTileX; tileX++ {
+			for tileY := geofence.minTileY; tileY <= geofence.maxTileY; tileY++ {
+				tileHash := geofence.tileHash(tileX, tileY)
+				assert.False(t, seen[tileHash])
+				seen[tileHash] = true
+				assert.Equal(t, tileX, float64(tileHash%tilesX)+geofence.minTileX)
+				assert.Equal(t, tileY, float64(tileHash/tilesX)+geofence.minTileY)
+
+				// Every tile that is not crossed by an edge agrees with the exact check at its center
+				center := NewPoint((tileX+0.5)*geofence.tileWidth, (tileY+0.5)*geofence.tileHeight)
+				switch geofence.tiles[tileHash] {
+				case TILE_IN:
+					assert.True(t, geofence.contains(center))
+				case TILE_OUT, 0:
+					assert.False(t, geofence.contains(center))
+				}
+			}
+		}
+		assert.Len(t, seen, int(tilesX*tilesY))
+		for tileHash := range geofence.tiles {
+			assert.True(t, seen[tileHash])
+		}
+	}
+}
+
+func TestInsideAllocations(t *testing.T) {
+	polygon := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 5), NewPoint(5, 0)}
+	geofence := NewGeofence(polygon, WithGranularity(2))
+	point := NewPoint(7, 5)
+	tileHash := geofence.tileHash(project(point.Lat(), geofence.tileWidth), project(point.Lng(), geofence.tileHeight))
+	assert.Equal(t, byte(TILE_EITHER), geofence.tiles[tileHash])
+
+	assert.True(t, geofence.Inside(point))
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		geofence.Inside(point)
+	}))
+}
+
+func TestHoles(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	island := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole, island}, WithGranularity(20))
+
+	assert.True(t, geofence.Inside(NewPoint(1, 1)))
+	assert.False(t, geofence.Inside(NewPoint(3, 3)))
+	assert.True(t, geofence.Inside(NewPoint(5, 5)))
+	assert.False(t, geofence.Inside(NewPoint(11, 5)))
+
+	// A point exactly on a hole edge must fall in a tile that runs the fine check
+	onEdge := NewPoint(2, 5)
+	polygon := NewPolygonWithHoles(outer, [][]*Point{hole, island})
+	assert.True(t, geofence.Inside(onEdge))
+	assert.False(t, NewGeofenceWithHoles(outer, [][]*Point{hole, island}, WithBoundary(Exclusive)).Inside(onEdge))
+	tileHash := geofence.tileHash(project(onEdge.Lat(), geofence.tileWidth), project(onEdge.Lng(), geofence.tileHeight))
+	assert.Equal(t, byte(TILE_EITHER), geofence.tiles[tileHash])
+
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(0, 10, 0, 10, 1.2)
+		assert.Equal(t, polygon.Contains(point), geofence.Inside(point))
+	}
+}
+
+func TestGeodesic(t *testing.T) {
+	// A band between 60N and 70N, the great circle edges bulge north of the parallels
+	polygon := []*Point{NewPoint(60, -30), NewPoint(60, 30), NewPoint(70, 30), NewPoint(70, -30)}
+	planar := NewGeofence(polygon)
+	geodesic := NewGeodesicGeofence(polygon)
+
+	assert.True(t, planar.Inside(NewPoint(61, 0)))
+	assert.False(t, geodesic.Inside(NewPoint(61, 0)))
+	assert.False(t, planar.Inside(NewPoint(71, 0)))
+	assert.True(t, geodesic.Inside(NewPoint(71, 0)))
+
+	assert.True(t, geodesic.Inside(NewPoint(65, 0)))
+	assert.True(t, geodesic.Inside(NewPoint(61, 29)))
+	assert.False(t, geodesic.Inside(NewPoint(65, 31)))
+	assert.False(t, geodesic.Inside(NewPoint(59, 0)))
+
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(60, 70, -30, 30, 1.4)
+		assert.Equal(t, sphericalRingContains(polygon, point), geodesic.Inside(point))
+	}
+}
+
+func TestWithDensify(t *testing.T) {
+	// The great circle between the northern vertices bulges to about 63.4°N
+	polygon := []*Point{NewPoint(60, -30), NewPoint(60, 30), NewPoint(50, 30), NewPoint(50, -30)}
+	north := NewPoint(62, 0)
+	assert.False(t, NewGeofence(polygon).Inside(north))
+	densified := NewGeofence(polygon, WithDensify(10000))
+	assert.True(t, densified.Inside(north))
+	assert.True(t, NewGeodesicGeofence(polygon).Inside(north))
+	assert.Greater(t, len(densified.Vertices()), 100)
+	assert.NotNil(t, densified.buckets)
+
+	geodesic := NewGeodesicGeofence(polygon)
+	mismatches := 0
+	for i := 0; i < 10000; i++ {
+		point := NewPoint(45+rand.Float64()*20, rand.Float64()*70-35)
+		if densified.Inside(point) != geodesic.Inside(point) {
+			mismatches++
+		}
+	}
+	assert.Less(t, mismatches, 10)
+
+	_, err := NewGeofenceChecked(polygon, WithDensify(0))
+	assert.EqualError(t, err, "densify segment must be a positive number of meters, got 0")
+}
+
+/*
+===================================================
 Benchmark Result 1st version:
 BenchmarkGeofence	10000000	       109 ns/op
 BenchmarkGeoContains	 3000000	       475 ns/op
-====================================================*/
+====================================================
+*/
 func BenchmarkGeofence(b *testing.B) {
 	// Chicago geofence
 	polygon := []*Point{
@@ -76,6 +400,35 @@ func BenchmarkGeofence(b *testing.B) {
 	}
 }
 
+func BenchmarkGeofenceEitherTile(b *testing.B) {
+	polygon := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	geofence := NewGeofence(polygon)
+	point := NewPoint(0.1, 5.1)
+	tileHash := geofence.tileHash(project(point.Lat(), geofence.tileWidth), project(point.Lng(), geofence.tileHeight))
+	if geofence.tiles[tileHash] != TILE_EITHER {
+		b.Fatal("benchmark point is not in a TILE_EITHER tile")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geofence.Inside(point)
+	}
+}
+
+func BenchmarkGeofenceEitherTileVertexLng(b *testing.B) {
+	// The point shares a longitude with a vertex, so the ray cast nudges it
+	polygon := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 5), NewPoint(5, 0)}
+	geofence := NewGeofence(polygon, WithGranularity(2))
+	point := NewPoint(7, 5)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geofence.Inside(point)
+	}
+}
+
 func BenchmarkGeoContains(b *testing.B) {
 	// Chicago geofence
 	polygon := []*Point{
@@ -146,3 +499,61 @@ func randomPointCustom(minLat float64, maxLat float64, minLng float64, maxLng fl
 	lngRange := maxLng - minLng
 	return NewPoint((minLat+maxLat)/2-latRange*factor/2+latRange*factor*rand.Float64(), (minLng+maxLng)/2-lngRange*factor/2+lngRange*factor*rand.Float64())
 }
+
+func TestWithPlanar(t *testing.T) {
+	// A 340m long shed on a local grid centred on its middle
+	shed := []*Point{NewPointXY(0, -170), NewPointXY(10, -170), NewPointXY(10, 170), NewPointXY(0, 170)}
+	// As lat/lng it would be a fence between 170° and -170° across the antimeridian
+	assert.False(t, NewGeofence(shed).Inside(NewPointXY(5, 0)))
+
+	geofence := NewGeofence(shed, WithPlanar(), WithTileSizeMeters(5))
+	assert.False(t, geofence.wrapLng)
+	assert.True(t, geofence.Inside(NewPointXY(5, 0)))
+	assert.True(t, geofence.Inside(NewPointXY(5, 169)))
+	assert.False(t, geofence.Inside(NewPointXY(11, 0)))
+	assert.Equal(t, 5.0, geofence.tileWidth)
+	assert.Equal(t, 5.0, geofence.tileHeight)
+	assert.InDelta(t, -4, geofence.DistanceToBoundary(NewPointXY(4, 0)), 1e-9)
+	assert.InDelta(t, 3, geofence.DistanceToBoundary(NewPointXY(13, 0)), 1e-9)
+
+	// The last of WithPlanar and WithGeodesic wins, and geographic options do not apply
+	assert.False(t, NewGeofence(shed, WithGeodesic(), WithPlanar()).geodesic)
+	assert.False(t, NewGeofence(shed, WithPlanar(), WithGeodesic()).planar)
+	_, err := NewGeofenceChecked([]*Point{NewPointXY(0, 0), NewPointXY(500, 0), NewPointXY(500, 300)}, WithPlanar(), WithCoordinateOrder(LatLng))
+	assert.NoError(t, err)
+	assert.Len(t, NewGeofence(shed, WithPlanar(), WithDensify(1)).Vertices(), 4)
+
+	point := NewPointXY(3, 4)
+	assert.Equal(t, 3.0, point.X())
+	assert.Equal(t, 4.0, point.Y())
+}
+
+func TestWithAltitudeRange(t *testing.T) {
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	zone := NewGeofence(square, WithAltitudeRange(0, 120))
+	assert.True(t, zone.Inside(NewPointWithAltitude(5, 5, 50)))
+	assert.True(t, zone.Inside(NewPointWithAltitude(5, 5, 120)))
+	assert.False(t, zone.Inside(NewPointWithAltitude(5, 5, 121)))
+	assert.False(t, zone.Inside(NewPointWithAltitude(5, 5, -1)))
+	assert.False(t, zone.Inside(NewPointWithAltitude(15, 5, 50)))
+	// Without an altitude only the outline is checked
+	assert.True(t, zone.Inside(NewPoint(5, 5)))
+	assert.False(t, NewGeofence(square, WithAltitudeRange(0, 120), WithBoundary(Exclusive)).Inside(NewPointWithAltitude(5, 5, 120)))
+
+	// The range carries through to each polygon, rectangles and rebuilt geofences
+	for _, geofence := range []*Geofence{
+		NewMultiGeofence([][]*Point{square, {NewPoint(20, 20), NewPoint(20, 21), NewPoint(21, 21)}}, WithAltitudeRange(10, 20)),
+		NewBBoxGeofence(0, 0, 10, 10, WithAltitudeRange(10, 20)),
+		NewGeofence(square, WithAltitudeRange(10, 20)).Simplify(1),
+		NewGeofence(square, WithAltitudeRange(10, 20)).Clone(),
+	} {
+		assert.True(t, geofence.Inside(NewPointWithAltitude(5, 5, 15)))
+		assert.False(t, geofence.Inside(NewPointWithAltitude(5, 5, 25)))
+		assert.False(t, geofence.Inside(NewPointWithAltitude(5, 5, 5)))
+	}
+
+	for _, bad := range [][2]float64{{10, 5}, {math.NaN(), 5}, {0, math.NaN()}} {
+		_, err := newGeofence(square, nil, []Option{WithAltitudeRange(bad[0], bad[1])})
+		assert.Error(t, err)
+	}
+}
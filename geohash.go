@@ -0,0 +1,132 @@
+package geofence
+
+import (
+	"sort"
+	"strings"
+)
+
+// geohashAlphabet is the base 32 alphabet of geohashes, without a, i, l and o.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashMaxPrecision is the longest geohash, whose cells are a few centimeters across.
+const GeohashMaxPrecision = 12
+
+// Geohash returns the geohash of the point with the given number of characters, from 1 to
+// GeohashMaxPrecision, e.g. "gcpvj" for central London at precision 5. Precisions outside
+// the range are clamped.
+func Geohash(point *Point, precision int) string {
+	precision = clampLevel(precision, GeohashMaxPrecision)
+	if precision == 0 {
+		precision = 1
+	}
+	minLat, maxLat, minLng, maxLng := -90.0, 90.0, -180.0, 180.0
+	var builder strings.Builder
+	even := true
+	for builder.Len() < precision {
+		index := 0
+		for bit := 0; bit < 5; bit++ {
+			// Bits alternate between lng and lat, starting with lng
+			index <<= 1
+			if even {
+				if mid := (minLng + maxLng) / 2; point.Lng() >= mid {
+					index |= 1
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				if mid := (minLat + maxLat) / 2; point.Lat() >= mid {
+					index |= 1
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+		builder.WriteByte(geohashAlphabet[index])
+	}
+	return builder.String()
+}
+
+// geohashBounds returns the rectangle of the geohash's cell.
+func geohashBounds(hash string) (minLat, minLng, maxLat, maxLng float64) {
+	minLat, maxLat, minLng, maxLng = -90, 90, -180, 180
+	even := true
+	for i := 0; i < len(hash); i++ {
+		index := strings.IndexByte(geohashAlphabet, hash[i])
+		for bit := 4; bit >= 0; bit-- {
+			set := index>>bit&1 == 1
+			if even {
+				if mid := (minLng + maxLng) / 2; set {
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				if mid := (minLat + maxLat) / 2; set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return minLat, minLng, maxLat, maxLng
+}
+
+// GeohashCovering returns geohashes, no longer than the precision, whose cells together cover
+// the geofence, in increasing order. As with S2Covering, cells wholly inside the geofence are kept
+// as large as possible, so a point's geohash at the precision is inside the geofence's covering
+// when it starts with one of them, e.g. for prefix queries in Redis or Elasticsearch. A geofence
+// without vertices returns nil.
+func (geofence *Geofence) GeohashCovering(precision int) []string {
+	precision = clampLevel(precision, GeohashMaxPrecision)
+	if precision == 0 {
+		precision = 1
+	}
+	return geofence.geohashCovering(precision, 0)
+}
+
+// geohashCovering returns the covering at the precision, stopping short of it when maxCells is
+// positive and splitting the boundary cells any further would need more cells than that.
+func (geofence *Geofence) geohashCovering(precision, maxCells int) []string {
+	if geofence.empty() {
+		return nil
+	}
+	classifier := newRectClassifier(geofence)
+
+	var covering []string
+	frontier := geohashChildren("")
+	for len(frontier) > 0 {
+		var boundary []string
+		for _, hash := range frontier {
+			switch classifier.classify(geohashBounds(hash)) {
+			case TILE_IN:
+				covering = append(covering, hash)
+			case TILE_EITHER:
+				boundary = append(boundary, hash)
+			}
+		}
+		frontier = nil
+		for _, hash := range boundary {
+			if len(hash) == precision || (maxCells > 0 && len(covering)+32*len(boundary) > maxCells) {
+				covering = append(covering, hash)
+				continue
+			}
+			frontier = append(frontier, geohashChildren(hash)...)
+		}
+	}
+	sort.Strings(covering)
+	return covering
+}
+
+// geohashChildren returns the 32 geohashes one character longer than the hash.
+func geohashChildren(hash string) []string {
+	children := make([]string, len(geohashAlphabet))
+	for i := range geohashAlphabet {
+		children[i] = hash + geohashAlphabet[i:i+1]
+	}
+	return children
+}
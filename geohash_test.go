@@ -0,0 +1,63 @@
+package geofence
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	// Well known geohashes
+	assert.Equal(t, "gcpvj", Geohash(NewPoint(51.5074, -0.1278), 5))
+	assert.Equal(t, "u4pruydqqvj", Geohash(NewPoint(57.64911, 10.40744), 11))
+	assert.Equal(t, "s", Geohash(NewPoint(0.1, 0.1), 0))
+	assert.Len(t, Geohash(NewPoint(0, 0), 20), GeohashMaxPrecision)
+
+	minLat, minLng, maxLat, maxLng := geohashBounds("u4pruydqqvj")
+	assert.True(t, minLat <= 57.64911 && 57.64911 <= maxLat)
+	assert.True(t, minLng <= 10.40744 && 10.40744 <= maxLng)
+	assert.Less(t, maxLat-minLat, 1e-5)
+}
+
+func TestGeohashCovering(t *testing.T) {
+	fences := []*Geofence{
+		NewGeofence([]*Point{NewPoint(51.50, -0.13), NewPoint(51.50, -0.11), NewPoint(51.51, -0.11), NewPoint(51.51, -0.13)}),
+		NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, [][]*Point{{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}}),
+		NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)}),
+	}
+	for _, geofence := range fences {
+		covering := geofence.GeohashCovering(5)
+		assert.NotEmpty(t, covering)
+		min, max := geofence.Bounds()
+		for i := 0; i < 300; i++ {
+			lng := min.Lng() + rand.Float64()*(max.Lng()-min.Lng())
+			if geofence.wrapLng {
+				lng = min.Lng() + rand.Float64()*(max.Lng()+360-min.Lng())
+			}
+			point := NewPoint(min.Lat()+rand.Float64()*(max.Lat()-min.Lat()), lng)
+			if !geofence.Inside(point) {
+				continue
+			}
+			hash, found := Geohash(point, 5), false
+			for _, cell := range covering {
+				found = found || strings.HasPrefix(hash, cell)
+			}
+			assert.True(t, found, point)
+		}
+	}
+
+	// The hole's cells are left out and cells inside are kept whole
+	covering := fences[1].GeohashCovering(4)
+	assert.NotContains(t, covering, Geohash(NewPoint(5, 5), 4))
+	lengths := map[int]bool{}
+	for _, hash := range covering {
+		lengths[len(hash)] = true
+	}
+	assert.Greater(t, len(lengths), 1)
+	for _, hash := range NewBBoxGeofence(51.50, -0.13, 51.51, -0.12).GeohashCovering(5) {
+		assert.True(t, strings.HasPrefix(hash, "gcp"), hash)
+	}
+	assert.Nil(t, NewGeofence(nil).GeohashCovering(5))
+}
@@ -0,0 +1,359 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// GeoJSON positions are ordered [lng, lat], the opposite of NewPoint(lat, lng),
+// so every conversion below goes through positionToPoint and pointToPosition.
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometry    *geoJSONObject  `json:"geometry,omitempty"`
+}
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+type geoJSONMultiPolygon struct {
+	Type        string           `json:"type"`
+	Coordinates [][][][2]float64 `json:"coordinates"`
+}
+
+// NewGeofenceFromGeoJSON builds a Geofence from a GeoJSON Polygon or MultiPolygon geometry,
+// or a Feature with one. In each polygon the first ring is the outer boundary and any
+// further rings are holes. A MultiPolygon becomes a Geofence as built by NewMultiGeofence.
+// Options are applied as for NewGeofence.
+func NewGeofenceFromGeoJSON(data []byte, opts ...Option) (*Geofence, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("unable to decode GeoJSON: %v", err)
+	}
+	if obj.Type == "Feature" {
+		if obj.Geometry == nil {
+			return nil, fmt.Errorf("GeoJSON feature has no geometry")
+		}
+		obj = *obj.Geometry
+	}
+	return geoJSONGeometryToGeofence(&obj, opts)
+}
+
+// FromGeoJSON builds a Geofence from GeoJSON, it is the same as NewGeofenceFromGeoJSON.
+func FromGeoJSON(data []byte, opts ...Option) (*Geofence, error) {
+	return NewGeofenceFromGeoJSON(data, opts...)
+}
+
+// GeoJSONBlacklistProperty is the feature property that, when true, makes
+// NewGeofenceGroupFromGeoJSON add the feature's geofence to its key's blacklist.
+const GeoJSONBlacklistProperty = "blacklist"
+
+// NewGeofenceGroupFromGeoJSON builds a GeofenceGroup from a GeoJSON FeatureCollection, keyed by
+// the keyProperty of each feature, a string or a number. Each feature's Polygon or MultiPolygon
+// is added to its key's whitelist, or to its blacklist when its GeoJSONBlacklistProperty is
+// true, so several features may share a key. Options are applied to each geofence as for
+// NewGeofence.
+func NewGeofenceGroupFromGeoJSON(data []byte, keyProperty string, opts ...Option) (*GeofenceGroup[string], error) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   *geoJSONObject             `json:"geometry"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("unable to decode GeoJSON: %v", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("GeoJSON type %q is not a FeatureCollection", collection.Type)
+	}
+
+	group := NewGeofenceGroup[string]()
+	for i, feature := range collection.Features {
+		key, err := geoJSONKey(feature.Properties[keyProperty])
+		if err != nil {
+			return nil, fmt.Errorf("GeoJSON feature %d property %q: %v", i, keyProperty, err)
+		}
+		var blacklist bool
+		if value, ok := feature.Properties[GeoJSONBlacklistProperty]; ok && string(value) != "null" {
+			if err := json.Unmarshal(value, &blacklist); err != nil {
+				return nil, fmt.Errorf("GeoJSON feature %d property %q must be a boolean", i, GeoJSONBlacklistProperty)
+			}
+		}
+		if feature.Geometry == nil {
+			return nil, fmt.Errorf("GeoJSON feature %d has no geometry", i)
+		}
+		geofence, err := geoJSONGeometryToGeofence(feature.Geometry, opts)
+		if err != nil {
+			return nil, fmt.Errorf("GeoJSON feature %d: %v", i, err)
+		}
+		if blacklist {
+			group.Add(key, nil, []*Geofence{geofence})
+		} else {
+			group.Add(key, []*Geofence{geofence}, nil)
+		}
+	}
+	return group, nil
+}
+
+// geoJSONKey returns a string or number property as a group key, numbers as written.
+func geoJSONKey(value json.RawMessage) (string, error) {
+	var key interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if len(value) == 0 || decoder.Decode(&key) != nil {
+		return "", fmt.Errorf("is missing")
+	}
+	switch key := key.(type) {
+	case string:
+		return key, nil
+	case json.Number:
+		return key.String(), nil
+	case nil:
+		return "", fmt.Errorf("is missing")
+	}
+	return "", fmt.Errorf("must be a string or a number")
+}
+
+func geoJSONGeometryToGeofence(obj *geoJSONObject, opts []Option) (*Geofence, error) {
+	switch obj.Type {
+	case "Polygon":
+		rings, err := decodeGeoJSONRings(obj.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		return NewGeofenceWithHoles(rings[0], rings[1:], opts...), nil
+	case "MultiPolygon":
+		var polygons []json.RawMessage
+		if err := json.Unmarshal(obj.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("unable to decode GeoJSON coordinates: %v", err)
+		}
+		if len(polygons) == 0 {
+			// As ToGeoJSON writes a geofence without vertices
+			return NewGeofence(nil, opts...), nil
+		}
+		parts := make([]*Geofence, len(polygons))
+		for i, polygon := range polygons {
+			rings, err := decodeGeoJSONRings(polygon)
+			if err != nil {
+				return nil, fmt.Errorf("GeoJSON polygon %d: %v", i, err)
+			}
+			parts[i] = NewGeofenceWithHoles(rings[0], rings[1:], opts...)
+		}
+		return newMultiGeofence(parts), nil
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON geometry type %q", obj.Type)
+	}
+}
+
+// ToGeoJSON renders the Geofence as a GeoJSON Polygon with the holes as interior rings,
+// or as a MultiPolygon for a NewMultiGeofence. A geofence without vertices, such as the
+// Intersect of disjoint fences, is a MultiPolygon with no polygons, since a Polygon needs a ring.
+func (geofence *Geofence) ToGeoJSON() ([]byte, error) {
+	if len(geofence.parts) > 0 || geofence.empty() {
+		multi := geoJSONMultiPolygon{Type: "MultiPolygon", Coordinates: [][][][2]float64{}}
+		for _, part := range geofence.parts {
+			if !part.empty() {
+				multi.Coordinates = append(multi.Coordinates, part.polygonPositions())
+			}
+		}
+		return json.Marshal(multi)
+	}
+	return json.Marshal(geoJSONPolygon{Type: "Polygon", Coordinates: geofence.polygonPositions()})
+}
+
+// ToGeoJSONFeature renders the Geofence as a GeoJSON Feature whose geometry is as for
+// ToGeoJSON, with the given properties. Nil properties are rendered as an empty object.
+func (geofence *Geofence) ToGeoJSONFeature(properties map[string]interface{}) ([]byte, error) {
+	geometry, err := geofence.ToGeoJSON()
+	if err != nil {
+		return nil, err
+	}
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	feature := struct {
+		Type       string                 `json:"type"`
+		Geometry   json.RawMessage        `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}{"Feature", geometry, properties}
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode GeoJSON feature: %v", err)
+	}
+	return data, nil
+}
+
+// polygonPositions returns the GeoJSON rings of a single polygon geofence.
+func (geofence *Geofence) polygonPositions() [][][2]float64 {
+	positions := [][][2]float64{ringToPositions(geofence.unwrapRing(geofence.vertices))}
+	for _, hole := range geofence.holes {
+		positions = append(positions, ringToPositions(geofence.unwrapRing(hole)))
+	}
+	return positions
+}
+
+func decodeGeoJSONRings(data json.RawMessage) ([][]*Point, error) {
+	var coordinates [][][]float64
+	if err := json.Unmarshal(data, &coordinates); err != nil {
+		return nil, fmt.Errorf("unable to decode GeoJSON coordinates: %v", err)
+	}
+	if len(coordinates) == 0 {
+		return nil, fmt.Errorf("GeoJSON polygon has no rings")
+	}
+
+	rings := make([][]*Point, len(coordinates))
+	for i, positions := range coordinates {
+		if len(positions) < 4 {
+			return nil, fmt.Errorf("GeoJSON ring %d has %d positions, at least 4 are required", i, len(positions))
+		}
+		ring := make([]*Point, 0, len(positions))
+		for _, position := range positions {
+			point, err := positionToPoint(position)
+			if err != nil {
+				return nil, err
+			}
+			ring = append(ring, point)
+		}
+		// GeoJSON rings repeat the first position at the end, Geofence rings do not
+		if first, last := ring[0], ring[len(ring)-1]; first.lat == last.lat && first.lng == last.lng {
+			ring = ring[:len(ring)-1]
+		}
+		rings[i] = ring
+	}
+	return rings, nil
+}
+
+// positionToPoint converts a GeoJSON [lng, lat] position into a Point.
+func positionToPoint(position []float64) (*Point, error) {
+	if len(position) < 2 {
+		return nil, fmt.Errorf("GeoJSON position %v needs both lng and lat", position)
+	}
+	return NewPoint(position[1], position[0]), nil
+}
+
+// pointToPosition converts a Point into a GeoJSON [lng, lat] position.
+func pointToPosition(point *Point) [2]float64 {
+	return [2]float64{point.Lng(), point.Lat()}
+}
+
+func ringToPositions(ring []*Point) [][2]float64 {
+	positions := make([][2]float64, 0, len(ring)+1)
+	for _, point := range closeRing(ring) {
+		positions = append(positions, pointToPosition(point))
+	}
+	return positions
+}
+
+type geoJSONTileFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPolygon         `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// TilesToGeoJSON renders the tile grid as a GeoJSON FeatureCollection with a Polygon Feature
+// per tile, whose "tile" property is "IN", "OUT" or "EITHER" and whose "x" and "y" properties
+// give its place in the grid. It is meant for debugging, overlaid on the geofence in a viewer
+// such as geojson.io. Tiles split by WithRefinement are rendered as their smallest quarters,
+// with a "depth" property. The tiles of each polygon of a NewMultiGeofence are included
+// with a "part" property giving the polygon's index.
+func (geofence *Geofence) TilesToGeoJSON() ([]byte, error) {
+	features := []geoJSONTileFeature{}
+	if len(geofence.parts) > 0 {
+		for i, part := range geofence.parts {
+			for _, feature := range part.tileFeatures() {
+				feature.Properties["part"] = i
+				features = append(features, feature)
+			}
+		}
+	} else {
+		features = append(features, geofence.tileFeatures()...)
+	}
+
+	collection := struct {
+		Type     string               `json:"type"`
+		Features []geoJSONTileFeature `json:"features"`
+	}{"FeatureCollection", features}
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode GeoJSON tiles: %v", err)
+	}
+	return data, nil
+}
+
+// tileFeatures returns a feature for each tile of a single polygon geofence.
+func (geofence *Geofence) tileFeatures() []geoJSONTileFeature {
+	var features []geoJSONTileFeature
+	if geofence.rect || geofence.tileWidth == 0 || geofence.tileHeight == 0 {
+		return features
+	}
+
+	for tileX := geofence.minTileX; tileX <= geofence.maxTileX; tileX++ {
+		for tileY := geofence.minTileY; tileY <= geofence.maxTileY; tileY++ {
+			tileHash := geofence.tileHash(tileX, tileY)
+			if bucket := geofence.buckets[tileHash]; bucket != nil && bucket.children != nil {
+				features = geofence.appendBucketFeatures(features, bucket, tileX, tileY, 0)
+				continue
+			}
+			features = append(features, geofence.tileFeature(tileX*geofence.tileWidth, tileY*geofence.tileHeight,
+				(tileX+1)*geofence.tileWidth, (tileY+1)*geofence.tileHeight, geofence.tiles[tileHash],
+				map[string]interface{}{"x": tileX, "y": tileY}))
+		}
+	}
+	return features
+}
+
+// appendBucketFeatures appends a feature for each of the smallest quarters of a refined bucket.
+func (geofence *Geofence) appendBucketFeatures(features []geoJSONTileFeature, bucket *tileBucket, tileX, tileY float64, depth int) []geoJSONTileFeature {
+	if bucket.children == nil {
+		tile := bucket.tile
+		if tile == 0 {
+			tile = TILE_EITHER
+		}
+		return append(features, geofence.tileFeature(bucket.minX, bucket.minY, bucket.maxX, bucket.maxY, tile,
+			map[string]interface{}{"x": tileX, "y": tileY, "depth": depth}))
+	}
+	for _, child := range bucket.children {
+		features = geofence.appendBucketFeatures(features, child, tileX, tileY, depth+1)
+	}
+	return features
+}
+
+// tileFeature returns the feature for a tile, or part of one, covering minX to maxX in lat and
+// minY to maxY in lng. Tiles of a geofence crossing the antimeridian are moved back by 360
+// degrees of lng as a whole when they start east of it, and the corners of projected tiles
+// are unprojected.
+func (geofence *Geofence) tileFeature(minX, minY, maxX, maxY float64, tile byte, properties map[string]interface{}) geoJSONTileFeature {
+	if geofence.wrapLng && minY >= 180 {
+		minY, maxY = minY-360, maxY-360
+	}
+	properties["tile"] = tileName(tile)
+	ring := [][2]float64{{minY, minX}, {maxY, minX}, {maxY, maxX}, {minY, maxX}, {minY, minX}}
+	if geofence.projection != nil {
+		for i, position := range ring {
+			ring[i] = pointToPosition(geofence.projection.Unproject(NewPointXY(position[1], position[0])))
+		}
+	}
+	return geoJSONTileFeature{
+		Type:       "Feature",
+		Geometry:   geoJSONPolygon{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+		Properties: properties,
+	}
+}
+
+// tileName returns the name of a tile value, tiles missing from the map being TILE_OUT.
+func tileName(tile byte) string {
+	switch tile {
+	case TILE_IN:
+		return "IN"
+	case TILE_EITHER:
+		return "EITHER"
+	default:
+		return "OUT"
+	}
+}
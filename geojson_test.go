@@ -0,0 +1,201 @@
+package geofence
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoJSONRoundTrip(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+
+	data, err := geofence.ToGeoJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"Polygon","coordinates":[
+		[[0,0],[10,0],[10,10],[0,10],[0,0]],
+		[[2,2],[8,2],[8,8],[2,8],[2,2]]]}`, string(data))
+
+	decoded, err := FromGeoJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, geofence.vertices, decoded.vertices)
+	assert.Equal(t, geofence.holes, decoded.holes)
+	for i := 0; i < 1000; i++ {
+		point := randomPointCustom(0, 10, 0, 10, 1.2)
+		assert.Equal(t, geofence.Inside(point), decoded.Inside(point))
+	}
+}
+
+func TestFromGeoJSONFeature(t *testing.T) {
+	geofence, err := FromGeoJSON([]byte(`{"type":"Feature","properties":{},"geometry":
+		{"type":"Polygon","coordinates":[[[-87.9,41.6],[-87.4,41.6],[-87.4,42.1],[-87.9,42.1],[-87.9,41.6]]]}}`))
+	assert.NoError(t, err)
+	assert.Len(t, geofence.vertices, 4)
+	assert.Equal(t, NewPoint(41.6, -87.9), geofence.vertices[0])
+	assert.True(t, geofence.Inside(NewPoint(41.8, -87.6)))
+	assert.False(t, geofence.Inside(NewPoint(-87.6, 41.8)))
+}
+
+func TestNewGeofenceFromGeoJSONMultiPolygon(t *testing.T) {
+	data := []byte(`{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]],
+		[[[20,20],[30,20],[30,30],[20,30],[20,20]]]]}`)
+	geofence, err := NewGeofenceFromGeoJSON(data)
+	assert.NoError(t, err)
+	assert.Len(t, geofence.parts, 2)
+	assert.True(t, geofence.Inside(NewPoint(1, 1)))
+	assert.False(t, geofence.Inside(NewPoint(5, 5)))
+	assert.True(t, geofence.Inside(NewPoint(25, 25)))
+	assert.False(t, geofence.Inside(NewPoint(15, 15)))
+
+	encoded, err := geofence.ToGeoJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(data), string(encoded))
+
+	_, err = NewGeofenceFromGeoJSON([]byte(`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,0]]]]}`))
+	assert.EqualError(t, err, "GeoJSON polygon 0: GeoJSON ring 0 has 3 positions, at least 4 are required")
+	empty, err := NewGeofenceFromGeoJSON([]byte(`{"type":"MultiPolygon","coordinates":[]}`))
+	assert.NoError(t, err)
+	assert.True(t, empty.empty())
+}
+
+func TestEmptyGeoJSONRoundTrip(t *testing.T) {
+	// Disjoint squares have no intersection
+	disjoint := Intersect(square(0, 0, 1), square(5, 5, 1))
+	for _, geofence := range []*Geofence{disjoint, NewGeofence(nil), NewMultiGeofence(nil)} {
+		encoded, err := geofence.ToGeoJSON()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type":"MultiPolygon","coordinates":[]}`, string(encoded))
+		decoded, err := NewGeofenceFromGeoJSON(encoded)
+		assert.NoError(t, err)
+		assert.True(t, decoded.empty())
+		assert.False(t, decoded.Inside(NewPoint(0.5, 0.5)))
+		assert.Equal(t, "POLYGON EMPTY", geofence.WKT())
+	}
+}
+
+func TestToGeoJSONFeature(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+
+	data, err := geofence.ToGeoJSONFeature(map[string]interface{}{"name": "depot", "speedLimit": 30})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"Feature","properties":{"name":"depot","speedLimit":30},"geometry":{"type":"Polygon","coordinates":[
+		[[0,0],[10,0],[10,10],[0,10],[0,0]],
+		[[2,2],[8,2],[8,8],[2,8],[2,2]]]}}`, string(data))
+
+	decoded, err := NewGeofenceFromGeoJSON(data)
+	assert.NoError(t, err)
+	assert.Equal(t, geofence.holes, decoded.holes)
+
+	data, err = geofence.ToGeoJSONFeature(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"properties":{}`)
+
+	_, err = geofence.ToGeoJSONFeature(map[string]interface{}{"bad": func() {}})
+	assert.Error(t, err)
+}
+
+func TestFromGeoJSONErrors(t *testing.T) {
+	_, err := FromGeoJSON([]byte(`{"type":"LineString","coordinates":[[0,0],[1,1]]}`))
+	assert.EqualError(t, err, `unsupported GeoJSON geometry type "LineString"`)
+
+	_, err = FromGeoJSON([]byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]}}`))
+	assert.EqualError(t, err, `unsupported GeoJSON geometry type "Point"`)
+
+	_, err = FromGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`))
+	assert.EqualError(t, err, "GeoJSON ring 0 has 3 positions, at least 4 are required")
+
+	_, err = FromGeoJSON([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestTilesToGeoJSON(t *testing.T) {
+	type collection struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates [][][2]float64
+			}
+			Properties map[string]interface{}
+		}
+	}
+	decode := func(geofence *Geofence) collection {
+		data, err := geofence.TilesToGeoJSON()
+		assert.NoError(t, err)
+		var decoded collection
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "FeatureCollection", decoded.Type)
+		return decoded
+	}
+
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2.5, 2.5), NewPoint(2.5, 7.5), NewPoint(7.5, 7.5), NewPoint(7.5, 2.5)}
+	geofence := NewGeofenceWithHoles(square, [][]*Point{hole}, WithGranularity(10))
+	tiles := decode(geofence)
+	assert.Len(t, tiles.Features, 121)
+	counts := map[interface{}]int{}
+	for _, feature := range tiles.Features {
+		counts[feature.Properties["tile"]]++
+		if feature.Properties["x"] == 1.0 && feature.Properties["y"] == 3.0 {
+			// Positions are [lng, lat]
+			assert.Equal(t, [][][2]float64{{{3, 1}, {4, 1}, {4, 2}, {3, 2}, {3, 1}}}, feature.Geometry.Coordinates)
+			assert.Equal(t, "IN", feature.Properties["tile"])
+		}
+	}
+	in, out, either := geofence.TileStats()
+	assert.Equal(t, map[interface{}]int{"IN": in, "OUT": out, "EITHER": either}, counts)
+
+	// Refined tiles are rendered as their quarters
+	refined := decode(NewGeofence([]*Point{NewPoint(0.5, 0.5), NewPoint(0.5, 9.5), NewPoint(9.5, 9.5)}, WithGranularity(2), WithRefinement(2)))
+	assert.Greater(t, len(refined.Features), 9)
+	depths := map[interface{}]bool{}
+	for _, feature := range refined.Features {
+		depths[feature.Properties["depth"]] = true
+	}
+	assert.True(t, depths[2.0])
+
+	multi := decode(NewMultiGeofence([][]*Point{square, hole}))
+	assert.Equal(t, 0.0, multi.Features[0].Properties["part"])
+	assert.Equal(t, 1.0, multi.Features[len(multi.Features)-1].Properties["part"])
+
+	// Tiles east of the antimeridian are moved back into range
+	for _, feature := range decode(NewGeofence([]*Point{NewPoint(-10, 170), NewPoint(-10, -170), NewPoint(10, -170), NewPoint(10, 170)})).Features {
+		assert.LessOrEqual(t, feature.Geometry.Coordinates[0][0][0], 180.0)
+		assert.GreaterOrEqual(t, feature.Geometry.Coordinates[0][0][0], -180.0)
+	}
+
+	assert.Empty(t, decode(NewBBoxGeofence(0, 0, 10, 10)).Features)
+}
+
+func TestNewGeofenceGroupFromGeoJSON(t *testing.T) {
+	group, err := NewGeofenceGroupFromGeoJSON([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"zone":"depot"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}},
+		{"type":"Feature","properties":{"zone":"depot","blacklist":true},"geometry":{"type":"Polygon","coordinates":[[[4,4],[6,4],[6,6],[4,6],[4,4]]]}},
+		{"type":"Feature","properties":{"zone":"depot","blacklist":false},"geometry":{"type":"Polygon","coordinates":[[[20,20],[21,20],[21,21],[20,21],[20,20]]]}},
+		{"type":"Feature","properties":{"zone":12,"name":"yard"},"geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,2],[0,0]]]]}}
+	]}`), "zone", WithGranularity(5))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"depot", "12"}, group.Keys())
+	assert.Equal(t, map[string]bool{"depot": true, "12": true}, group.GetValidKeys(NewPoint(1, 1)))
+	assert.Equal(t, map[string]bool{"depot": true}, group.GetValidKeys(NewPoint(8, 8)))
+	assert.Equal(t, map[string]bool{}, group.GetValidKeys(NewPoint(5, 5)))
+	assert.Equal(t, map[string]bool{"depot": true}, group.GetValidKeys(NewPoint(20.5, 20.5)))
+	assert.Equal(t, int64(5), group.entries["depot"].whitelist[0].granularityX)
+
+	for _, bad := range []string{
+		`{"type":"Feature","properties":{"zone":"a"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"name":"a"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"zone":["a"]},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"zone":"a","blacklist":"yes"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"zone":"a"},"geometry":null}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"zone":"a"},"geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+	} {
+		_, err := NewGeofenceGroupFromGeoJSON([]byte(bad), "zone")
+		assert.Error(t, err, bad)
+	}
+}
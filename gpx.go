@@ -0,0 +1,78 @@
+package geofence
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// GPXCorridor is a corridor geofence along a GPX track or route, with its name.
+type GPXCorridor struct {
+	Name     string
+	Geofence *Geofence
+}
+
+type gpxFile struct {
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Name   string     `xml:"name"`
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+// NewCorridorGeofencesFromGPX builds a corridor Geofence, as by NewCorridorGeofence, covering
+// every point within widthMeters/2 of each track and then each route in a GPX file, e.g. to
+// alert a rider who leaves the planned route. The segments of a track are separate corridors
+// combined into the one geofence, so the gaps between them are not covered. Tracks and routes
+// without points are skipped. Options are applied to each geofence as for NewCorridorGeofence.
+func NewCorridorGeofencesFromGPX(data []byte, widthMeters float64, opts ...Option) ([]GPXCorridor, error) {
+	if !(widthMeters > 0) {
+		return nil, fmt.Errorf("corridor width must be positive, got %v", widthMeters)
+	}
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to decode GPX: %v", err)
+	}
+
+	var corridors []GPXCorridor
+	add := func(name string, paths [][]gpxPoint) {
+		var parts []*Geofence
+		for _, path := range paths {
+			if len(path) == 0 {
+				continue
+			}
+			points := make([]*Point, len(path))
+			for i, point := range path {
+				points[i] = NewPoint(point.Lat, point.Lon)
+			}
+			parts = append(parts, NewCorridorGeofence(points, widthMeters, opts...).polygons()...)
+		}
+		if len(parts) > 0 {
+			corridors = append(corridors, GPXCorridor{Name: strings.TrimSpace(name), Geofence: newMultiGeofence(parts)})
+		}
+	}
+	for _, track := range file.Tracks {
+		paths := make([][]gpxPoint, len(track.Segments))
+		for i, segment := range track.Segments {
+			paths[i] = segment.Points
+		}
+		add(track.Name, paths)
+	}
+	for _, route := range file.Routes {
+		add(route.Name, [][]gpxPoint{route.Points})
+	}
+	if len(corridors) == 0 {
+		return nil, fmt.Errorf("GPX has no tracks or routes with points")
+	}
+	return corridors, nil
+}
@@ -0,0 +1,59 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCorridorGeofencesFromGPX(t *testing.T) {
+	gpx := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <name> Morning ride </name>
+    <trkseg>
+      <trkpt lat="51.5000" lon="-0.1000"><ele>12</ele></trkpt>
+      <trkpt lat="51.5000" lon="-0.0900"></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="51.5100" lon="-0.0900"></trkpt>
+      <trkpt lat="51.5100" lon="-0.0800"></trkpt>
+    </trkseg>
+  </trk>
+  <trk><name>Empty</name><trkseg></trkseg></trk>
+  <rte>
+    <name>Planned</name>
+    <rtept lat="52.0" lon="0.0"></rtept>
+    <rtept lat="52.01" lon="0.0"></rtept>
+  </rte>
+</gpx>`
+	corridors, err := NewCorridorGeofencesFromGPX([]byte(gpx), 100, WithGranularity(5))
+	assert.NoError(t, err)
+	if assert.Len(t, corridors, 2) {
+		ride := corridors[0]
+		assert.Equal(t, "Morning ride", ride.Name)
+		assert.True(t, ride.Geofence.geodesic)
+		assert.True(t, ride.Geofence.Inside(NewPoint(51.5, -0.095)))
+		assert.True(t, ride.Geofence.Inside(NewPoint(51.5004, -0.095)))
+		assert.False(t, ride.Geofence.Inside(NewPoint(51.5006, -0.095)))
+		assert.True(t, ride.Geofence.Inside(NewPoint(51.51, -0.085)))
+		// The gap between the segments is not covered
+		assert.False(t, ride.Geofence.Inside(NewPoint(51.505, -0.09)))
+
+		planned := corridors[1]
+		assert.Equal(t, "Planned", planned.Name)
+		assert.True(t, planned.Geofence.Inside(NewPoint(52.005, 0.0005)))
+		assert.False(t, planned.Geofence.Inside(NewPoint(52.005, 0.001)))
+	}
+
+	for _, bad := range []string{
+		`<gpx><trk><trkseg></trkseg></trk></gpx>`,
+		`<gpx><trk><trkseg><trkpt lat="north" lon="0"/></trkseg></trk></gpx>`,
+		`<gpx><trk>`,
+	} {
+		_, err := NewCorridorGeofencesFromGPX([]byte(bad), 100)
+		assert.Error(t, err, bad)
+	}
+	_, err = NewCorridorGeofencesFromGPX([]byte(gpx), 0)
+	assert.Error(t, err)
+}
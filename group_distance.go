@@ -0,0 +1,163 @@
+package geofence
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// KeyDistance is a key of a GeofenceGroup with the distance in meters from a point to the
+// boundary of its nearest whitelist geofence, negative when the point is inside it.
+type KeyDistance[K comparable] struct {
+	Key    K
+	Meters float64
+}
+
+// Nearest returns up to k keys whose whitelist geofences are nearest the point, nearest first,
+// with the distance to each as for DistanceToBoundaryMeters. Keys whose geofences contain the
+// point have negative distances and so come first. Keys without a whitelist are left out and
+// blacklists are ignored. The group's R-tree is walked nearest bounding box first, so only the
+// geofences that could be among the nearest are measured.
+func (group *GeofenceGroup[K]) Nearest(point *Point, k int) []KeyDistance[K] {
+	if k <= 0 {
+		return nil
+	}
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	var nearest []KeyDistance[K]
+	limit := func() float64 {
+		if len(nearest) < k {
+			return math.Inf(1)
+		}
+		return nearest[k-1].Meters
+	}
+	group.index.visitNearest(point, limit, func(key K, geofence *Geofence) {
+		distance := geofence.DistanceToBoundaryMeters(point)
+		nearest = insertKeyDistance(nearest, KeyDistance[K]{key, distance}, k)
+	})
+	return nearest
+}
+
+// WithinDistance returns the keys with a whitelist geofence containing the point or whose
+// boundary is no more than meters from it, nearest first, e.g. for approach alerts before an
+// entity is inside. Keys without a whitelist are left out and blacklists are ignored.
+func (group *GeofenceGroup[K]) WithinDistance(point *Point, meters float64) []K {
+	group.rLockIndexed()
+	defer group.mu.RUnlock()
+
+	var within []KeyDistance[K]
+	limit := func() float64 {
+		return meters
+	}
+	group.index.visitNearest(point, limit, func(key K, geofence *Geofence) {
+		if distance := geofence.DistanceToBoundaryMeters(point); distance <= meters {
+			within = insertKeyDistance(within, KeyDistance[K]{key, distance}, math.MaxInt32)
+		}
+	})
+	keys := make([]K, len(within))
+	for i, keyDistance := range within {
+		keys[i] = keyDistance.Key
+	}
+	return keys
+}
+
+// nearItem is a node of the nearest tree waiting to be visited, with a lower bound of the
+// distance from the point to the geofences below it.
+type nearItem[K comparable] struct {
+	node  *indexNode[K]
+	bound float64
+}
+
+// nearQueue is a min-heap of nodes by their bound, for container/heap.
+type nearQueue[K comparable] []nearItem[K]
+
+func (queue nearQueue[K]) Len() int           { return len(queue) }
+func (queue nearQueue[K]) Less(i, j int) bool { return queue[i].bound < queue[j].bound }
+func (queue nearQueue[K]) Swap(i, j int)      { queue[i], queue[j] = queue[j], queue[i] }
+
+func (queue *nearQueue[K]) Push(item interface{}) {
+	*queue = append(*queue, item.(nearItem[K]))
+}
+
+func (queue *nearQueue[K]) Pop() interface{} {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return item
+}
+
+// visitNearest calls visit with each whitelist geofence and its key in order of the lower bound
+// of their distance from the point, until the next bound is beyond limit(). Points inside a
+// bounding box may be inside the geofence, so those bounds are -Inf.
+func (index *groupIndex[K]) visitNearest(point *Point, limit func() float64, visit func(key K, geofence *Geofence)) {
+	queue := &nearQueue[K]{}
+	push := func(node *indexNode[K]) {
+		var bound float64
+		if node.geofence != nil {
+			bound = node.geofence.boundsMeters(point)
+		} else {
+			bound = boxMeters(point, node.minX, node.maxX, node.minY, node.maxY)
+		}
+		if bound == 0 {
+			bound = math.Inf(-1)
+		}
+		heap.Push(queue, nearItem[K]{node, bound})
+	}
+	if index.nearRoot != nil {
+		push(index.nearRoot)
+	}
+	for _, leaf := range index.nearOthers {
+		push(leaf)
+	}
+
+	// Geofences crossing the antimeridian have a leaf for each side
+	type leaf struct {
+		key      K
+		geofence *Geofence
+	}
+	visited := make(map[leaf]bool)
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(nearItem[K])
+		if item.bound > limit() {
+			return
+		}
+		if node := item.node; node.geofence != nil {
+			if !visited[leaf{node.key, node.geofence}] {
+				visited[leaf{node.key, node.geofence}] = true
+				visit(node.key, node.geofence)
+			}
+			continue
+		}
+		for _, child := range item.node.children {
+			push(child)
+		}
+	}
+}
+
+// insertKeyDistance adds the key distance to the sorted list, keeping only the nearest distance
+// of each key and at most limit keys.
+func insertKeyDistance[K comparable](list []KeyDistance[K], keyDistance KeyDistance[K], limit int) []KeyDistance[K] {
+	for i, existing := range list {
+		if existing.Key == keyDistance.Key {
+			if existing.Meters <= keyDistance.Meters {
+				return list
+			}
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i].Meters > keyDistance.Meters
+	})
+	if i >= limit {
+		return list
+	}
+	list = append(list, KeyDistance[K]{})
+	copy(list[i+1:], list[i:])
+	list[i] = keyDistance
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
@@ -0,0 +1,256 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// indexNodeCapacity is the most children of a node of the group index.
+const indexNodeCapacity = 16
+
+// maxCoveringCells is the most S2 cells covering each geofence WithS2Index.
+const maxCoveringCells = 64
+
+// maxGeohashCells is the most geohashes covering each geofence WithGeohashIndex. Geohashes split
+// into 32 rather than 4, so more are allowed.
+const maxGeohashCells = 256
+
+// groupIndex is an R-tree over the bounding boxes of a group's whitelist geofences, so
+// GetValidKeys only checks the keys whose geofences could contain the point. The tree is
+// packed with the Sort-Tile-Recursive algorithm, as the group rebuilds it whenever the
+// entries change rather than updating it in place. WithS2Index and WithGeohashIndex replace
+// the tree with a map from the cells covering each geofence to its keys.
+type groupIndex[K comparable] struct {
+	root             *indexNode[K]
+	s2Level          int
+	s2Cells          map[uint64][]K
+	geohashPrecision int
+	geohashCells     map[string][]K
+	// always holds the keys that must be checked for every point: keys without a whitelist,
+	// and keys with geofences the index cannot hold. The tree cannot hold projected geofences,
+	// whose bounds are not lat/lng, and cells cannot hold WithPlanar geofences.
+	always []K
+	// nearRoot is an R-tree over the bounding boxes of the whitelist geofences in lat/lng, for
+	// Nearest and WithinDistance, whose leaves hold the geofences. nearOthers are leaves for the
+	// WithPlanar and projected geofences, whose bounds are in other units, and are always measured.
+	nearRoot   *indexNode[K]
+	nearOthers []*indexNode[K]
+}
+
+// indexNode is a node of the R-tree, with the bounding box of everything below it. Nodes
+// without children are the bounding boxes of the geofences of key, or of the one geofence in
+// the nearest tree.
+type indexNode[K comparable] struct {
+	minX, maxX, minY, maxY float64
+	children               []*indexNode[K]
+	key                    K
+	geofence               *Geofence
+}
+
+// newGroupIndex builds the tree bottom up from a box for each whitelist geofence, or two for
+// geofences crossing the antimeridian, or the cell map WithS2Index or WithGeohashIndex.
+func newGroupIndex[K comparable](entries map[K]*groupEntry, options groupOptions) *groupIndex[K] {
+	if options.s2Level > 0 || options.geohashPrecision > 0 {
+		return newCellGroupIndex(entries, options)
+	}
+	index := &groupIndex[K]{}
+	index.nearRoot, index.nearOthers = newNearestTree(entries)
+
+	var nodes []*indexNode[K]
+	for key, entry := range entries {
+		if len(entry.whitelist) == 0 || entry.projected() {
+			index.always = append(index.always, key)
+			continue
+		}
+		for _, geofence := range entry.whitelist {
+			if geofence.empty() {
+				continue
+			}
+			for _, lngRange := range geofence.lngRanges() {
+				nodes = append(nodes, &indexNode[K]{minX: geofence.minX, maxX: geofence.maxX, minY: lngRange[0], maxY: lngRange[1], key: key})
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return index
+	}
+	index.root = packIndexTree(nodes)
+	return index
+}
+
+// newNearestTree builds the tree of the whitelist geofences in lat/lng, with a leaf for each
+// geofence, or two for geofences crossing the antimeridian, and returns the leaves of the
+// geofences it cannot hold.
+func newNearestTree[K comparable](entries map[K]*groupEntry) (*indexNode[K], []*indexNode[K]) {
+	var nodes, others []*indexNode[K]
+	for key, entry := range entries {
+		for _, geofence := range entry.whitelist {
+			switch {
+			case geofence.empty():
+			case geofence.projected() || geofence.planarPolygons():
+				others = append(others, &indexNode[K]{key: key, geofence: geofence})
+			default:
+				for _, lngRange := range geofence.lngRanges() {
+					nodes = append(nodes, &indexNode[K]{minX: geofence.minX, maxX: geofence.maxX, minY: lngRange[0], maxY: lngRange[1], key: key, geofence: geofence})
+				}
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, others
+	}
+	return packIndexTree(nodes), others
+}
+
+// packIndexTree packs the leaves level by level into a tree and returns its root.
+func packIndexTree[K comparable](nodes []*indexNode[K]) *indexNode[K] {
+	for len(nodes) > indexNodeCapacity {
+		nodes = packIndexLevel(nodes)
+	}
+	return newIndexParent(nodes)
+}
+
+// newCellGroupIndex maps the S2 cells or geohashes covering each whitelist geofence to its key.
+func newCellGroupIndex[K comparable](entries map[K]*groupEntry, options groupOptions) *groupIndex[K] {
+	index := &groupIndex[K]{s2Level: options.s2Level, geohashPrecision: options.geohashPrecision}
+	index.nearRoot, index.nearOthers = newNearestTree(entries)
+	if index.s2Level > 0 {
+		index.s2Cells = make(map[uint64][]K)
+	} else {
+		index.geohashCells = make(map[string][]K)
+	}
+	for key, entry := range entries {
+		if len(entry.whitelist) == 0 || entry.planar() {
+			index.always = append(index.always, key)
+			continue
+		}
+		for _, geofence := range entry.whitelist {
+			if index.s2Cells != nil {
+				for _, cell := range geofence.s2Covering(index.s2Level, maxCoveringCells) {
+					index.s2Cells[cell] = append(index.s2Cells[cell], key)
+				}
+				continue
+			}
+			for _, hash := range geofence.geohashCovering(index.geohashPrecision, maxGeohashCells) {
+				index.geohashCells[hash] = append(index.geohashCells[hash], key)
+			}
+		}
+	}
+	return index
+}
+
+// packIndexLevel groups the nodes into parents of up to indexNodeCapacity nodes each. The
+// nodes are sorted into vertical slices by lat, and each slice into runs by lng, so that
+// each parent covers a compact area.
+func packIndexLevel[K comparable](nodes []*indexNode[K]) []*indexNode[K] {
+	parents := (len(nodes) + indexNodeCapacity - 1) / indexNodeCapacity
+	sliceSize := int(math.Ceil(math.Sqrt(float64(parents)))) * indexNodeCapacity
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].minX+nodes[i].maxX < nodes[j].minX+nodes[j].maxX
+	})
+	packed := make([]*indexNode[K], 0, parents)
+	for start := 0; start < len(nodes); start += sliceSize {
+		slice := nodes[start:minInt(start+sliceSize, len(nodes))]
+		sort.Slice(slice, func(i, j int) bool {
+			return slice[i].minY+slice[i].maxY < slice[j].minY+slice[j].maxY
+		})
+		for run := 0; run < len(slice); run += indexNodeCapacity {
+			packed = append(packed, newIndexParent(slice[run:minInt(run+indexNodeCapacity, len(slice))]))
+		}
+	}
+	return packed
+}
+
+// newIndexParent returns a node over the children.
+func newIndexParent[K comparable](children []*indexNode[K]) *indexNode[K] {
+	parent := &indexNode[K]{children: append([]*indexNode[K](nil), children...)}
+	parent.minX, parent.maxX, parent.minY, parent.maxY = children[0].minX, children[0].maxX, children[0].minY, children[0].maxY
+	for _, child := range children[1:] {
+		parent.minX = math.Min(parent.minX, child.minX)
+		parent.maxX = math.Max(parent.maxX, child.maxX)
+		parent.minY = math.Min(parent.minY, child.minY)
+		parent.maxY = math.Max(parent.maxY, child.maxY)
+	}
+	return parent
+}
+
+// candidates calls fn for every key that may be valid for the point. A key can be passed more than once.
+func (index *groupIndex[K]) candidates(point *Point, fn func(key K)) {
+	for _, key := range index.always {
+		fn(key)
+	}
+	if index.s2Cells != nil {
+		// The covering cells may be of any level up to the index's
+		leaf := s2LeafID(point)
+		for level := 0; level <= index.s2Level; level++ {
+			for _, key := range index.s2Cells[s2Parent(leaf, level)] {
+				fn(key)
+			}
+		}
+		return
+	}
+	if index.geohashCells != nil {
+		hash := Geohash(point, index.geohashPrecision)
+		for length := 1; length <= len(hash); length++ {
+			for _, key := range index.geohashCells[hash[:length]] {
+				fn(key)
+			}
+		}
+		return
+	}
+	if index.root != nil {
+		index.root.candidates(point.Lat(), point.Lng(), fn)
+	}
+}
+
+func (node *indexNode[K]) candidates(lat, lng float64, fn func(key K)) {
+	if lat < node.minX || lat > node.maxX || lng < node.minY || lng > node.maxY {
+		return
+	}
+	if node.children == nil {
+		fn(node.key)
+		return
+	}
+	for _, child := range node.children {
+		child.candidates(lat, lng, fn)
+	}
+}
+
+// projected returns whether any of the entry's whitelist geofences is projected.
+func (entry *groupEntry) projected() bool {
+	for _, geofence := range entry.whitelist {
+		if geofence.projected() {
+			return true
+		}
+	}
+	return false
+}
+
+// planar returns whether any of the entry's whitelist geofences is WithPlanar.
+func (entry *groupEntry) planar() bool {
+	for _, geofence := range entry.whitelist {
+		if geofence.planarPolygons() {
+			return true
+		}
+	}
+	return false
+}
+
+// planarPolygons returns whether any polygon of the geofence is WithPlanar, as a
+// NewMultiGeofence keeps the options on its polygons.
+func (geofence *Geofence) planarPolygons() bool {
+	for _, part := range geofence.polygons() {
+		if part.planar {
+			return true
+		}
+	}
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
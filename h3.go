@@ -0,0 +1,622 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// H3MaxResolution is the resolution of the smallest H3 cells, about 1m² in area.
+const H3MaxResolution = 15
+
+// Constants of the H3 grid: the aperture 7 scale between resolutions, the edge of a resolution 0
+// cell on the gnomonic projection of its face, and the rotation of Class III (odd) resolutions
+// from Class II.
+const (
+	h3Sqrt7         = 2.6457513110645905905
+	h3Sin60         = 0.8660254037844386467637231707529361834714
+	h3Res0Gnomonic  = 0.38196601125010500003
+	h3Ap7Rotation   = 0.333473172251832115336090755351601070065900389
+	h3Epsilon       = 1e-16
+	h3NumBaseCells  = 122
+	h3MaxFaceCoord  = 2
+	h3CellMode      = 1
+	h3ModeBit       = 59
+	h3DigitBits     = 3
+	h3ResolutionBit = 52
+	h3BaseCellBit   = 45
+	// h3Init is an index whose digits are all 7, as they are below the cell's resolution
+	h3Init uint64 = 1<<45 - 1
+)
+
+// The indexing digits of H3, each a direction from the center of the parent cell. The K digit is
+// the one missing around pentagons.
+const (
+	h3CenterDigit = iota
+	h3KDigit
+	h3JDigit
+	h3JKDigit
+	h3IDigit
+	h3IKDigit
+	h3IJDigit
+)
+
+// The quadrants of a face that an overage leaves through, indexing h3FaceNeighbors.
+const (
+	h3IJQuadrant = 1
+	h3KIQuadrant = 2
+	h3JKQuadrant = 3
+)
+
+// h3EdgeMeters are the average edges of the hexagons at each resolution, in meters.
+var h3EdgeMeters = [H3MaxResolution + 1]float64{
+	1107712.591, 418676.0055, 158244.6558, 59810.85794, 22606.3794, 8544.408276, 3229.482772, 1220.629759,
+	461.3546837, 174.3756681, 65.90780749, 24.9105614, 9.415526211, 3.559893033, 1.348574562, 0.509713273,
+}
+
+var (
+	// h3UnitVectors are the i, j, k coordinates of each digit's direction.
+	h3UnitVectors = [7]h3IJK{{0, 0, 0}, {0, 0, 1}, {0, 1, 0}, {0, 1, 1}, {1, 0, 0}, {1, 0, 1}, {1, 1, 0}}
+	// h3RotateDigitCCW and h3RotateDigitCW turn each digit 60 degrees.
+	h3RotateDigitCCW = [7]int{0, 5, 3, 1, 6, 4, 2}
+	h3RotateDigitCW  = [7]int{0, 3, 6, 2, 5, 1, 4}
+)
+
+// h3IJK is a position on H3's hexagonal grid of a face, as i, j and k coordinates along axes
+// 120 degrees apart. Normalized, at least one is 0 and none is negative.
+type h3IJK struct {
+	i, j, k int
+}
+
+// h3FaceIJK is a position on a face of the icosahedron.
+type h3FaceIJK struct {
+	face  int
+	coord h3IJK
+}
+
+// h3FaceOrientation is how a neighbouring face's coordinates lie relative to a face.
+type h3FaceOrientation struct {
+	face      int
+	translate h3IJK
+	ccwRot60  int
+}
+
+// h3BaseCell is a resolution 0 cell, with its home face and coordinates on it.
+type h3BaseCell struct {
+	face     int
+	coord    h3IJK
+	pentagon bool
+	cwOffset [2]int
+}
+
+// H3CellID returns the ID of the H3 cell at the resolution, 0 to H3MaxResolution, containing the
+// point, as used by the H3 library and databases such as BigQuery, Snowflake and ClickHouse.
+// Resolutions outside the range are clamped.
+func H3CellID(point *Point, resolution int) uint64 {
+	resolution = clampLevel(resolution, H3MaxResolution)
+	return h3FromFaceIJK(h3PointToFaceIJK(point, resolution), resolution)
+}
+
+// h3PointToFaceIJK returns the face nearest the point and the position on it of the cell at the
+// resolution containing the point.
+func h3PointToFaceIJK(point *Point, resolution int) h3FaceIJK {
+	v := toVector3(point)
+	face, sqd := 0, 5.0
+	for f, center := range h3FaceCenterVectors {
+		dx, dy, dz := center.x-v.x, center.y-v.y, center.z-v.z
+		if d := dx*dx + dy*dy + dz*dz; d < sqd {
+			face, sqd = f, d
+		}
+	}
+
+	// The angle from the face's center, as cos(r) = 1 - sqd/2
+	r := math.Acos(1 - sqd/2)
+	if r < h3Epsilon {
+		return h3FaceIJK{face: face}
+	}
+	lat, lng := point.Lat()*math.Pi/180.0, point.Lng()*math.Pi/180.0
+	center := h3FaceCenters[face]
+	theta := h3PositiveAngle(h3FaceAxes[face] - h3PositiveAngle(h3Azimuth(center[0], center[1], lat, lng)))
+	if resolution%2 == 1 {
+		theta = h3PositiveAngle(theta - h3Ap7Rotation)
+	}
+
+	// Gnomonic projection, scaled to the cells at the resolution
+	r = math.Tan(r) / h3Res0Gnomonic
+	for i := 0; i < resolution; i++ {
+		r *= h3Sqrt7
+	}
+	return h3FaceIJK{face: face, coord: h3Hex2dToIJK(r*math.Cos(theta), r*math.Sin(theta))}
+}
+
+// h3Hex2dToIJK returns the hexagon containing the x, y position on the face's grid, where the
+// hexagons' centers are a unit apart.
+func h3Hex2dToIJK(x, y float64) h3IJK {
+	a1, a2 := math.Abs(x), math.Abs(y)
+
+	// Quantize into the i, j system
+	x2 := a2 / h3Sin60
+	x1 := a1 + x2/2
+	m1, m2 := int(x1), int(x2)
+	r1, r2 := x1-float64(m1), x2-float64(m2)
+
+	var c h3IJK
+	switch {
+	case r1 < 1.0/3.0:
+		c.i, c.j = m1, m2
+		if r2 >= (1+r1)/2 {
+			c.j++
+		}
+	case r1 < 0.5:
+		c.i, c.j = m1, m2
+		if r2 >= 1-r1 {
+			c.j++
+		}
+		if 1-r1 <= r2 && r2 < 2*r1 {
+			c.i++
+		}
+	case r1 < 2.0/3.0:
+		c.i, c.j = m1+1, m2
+		if r2 >= 1-r1 {
+			c.j++
+		}
+		if 2*r1-1 < r2 && r2 < 1-r1 {
+			c.i--
+		}
+	default:
+		c.i, c.j = m1+1, m2
+		if r2 >= r1/2 {
+			c.j++
+		}
+	}
+
+	// Fold across the axes for the other quadrants
+	if x < 0 {
+		if c.j%2 == 0 {
+			c.i -= 2 * (c.i - c.j/2)
+		} else {
+			c.i -= 2*(c.i-(c.j+1)/2) + 1
+		}
+	}
+	if y < 0 {
+		c.i -= (2*c.j + 1) / 2
+		c.j = -c.j
+	}
+	return c.normalized()
+}
+
+// h3FromFaceIJK returns the ID of the cell at the position on a face, walking up from the
+// resolution to the base cell and then rotating the digits into the base cell's orientation.
+func h3FromFaceIJK(fijk h3FaceIJK, resolution int) uint64 {
+	id := h3Init | h3CellMode<<h3ModeBit | uint64(resolution)<<h3ResolutionBit
+	coord := fijk.coord
+	for r := resolution - 1; r >= 0; r-- {
+		last := coord
+		var center h3IJK
+		if (r+1)%2 == 1 {
+			coord = coord.upAp7()
+			center = coord.downAp7()
+		} else {
+			coord = coord.upAp7r()
+			center = coord.downAp7r()
+		}
+		id = h3SetDigit(id, r+1, h3UnitDigit(last.sub(center)))
+	}
+	if coord.i > h3MaxFaceCoord || coord.j > h3MaxFaceCoord || coord.k > h3MaxFaceCoord {
+		return 0
+	}
+
+	lookup := h3FaceBaseCells[fijk.face][coord.i][coord.j][coord.k]
+	baseCell, rotations := lookup[0], lookup[1]
+	id |= uint64(baseCell) << h3BaseCellBit
+	if !h3BaseCells[baseCell].pentagon {
+		for i := 0; i < rotations; i++ {
+			id = h3Rotate(id, h3RotateDigitCCW)
+		}
+		return id
+	}
+
+	// Pentagons have no K sub-sequence, so rotate out of it
+	if h3LeadingDigit(id) == h3KDigit {
+		if offset := h3BaseCells[baseCell].cwOffset; offset[0] == fijk.face || offset[1] == fijk.face {
+			id = h3Rotate(id, h3RotateDigitCW)
+		} else {
+			id = h3Rotate(id, h3RotateDigitCCW)
+		}
+	}
+	for i := 0; i < rotations; i++ {
+		id = h3RotatePentagon(id)
+	}
+	return id
+}
+
+// h3ToFaceIJK returns the face and position on it of the cell, starting from its base cell's home
+// face and moving to a neighbouring face when the cell lies beyond the home face's edge.
+func h3ToFaceIJK(id uint64) h3FaceIJK {
+	baseCell := h3BaseCellNumber(id)
+	pentagon := h3BaseCells[baseCell].pentagon
+	// Pentagons have no K sub-sequence, so all of the IK sub-sequence is rotated
+	if pentagon && h3LeadingDigit(id) == h3IKDigit {
+		id = h3Rotate(id, h3RotateDigitCW)
+	}
+
+	resolution := h3Resolution(id)
+	fijk := h3FaceIJK{face: h3BaseCells[baseCell].face, coord: h3BaseCells[baseCell].coord}
+	// The center base cell's hierarchy is entirely on its home face
+	possibleOverage := pentagon || (resolution > 0 && fijk.coord != h3IJK{})
+	for r := 1; r <= resolution; r++ {
+		if r%2 == 1 {
+			fijk.coord = fijk.coord.downAp7()
+		} else {
+			fijk.coord = fijk.coord.downAp7r()
+		}
+		fijk.coord = fijk.coord.neighbour(h3Digit(id, r))
+	}
+	if !possibleOverage {
+		return fijk
+	}
+
+	// Class III resolutions are adjusted on the next finer Class II grid
+	original := fijk.coord
+	adjusted := resolution
+	if resolution%2 == 1 {
+		fijk.coord = fijk.coord.downAp7r()
+		adjusted++
+	}
+	pentLeading4 := pentagon && h3LeadingDigit(id) == h3IDigit
+	if fijk.adjustOverage(adjusted, pentLeading4) {
+		// Pentagons may need a second move
+		if pentagon {
+			for fijk.adjustOverage(adjusted, false) {
+			}
+		}
+		if adjusted != resolution {
+			fijk.coord = fijk.coord.upAp7r()
+		}
+	} else if adjusted != resolution {
+		fijk.coord = original
+	}
+	return fijk
+}
+
+// adjustOverage moves a position on a Class II grid that lies beyond the edge of its face to the
+// neighbouring face, and reports whether it did.
+func (fijk *h3FaceIJK) adjustOverage(resolution int, pentLeading4 bool) bool {
+	unitScale := 1
+	for i := 0; i < resolution/2; i++ {
+		unitScale *= 7
+	}
+	maxDim := 2 * unitScale
+
+	coord := fijk.coord
+	if coord.i+coord.j+coord.k <= maxDim {
+		return false
+	}
+	var orientation h3FaceOrientation
+	switch {
+	case coord.k > 0 && coord.j > 0:
+		orientation = h3FaceNeighbors[fijk.face][h3JKQuadrant]
+	case coord.k > 0:
+		orientation = h3FaceNeighbors[fijk.face][h3KIQuadrant]
+		if pentLeading4 {
+			// Rotate about the pentagon's center for the missing sub-sequence
+			origin := h3IJK{maxDim, 0, 0}
+			coord = coord.sub(origin).rotate60cw().add(origin)
+		}
+	default:
+		orientation = h3FaceNeighbors[fijk.face][h3IJQuadrant]
+	}
+
+	for i := 0; i < orientation.ccwRot60; i++ {
+		coord = coord.rotate60ccw()
+	}
+	fijk.face = orientation.face
+	fijk.coord = coord.add(orientation.translate.scale(unitScale)).normalized()
+	return true
+}
+
+// h3CellCenter returns the center of the cell.
+func h3CellCenter(id uint64) *Point {
+	fijk := h3ToFaceIJK(id)
+	i, j := fijk.coord.i-fijk.coord.k, fijk.coord.j-fijk.coord.k
+	x, y := float64(i)-0.5*float64(j), float64(j)*h3Sin60
+
+	center := h3FaceCenters[fijk.face]
+	r := math.Hypot(x, y)
+	if r < h3Epsilon {
+		return NewPoint(center[0]*180.0/math.Pi, center[1]*180.0/math.Pi)
+	}
+	theta := math.Atan2(y, x)
+	resolution := h3Resolution(id)
+	for k := 0; k < resolution; k++ {
+		r /= h3Sqrt7
+	}
+	r = math.Atan(r * h3Res0Gnomonic)
+	if resolution%2 == 1 {
+		theta = h3PositiveAngle(theta + h3Ap7Rotation)
+	}
+	theta = h3PositiveAngle(h3FaceAxes[fijk.face] - theta)
+	lat, lng := h3AzimuthDistance(center[0], center[1], theta, r)
+	return NewPoint(lat*180.0/math.Pi, lng*180.0/math.Pi)
+}
+
+// h3Azimuth returns the azimuth in radians from the first point to the second.
+func h3Azimuth(lat1, lng1, lat2, lng2 float64) float64 {
+	return math.Atan2(math.Cos(lat2)*math.Sin(lng2-lng1), math.Cos(lat1)*math.Sin(lat2)-math.Sin(lat1)*math.Cos(lat2)*math.Cos(lng2-lng1))
+}
+
+// h3AzimuthDistance returns the point at the azimuth and angular distance from the point, all in
+// radians, computed exactly as the H3 library does so that cell centers match its own.
+func h3AzimuthDistance(lat, lng, azimuth, distance float64) (float64, float64) {
+	if distance < h3Epsilon {
+		return lat, lng
+	}
+	azimuth = h3PositiveAngle(azimuth)
+
+	var lat2 float64
+	if azimuth < h3Epsilon || math.Abs(azimuth-math.Pi) < h3Epsilon {
+		// Due north or south
+		if azimuth < h3Epsilon {
+			lat2 = lat + distance
+		} else {
+			lat2 = lat - distance
+		}
+		if math.Abs(math.Abs(lat2)-math.Pi/2) < h3Epsilon {
+			return math.Copysign(math.Pi/2, lat2), 0
+		}
+		return lat2, h3ConstrainLng(lng)
+	}
+
+	sinLat := math.Max(-1, math.Min(1, math.Sin(lat)*math.Cos(distance)+math.Cos(lat)*math.Sin(distance)*math.Cos(azimuth)))
+	lat2 = math.Asin(sinLat)
+	if math.Abs(math.Abs(lat2)-math.Pi/2) < h3Epsilon {
+		return math.Copysign(math.Pi/2, lat2), 0
+	}
+	sinLng := math.Max(-1, math.Min(1, math.Sin(azimuth)*math.Sin(distance)/math.Cos(lat2)))
+	cosLng := math.Max(-1, math.Min(1, (math.Cos(distance)-math.Sin(lat)*math.Sin(lat2))/math.Cos(lat)/math.Cos(lat2)))
+	return lat2, h3ConstrainLng(lng + math.Atan2(sinLng, cosLng))
+}
+
+// h3PositiveAngle returns the angle in radians within 0 to 2π.
+func h3PositiveAngle(angle float64) float64 {
+	if angle < 0 {
+		return angle + 2*math.Pi
+	}
+	if angle >= 2*math.Pi {
+		return angle - 2*math.Pi
+	}
+	return angle
+}
+
+// h3ConstrainLng returns the longitude in radians within -π to π.
+func h3ConstrainLng(lng float64) float64 {
+	for lng > math.Pi {
+		lng -= 2 * math.Pi
+	}
+	for lng < -math.Pi {
+		lng += 2 * math.Pi
+	}
+	return lng
+}
+
+// h3Resolution returns the resolution of the cell.
+func h3Resolution(id uint64) int {
+	return int(id >> h3ResolutionBit & 0xf)
+}
+
+// h3BaseCellNumber returns the base cell the cell descends from.
+func h3BaseCellNumber(id uint64) int {
+	return int(id >> h3BaseCellBit & 0x7f)
+}
+
+// h3Digit returns the cell's digit at the resolution, 1 to 15.
+func h3Digit(id uint64, resolution int) int {
+	return int(id >> ((H3MaxResolution - resolution) * h3DigitBits) & 7)
+}
+
+func h3SetDigit(id uint64, resolution, digit int) uint64 {
+	shift := (H3MaxResolution - resolution) * h3DigitBits
+	return id&^(7<<shift) | uint64(digit)<<shift
+}
+
+// h3LeadingDigit returns the cell's first digit that is not h3CenterDigit.
+func h3LeadingDigit(id uint64) int {
+	for r := 1; r <= h3Resolution(id); r++ {
+		if digit := h3Digit(id, r); digit != h3CenterDigit {
+			return digit
+		}
+	}
+	return h3CenterDigit
+}
+
+// h3Rotate turns each of the cell's digits by the rotation.
+func h3Rotate(id uint64, rotation [7]int) uint64 {
+	for r := 1; r <= h3Resolution(id); r++ {
+		id = h3SetDigit(id, r, rotation[h3Digit(id, r)])
+	}
+	return id
+}
+
+// h3RotatePentagon turns the cell 60 degrees counter-clockwise about its pentagon base cell,
+// turning once more if that would lead into the missing K sub-sequence.
+func h3RotatePentagon(id uint64) uint64 {
+	found := false
+	for r := 1; r <= h3Resolution(id); r++ {
+		id = h3SetDigit(id, r, h3RotateDigitCCW[h3Digit(id, r)])
+		if !found && h3Digit(id, r) != h3CenterDigit {
+			found = true
+			if h3LeadingDigit(id) == h3KDigit {
+				id = h3Rotate(id, h3RotateDigitCCW)
+			}
+		}
+	}
+	return id
+}
+
+// h3UnitDigit returns the digit of the unit vector.
+func h3UnitDigit(c h3IJK) int {
+	c = c.normalized()
+	for digit, unit := range h3UnitVectors {
+		if c == unit {
+			return digit
+		}
+	}
+	return 7
+}
+
+func (c h3IJK) add(other h3IJK) h3IJK {
+	return h3IJK{c.i + other.i, c.j + other.j, c.k + other.k}
+}
+
+func (c h3IJK) sub(other h3IJK) h3IJK {
+	return h3IJK{c.i - other.i, c.j - other.j, c.k - other.k}
+}
+
+func (c h3IJK) scale(factor int) h3IJK {
+	return h3IJK{c.i * factor, c.j * factor, c.k * factor}
+}
+
+// normalized returns the same position with no negative coordinate and at least one zero.
+func (c h3IJK) normalized() h3IJK {
+	if c.i < 0 {
+		c.j -= c.i
+		c.k -= c.i
+		c.i = 0
+	}
+	if c.j < 0 {
+		c.i -= c.j
+		c.k -= c.j
+		c.j = 0
+	}
+	if c.k < 0 {
+		c.i -= c.k
+		c.j -= c.k
+		c.k = 0
+	}
+	least := c.i
+	if c.j < least {
+		least = c.j
+	}
+	if c.k < least {
+		least = c.k
+	}
+	if least > 0 {
+		c.i -= least
+		c.j -= least
+		c.k -= least
+	}
+	return c
+}
+
+// combine returns the position given by the coordinates of the unit vectors i, j and k.
+func (c h3IJK) combine(i, j, k h3IJK) h3IJK {
+	return i.scale(c.i).add(j.scale(c.j)).add(k.scale(c.k)).normalized()
+}
+
+// upAp7 returns the parent cell on the counter-clockwise aperture 7 grid, and upAp7r on the
+// clockwise one.
+func (c h3IJK) upAp7() h3IJK {
+	i, j := c.i-c.k, c.j-c.k
+	return h3IJK{int(math.Round(float64(3*i-j) / 7)), int(math.Round(float64(i+2*j) / 7)), 0}.normalized()
+}
+
+func (c h3IJK) upAp7r() h3IJK {
+	i, j := c.i-c.k, c.j-c.k
+	return h3IJK{int(math.Round(float64(2*i+j) / 7)), int(math.Round(float64(3*j-i) / 7)), 0}.normalized()
+}
+
+// downAp7 returns the center child cell on the counter-clockwise aperture 7 grid, and downAp7r
+// on the clockwise one.
+func (c h3IJK) downAp7() h3IJK {
+	return c.combine(h3IJK{3, 0, 1}, h3IJK{1, 3, 0}, h3IJK{0, 1, 3})
+}
+
+func (c h3IJK) downAp7r() h3IJK {
+	return c.combine(h3IJK{3, 1, 0}, h3IJK{0, 3, 1}, h3IJK{1, 0, 3})
+}
+
+func (c h3IJK) rotate60ccw() h3IJK {
+	return c.combine(h3IJK{1, 1, 0}, h3IJK{0, 1, 1}, h3IJK{1, 0, 1})
+}
+
+func (c h3IJK) rotate60cw() h3IJK {
+	return c.combine(h3IJK{1, 0, 1}, h3IJK{1, 1, 0}, h3IJK{0, 1, 1})
+}
+
+// neighbour returns the adjacent cell in the digit's direction.
+func (c h3IJK) neighbour(digit int) h3IJK {
+	if digit <= h3CenterDigit || digit >= len(h3UnitVectors) {
+		return c
+	}
+	return c.add(h3UnitVectors[digit]).normalized()
+}
+
+// H3Covering returns the IDs of the H3 cells at the resolution whose centers are inside the
+// geofence, in increasing order, as the H3 library's polygon to cells function returns them.
+// Unlike S2Covering, the cells are all at the one resolution, since H3 cells do not nest exactly,
+// and cells the boundary crosses are only included when their center is inside, so the cells
+// together may leave out a sliver along the edges. Resolutions outside 0 to H3MaxResolution are
+// clamped. A geofence without vertices returns nil.
+func (geofence *Geofence) H3Covering(resolution int) []uint64 {
+	if geofence.empty() {
+		return nil
+	}
+	resolution = clampLevel(resolution, H3MaxResolution)
+	classifier := newRectClassifier(geofence)
+	// Every cell contains the disk of 0.4 average edges around its center. Rectangles no more than
+	// half an edge across are within 0.36 edges of their middle, so sampling the middle of each
+	// finds every cell whose center is inside
+	step := h3EdgeMeters[resolution] / 2 / (EARTH_RADIUS * 1000) * 180.0 / math.Pi
+
+	var covering []uint64
+	tested := make(map[uint64]bool)
+	var visit func(minLat, minLng, maxLat, maxLng float64, inside bool)
+	visit = func(minLat, minLng, maxLat, maxLng float64, inside bool) {
+		if !inside {
+			switch classifier.classify(minLat, minLng, maxLat, maxLng) {
+			case TILE_OUT:
+				return
+			case TILE_IN:
+				inside = true
+			}
+		}
+		// The rectangle is widest on the ground at the latitude nearest the equator
+		widest := 0.0
+		if minLat > 0 || maxLat < 0 {
+			widest = math.Min(math.Abs(minLat), math.Abs(maxLat))
+		}
+		splitLat := maxLat-minLat > step
+		splitLng := (maxLng-minLng)*math.Cos(widest*math.Pi/180.0) > step
+		if !splitLat && !splitLng {
+			id := H3CellID(NewPoint((minLat+maxLat)/2, (minLng+maxLng)/2), resolution)
+			if !tested[id] {
+				tested[id] = true
+				if geofence.Inside(h3CellCenter(id)) {
+					covering = append(covering, id)
+				}
+			}
+			return
+		}
+
+		lats, lngs := []float64{minLat, maxLat}, []float64{minLng, maxLng}
+		if splitLat {
+			lats = []float64{minLat, (minLat + maxLat) / 2, maxLat}
+		}
+		if splitLng {
+			lngs = []float64{minLng, (minLng + maxLng) / 2, maxLng}
+		}
+		for i := 0; i+1 < len(lats); i++ {
+			for j := 0; j+1 < len(lngs); j++ {
+				visit(lats[i], lngs[j], lats[i+1], lngs[j+1], inside)
+			}
+		}
+	}
+	// Start from rectangles no larger than S2's faces, which stay simple shapes when projected
+	for lat := -90.0; lat < 90; lat += 90 {
+		for lng := -180.0; lng < 180; lng += 90 {
+			visit(lat, lng, lat+90, lng+90, false)
+		}
+	}
+
+	sort.Slice(covering, func(i, j int) bool { return covering[i] < covering[j] })
+	return covering
+}
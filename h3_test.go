@@ -0,0 +1,89 @@
+package geofence
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestH3CellID(t *testing.T) {
+	// IDs from the H3 library
+	point := NewPoint(67.1509268640, -168.3908885810)
+	assert.Equal(t, uint64(0x850dab63fffffff), H3CellID(point, 5))
+	assert.InDelta(t, point.Lat(), h3CellCenter(0x850dab63fffffff).Lat(), 1e-4)
+	assert.InDelta(t, point.Lng(), h3CellCenter(0x850dab63fffffff).Lng(), 1e-4)
+	assert.Equal(t, uint64(0x8928308280fffff), H3CellID(NewPoint(37.7752702151959, -122.418307270836), 9))
+	assert.Equal(t, 9, h3Resolution(H3CellID(NewPoint(37.7752702151959, -122.418307270836), 9)))
+
+	// Base cells, the first of them a pentagon, and a pentagon at resolution 2
+	for _, id := range []uint64{0x8001fffffffffff, 0x8009fffffffffff, 0x80f3fffffffffff, 0x821c07fffffffff} {
+		assert.Equal(t, id, H3CellID(h3CellCenter(id), h3Resolution(id)), "%x", id)
+	}
+	assert.Equal(t, 121, h3BaseCellNumber(0x80f3fffffffffff))
+
+	assert.Equal(t, H3CellID(point, H3MaxResolution), H3CellID(point, 20))
+	assert.Equal(t, H3CellID(point, 0), H3CellID(point, -1))
+	// The poles and the antimeridian
+	assert.Equal(t, H3CellID(NewPoint(10, 180), 7), H3CellID(NewPoint(10, -180), 7))
+	for _, lat := range []float64{90, -90} {
+		id := H3CellID(NewPoint(lat, 0), 3)
+		assert.Equal(t, id, H3CellID(NewPoint(lat, 120), 3))
+		assert.InDelta(t, lat, h3CellCenter(id).Lat(), 1)
+	}
+
+	// A point's cell's center is in the same cell
+	for i := 0; i < 1000; i++ {
+		point := NewPoint(rand.Float64()*180-90, rand.Float64()*360-180)
+		resolution := rand.Intn(H3MaxResolution + 1)
+		id := H3CellID(point, resolution)
+		assert.Equal(t, id, H3CellID(h3CellCenter(id), resolution), "%v at %d", point, resolution)
+	}
+}
+
+func TestH3Covering(t *testing.T) {
+	// Cells from the H3 library's polygon to cells
+	fence := NewGeofence([]*Point{
+		NewPoint(67.224749856, -168.523006585), NewPoint(67.140938355, -168.626914333), NewPoint(67.067252558, -168.494913285),
+		NewPoint(67.077062918, -168.259695931), NewPoint(67.160561948, -168.154801171), NewPoint(67.234563187, -168.286102782),
+	})
+	assert.Equal(t, []uint64{
+		0x860dab607ffffff, 0x860dab60fffffff, 0x860dab617ffffff, 0x860dab61fffffff,
+		0x860dab627ffffff, 0x860dab62fffffff, 0x860dab637ffffff,
+	}, fence.H3Covering(6))
+	assert.Equal(t, fence.H3Covering(H3MaxResolution-8), fence.H3Covering(7))
+
+	fences := []*Geofence{
+		NewGeofence([]*Point{NewPoint(51.50, -0.13), NewPoint(51.50, -0.11), NewPoint(51.51, -0.11), NewPoint(51.51, -0.13)}),
+		NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, [][]*Point{{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}}),
+		NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)}),
+		NewGeodesicGeofence(parallel(70, 12, false)),
+		NewGeofence([]*Point{NewPoint(44, 0), NewPoint(46, 0), NewPoint(46, 1)}, WithLocalProjection()),
+	}
+	for i, resolution := range []int{10, 4, 5, 4, 6} {
+		geofence := fences[i]
+		covering := geofence.H3Covering(resolution)
+		assert.NotEmpty(t, covering)
+		assert.True(t, sort.SliceIsSorted(covering, func(i, j int) bool { return covering[i] < covering[j] }))
+		cells := make(map[uint64]bool)
+		for _, id := range covering {
+			assert.Equal(t, resolution, h3Resolution(id))
+			assert.True(t, geofence.Inside(h3CellCenter(id)), "%x", id)
+			cells[id] = true
+		}
+
+		// Every cell whose center is inside is in the covering
+		min, max := geofence.Bounds()
+		for i := 0; i < 300; i++ {
+			point := NewPoint(min.Lat()+rand.Float64()*(max.Lat()-min.Lat()), min.Lng()+rand.Float64()*(max.Lng()-min.Lng()))
+			if geofence.wrapLng {
+				point = NewPoint(point.Lat(), min.Lng()+rand.Float64()*(max.Lng()+360-min.Lng()))
+			}
+			id := H3CellID(point, resolution)
+			assert.Equal(t, geofence.Inside(h3CellCenter(id)), cells[id], "%v", point)
+		}
+	}
+
+	assert.Nil(t, NewGeofence(nil).H3Covering(5))
+}
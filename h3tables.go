@@ -0,0 +1,296 @@
+package geofence
+
+// The lookup tables of the H3 library (https://github.com/uber/h3), which fix how its grid is laid
+// on the icosahedron: where each face is, which faces meet at its edges, and which of the 122 base
+// cells each face's coordinates fall in. H3 cell IDs can only be reproduced with these exact values.
+
+// h3FaceCenters are the latitude and longitude, in radians, of the center of each icosahedron
+// face.
+var h3FaceCenters = [20][2]float64{
+	{0.803582649718989942, 1.248397419617396099},
+	{1.307747883455638156, 2.536945009877921159},
+	{1.054751253523952054, -1.347517358900396623},
+	{0.600191595538186799, -0.450603909469755746},
+	{0.491715428198773866, 0.401988202911306943},
+	{0.172745327415618701, 1.678146885280433686},
+	{0.605929321571350690, 2.953923329812411617},
+	{0.427370518328979641, -1.888876200336285401},
+	{-0.079066118549212831, -0.733429513380867741},
+	{-0.230961644455383637, 0.506495587332349035},
+	{0.079066118549212831, 2.408163140208925497},
+	{0.230961644455383637, -2.635097066257444203},
+	{-0.172745327415618701, -1.463445768309359553},
+	{-0.605929321571350690, -0.187669323777381622},
+	{-0.427370518328979641, 1.252716453253507838},
+	{-0.600191595538186799, 2.690988744120037492},
+	{-0.491715428198773866, -2.739604450678486295},
+	{-0.803582649718989942, -1.893195233972397139},
+	{-1.307747883455638156, -0.604647643711872080},
+	{-1.054751253523952054, 1.794075294689396615},
+}
+
+// h3FaceCenterVectors are the face centers as unit vectors, to find the face nearest a point.
+var h3FaceCenterVectors = [20]vector3{
+	{0.2199307791404606, 0.6583691780274996, 0.7198475378926182},
+	{-0.2139234834501421, 0.1478171829550703, 0.9656017935214205},
+	{0.1092625278784797, -0.4811951572873210, 0.8697775121287253},
+	{0.7428567301586791, -0.3593941678278028, 0.5648005936517033},
+	{0.8112534709140969, 0.3448953237639384, 0.4721387736413930},
+	{-0.1055498149613921, 0.9794457296411413, 0.1718874610009365},
+	{-0.8075407579970092, 0.1533552485898818, 0.5695261994882688},
+	{-0.2846148069787907, -0.8644080972654206, 0.4144792552473539},
+	{0.7405621473854482, -0.6673299564565524, -0.0789837646326737},
+	{0.8512303986474293, 0.4722343788582681, -0.2289137388687808},
+	{-0.7405621473854481, 0.6673299564565524, 0.0789837646326737},
+	{-0.8512303986474292, -0.4722343788582682, 0.2289137388687808},
+	{0.1055498149613919, -0.9794457296411413, -0.1718874610009365},
+	{0.8075407579970092, -0.1533552485898819, -0.5695261994882688},
+	{0.2846148069787908, 0.8644080972654204, -0.4144792552473539},
+	{-0.7428567301586791, 0.3593941678278027, -0.5648005936517033},
+	{-0.8112534709140971, -0.3448953237639382, -0.4721387736413930},
+	{-0.2199307791404607, -0.6583691780274996, -0.7198475378926182},
+	{0.2139234834501420, -0.1478171829550704, -0.9656017935214205},
+	{-0.1092625278784796, 0.4811951572873210, -0.8697775121287253},
+}
+
+// h3FaceAxes are the azimuths, in radians, from each face center to its Class II i axis.
+var h3FaceAxes = [20]float64{
+	5.619958268523939882,
+	5.760339081714187279,
+	0.780213654393430055,
+	0.430469363979999913,
+	6.130269123335111400,
+	2.692877706530642877,
+	2.982963003477243874,
+	3.532912002790141181,
+	3.494305004259568154,
+	3.003214169499538391,
+	5.930472956509811562,
+	0.138378484090254847,
+	0.448714947059150361,
+	0.158629650112549365,
+	5.891865957979238535,
+	2.711123289609793325,
+	3.294508837434268316,
+	3.804819692245439833,
+	3.664438879055192436,
+	2.361378999196363184,
+}
+
+// h3FaceNeighbors are the faces across each face's edges, indexed by h3IJQuadrant, h3KIQuadrant
+// and h3JKQuadrant, with the translation and rotation into the neighbour's coordinates. The first
+// entry is the face itself.
+var h3FaceNeighbors = [20][4]h3FaceOrientation{
+	{{0, h3IJK{0, 0, 0}, 0}, {4, h3IJK{2, 0, 2}, 1}, {1, h3IJK{2, 2, 0}, 5}, {5, h3IJK{0, 2, 2}, 3}},
+	{{1, h3IJK{0, 0, 0}, 0}, {0, h3IJK{2, 0, 2}, 1}, {2, h3IJK{2, 2, 0}, 5}, {6, h3IJK{0, 2, 2}, 3}},
+	{{2, h3IJK{0, 0, 0}, 0}, {1, h3IJK{2, 0, 2}, 1}, {3, h3IJK{2, 2, 0}, 5}, {7, h3IJK{0, 2, 2}, 3}},
+	{{3, h3IJK{0, 0, 0}, 0}, {2, h3IJK{2, 0, 2}, 1}, {4, h3IJK{2, 2, 0}, 5}, {8, h3IJK{0, 2, 2}, 3}},
+	{{4, h3IJK{0, 0, 0}, 0}, {3, h3IJK{2, 0, 2}, 1}, {0, h3IJK{2, 2, 0}, 5}, {9, h3IJK{0, 2, 2}, 3}},
+	{{5, h3IJK{0, 0, 0}, 0}, {10, h3IJK{2, 2, 0}, 3}, {14, h3IJK{2, 0, 2}, 3}, {0, h3IJK{0, 2, 2}, 3}},
+	{{6, h3IJK{0, 0, 0}, 0}, {11, h3IJK{2, 2, 0}, 3}, {10, h3IJK{2, 0, 2}, 3}, {1, h3IJK{0, 2, 2}, 3}},
+	{{7, h3IJK{0, 0, 0}, 0}, {12, h3IJK{2, 2, 0}, 3}, {11, h3IJK{2, 0, 2}, 3}, {2, h3IJK{0, 2, 2}, 3}},
+	{{8, h3IJK{0, 0, 0}, 0}, {13, h3IJK{2, 2, 0}, 3}, {12, h3IJK{2, 0, 2}, 3}, {3, h3IJK{0, 2, 2}, 3}},
+	{{9, h3IJK{0, 0, 0}, 0}, {14, h3IJK{2, 2, 0}, 3}, {13, h3IJK{2, 0, 2}, 3}, {4, h3IJK{0, 2, 2}, 3}},
+	{{10, h3IJK{0, 0, 0}, 0}, {5, h3IJK{2, 2, 0}, 3}, {6, h3IJK{2, 0, 2}, 3}, {15, h3IJK{0, 2, 2}, 3}},
+	{{11, h3IJK{0, 0, 0}, 0}, {6, h3IJK{2, 2, 0}, 3}, {7, h3IJK{2, 0, 2}, 3}, {16, h3IJK{0, 2, 2}, 3}},
+	{{12, h3IJK{0, 0, 0}, 0}, {7, h3IJK{2, 2, 0}, 3}, {8, h3IJK{2, 0, 2}, 3}, {17, h3IJK{0, 2, 2}, 3}},
+	{{13, h3IJK{0, 0, 0}, 0}, {8, h3IJK{2, 2, 0}, 3}, {9, h3IJK{2, 0, 2}, 3}, {18, h3IJK{0, 2, 2}, 3}},
+	{{14, h3IJK{0, 0, 0}, 0}, {9, h3IJK{2, 2, 0}, 3}, {5, h3IJK{2, 0, 2}, 3}, {19, h3IJK{0, 2, 2}, 3}},
+	{{15, h3IJK{0, 0, 0}, 0}, {16, h3IJK{2, 0, 2}, 1}, {19, h3IJK{2, 2, 0}, 5}, {10, h3IJK{0, 2, 2}, 3}},
+	{{16, h3IJK{0, 0, 0}, 0}, {17, h3IJK{2, 0, 2}, 1}, {15, h3IJK{2, 2, 0}, 5}, {11, h3IJK{0, 2, 2}, 3}},
+	{{17, h3IJK{0, 0, 0}, 0}, {18, h3IJK{2, 0, 2}, 1}, {16, h3IJK{2, 2, 0}, 5}, {12, h3IJK{0, 2, 2}, 3}},
+	{{18, h3IJK{0, 0, 0}, 0}, {19, h3IJK{2, 0, 2}, 1}, {17, h3IJK{2, 2, 0}, 5}, {13, h3IJK{0, 2, 2}, 3}},
+	{{19, h3IJK{0, 0, 0}, 0}, {15, h3IJK{2, 0, 2}, 1}, {18, h3IJK{2, 2, 0}, 5}, {14, h3IJK{0, 2, 2}, 3}},
+}
+
+// h3FaceBaseCells are the base cell at each resolution 0 i, j, k coordinate of each face, and the
+// number of 60 degree counter-clockwise rotations into the base cell's own coordinates.
+var h3FaceBaseCells = [20][3][3][3][2]int{
+	{ // face 0
+		{{{16, 0}, {18, 0}, {24, 0}}, {{33, 0}, {30, 0}, {32, 3}}, {{49, 1}, {48, 3}, {50, 3}}},
+		{{{8, 0}, {5, 5}, {10, 5}}, {{22, 0}, {16, 0}, {18, 0}}, {{41, 1}, {33, 0}, {30, 0}}},
+		{{{4, 0}, {0, 5}, {2, 5}}, {{15, 1}, {8, 0}, {5, 5}}, {{31, 1}, {22, 0}, {16, 0}}},
+	},
+	{ // face 1
+		{{{2, 0}, {6, 0}, {14, 0}}, {{10, 0}, {11, 0}, {17, 3}}, {{24, 1}, {23, 3}, {25, 3}}},
+		{{{0, 0}, {1, 5}, {9, 5}}, {{5, 0}, {2, 0}, {6, 0}}, {{18, 1}, {10, 0}, {11, 0}}},
+		{{{4, 1}, {3, 5}, {7, 5}}, {{8, 1}, {0, 0}, {1, 5}}, {{16, 1}, {5, 0}, {2, 0}}},
+	},
+	{ // face 2
+		{{{7, 0}, {21, 0}, {38, 0}}, {{9, 0}, {19, 0}, {34, 3}}, {{14, 1}, {20, 3}, {36, 3}}},
+		{{{3, 0}, {13, 5}, {29, 5}}, {{1, 0}, {7, 0}, {21, 0}}, {{6, 1}, {9, 0}, {19, 0}}},
+		{{{4, 2}, {12, 5}, {26, 5}}, {{0, 1}, {3, 0}, {13, 5}}, {{2, 1}, {1, 0}, {7, 0}}},
+	},
+	{ // face 3
+		{{{26, 0}, {42, 0}, {58, 0}}, {{29, 0}, {43, 0}, {62, 3}}, {{38, 1}, {47, 3}, {64, 3}}},
+		{{{12, 0}, {28, 5}, {44, 5}}, {{13, 0}, {26, 0}, {42, 0}}, {{21, 1}, {29, 0}, {43, 0}}},
+		{{{4, 3}, {15, 5}, {31, 5}}, {{3, 1}, {12, 0}, {28, 5}}, {{7, 1}, {13, 0}, {26, 0}}},
+	},
+	{ // face 4
+		{{{31, 0}, {41, 0}, {49, 0}}, {{44, 0}, {53, 0}, {61, 3}}, {{58, 1}, {65, 3}, {75, 3}}},
+		{{{15, 0}, {22, 5}, {33, 5}}, {{28, 0}, {31, 0}, {41, 0}}, {{42, 1}, {44, 0}, {53, 0}}},
+		{{{4, 4}, {8, 5}, {16, 5}}, {{12, 1}, {15, 0}, {22, 5}}, {{26, 1}, {28, 0}, {31, 0}}},
+	},
+	{ // face 5
+		{{{50, 0}, {48, 0}, {49, 3}}, {{32, 0}, {30, 3}, {33, 3}}, {{24, 3}, {18, 3}, {16, 3}}},
+		{{{70, 0}, {67, 0}, {66, 3}}, {{52, 3}, {50, 0}, {48, 0}}, {{37, 3}, {32, 0}, {30, 3}}},
+		{{{83, 0}, {87, 3}, {85, 3}}, {{74, 3}, {70, 0}, {67, 0}}, {{57, 1}, {52, 3}, {50, 0}}},
+	},
+	{ // face 6
+		{{{25, 0}, {23, 0}, {24, 3}}, {{17, 0}, {11, 3}, {10, 3}}, {{14, 3}, {6, 3}, {2, 3}}},
+		{{{45, 0}, {39, 0}, {37, 3}}, {{35, 3}, {25, 0}, {23, 0}}, {{27, 3}, {17, 0}, {11, 3}}},
+		{{{63, 0}, {59, 3}, {57, 3}}, {{56, 3}, {45, 0}, {39, 0}}, {{46, 3}, {35, 3}, {25, 0}}},
+	},
+	{ // face 7
+		{{{36, 0}, {20, 0}, {14, 3}}, {{34, 0}, {19, 3}, {9, 3}}, {{38, 3}, {21, 3}, {7, 3}}},
+		{{{55, 0}, {40, 0}, {27, 3}}, {{54, 3}, {36, 0}, {20, 0}}, {{51, 3}, {34, 0}, {19, 3}}},
+		{{{72, 0}, {60, 3}, {46, 3}}, {{73, 3}, {55, 0}, {40, 0}}, {{71, 3}, {54, 3}, {36, 0}}},
+	},
+	{ // face 8
+		{{{64, 0}, {47, 0}, {38, 3}}, {{62, 0}, {43, 3}, {29, 3}}, {{58, 3}, {42, 3}, {26, 3}}},
+		{{{84, 0}, {69, 0}, {51, 3}}, {{82, 3}, {64, 0}, {47, 0}}, {{76, 3}, {62, 0}, {43, 3}}},
+		{{{97, 0}, {89, 3}, {71, 3}}, {{98, 3}, {84, 0}, {69, 0}}, {{96, 3}, {82, 3}, {64, 0}}},
+	},
+	{ // face 9
+		{{{75, 0}, {65, 0}, {58, 3}}, {{61, 0}, {53, 3}, {44, 3}}, {{49, 3}, {41, 3}, {31, 3}}},
+		{{{94, 0}, {86, 0}, {76, 3}}, {{81, 3}, {75, 0}, {65, 0}}, {{66, 3}, {61, 0}, {53, 3}}},
+		{{{107, 0}, {104, 3}, {96, 3}}, {{101, 3}, {94, 0}, {86, 0}}, {{85, 3}, {81, 3}, {75, 0}}},
+	},
+	{ // face 10
+		{{{57, 0}, {59, 0}, {63, 3}}, {{74, 0}, {78, 3}, {79, 3}}, {{83, 3}, {92, 3}, {95, 3}}},
+		{{{37, 0}, {39, 3}, {45, 3}}, {{52, 0}, {57, 0}, {59, 0}}, {{70, 3}, {74, 0}, {78, 3}}},
+		{{{24, 0}, {23, 3}, {25, 3}}, {{32, 3}, {37, 0}, {39, 3}}, {{50, 3}, {52, 0}, {57, 0}}},
+	},
+	{ // face 11
+		{{{46, 0}, {60, 0}, {72, 3}}, {{56, 0}, {68, 3}, {80, 3}}, {{63, 3}, {77, 3}, {90, 3}}},
+		{{{27, 0}, {40, 3}, {55, 3}}, {{35, 0}, {46, 0}, {60, 0}}, {{45, 3}, {56, 0}, {68, 3}}},
+		{{{14, 0}, {20, 3}, {36, 3}}, {{17, 3}, {27, 0}, {40, 3}}, {{25, 3}, {35, 0}, {46, 0}}},
+	},
+	{ // face 12
+		{{{71, 0}, {89, 0}, {97, 3}}, {{73, 0}, {91, 3}, {103, 3}}, {{72, 3}, {88, 3}, {105, 3}}},
+		{{{51, 0}, {69, 3}, {84, 3}}, {{54, 0}, {71, 0}, {89, 0}}, {{55, 3}, {73, 0}, {91, 3}}},
+		{{{38, 0}, {47, 3}, {64, 3}}, {{34, 3}, {51, 0}, {69, 3}}, {{36, 3}, {54, 0}, {71, 0}}},
+	},
+	{ // face 13
+		{{{96, 0}, {104, 0}, {107, 3}}, {{98, 0}, {110, 3}, {115, 3}}, {{97, 3}, {111, 3}, {119, 3}}},
+		{{{76, 0}, {86, 3}, {94, 3}}, {{82, 0}, {96, 0}, {104, 0}}, {{84, 3}, {98, 0}, {110, 3}}},
+		{{{58, 0}, {65, 3}, {75, 3}}, {{62, 3}, {76, 0}, {86, 3}}, {{64, 3}, {82, 0}, {96, 0}}},
+	},
+	{ // face 14
+		{{{85, 0}, {87, 0}, {83, 3}}, {{101, 0}, {102, 3}, {100, 3}}, {{107, 3}, {112, 3}, {114, 3}}},
+		{{{66, 0}, {67, 3}, {70, 3}}, {{81, 0}, {85, 0}, {87, 0}}, {{94, 3}, {101, 0}, {102, 3}}},
+		{{{49, 0}, {48, 3}, {50, 3}}, {{61, 3}, {66, 0}, {67, 3}}, {{75, 3}, {81, 0}, {85, 0}}},
+	},
+	{ // face 15
+		{{{95, 0}, {92, 0}, {83, 0}}, {{79, 0}, {78, 0}, {74, 3}}, {{63, 1}, {59, 3}, {57, 3}}},
+		{{{109, 0}, {108, 0}, {100, 5}}, {{93, 1}, {95, 0}, {92, 0}}, {{77, 1}, {79, 0}, {78, 0}}},
+		{{{117, 4}, {118, 5}, {114, 5}}, {{106, 1}, {109, 0}, {108, 0}}, {{90, 1}, {93, 1}, {95, 0}}},
+	},
+	{ // face 16
+		{{{90, 0}, {77, 0}, {63, 0}}, {{80, 0}, {68, 0}, {56, 3}}, {{72, 1}, {60, 3}, {46, 3}}},
+		{{{106, 0}, {93, 0}, {79, 5}}, {{99, 1}, {90, 0}, {77, 0}}, {{88, 1}, {80, 0}, {68, 0}}},
+		{{{117, 3}, {109, 5}, {95, 5}}, {{113, 1}, {106, 0}, {93, 0}}, {{105, 1}, {99, 1}, {90, 0}}},
+	},
+	{ // face 17
+		{{{105, 0}, {88, 0}, {72, 0}}, {{103, 0}, {91, 0}, {73, 3}}, {{97, 1}, {89, 3}, {71, 3}}},
+		{{{113, 0}, {99, 0}, {80, 5}}, {{116, 1}, {105, 0}, {88, 0}}, {{111, 1}, {103, 0}, {91, 0}}},
+		{{{117, 2}, {106, 5}, {90, 5}}, {{121, 1}, {113, 0}, {99, 0}}, {{119, 1}, {116, 1}, {105, 0}}},
+	},
+	{ // face 18
+		{{{119, 0}, {111, 0}, {97, 0}}, {{115, 0}, {110, 0}, {98, 3}}, {{107, 1}, {104, 3}, {96, 3}}},
+		{{{121, 0}, {116, 0}, {103, 5}}, {{120, 1}, {119, 0}, {111, 0}}, {{112, 1}, {115, 0}, {110, 0}}},
+		{{{117, 1}, {113, 5}, {105, 5}}, {{118, 1}, {121, 0}, {116, 0}}, {{114, 1}, {120, 1}, {119, 0}}},
+	},
+	{ // face 19
+		{{{114, 0}, {112, 0}, {107, 0}}, {{100, 0}, {102, 0}, {101, 3}}, {{83, 1}, {87, 3}, {85, 3}}},
+		{{{118, 0}, {120, 0}, {115, 5}}, {{108, 1}, {114, 0}, {112, 0}}, {{92, 1}, {100, 0}, {102, 0}}},
+		{{{117, 0}, {121, 5}, {119, 5}}, {{109, 1}, {118, 0}, {120, 0}}, {{95, 1}, {108, 1}, {114, 0}}},
+	},
+}
+
+// h3BaseCells are the home face and coordinates of each base cell, whether it is one of the 12
+// pentagons, and for the pentagons the faces whose coordinates are offset clockwise from it.
+var h3BaseCells = [h3NumBaseCells]h3BaseCell{
+	// 0 to 2
+	{1, h3IJK{1, 0, 0}, false, [2]int{}}, {2, h3IJK{1, 1, 0}, false, [2]int{}}, {1, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 3 to 5
+	{2, h3IJK{1, 0, 0}, false, [2]int{}}, {0, h3IJK{2, 0, 0}, true, [2]int{-1, -1}}, {1, h3IJK{1, 1, 0}, false, [2]int{}},
+	// 6 to 8
+	{1, h3IJK{0, 0, 1}, false, [2]int{}}, {2, h3IJK{0, 0, 0}, false, [2]int{}}, {0, h3IJK{1, 0, 0}, false, [2]int{}},
+	// 9 to 11
+	{2, h3IJK{0, 1, 0}, false, [2]int{}}, {1, h3IJK{0, 1, 0}, false, [2]int{}}, {1, h3IJK{0, 1, 1}, false, [2]int{}},
+	// 12 to 14
+	{3, h3IJK{1, 0, 0}, false, [2]int{}}, {3, h3IJK{1, 1, 0}, false, [2]int{}}, {11, h3IJK{2, 0, 0}, true, [2]int{2, 6}},
+	// 15 to 17
+	{4, h3IJK{1, 0, 0}, false, [2]int{}}, {0, h3IJK{0, 0, 0}, false, [2]int{}}, {6, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 18 to 20
+	{0, h3IJK{0, 0, 1}, false, [2]int{}}, {2, h3IJK{0, 1, 1}, false, [2]int{}}, {7, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 21 to 23
+	{2, h3IJK{0, 0, 1}, false, [2]int{}}, {0, h3IJK{1, 1, 0}, false, [2]int{}}, {6, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 24 to 26
+	{10, h3IJK{2, 0, 0}, true, [2]int{1, 5}}, {6, h3IJK{0, 0, 0}, false, [2]int{}}, {3, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 27 to 29
+	{11, h3IJK{1, 0, 0}, false, [2]int{}}, {4, h3IJK{1, 1, 0}, false, [2]int{}}, {3, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 30 to 32
+	{0, h3IJK{0, 1, 1}, false, [2]int{}}, {4, h3IJK{0, 0, 0}, false, [2]int{}}, {5, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 33 to 35
+	{0, h3IJK{0, 1, 0}, false, [2]int{}}, {7, h3IJK{0, 1, 0}, false, [2]int{}}, {11, h3IJK{1, 1, 0}, false, [2]int{}},
+	// 36 to 38
+	{7, h3IJK{0, 0, 0}, false, [2]int{}}, {10, h3IJK{1, 0, 0}, false, [2]int{}}, {12, h3IJK{2, 0, 0}, true, [2]int{3, 7}},
+	// 39 to 41
+	{6, h3IJK{1, 0, 1}, false, [2]int{}}, {7, h3IJK{1, 0, 1}, false, [2]int{}}, {4, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 42 to 44
+	{3, h3IJK{0, 0, 1}, false, [2]int{}}, {3, h3IJK{0, 1, 1}, false, [2]int{}}, {4, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 45 to 47
+	{6, h3IJK{1, 0, 0}, false, [2]int{}}, {11, h3IJK{0, 0, 0}, false, [2]int{}}, {8, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 48 to 50
+	{5, h3IJK{0, 0, 1}, false, [2]int{}}, {14, h3IJK{2, 0, 0}, true, [2]int{0, 9}}, {5, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 51 to 53
+	{12, h3IJK{1, 0, 0}, false, [2]int{}}, {10, h3IJK{1, 1, 0}, false, [2]int{}}, {4, h3IJK{0, 1, 1}, false, [2]int{}},
+	// 54 to 56
+	{12, h3IJK{1, 1, 0}, false, [2]int{}}, {7, h3IJK{1, 0, 0}, false, [2]int{}}, {11, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 57 to 59
+	{10, h3IJK{0, 0, 0}, false, [2]int{}}, {13, h3IJK{2, 0, 0}, true, [2]int{4, 8}}, {10, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 60 to 62
+	{11, h3IJK{0, 0, 1}, false, [2]int{}}, {9, h3IJK{0, 1, 0}, false, [2]int{}}, {8, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 63 to 65
+	{6, h3IJK{2, 0, 0}, true, [2]int{11, 15}}, {8, h3IJK{0, 0, 0}, false, [2]int{}}, {9, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 66 to 68
+	{14, h3IJK{1, 0, 0}, false, [2]int{}}, {5, h3IJK{1, 0, 1}, false, [2]int{}}, {16, h3IJK{0, 1, 1}, false, [2]int{}},
+	// 69 to 71
+	{8, h3IJK{1, 0, 1}, false, [2]int{}}, {5, h3IJK{1, 0, 0}, false, [2]int{}}, {12, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 72 to 74
+	{7, h3IJK{2, 0, 0}, true, [2]int{12, 16}}, {12, h3IJK{0, 1, 0}, false, [2]int{}}, {10, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 75 to 77
+	{9, h3IJK{0, 0, 0}, false, [2]int{}}, {13, h3IJK{1, 0, 0}, false, [2]int{}}, {16, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 78 to 80
+	{15, h3IJK{0, 1, 1}, false, [2]int{}}, {15, h3IJK{0, 1, 0}, false, [2]int{}}, {16, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 81 to 83
+	{14, h3IJK{1, 1, 0}, false, [2]int{}}, {13, h3IJK{1, 1, 0}, false, [2]int{}}, {5, h3IJK{2, 0, 0}, true, [2]int{10, 19}},
+	// 84 to 86
+	{8, h3IJK{1, 0, 0}, false, [2]int{}}, {14, h3IJK{0, 0, 0}, false, [2]int{}}, {9, h3IJK{1, 0, 1}, false, [2]int{}},
+	// 87 to 89
+	{14, h3IJK{0, 0, 1}, false, [2]int{}}, {17, h3IJK{0, 0, 1}, false, [2]int{}}, {12, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 90 to 92
+	{16, h3IJK{0, 0, 0}, false, [2]int{}}, {17, h3IJK{0, 1, 1}, false, [2]int{}}, {15, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 93 to 95
+	{16, h3IJK{1, 0, 1}, false, [2]int{}}, {9, h3IJK{1, 0, 0}, false, [2]int{}}, {15, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 96 to 98
+	{13, h3IJK{0, 0, 0}, false, [2]int{}}, {8, h3IJK{2, 0, 0}, true, [2]int{13, 17}}, {13, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 99 to 101
+	{17, h3IJK{1, 0, 1}, false, [2]int{}}, {19, h3IJK{0, 1, 0}, false, [2]int{}}, {14, h3IJK{0, 1, 0}, false, [2]int{}},
+	// 102 to 104
+	{19, h3IJK{0, 1, 1}, false, [2]int{}}, {17, h3IJK{0, 1, 0}, false, [2]int{}}, {13, h3IJK{0, 0, 1}, false, [2]int{}},
+	// 105 to 107
+	{17, h3IJK{0, 0, 0}, false, [2]int{}}, {16, h3IJK{1, 0, 0}, false, [2]int{}}, {9, h3IJK{2, 0, 0}, true, [2]int{14, 18}},
+	// 108 to 110
+	{15, h3IJK{1, 0, 1}, false, [2]int{}}, {15, h3IJK{1, 0, 0}, false, [2]int{}}, {18, h3IJK{0, 1, 1}, false, [2]int{}},
+	// 111 to 113
+	{18, h3IJK{0, 0, 1}, false, [2]int{}}, {19, h3IJK{0, 0, 1}, false, [2]int{}}, {17, h3IJK{1, 0, 0}, false, [2]int{}},
+	// 114 to 116
+	{19, h3IJK{0, 0, 0}, false, [2]int{}}, {18, h3IJK{0, 1, 0}, false, [2]int{}}, {18, h3IJK{1, 0, 1}, false, [2]int{}},
+	// 117 to 119
+	{19, h3IJK{2, 0, 0}, true, [2]int{-1, -1}}, {19, h3IJK{1, 0, 0}, false, [2]int{}}, {18, h3IJK{0, 0, 0}, false, [2]int{}},
+	// 120 to 121
+	{19, h3IJK{1, 0, 1}, false, [2]int{}}, {18, h3IJK{1, 0, 0}, false, [2]int{}},
+}
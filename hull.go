@@ -0,0 +1,219 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// NewGeofenceFromConvexHull is the construct for a Geofence around the convex hull of the
+// points, e.g. a zone drawn around a set of recorded site visits. Repeated and collinear points
+// are left out, so the vertices are the corners of the hull only, counterclockwise. Points
+// either side of the antimeridian are hulled across it unless WithPlanar is given. Fewer than
+// three points that are not all on a line give a geofence without area.
+func NewGeofenceFromConvexHull(points []*Point, opts ...Option) *Geofence {
+	return NewGeofence(convexHull(points, !optionsPlanar(opts)), opts...)
+}
+
+// convexHull returns the corners of the convex hull of the points by Andrew's monotone chain,
+// counterclockwise in lat, lng, with longitudes shifted across the antimeridian when geographic.
+func convexHull(points []*Point, geographic bool) []*Point {
+	shifted := geographic && crossesAntimeridian(points)
+	sorted := make([]*Point, 0, len(points))
+	for _, point := range points {
+		if point == nil {
+			continue
+		}
+		if shifted && point.Lng() < 0 {
+			point = NewPoint(point.Lat(), point.Lng()+360)
+		}
+		sorted = append(sorted, point)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X() != sorted[j].X() {
+			return sorted[i].X() < sorted[j].X()
+		}
+		return sorted[i].Y() < sorted[j].Y()
+	})
+	if len(sorted) < 3 {
+		return unshiftRing(sorted, shifted)
+	}
+
+	// The lower chain left to right, then the upper chain back, each turning left only
+	hull := make([]*Point, 0, 2*len(sorted))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for i := range sorted {
+			point := sorted[i]
+			if pass == 1 {
+				point = sorted[len(sorted)-1-i]
+			}
+			for len(hull) >= start+2 && vectorCrossProduct(vectorDifference(hull[len(hull)-1], hull[len(hull)-2]), vectorDifference(point, hull[len(hull)-2])) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, point)
+		}
+		// The last point of each chain starts the other
+		hull = hull[:len(hull)-1]
+	}
+	return unshiftRing(hull, shifted)
+}
+
+// unshiftRing returns the ring with shifted longitudes back in [-180, 180].
+func unshiftRing(ring []*Point, shifted bool) []*Point {
+	if !shifted {
+		return ring
+	}
+	for i, point := range ring {
+		if point.Lng() > 180 {
+			ring[i] = NewPoint(point.Lat(), point.Lng()-360)
+		}
+	}
+	return ring
+}
+
+// NewGeofenceFromConcaveHull is the construct for a Geofence tightly around the points, e.g. GPS
+// traces of a yard, field or campus, where a convex hull would take in the gaps between them.
+// It is the alpha shape of the points: the triangles of their Delaunay triangulation whose
+// circumcircle has a radius of at most alpha, in meters, or grid units WithPlanar. An alpha of
+// about twice the usual spacing between the points follows their outline, larger ones approach
+// the convex hull. Areas left uncovered within the points become holes, and clusters further
+// than about 2*alpha apart become separate polygons of a NewMultiGeofence. An alpha too small
+// to keep any triangle gives a geofence without vertices.
+func NewGeofenceFromConcaveHull(points []*Point, alpha float64, opts ...Option) *Geofence {
+	vertices, xs, ys := hullPlane(points, !optionsPlanar(opts))
+	var rings, planeRings [][]*Point
+	for _, ring := range alphaShapeRings(xs, ys, alpha) {
+		ringPoints := make([]*Point, len(ring))
+		planePoints := make([]*Point, len(ring))
+		for i, index := range ring {
+			ringPoints[i] = vertices[index]
+			planePoints[i] = NewPointXY(xs[index], ys[index])
+		}
+		rings, planeRings = append(rings, ringPoints), append(planeRings, planePoints)
+	}
+
+	// Counterclockwise rings are outlines and clockwise ones holes in the outline around them
+	outlines, holeIndices := groupRings(planeRings)
+	holes := make(map[int][][]*Point)
+	for outline, indices := range holeIndices {
+		for _, index := range indices {
+			holes[outline] = append(holes[outline], rings[index])
+		}
+	}
+	switch len(outlines) {
+	case 0:
+		return NewGeofence(nil, opts...)
+	case 1:
+		return NewGeofenceWithHoles(rings[outlines[0]], holes[outlines[0]], opts...)
+	}
+	parts := make([]*Geofence, len(outlines))
+	for i, outline := range outlines {
+		parts[i] = NewGeofenceWithHoles(rings[outline], holes[outline], opts...)
+	}
+	return newMultiGeofence(parts)
+}
+
+// hullPlane returns the distinct points with their coordinates on a plane: meters east and north
+// on a plane through the points' mean latitude when geographic, across the antimeridian if need
+// be, and the points' own coordinates otherwise.
+func hullPlane(points []*Point, geographic bool) (vertices []*Point, xs, ys []float64) {
+	seen := make(map[[2]float64]bool)
+	for _, point := range points {
+		if point == nil || seen[[2]float64{point.X(), point.Y()}] {
+			continue
+		}
+		seen[[2]float64{point.X(), point.Y()}] = true
+		vertices = append(vertices, point)
+	}
+	if !geographic {
+		for _, vertex := range vertices {
+			xs, ys = append(xs, vertex.X()), append(ys, vertex.Y())
+		}
+		return vertices, xs, ys
+	}
+
+	shifted := crossesAntimeridian(vertices)
+	meanLat := 0.0
+	for _, vertex := range vertices {
+		meanLat += vertex.Lat() / float64(len(vertices))
+	}
+	degree := math.Pi / 180.0 * EARTH_RADIUS * 1000
+	scale := math.Cos(meanLat * math.Pi / 180.0)
+	for _, vertex := range vertices {
+		lng := vertex.Lng()
+		if shifted && lng < 0 {
+			lng += 360
+		}
+		xs, ys = append(xs, lng*scale*degree), append(ys, vertex.Lat()*degree)
+	}
+	return vertices, xs, ys
+}
+
+// alphaShapeRings returns the boundary of the Delaunay triangles with a circumradius of at most
+// alpha as rings of vertex indices, counterclockwise around the triangles they bound.
+func alphaShapeRings(xs, ys []float64, alpha float64) [][]int {
+	// Edges of a single kept triangle are on the boundary, directed with the triangle
+	counts := make(map[delaunayEdge]int)
+	var directed []delaunayEdge
+	for _, triangle := range delaunay(xs, ys) {
+		if !(triangle.r2 <= alpha*alpha) {
+			continue
+		}
+		for _, edge := range []delaunayEdge{{triangle.a, triangle.b}, {triangle.b, triangle.c}, {triangle.c, triangle.a}} {
+			counts[delaunayEdge{minInt(edge.a, edge.b), maxInt(edge.a, edge.b)}]++
+			directed = append(directed, edge)
+		}
+	}
+	var boundary []delaunayEdge
+	for _, edge := range directed {
+		if counts[delaunayEdge{minInt(edge.a, edge.b), maxInt(edge.a, edge.b)}] == 1 {
+			boundary = append(boundary, edge)
+		}
+	}
+	return traceRings(xs, ys, boundary)
+}
+
+// nextBoundaryVertex picks the boundary edge leaving at that continues the region arriving from
+// from, the first clockwise from the way back. Where two regions touch at a vertex, this keeps
+// their rings apart.
+func nextBoundaryVertex(xs, ys []float64, from, at int, candidates []int) int {
+	back := math.Atan2(ys[from]-ys[at], xs[from]-xs[at])
+	best, bestAngle := -1, math.Inf(1)
+	for _, candidate := range candidates {
+		angle := back - math.Atan2(ys[candidate]-ys[at], xs[candidate]-xs[at])
+		for angle <= 0 {
+			angle += 2 * math.Pi
+		}
+		if angle < bestAngle {
+			best, bestAngle = candidate, angle
+		}
+	}
+	return best
+}
+
+func containsInt(values []int, value int) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeInt removes the edge from a to b from the outgoing edges.
+func removeInt(outgoing map[int][]int, a, b int) {
+	values := outgoing[a]
+	for i, value := range values {
+		if value == b {
+			outgoing[a] = append(values[:i], values[i+1:]...)
+			return
+		}
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,117 @@
+package geofence
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGeofenceFromConvexHull(t *testing.T) {
+	// The corners of a square, with noise inside and points along its edges
+	points := []*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0), NewPoint(0, 0.5), NewPoint(0.5, 1), NewPoint(1, 1)}
+	for i := 0; i < 200; i++ {
+		points = append(points, NewPoint(0.01+rand.Float64()*0.98, 0.01+rand.Float64()*0.98))
+	}
+	rand.Shuffle(len(points), func(i, j int) { points[i], points[j] = points[j], points[i] })
+	geofence := NewGeofenceFromConvexHull(points)
+	assert.Equal(t, []*Point{NewPoint(0, 0), NewPoint(1, 0), NewPoint(1, 1), NewPoint(0, 1)}, geofence.Vertices())
+	for _, point := range points {
+		assert.True(t, geofence.Inside(point))
+	}
+	assert.False(t, geofence.Inside(NewPoint(1.01, 0.5)))
+
+	// A cloud of visits around a site is fenced by its outermost visits
+	center := NewPoint(51.5, -0.1)
+	visits := make([]*Point, 500)
+	for i := range visits {
+		visits[i] = center.PointAtDistanceAndBearing(rand.Float64()*0.3, rand.Float64()*360)
+	}
+	geofence = NewGeofenceFromConvexHull(visits, WithGeodesic())
+	assert.True(t, geofence.geodesic)
+	assert.Less(t, len(geofence.Vertices()), len(visits))
+	for _, visit := range visits {
+		assert.True(t, geofence.InsideWithin(visit, 0.01))
+	}
+
+	// Across the antimeridian, unless planar
+	points = []*Point{NewPoint(0, 179), NewPoint(0, -179), NewPoint(2, -179), NewPoint(2, 179), NewPoint(1, 180)}
+	geofence = NewGeofenceFromConvexHull(points)
+	assert.True(t, geofence.Inside(NewPoint(1, -179.5)))
+	assert.Len(t, geofence.Vertices(), 4)
+	planar := NewGeofenceFromConvexHull(points, WithPlanar())
+	assert.True(t, planar.Inside(NewPointXY(1, 0)))
+
+	// Points on a line have no area
+	assert.Len(t, NewGeofenceFromConvexHull([]*Point{NewPoint(0, 0), NewPoint(1, 1), NewPoint(2, 2), NewPoint(1, 1)}).Vertices(), 2)
+	assert.Empty(t, NewGeofenceFromConvexHull(nil).Vertices())
+}
+
+// gridPoints returns points about every 10m in the rectangle from the origin, in meters east
+// and north of a point in London, jittered so no four are on a circle.
+func gridPoints(east, north, width, height float64) []*Point {
+	origin := NewPoint(51.5, -0.1)
+	var points []*Point
+	for x := east; x <= east+width; x += 10 {
+		for y := north; y <= north+height; y += 10 {
+			dx, dy := x+rand.Float64()*4-2, y+rand.Float64()*4-2
+			points = append(points, origin.PointAtDistanceAndBearing(math.Hypot(dx, dy)/1000, math.Atan2(dx, dy)*180/math.Pi))
+		}
+	}
+	return points
+}
+
+// metersFrom returns the point at the given meters east and north of the point in London.
+func metersFrom(east, north float64) *Point {
+	return NewPoint(51.5, -0.1).PointAtDistanceAndBearing(math.Hypot(east, north)/1000, math.Atan2(east, north)*180/math.Pi)
+}
+
+func TestNewGeofenceFromConcaveHull(t *testing.T) {
+	// An L of visits, whose notch a convex hull takes in
+	points := append(gridPoints(0, 0, 200, 100), gridPoints(0, 110, 100, 90)...)
+	geofence := NewGeofenceFromConcaveHull(points, 20)
+	for _, point := range points {
+		assert.True(t, geofence.Inside(point), point)
+	}
+	assert.True(t, geofence.Inside(metersFrom(50, 150)))
+	assert.False(t, geofence.Inside(metersFrom(130, 130)))
+	assert.True(t, NewGeofenceFromConvexHull(points).Inside(metersFrom(130, 130)))
+	assert.Nil(t, geofence.Holes())
+
+	// A large alpha approaches the convex hull, and a tiny one keeps nothing
+	assert.True(t, NewGeofenceFromConcaveHull(points, 10000).Inside(metersFrom(130, 130)))
+	assert.Empty(t, NewGeofenceFromConcaveHull(points, 1).Vertices())
+
+	// A field around a pond, and two far apart yards
+	var field []*Point
+	for _, point := range gridPoints(0, 0, 200, 200) {
+		if point.DistanceTo(metersFrom(100, 100)) > 50 {
+			field = append(field, point)
+		}
+	}
+	geofence = NewGeofenceFromConcaveHull(field, 20)
+	assert.Len(t, geofence.Holes(), 1)
+	assert.False(t, geofence.Inside(metersFrom(100, 100)))
+	assert.True(t, geofence.Inside(metersFrom(100, 20)))
+
+	yards := append(gridPoints(0, 0, 50, 50), gridPoints(500, 0, 50, 50)...)
+	geofence = NewGeofenceFromConcaveHull(yards, 20)
+	assert.Len(t, geofence.polygons(), 2)
+	assert.True(t, geofence.Inside(metersFrom(525, 25)))
+	assert.False(t, geofence.Inside(metersFrom(250, 25)))
+
+	// Grid units WithPlanar
+	var planar []*Point
+	for x := 0.0; x <= 20; x++ {
+		for y := 0.0; y <= 20; y++ {
+			if x <= 10 || y <= 10 {
+				planar = append(planar, NewPointXY(x+rand.Float64()*0.2, y+rand.Float64()*0.2))
+			}
+		}
+	}
+	geofence = NewGeofenceFromConcaveHull(planar, 2, WithPlanar())
+	assert.True(t, geofence.Inside(NewPointXY(5, 15)))
+	assert.False(t, geofence.Inside(NewPointXY(15, 15)))
+	assert.Empty(t, NewGeofenceFromConcaveHull(nil, 2).Vertices())
+}
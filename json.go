@@ -0,0 +1,301 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// geofenceJSON is the JSON form of a Geofence: a GeoJSON Polygon or MultiPolygon geometry, with
+// the options needed to build the same geofence again as extra members.
+type geofenceJSON struct {
+	Type            string          `json:"type"`
+	Coordinates     json.RawMessage `json:"coordinates"`
+	Granularity     []int           `json:"granularity,omitempty"`
+	AutoGranularity bool            `json:"autoGranularity,omitempty"`
+	TileSizeMeters  float64         `json:"tileSizeMeters,omitempty"`
+	Refinement      int             `json:"refinement,omitempty"`
+	Geodesic        bool            `json:"geodesic,omitempty"`
+	Planar          bool            `json:"planar,omitempty"`
+	Projection      string          `json:"projection,omitempty"`
+	CentralMeridian float64         `json:"centralMeridian,omitempty"`
+	LocalProjection bool            `json:"localProjection,omitempty"`
+	Boundary        string          `json:"boundary,omitempty"`
+	Containment     string          `json:"containment,omitempty"`
+	ExactPredicates bool            `json:"exactPredicates,omitempty"`
+	Rectangle       bool            `json:"rectangle,omitempty"`
+	AltitudeRange   []float64       `json:"altitudeRange,omitempty"`
+}
+
+// Renders the Geofence as JSON: its GeoJSON geometry, as for ToGeoJSON, along with its tile grid,
+// mode and boundary rules, e.g. {"type":"Polygon","coordinates":[...],"granularity":[40,40]}.
+// Densify, simplify and coordinate order options are applied to the vertices as built, so they
+// are not saved. Custom projections cannot be saved.
+// Implements the json.Marshaler Interface.
+func (geofence *Geofence) MarshalJSON() ([]byte, error) {
+	encoded, err := geofence.encodedOptions()
+	if err != nil {
+		return nil, err
+	}
+	encoded.Type, encoded.Coordinates = "Polygon", json.RawMessage("[]")
+	if !geofence.empty() {
+		geometry, err := geofence.ToGeoJSON()
+		if err != nil {
+			return nil, err
+		}
+		var object geoJSONObject
+		if err := json.Unmarshal(geometry, &object); err != nil {
+			return nil, fmt.Errorf("unable to encode geofence: %v", err)
+		}
+		encoded.Type, encoded.Coordinates = object.Type, object.Coordinates
+	}
+	return json.Marshal(encoded)
+}
+
+// encodedOptions returns the options of the geofence, as saved by MarshalJSON and ToProto,
+// without its geometry.
+func (geofence *Geofence) encodedOptions() (geofenceJSON, error) {
+	template := geofence.template()
+	encoded := geofenceJSON{
+		Refinement:      template.refinement,
+		Geodesic:        template.geodesic,
+		Planar:          template.planar,
+		LocalProjection: template.localProj,
+		ExactPredicates: template.exact,
+		Rectangle:       template.rect,
+	}
+	switch {
+	case template.autoGrid:
+		encoded.AutoGranularity = true
+	case template.tileMeters > 0:
+		encoded.TileSizeMeters = template.tileMeters
+	default:
+		encoded.Granularity = []int{int(template.granularityX), int(template.granularityY)}
+	}
+	if !template.localProj {
+		projection, meridian, err := projectionSnapshot(template.projection)
+		if err != nil {
+			return geofenceJSON{}, err
+		}
+		encoded.Projection, encoded.CentralMeridian = projection, meridian
+	}
+	if template.boundary == Exclusive {
+		encoded.Boundary = "exclusive"
+	}
+	if template.containment == WindingNumber {
+		encoded.Containment = "winding"
+	}
+	if template.altitudeRange {
+		encoded.AltitudeRange = []float64{template.minAltitude, template.maxAltitude}
+	}
+	return encoded, nil
+}
+
+// Decodes a Geofence rendered by MarshalJSON, or any GeoJSON Polygon or MultiPolygon geometry,
+// which gives a geofence with the default options. The tiles are built again.
+// Implements the json.Unmarshaler Interface.
+func (geofence *Geofence) UnmarshalJSON(data []byte) error {
+	var encoded geofenceJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return fmt.Errorf("unable to decode geofence: %v", err)
+	}
+	opts, err := encoded.options()
+	if err != nil {
+		return fmt.Errorf("unable to decode geofence: %v", err)
+	}
+
+	var decoded *Geofence
+	switch {
+	case len(bytes.TrimSpace(encoded.Coordinates)) == 0 || bytes.Equal(bytes.TrimSpace(encoded.Coordinates), []byte("[]")):
+		decoded = NewGeofence(nil, opts...)
+	default:
+		decoded, err = geoJSONGeometryToGeofence(&geoJSONObject{Type: encoded.Type, Coordinates: encoded.Coordinates}, opts)
+		if err != nil {
+			return err
+		}
+	}
+	*geofence = *encoded.restoreRectangle(decoded, opts)
+	return nil
+}
+
+// restoreRectangle returns the decoded geofence as a NewBBoxGeofence when it was saved as one.
+func (encoded *geofenceJSON) restoreRectangle(decoded *Geofence, opts []Option) *Geofence {
+	if !encoded.Rectangle || len(decoded.parts) > 0 || len(decoded.vertices) != 4 || len(decoded.holes) > 0 {
+		return decoded
+	}
+	// The corners of a NewBBoxGeofence start at the southwest, then along its southern edge
+	corners := decoded.Vertices()
+	return NewBBoxGeofence(corners[0].Lat(), corners[0].Lng(), corners[2].Lat(), corners[2].Lng(), opts...)
+}
+
+// options returns the options the JSON form of a geofence was saved with.
+func (encoded *geofenceJSON) options() ([]Option, error) {
+	var opts []Option
+	switch {
+	case encoded.AutoGranularity:
+		opts = append(opts, WithAutoGranularity())
+	case encoded.TileSizeMeters != 0:
+		opts = append(opts, WithTileSizeMeters(encoded.TileSizeMeters))
+	case len(encoded.Granularity) == 1:
+		opts = append(opts, WithGranularity(encoded.Granularity[0]))
+	case len(encoded.Granularity) == 2:
+		opts = append(opts, WithGranularityXY(encoded.Granularity[0], encoded.Granularity[1]))
+	case len(encoded.Granularity) > 2:
+		return nil, fmt.Errorf("granularity must have 1 or 2 values, got %d", len(encoded.Granularity))
+	}
+	if encoded.Refinement != 0 {
+		opts = append(opts, WithRefinement(encoded.Refinement))
+	}
+	switch {
+	case encoded.Geodesic:
+		opts = append(opts, WithGeodesic())
+	case encoded.Planar:
+		opts = append(opts, WithPlanar())
+	case encoded.LocalProjection:
+		opts = append(opts, WithLocalProjection())
+	case encoded.Projection != "":
+		projection := restoreProjection(encoded.Projection, encoded.CentralMeridian)
+		if projection == nil {
+			return nil, fmt.Errorf("unknown projection %q", encoded.Projection)
+		}
+		opts = append(opts, WithProjection(projection))
+	}
+	switch encoded.Boundary {
+	case "", "inclusive":
+	case "exclusive":
+		opts = append(opts, WithBoundary(Exclusive))
+	default:
+		return nil, fmt.Errorf("unknown boundary %q", encoded.Boundary)
+	}
+	switch encoded.Containment {
+	case "", "raycasting":
+	case "winding":
+		opts = append(opts, WithContainment(WindingNumber))
+	default:
+		return nil, fmt.Errorf("unknown containment %q", encoded.Containment)
+	}
+	if encoded.ExactPredicates {
+		opts = append(opts, WithExactPredicates())
+	}
+	switch len(encoded.AltitudeRange) {
+	case 0:
+	case 2:
+		opts = append(opts, WithAltitudeRange(encoded.AltitudeRange[0], encoded.AltitudeRange[1]))
+	default:
+		return nil, fmt.Errorf("altitude range must have 2 values, got %d", len(encoded.AltitudeRange))
+	}
+	return opts, (&Geofence{}).applyOptions(opts)
+}
+
+// groupJSON is the JSON form of a GeofenceGroup.
+type groupJSON[K comparable] struct {
+	S2IndexLevel          int                 `json:"s2IndexLevel,omitempty"`
+	GeohashIndexPrecision int                 `json:"geohashIndexPrecision,omitempty"`
+	Entries               []groupEntryJSON[K] `json:"entries"`
+}
+
+type groupEntryJSON[K comparable] struct {
+	Key       K           `json:"key"`
+	Whitelist []*Geofence `json:"whitelist,omitempty"`
+	Blacklist []*Geofence `json:"blacklist,omitempty"`
+	// WhitelistExpires and BlacklistExpires are the AddWithExpiry times of each geofence, the
+	// zero time for those that never expire
+	WhitelistExpires []time.Time `json:"whitelistExpires,omitempty"`
+	BlacklistExpires []time.Time `json:"blacklistExpires,omitempty"`
+	Metadata         any         `json:"metadata,omitempty"`
+	Priority         int         `json:"priority,omitempty"`
+}
+
+// Renders the GeofenceGroup as JSON: its index options and each key with its whitelist and
+// blacklist geofences, as for Geofence.MarshalJSON, their expiry times if they were added by
+// AddWithExpiry, and the key's metadata and priority. Geofences that have already expired are
+// left out. The keys and metadata must render to JSON themselves. The keys are sorted by their
+// JSON so the same group always renders the same, and metadata decodes as json.Unmarshal
+// decodes into an any, e.g. objects as map[string]any.
+// Implements the json.Marshaler Interface.
+func (group *GeofenceGroup[K]) MarshalJSON() ([]byte, error) {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	encoded := groupJSON[K]{
+		S2IndexLevel:          group.options.s2Level,
+		GeohashIndexPrecision: group.options.geohashPrecision,
+		Entries:               make([]groupEntryJSON[K], 0, len(group.entries)),
+	}
+	sortKeys := make(map[K]string, len(group.entries))
+	for key, entry := range group.entries {
+		sortKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode geofence group key %v: %v", key, err)
+		}
+		sortKeys[key] = string(sortKey)
+		encoded.Entries = append(encoded.Entries, groupEntryJSON[K]{
+			Key:              key,
+			Whitelist:        entry.whitelist,
+			Blacklist:        entry.blacklist,
+			WhitelistExpires: entry.whitelistExpires,
+			BlacklistExpires: entry.blacklistExpires,
+			Metadata:         entry.metadata,
+			Priority:         entry.priority,
+		})
+	}
+	sort.Slice(encoded.Entries, func(i, j int) bool {
+		return sortKeys[encoded.Entries[i].Key] < sortKeys[encoded.Entries[j].Key]
+	})
+	return json.Marshal(encoded)
+}
+
+// Decodes a GeofenceGroup rendered by MarshalJSON, replacing the group's keys and options.
+// Implements the json.Unmarshaler Interface.
+func (group *GeofenceGroup[K]) UnmarshalJSON(data []byte) error {
+	var encoded groupJSON[K]
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return fmt.Errorf("unable to decode geofence group: %v", err)
+	}
+	var options groupOptions
+	if encoded.S2IndexLevel != 0 {
+		if err := WithS2Index(encoded.S2IndexLevel)(&options); err != nil {
+			return fmt.Errorf("unable to decode geofence group: %v", err)
+		}
+	}
+	if encoded.GeohashIndexPrecision != 0 {
+		if err := WithGeohashIndex(encoded.GeohashIndexPrecision)(&options); err != nil {
+			return fmt.Errorf("unable to decode geofence group: %v", err)
+		}
+	}
+	entries := make(map[K]*groupEntry, len(encoded.Entries))
+	for _, entry := range encoded.Entries {
+		if _, ok := entries[entry.Key]; ok {
+			return fmt.Errorf("unable to decode geofence group: key %v appears more than once", entry.Key)
+		}
+		for _, geofence := range append(append([]*Geofence{}, entry.Whitelist...), entry.Blacklist...) {
+			if geofence == nil {
+				return fmt.Errorf("unable to decode geofence group: key %v has a null geofence", entry.Key)
+			}
+		}
+		if (entry.WhitelistExpires != nil && len(entry.WhitelistExpires) != len(entry.Whitelist)) ||
+			(entry.BlacklistExpires != nil && len(entry.BlacklistExpires) != len(entry.Blacklist)) {
+			return fmt.Errorf("unable to decode geofence group: key %v must have an expiry time for each geofence", entry.Key)
+		}
+		entries[entry.Key] = &groupEntry{
+			whitelist:        entry.Whitelist,
+			blacklist:        entry.Blacklist,
+			whitelistExpires: entry.WhitelistExpires,
+			blacklistExpires: entry.BlacklistExpires,
+			metadata:         entry.Metadata,
+			priority:         entry.Priority,
+		}
+	}
+
+	group.mu.Lock()
+	defer group.mu.Unlock()
+	group.entries, group.options = entries, options
+	group.index, group.dirty = nil, true
+	group.nextExpiry = time.Time{}
+	for _, entry := range entries {
+		group.scheduleExpiry(entry)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package geofence
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofenceJSON(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(5, 14), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithGranularityXY(40, 30), WithBoundary(Exclusive))
+
+	data, err := json.Marshal(geofence)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[14,5],[10,10],[0,10],[0,0]],[[2,2],[8,2],[8,8],[2,8],[2,2]]],
+		"granularity":[40,30],"boundary":"exclusive"}`, string(data))
+	decoded := &Geofence{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, geofence.tiles, decoded.tiles)
+	assert.Equal(t, geofence.Vertices(), decoded.Vertices())
+	assert.Equal(t, geofence.Holes(), decoded.Holes())
+	assert.Equal(t, Exclusive, decoded.boundary)
+
+	// Each mode and grid comes back
+	for _, original := range []*Geofence{
+		NewGeofence(outer, WithAutoGranularity(), WithGeodesic(), WithContainment(WindingNumber)),
+		NewGeofence(outer, WithTileSizeMeters(5000), WithProjection(TransverseMercator{CentralMeridian: 5})),
+		NewGeofence(outer, WithLocalProjection(), WithExactPredicates(), WithRefinement(2)),
+		NewGeofence(outer, WithPlanar(), WithGranularity(8), WithAltitudeRange(-5, 120)),
+		NewBBoxGeofence(-1, 179, 1, -179),
+		NewMultiGeofence([][]*Point{outer, {NewPoint(20, 20), NewPoint(20, 21), NewPoint(21, 21)}}, WithGranularity(10)),
+		NewGeofence(nil),
+	} {
+		data, err := json.Marshal(original)
+		assert.NoError(t, err)
+		decoded := &Geofence{}
+		if assert.NoError(t, json.Unmarshal(data, decoded), string(data)) {
+			want, got := original.template(), decoded.template()
+			assert.Equal(t, []interface{}{want.granularityX, want.granularityY, want.autoGrid, want.tileMeters, want.refinement, want.geodesic, want.planar,
+				want.projection, want.localProj, want.boundary, want.containment, want.exact, want.rect, want.altitudeRange, want.minAltitude, want.maxAltitude},
+				[]interface{}{got.granularityX, got.granularityY, got.autoGrid, got.tileMeters, got.refinement, got.geodesic, got.planar,
+					got.projection, got.localProj, got.boundary, got.containment, got.exact, got.rect, got.altitudeRange, got.minAltitude, got.maxAltitude}, string(data))
+			assert.Equal(t, len(original.polygons()), len(decoded.polygons()))
+			assert.True(t, original.Equal(decoded), string(data))
+			assert.Equal(t, original.Inside(NewPoint(5, 5)), decoded.Inside(NewPoint(5, 5)))
+		}
+	}
+
+	// Plain GeoJSON decodes with the default options
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]]}`), decoded))
+	assert.Equal(t, int64(defaultGranularity), decoded.granularityX)
+	assert.True(t, decoded.Inside(NewPoint(2, 5)))
+
+	for _, bad := range []string{
+		`[1, 2]`,
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"granularity":[0,10]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"granularity":[1,2,3]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"projection":"Mollweide"}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"boundary":"fuzzy"}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"altitudeRange":[120]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"altitudeRange":[120,0]}`,
+	} {
+		assert.Error(t, json.Unmarshal([]byte(bad), &Geofence{}), bad)
+	}
+
+	_, err = json.Marshal(NewGeofence(outer, WithProjection(flippedProjection{})))
+	assert.Error(t, err)
+}
+
+func TestGeofenceGroupJSON(t *testing.T) {
+	square := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, WithGranularity(5))
+	inner := NewGeofence([]*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)})
+	group := NewGeofenceGroup[string](WithS2Index(8))
+	group.Add("b", []*Geofence{square}, []*Geofence{inner})
+	group.Add("a", nil, []*Geofence{inner})
+
+	data, err := json.Marshal(group)
+	assert.NoError(t, err)
+	var entries struct {
+		S2IndexLevel int `json:"s2IndexLevel"`
+		Entries      []struct {
+			Key string `json:"key"`
+		} `json:"entries"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &entries))
+	assert.Equal(t, 8, entries.S2IndexLevel)
+	if assert.Len(t, entries.Entries, 2) {
+		assert.Equal(t, "a", entries.Entries[0].Key)
+		assert.Equal(t, "b", entries.Entries[1].Key)
+	}
+
+	decoded := NewGeofenceGroup[string]()
+	decoded.Add("stale", []*Geofence{square}, nil)
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.ElementsMatch(t, []string{"a", "b"}, decoded.Keys())
+	assert.Equal(t, 8, decoded.options.s2Level)
+	for _, point := range []*Point{NewPoint(1, 1), NewPoint(5, 5), NewPoint(20, 20)} {
+		assert.Equal(t, group.GetValidKeys(point), decoded.GetValidKeys(point))
+	}
+
+	// Expiry times of geofences added by AddWithExpiry
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	group.AddWithExpiry("b", nil, []*Geofence{square}, expires)
+	group.AddWithExpiry("c", []*Geofence{square}, nil, time.Now().Add(-time.Second))
+	data, err = json.Marshal(group)
+	assert.NoError(t, err)
+	decoded = NewGeofenceGroup[string]()
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.ElementsMatch(t, []string{"a", "b"}, decoded.Keys())
+	if assert.Len(t, decoded.entries["b"].blacklistExpires, 2) {
+		assert.True(t, decoded.entries["b"].blacklistExpires[0].IsZero())
+		assert.True(t, expires.Equal(decoded.entries["b"].blacklistExpires[1]))
+	}
+	assert.True(t, expires.Equal(decoded.nextExpiry))
+	assert.Error(t, json.Unmarshal([]byte(`{"entries":[{"key":"a","whitelist":[],"whitelistExpires":["2024-01-01T00:00:00Z"]}]}`), decoded))
+
+	// Metadata
+	assert.NoError(t, group.SetMetadata("a", map[string]any{"owner": "fleet", "speedLimit": 30.0}))
+	assert.NoError(t, group.SetPriority("b", -2))
+	data, err = json.Marshal(group)
+	assert.NoError(t, err)
+	decoded = NewGeofenceGroup[string]()
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	metadata, ok := decoded.Metadata("a")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"owner": "fleet", "speedLimit": 30.0}, metadata)
+	metadata, ok = decoded.Metadata("b")
+	assert.True(t, ok)
+	assert.Nil(t, metadata)
+	priority, _ := decoded.Priority("b")
+	assert.Equal(t, -2, priority)
+
+	// Keys other than strings
+	numbered := NewGeofenceGroup[int]()
+	numbered.Add(7, []*Geofence{square}, nil)
+	data, err = json.Marshal(numbered)
+	assert.NoError(t, err)
+	decodedNumbered := NewGeofenceGroup[int]()
+	assert.NoError(t, json.Unmarshal(data, decodedNumbered))
+	assert.Equal(t, map[int]bool{7: true}, decodedNumbered.GetValidKeys(NewPoint(1, 1)))
+
+	for _, bad := range []string{
+		`{"s2IndexLevel":99,"entries":[]}`,
+		`{"entries":[{"key":"a"},{"key":"a"}]}`,
+		`{"entries":[{"key":"a","whitelist":[null]}]}`,
+	} {
+		assert.Error(t, json.Unmarshal([]byte(bad), NewGeofenceGroup[string]()), bad)
+	}
+}
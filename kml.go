@@ -0,0 +1,185 @@
+package geofence
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// KMLPlacemark is a geofence read from a KML Placemark, with the Placemark's name and
+// description.
+type KMLPlacemark struct {
+	Name        string
+	Description string
+	Geofence    *Geofence
+}
+
+type kmlPlacemark struct {
+	Name          string             `xml:"name"`
+	Description   string             `xml:"description"`
+	Polygons      []kmlPolygon       `xml:"Polygon"`
+	MultiGeometry []kmlMultiGeometry `xml:"MultiGeometry"`
+}
+
+type kmlMultiGeometry struct {
+	Polygons      []kmlPolygon       `xml:"Polygon"`
+	MultiGeometry []kmlMultiGeometry `xml:"MultiGeometry"`
+}
+
+type kmlPolygon struct {
+	Outer string   `xml:"outerBoundaryIs>LinearRing>coordinates"`
+	Inner []string `xml:"innerBoundaryIs>LinearRing>coordinates"`
+}
+
+// NewGeofencesFromKML builds a Geofence from each Placemark with polygons in a KML document, or
+// in the doc.kml of a KMZ archive, as exported by Google Earth. Placemarks may be nested in any
+// Document or Folder. A Placemark's polygons, including those in nested MultiGeometry, become a
+// single geofence, as built by NewMultiGeofence when there are several, and Placemarks with only
+// points or lines are skipped. Options are applied to each geofence as for NewGeofence.
+func NewGeofencesFromKML(data []byte, opts ...Option) ([]KMLPlacemark, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		kml, err := readKMZ(data)
+		if err != nil {
+			return nil, err
+		}
+		data = kml
+	}
+
+	var placemarks []KMLPlacemark
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode KML: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+		var placemark kmlPlacemark
+		if err := decoder.DecodeElement(&placemark, &start); err != nil {
+			return nil, fmt.Errorf("unable to decode KML: %v", err)
+		}
+		polygons := placemark.polygons()
+		if len(polygons) == 0 {
+			continue
+		}
+		parts := make([]*Geofence, len(polygons))
+		for i, polygon := range polygons {
+			rings, err := polygon.rings()
+			if err != nil {
+				return nil, fmt.Errorf("KML placemark %d %q: %v", len(placemarks), placemark.Name, err)
+			}
+			parts[i] = NewGeofenceWithHoles(rings[0], rings[1:], opts...)
+		}
+		geofence := parts[0]
+		if len(parts) > 1 {
+			geofence = newMultiGeofence(parts)
+		}
+		placemarks = append(placemarks, KMLPlacemark{
+			Name:        strings.TrimSpace(placemark.Name),
+			Description: strings.TrimSpace(placemark.Description),
+			Geofence:    geofence,
+		})
+	}
+	if len(placemarks) == 0 {
+		return nil, fmt.Errorf("KML has no placemarks with polygons")
+	}
+	return placemarks, nil
+}
+
+// readKMZ returns the KML document of a KMZ archive: doc.kml, or else its first .kml file.
+func readKMZ(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read KMZ: %v", err)
+	}
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "doc.kml" {
+			document = file
+			break
+		}
+		if document == nil && strings.HasSuffix(strings.ToLower(file.Name), ".kml") {
+			document = file
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("KMZ has no KML document")
+	}
+	reader, err := document.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read KMZ: %v", err)
+	}
+	defer reader.Close()
+	kml, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read KMZ: %v", err)
+	}
+	return kml, nil
+}
+
+// polygons returns the placemark's polygons, followed by those of any MultiGeometry.
+func (placemark *kmlPlacemark) polygons() []kmlPolygon {
+	multi := kmlMultiGeometry{Polygons: placemark.Polygons, MultiGeometry: placemark.MultiGeometry}
+	return multi.polygons()
+}
+
+func (multi *kmlMultiGeometry) polygons() []kmlPolygon {
+	polygons := append([]kmlPolygon{}, multi.Polygons...)
+	for i := range multi.MultiGeometry {
+		polygons = append(polygons, multi.MultiGeometry[i].polygons()...)
+	}
+	return polygons
+}
+
+// rings returns the outer boundary followed by the inner boundaries of the polygon.
+func (polygon *kmlPolygon) rings() ([][]*Point, error) {
+	rings := make([][]*Point, 0, 1+len(polygon.Inner))
+	for i, coordinates := range append([]string{polygon.Outer}, polygon.Inner...) {
+		ring, err := kmlRing(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("KML ring %d: %v", i, err)
+		}
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
+
+// kmlRing parses the coordinates of a KML LinearRing, whitespace separated lng,lat[,alt] tuples.
+func kmlRing(coordinates string) ([]*Point, error) {
+	tuples := strings.Fields(coordinates)
+	ring := make([]*Point, 0, len(tuples))
+	for _, tuple := range tuples {
+		values := strings.Split(tuple, ",")
+		if len(values) < 2 || len(values) > 3 {
+			return nil, fmt.Errorf("invalid KML coordinates %q", tuple)
+		}
+		lng, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML coordinates %q", tuple)
+		}
+		lat, err := strconv.ParseFloat(values[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML coordinates %q", tuple)
+		}
+		ring = append(ring, NewPoint(lat, lng))
+	}
+	// KML rings repeat the first position at the end, Geofence rings do not
+	if len(ring) > 1 {
+		if first, last := ring[0], ring[len(ring)-1]; first.lat == last.lat && first.lng == last.lng {
+			ring = ring[:len(ring)-1]
+		}
+	}
+	if len(ring) < 3 {
+		return nil, fmt.Errorf("ring has %d points, at least 3 are required", len(ring))
+	}
+	return ring, nil
+}
@@ -0,0 +1,92 @@
+package geofence
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document>
+  <name>Zones</name>
+  <Folder>
+    <Placemark>
+      <name>Yard</name>
+      <description>Main yard</description>
+      <Polygon>
+        <outerBoundaryIs><LinearRing><coordinates>
+          0,0,0 10,0,0 10,10,0 0,10,0 0,0,0
+        </coordinates></LinearRing></outerBoundaryIs>
+        <innerBoundaryIs><LinearRing><coordinates>4,4 6,4 6,6 4,6 4,4</coordinates></LinearRing></innerBoundaryIs>
+      </Polygon>
+    </Placemark>
+    <Placemark>
+      <name>Gate</name>
+      <Point><coordinates>5,0</coordinates></Point>
+    </Placemark>
+  </Folder>
+  <Placemark>
+    <name>Sites</name>
+    <MultiGeometry>
+      <Point><coordinates>25,25</coordinates></Point>
+      <Polygon><outerBoundaryIs><LinearRing><coordinates>20,20 21,20 21,21 20,21 20,20</coordinates></LinearRing></outerBoundaryIs></Polygon>
+      <MultiGeometry>
+        <Polygon><outerBoundaryIs><LinearRing><coordinates>30,30 31,30 31,31 30,31</coordinates></LinearRing></outerBoundaryIs></Polygon>
+      </MultiGeometry>
+    </MultiGeometry>
+  </Placemark>
+</Document>
+</kml>`
+
+func TestNewGeofencesFromKML(t *testing.T) {
+	placemarks, err := NewGeofencesFromKML([]byte(testKML), WithGranularity(5))
+	assert.NoError(t, err)
+	if assert.Len(t, placemarks, 2) {
+		yard := placemarks[0]
+		assert.Equal(t, "Yard", yard.Name)
+		assert.Equal(t, "Main yard", yard.Description)
+		assert.Equal(t, NewPoint(0, 0), yard.Geofence.Vertices()[0])
+		assert.Len(t, yard.Geofence.Vertices(), 4)
+		assert.Len(t, yard.Geofence.Holes(), 1)
+		assert.True(t, yard.Geofence.Inside(NewPoint(2, 5)))
+		assert.False(t, yard.Geofence.Inside(NewPoint(5, 5)))
+		assert.Equal(t, int64(5), yard.Geofence.granularityX)
+
+		sites := placemarks[1]
+		assert.Equal(t, "Sites", sites.Name)
+		assert.Len(t, sites.Geofence.polygons(), 2)
+		assert.True(t, sites.Geofence.Inside(NewPoint(20.5, 20.5)))
+		assert.True(t, sites.Geofence.Inside(NewPoint(30.5, 30.5)))
+		assert.False(t, sites.Geofence.Inside(NewPoint(25, 25)))
+	}
+
+	// KMZ archives hold the KML as doc.kml
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, err := writer.Create("files/icon.png")
+	assert.NoError(t, err)
+	file.Write([]byte{0x89, 'P', 'N', 'G'})
+	file, err = writer.Create("doc.kml")
+	assert.NoError(t, err)
+	file.Write([]byte(testKML))
+	assert.NoError(t, writer.Close())
+	fromKMZ, err := NewGeofencesFromKML(archive.Bytes())
+	assert.NoError(t, err)
+	if assert.Len(t, fromKMZ, 2) {
+		assert.Equal(t, placemarks[0].Geofence.Vertices(), fromKMZ[0].Geofence.Vertices())
+	}
+
+	for _, bad := range []string{
+		`<kml><Placemark><Point><coordinates>0,0</coordinates></Point></Placemark></kml>`,
+		`<kml><Placemark><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,x 1,1</coordinates></LinearRing></outerBoundaryIs></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Polygon>`,
+		"PK\x03\x04 not a zip",
+	} {
+		_, err := NewGeofencesFromKML([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
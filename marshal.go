@@ -0,0 +1,177 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// geofenceSnapshotVersion is the version of the encoding written by MarshalBinary. Encodings
+// from before versioning decode as version 0, which is still read. Version 2 added altitude
+// ranges, which version 1 readers would drop.
+const geofenceSnapshotVersion = 2
+
+// geofenceSnapshot is the gob encoded form of a Geofence, including the precomputed tiles.
+type geofenceSnapshot struct {
+	Version       int
+	Vertices      []*Point
+	Holes         [][]*Point
+	Geodesic      bool
+	WrapLng       bool
+	Boundary      Boundary
+	Containment   Containment
+	Exact         bool
+	Planar        bool
+	Projection    string
+	Meridian      float64
+	Rect          bool
+	Parts         []geofenceSnapshot
+	Tiles         map[int64]byte
+	Granularity   int64
+	GranularityY  int64
+	Refinement    int
+	AutoGrid      bool
+	TileMeters    float64
+	LocalProj     bool
+	AltitudeRange bool
+	MinAltitude   float64
+	MaxAltitude   float64
+	MinX          float64
+	MaxX          float64
+	MinY          float64
+	MaxY          float64
+	TileWidth     float64
+	TileHeight    float64
+	MinTileX      float64
+	MaxTileX      float64
+	MinTileY      float64
+	MaxTileY      float64
+}
+
+// Renders the Geofence, including its precomputed tiles, to a byte slice.
+// Implements the encoding.BinaryMarshaler Interface.
+func (geofence *Geofence) MarshalBinary() ([]byte, error) {
+	snapshot, err := geofence.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("unable to encode geofence: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decodes a Geofence rendered by MarshalBinary. The tiles are restored as they were
+// saved, so Inside can be called straight away without recomputing them. Data written by a
+// later version of the package, with an encoding this one does not know, is an error.
+// Implements the encoding.BinaryUnmarshaler Interface.
+func (geofence *Geofence) UnmarshalBinary(data []byte) error {
+	var snapshot geofenceSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return fmt.Errorf("unable to decode geofence: %v", err)
+	}
+	if snapshot.Version > geofenceSnapshotVersion {
+		return fmt.Errorf("unable to decode geofence: unsupported encoding version %d", snapshot.Version)
+	}
+	*geofence = *snapshot.restore()
+	return nil
+}
+
+func (geofence *Geofence) snapshot() (geofenceSnapshot, error) {
+	projection, meridian, err := projectionSnapshot(geofence.projection)
+	if err != nil {
+		return geofenceSnapshot{}, err
+	}
+	snapshot := geofenceSnapshot{
+		Version:       geofenceSnapshotVersion,
+		Vertices:      geofence.vertices,
+		Holes:         geofence.holes,
+		Geodesic:      geofence.geodesic,
+		WrapLng:       geofence.wrapLng,
+		Boundary:      geofence.boundary,
+		Containment:   geofence.containment,
+		Exact:         geofence.exact,
+		Planar:        geofence.planar,
+		Projection:    projection,
+		Meridian:      meridian,
+		Rect:          geofence.rect,
+		Tiles:         geofence.tiles,
+		Granularity:   geofence.granularityX,
+		GranularityY:  geofence.granularityY,
+		Refinement:    geofence.refinement,
+		AutoGrid:      geofence.autoGrid,
+		TileMeters:    geofence.tileMeters,
+		LocalProj:     geofence.localProj,
+		AltitudeRange: geofence.altitudeRange,
+		MinAltitude:   geofence.minAltitude,
+		MaxAltitude:   geofence.maxAltitude,
+		MinX:          geofence.minX,
+		MaxX:          geofence.maxX,
+		MinY:          geofence.minY,
+		MaxY:          geofence.maxY,
+		TileWidth:     geofence.tileWidth,
+		TileHeight:    geofence.tileHeight,
+		MinTileX:      geofence.minTileX,
+		MaxTileX:      geofence.maxTileX,
+		MinTileY:      geofence.minTileY,
+		MaxTileY:      geofence.maxTileY,
+	}
+	for _, part := range geofence.parts {
+		partSnapshot, err := part.snapshot()
+		if err != nil {
+			return geofenceSnapshot{}, err
+		}
+		snapshot.Parts = append(snapshot.Parts, partSnapshot)
+	}
+	return snapshot, nil
+}
+
+func (snapshot *geofenceSnapshot) restore() *Geofence {
+	if len(snapshot.Parts) > 0 {
+		parts := make([]*Geofence, len(snapshot.Parts))
+		for i := range snapshot.Parts {
+			parts[i] = snapshot.Parts[i].restore()
+		}
+		return newMultiGeofence(parts)
+	}
+
+	geofence := &Geofence{
+		vertices:      snapshot.Vertices,
+		holes:         snapshot.Holes,
+		geodesic:      snapshot.Geodesic,
+		wrapLng:       snapshot.WrapLng,
+		boundary:      snapshot.Boundary,
+		containment:   snapshot.Containment,
+		exact:         snapshot.Exact,
+		planar:        snapshot.Planar,
+		projection:    restoreProjection(snapshot.Projection, snapshot.Meridian),
+		rect:          snapshot.Rect,
+		polygon:       NewPolygonWithHoles(snapshot.Vertices, snapshot.Holes),
+		tiles:         snapshot.Tiles,
+		granularityX:  snapshot.Granularity,
+		granularityY:  snapshot.GranularityY,
+		refinement:    snapshot.Refinement,
+		autoGrid:      snapshot.AutoGrid,
+		tileMeters:    snapshot.TileMeters,
+		localProj:     snapshot.LocalProj,
+		altitudeRange: snapshot.AltitudeRange,
+		minAltitude:   snapshot.MinAltitude,
+		maxAltitude:   snapshot.MaxAltitude,
+		minX:          snapshot.MinX,
+		maxX:          snapshot.MaxX,
+		minY:          snapshot.MinY,
+		maxY:          snapshot.MaxY,
+		tileWidth:     snapshot.TileWidth,
+		tileHeight:    snapshot.TileHeight,
+		minTileX:      snapshot.MinTileX,
+		maxTileX:      snapshot.MaxTileX,
+		minTileY:      snapshot.MinTileY,
+		maxTileY:      snapshot.MaxTileY,
+	}
+	if geofence.tiles == nil {
+		geofence.tiles = make(map[int64]byte)
+	}
+	geofence.bucketEdges()
+	return geofence
+}
@@ -0,0 +1,79 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofenceMarshalBinary(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(5, 14), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithGranularity(40))
+
+	data, err := geofence.MarshalBinary()
+	assert.NoError(t, err)
+
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, geofence.tiles, decoded.tiles)
+	assert.Equal(t, geofence.granularityX, decoded.granularityX)
+	assert.Equal(t, geofence.granularityY, decoded.granularityY)
+
+	for lat := -1.0; lat <= 11; lat += 0.1 {
+		for lng := -1.0; lng <= 15; lng += 0.1 {
+			point := NewPoint(lat, lng)
+			assert.Equal(t, geofence.Inside(point), decoded.Inside(point))
+		}
+	}
+}
+
+func TestGeofenceUnmarshalBinaryError(t *testing.T) {
+	assert.Error(t, (&Geofence{}).UnmarshalBinary([]byte("garbage")))
+}
+
+func TestGeofenceMarshalBinaryOptions(t *testing.T) {
+	outer := []*Point{NewPoint(51.5, -0.1), NewPoint(51.5, -0.09), NewPoint(51.51, -0.09), NewPoint(51.51, -0.1)}
+	for _, opts := range [][]Option{
+		{WithAutoGranularity()},
+		{WithTileSizeMeters(100), WithGeodesic()},
+		{WithLocalProjection(), WithBoundary(Exclusive)},
+		{WithAltitudeRange(-5, 120)},
+	} {
+		geofence := NewGeofence(outer, opts...)
+		data, err := geofence.MarshalBinary()
+		assert.NoError(t, err)
+		decoded := &Geofence{}
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, geofence.autoGrid, decoded.autoGrid)
+		assert.Equal(t, geofence.tileMeters, decoded.tileMeters)
+		assert.Equal(t, geofence.localProj, decoded.localProj)
+		assert.Equal(t, []interface{}{geofence.altitudeRange, geofence.minAltitude, geofence.maxAltitude},
+			[]interface{}{decoded.altitudeRange, decoded.minAltitude, decoded.maxAltitude})
+		assert.Equal(t, geofence.tiles, decoded.tiles)
+		// Geofences built from the decoded one keep its options
+		assert.Equal(t, geofence.Simplify(50).tiles, decoded.Simplify(50).tiles)
+	}
+}
+
+func TestGeofenceUnmarshalBinaryVersion(t *testing.T) {
+	geofence := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10)})
+	snapshot, err := geofence.snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, geofenceSnapshotVersion, snapshot.Version)
+
+	// Encodings from before versioning are read, later ones are refused
+	for version, ok := range map[int]bool{0: true, 1: true, geofenceSnapshotVersion: true, geofenceSnapshotVersion + 1: false} {
+		snapshot.Version = version
+		var buf bytes.Buffer
+		assert.NoError(t, gob.NewEncoder(&buf).Encode(snapshot))
+		err := (&Geofence{}).UnmarshalBinary(buf.Bytes())
+		if ok {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, "unable to decode geofence: unsupported encoding version 3")
+		}
+	}
+}
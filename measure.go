@@ -0,0 +1,105 @@
+package geofence
+
+import (
+	"math"
+)
+
+// maxPerimeterStep is the longest piece, in degrees, that the edges of a lat/lng geofence are
+// cut into when measuring them, as their length depends on the latitude along the way.
+const maxPerimeterStep = 0.1
+
+// Area returns the area of the geofence, less its holes. Geographic geofences return square
+// meters on the Earth's surface, with great circle edges for geodesic geofences and edges that
+// are straight in lat/lng otherwise. WithPlanar geofences return square grid units and
+// WithProjection geofences square meters on the plane. The areas of a NewMultiGeofence's
+// polygons are summed.
+func (geofence *Geofence) Area() float64 {
+	area := 0.0
+	for _, part := range geofence.polygons() {
+		if part.empty() {
+			continue
+		}
+		area += part.ringArea(part.vertices)
+		for _, hole := range part.holes {
+			area -= part.ringArea(hole)
+		}
+	}
+	return area
+}
+
+// ringArea returns the unsigned area of a ring of the single polygon geofence.
+func (geofence *Geofence) ringArea(ring []*Point) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+	if geofence.planar || geofence.projection != nil {
+		return math.Abs(ringArea(ring))
+	}
+
+	// Green's theorem: the area between each edge and the equator, which cancels out for
+	// rings that do not go round a pole
+	radians := math.Pi / 180.0
+	sum := 0.0
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		lat1, lat2 := a.Lat()*radians, b.Lat()*radians
+		dLng := math.Remainder(b.Lng()-a.Lng(), 360) * radians
+		if geofence.geodesic {
+			// The spherical excess of the great circle edge down to the equator
+			t1, t2 := math.Tan(lat1/2), math.Tan(lat2/2)
+			sum += 2 * math.Atan(math.Tan(dLng/2)*(t1+t2)/(1+t1*t2))
+		} else if dLat := lat2 - lat1; math.Abs(dLat) > 1e-12 {
+			// The integral of sin(lat) over lng, with lat linear in lng along the edge
+			sum += dLng * (math.Cos(lat1) - math.Cos(lat2)) / dLat
+		} else {
+			sum += dLng * math.Sin(lat1)
+		}
+	}
+	if enclosedPole(ring) != 0 {
+		// The ring bounds the cap from it to the pole
+		sum = 2*math.Pi - math.Abs(sum)
+	}
+	radius := float64(EARTH_RADIUS * 1000)
+	return math.Abs(sum) * radius * radius
+}
+
+// Perimeter returns the total length of the geofence's edges, including the edges of its holes
+// and of each polygon of a NewMultiGeofence. Units and edges are as for Area: meters for
+// geographic and projected geofences, and grid units WithPlanar.
+func (geofence *Geofence) Perimeter() float64 {
+	perimeter := 0.0
+	for _, part := range geofence.polygons() {
+		if part.empty() {
+			continue
+		}
+		for _, ring := range part.rings() {
+			for i := 0; i < len(ring); i++ {
+				perimeter += part.edgeLength(ring[i], ring[(i+1)%len(ring)])
+			}
+		}
+	}
+	return perimeter
+}
+
+// edgeLength returns the length of the edge a-b of the single polygon geofence.
+func (geofence *Geofence) edgeLength(a, b *Point) float64 {
+	switch {
+	case geofence.planar || geofence.projection != nil:
+		return math.Hypot(b.X()-a.X(), b.Y()-a.Y())
+	case geofence.geodesic:
+		return a.DistanceTo(b)
+	}
+
+	// Sum the great circle distances along the edge, straight in lat/lng
+	dLat, dLng := b.Lat()-a.Lat(), b.Lng()-a.Lng()
+	steps := int(math.Ceil(math.Max(math.Abs(dLat), math.Abs(dLng)) / maxPerimeterStep))
+	length := 0.0
+	previous := a
+	for step := 1; step <= steps; step++ {
+		f := float64(step) / float64(steps)
+		next := NewPoint(a.Lat()+f*dLat, a.Lng()+f*dLng)
+		length += previous.DistanceTo(next)
+		previous = next
+	}
+	return length
+}
@@ -0,0 +1,59 @@
+package geofence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArea(t *testing.T) {
+	radius := float64(EARTH_RADIUS * 1000)
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)}
+
+	// A degree square on the equator, whose edges follow the parallels and meridians
+	expected := radius * radius * math.Pi / 180 * math.Sin(math.Pi/180)
+	assert.InDelta(t, expected, NewGeofence(square).Area(), expected*1e-9)
+	assert.InDelta(t, expected, NewBBoxGeofence(0, 0, 1, 1).Area(), expected*1e-9)
+	assert.InDelta(t, expected, NewBBoxGeofence(0, 179.5, 1, -179.5).Area(), expected*1e-9)
+	// The northern edge of the geodesic square bulges north, adding a little
+	geodesic := NewGeodesicGeofence(square).Area()
+	assert.Greater(t, geodesic, expected)
+	assert.InDelta(t, expected, geodesic, expected*1e-3)
+
+	// A circle of 1km
+	circle := NewGeodesicCircleGeofence(NewPoint(51.5, -0.1), 1000, 360)
+	assert.InDelta(t, math.Pi*1e6, circle.Area(), math.Pi*1e6*1e-3)
+	assert.InDelta(t, 2*math.Pi*1000, circle.Perimeter(), 1)
+
+	// The cap north of 80°N
+	parallel := make([]*Point, 36)
+	for i := range parallel {
+		parallel[i] = NewPoint(80, float64(i*10-180))
+	}
+	cap := 2 * math.Pi * radius * radius * (1 - math.Sin(80*math.Pi/180))
+	assert.InDelta(t, cap, NewGeofence(parallel).Area(), cap*1e-9)
+
+	// Holes are taken away and polygons added up
+	planar := NewGeofenceWithHoles([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 10), NewPointXY(0, 10)},
+		[][]*Point{{NewPointXY(2, 2), NewPointXY(4, 2), NewPointXY(4, 4), NewPointXY(2, 4)}}, WithPlanar())
+	assert.InDelta(t, 96, planar.Area(), 1e-9)
+	multi := NewMultiGeofence([][]*Point{square, {NewPoint(0, 10), NewPoint(0, 11), NewPoint(1, 11), NewPoint(1, 10)}})
+	assert.InDelta(t, 2*expected, multi.Area(), expected*1e-9)
+	assert.Equal(t, 0.0, NewGeofence(nil).Area())
+}
+
+func TestPerimeter(t *testing.T) {
+	// A degree of a meridian or of the equator is about 111.2km
+	degree := math.Pi / 180 * float64(EARTH_RADIUS*1000)
+	square := []*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1), NewPoint(1, 0)}
+	perimeter := NewGeofence(square).Perimeter()
+	assert.InDelta(t, 3*degree+degree*math.Cos(math.Pi/180), perimeter, 0.01)
+	assert.InDelta(t, 3*degree+NewPoint(1, 0).DistanceTo(NewPoint(1, 1)), NewGeodesicGeofence(square).Perimeter(), 0.01)
+	assert.InDelta(t, perimeter, NewBBoxGeofence(0, 179.5, 1, -179.5).Perimeter(), 0.01)
+
+	planar := NewGeofenceWithHoles([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 10), NewPointXY(0, 10)},
+		[][]*Point{{NewPointXY(2, 2), NewPointXY(4, 2), NewPointXY(4, 4), NewPointXY(2, 4)}}, WithPlanar())
+	assert.InDelta(t, 48, planar.Perimeter(), 1e-9)
+	assert.Equal(t, 0.0, NewGeofence(nil).Perimeter())
+}
@@ -0,0 +1,74 @@
+package geofence
+
+import "sync"
+
+// MovingGeofence is a Geofence that moves with an anchor, e.g. a 300m exclusion zone around a
+// vessel or a convoy. The geofence is drawn once around a reference point, and Inside moves the
+// query point from the current anchor to the reference instead of moving the geofence, so
+// updating the anchor never rebuilds its tiles. A MovingGeofence is safe for concurrent use.
+type MovingGeofence struct {
+	mu        sync.RWMutex
+	geofence  *Geofence
+	reference *Point
+	anchor    *Point
+	// planar is whether any polygon of the geofence is WithPlanar, since a NewMultiGeofence
+	// keeps that on its parts
+	planar bool
+}
+
+// NewMovingGeofence returns the geofence, drawn around the reference point, anchored at the
+// reference until SetAnchor moves it, e.g.
+// NewMovingGeofence(NewGeodesicCircleGeofence(vessel, 300, 0), vessel).
+func NewMovingGeofence(geofence *Geofence, reference *Point) *MovingGeofence {
+	planar := false
+	for _, part := range geofence.polygons() {
+		planar = planar || part.planar
+	}
+	return &MovingGeofence{geofence: geofence, reference: reference, anchor: reference, planar: planar}
+}
+
+// SetAnchor moves the geofence so that its reference point is at the anchor.
+func (moving *MovingGeofence) SetAnchor(anchor *Point) {
+	moving.mu.Lock()
+	defer moving.mu.Unlock()
+	moving.anchor = anchor
+}
+
+// Anchor returns where the geofence's reference point currently is.
+func (moving *MovingGeofence) Anchor() *Point {
+	moving.mu.RLock()
+	defer moving.mu.RUnlock()
+	return moving.anchor
+}
+
+// Geofence returns the geofence as drawn around the reference point.
+func (moving *MovingGeofence) Geofence() *Geofence {
+	return moving.geofence
+}
+
+// Inside reports whether the point is inside the geofence at its current anchor. The point keeps
+// its distance and bearing from the anchor when it is moved to the reference, so the geofence
+// keeps its size and shape on the ground wherever the anchor goes, though over long distances
+// its orientation follows the great circle from the reference. A WithPlanar geofence is simply
+// shifted by the anchor's offset from the reference.
+func (moving *MovingGeofence) Inside(point *Point) bool {
+	return moving.geofence.Inside(moving.toReference(point))
+}
+
+// toReference returns the point moved by the reference's offset from the current anchor.
+func (moving *MovingGeofence) toReference(point *Point) *Point {
+	anchor := moving.Anchor()
+	var moved *Point
+	switch {
+	case anchor == moving.reference:
+		return point
+	case moving.planar:
+		moved = NewPointXY(point.X()-anchor.X()+moving.reference.X(), point.Y()-anchor.Y()+moving.reference.Y())
+	default:
+		moved = moving.reference.PointAtDistanceAndBearing(anchor.GreatCircleDistance(point), anchor.BearingTo(point))
+	}
+	if altitude, ok := point.Altitude(); ok {
+		moved = NewPointWithAltitude(moved.Lat(), moved.Lng(), altitude)
+	}
+	return moved
+}
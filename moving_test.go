@@ -0,0 +1,53 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingGeofence(t *testing.T) {
+	vessel := NewPoint(50, -1)
+	zone := NewGeodesicCircleGeofence(vessel, 300, 0)
+	moving := NewMovingGeofence(zone, vessel)
+	assert.Equal(t, zone, moving.Geofence())
+	assert.Equal(t, vessel, moving.Anchor())
+
+	near := vessel.PointAtDistanceAndBearing(0.2, 45)
+	assert.True(t, moving.Inside(near))
+	assert.True(t, moving.Inside(vessel))
+
+	// The vessel sails 10km north and then far south, and the zone keeps its size on the ground
+	for _, anchor := range []*Point{vessel.PointAtDistanceAndBearing(10, 0), NewPoint(-30, 40)} {
+		moving.SetAnchor(anchor)
+		assert.Equal(t, anchor, moving.Anchor())
+		assert.False(t, moving.Inside(near))
+		assert.True(t, moving.Inside(anchor))
+		for _, bearing := range []float64{0, 90, 200, 300} {
+			assert.True(t, moving.Inside(anchor.PointAtDistanceAndBearing(0.28, bearing)), "%v at %v", anchor, bearing)
+			assert.False(t, moving.Inside(anchor.PointAtDistanceAndBearing(0.32, bearing)), "%v at %v", anchor, bearing)
+		}
+	}
+
+	// Altitudes move with the point
+	ceiling := NewGeodesicCircleGeofence(vessel, 300, 0, WithAltitudeRange(0, 100))
+	drone := NewMovingGeofence(ceiling, vessel)
+	drone.SetAnchor(NewPoint(51, -1))
+	assert.True(t, drone.Inside(NewPointWithAltitude(51, -1, 50)))
+	assert.False(t, drone.Inside(NewPointWithAltitude(51, -1, 150)))
+
+	planar := NewMovingGeofence(NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(0, 10), NewPointXY(10, 10), NewPointXY(10, 0)}, WithPlanar()), NewPointXY(5, 5))
+	planar.SetAnchor(NewPointXY(105, 5))
+	assert.True(t, planar.Inside(NewPointXY(101, 9)))
+	assert.False(t, planar.Inside(NewPointXY(5, 5)))
+
+	// The polygons of a multi-polygon geofence are planar, not the geofence itself
+	grid := func(min float64) []*Point {
+		return []*Point{NewPointXY(min, min), NewPointXY(min, min+1), NewPointXY(min+1, min+1), NewPointXY(min+1, min)}
+	}
+	multi := NewMovingGeofence(NewMultiGeofence([][]*Point{grid(0), grid(10)}, WithPlanar()), NewPointXY(0, 0))
+	multi.SetAnchor(NewPointXY(100, 100))
+	assert.True(t, multi.Inside(NewPointXY(110.5, 110.5)))
+	assert.True(t, multi.Inside(NewPointXY(100.5, 100.5)))
+	assert.False(t, multi.Inside(NewPointXY(10.5, 10.5)))
+}
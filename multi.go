@@ -0,0 +1,138 @@
+package geofence
+
+// NewMultiGeofence is the construct for a Geofence covering several disjoint polygons,
+// polygons: {{(1,2),(2,3),(1,0)}, {(5,5),(5,6),(6,6)}}. A point is inside the geofence
+// when it is inside any of the polygons. Options apply to every polygon.
+func NewMultiGeofence(polygons [][]*Point, opts ...Option) *Geofence {
+	parts := make([]*Geofence, len(polygons))
+	for i, polygon := range polygons {
+		parts[i] = NewGeofence(polygon, opts...)
+	}
+	return newMultiGeofence(parts)
+}
+
+// newMultiGeofence combines the single polygon geofences into one, with their union as bounds.
+func newMultiGeofence(parts []*Geofence) *Geofence {
+	geofence := &Geofence{parts: parts, tiles: make(map[int64]byte)}
+	first, projected := true, false
+	for _, part := range parts {
+		if part.empty() {
+			continue
+		}
+		if first {
+			geofence.minX, geofence.maxX, geofence.minY, geofence.maxY = part.minX, part.maxX, part.minY, part.maxY
+			first = false
+		}
+		if part.minX < geofence.minX {
+			geofence.minX = part.minX
+		}
+		if part.maxX > geofence.maxX {
+			geofence.maxX = part.maxX
+		}
+		if part.minY < geofence.minY {
+			geofence.minY = part.minY
+		}
+		if part.maxY > geofence.maxY {
+			geofence.maxY = part.maxY
+		}
+		geofence.geodesic = geofence.geodesic || part.geodesic
+		geofence.wrapLng = geofence.wrapLng || part.wrapLng
+		projected = projected || part.projection != nil
+	}
+	// Parts crossing the antimeridian have bounds in a shifted longitude range, and projected
+	// parts in their own plane, so neither are indexed
+	if !geofence.wrapLng && !projected {
+		geofence.indexParts()
+	}
+	return geofence
+}
+
+// indexParts lays a tile grid over the union of the parts' bounding boxes and lists, for
+// each tile, the parts whose bounding box overlaps it.
+func (geofence *Geofence) indexParts() {
+	geofence.granularityX, geofence.granularityY = defaultGranularity, defaultGranularity
+	geofence.tileWidth = (geofence.maxX - geofence.minX) / float64(geofence.granularityX)
+	geofence.tileHeight = (geofence.maxY - geofence.minY) / float64(geofence.granularityY)
+	if geofence.tileWidth == 0 || geofence.tileHeight == 0 {
+		return
+	}
+	geofence.minTileX = project(geofence.minX, geofence.tileWidth)
+	geofence.minTileY = project(geofence.minY, geofence.tileHeight)
+	geofence.maxTileX = project(geofence.maxX, geofence.tileWidth)
+	geofence.maxTileY = project(geofence.maxY, geofence.tileHeight)
+
+	geofence.partTiles = make(map[int64][]int)
+	for i, part := range geofence.parts {
+		if part.empty() {
+			continue
+		}
+		for tileX := project(part.minX, geofence.tileWidth); tileX <= project(part.maxX, geofence.tileWidth); tileX++ {
+			for tileY := project(part.minY, geofence.tileHeight); tileY <= project(part.maxY, geofence.tileHeight); tileY++ {
+				tileHash := geofence.tileHash(tileX, tileY)
+				geofence.partTiles[tileHash] = append(geofence.partTiles[tileHash], i)
+			}
+		}
+	}
+}
+
+// insideParts checks the point against each part whose bounding box contains it, using
+// the part index to skip parts far from the point.
+func (geofence *Geofence) insideParts(point *Point) bool {
+	if geofence.partTiles != nil {
+		if !geofence.insideBounds(point) {
+			return false
+		}
+		tileHash := geofence.tileHash(project(point.Lat(), geofence.tileWidth), project(point.Lng(), geofence.tileHeight))
+		for _, i := range geofence.partTiles[tileHash] {
+			if part := geofence.parts[i]; part.insideBounds(point) && part.Inside(point) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, part := range geofence.parts {
+		if part.insideBounds(part.wrapPoint(point)) && part.Inside(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// insideBounds returns whether the point, already wrapped, is inside the bounding box.
+func (geofence *Geofence) insideBounds(point *Point) bool {
+	return point.Lat() >= geofence.minX && point.Lat() <= geofence.maxX && point.Lng() >= geofence.minY && point.Lng() <= geofence.maxY
+}
+
+// empty returns whether the geofence has no vertices at all.
+func (geofence *Geofence) empty() bool {
+	if len(geofence.parts) == 0 {
+		return len(geofence.vertices) == 0
+	}
+	for _, part := range geofence.parts {
+		if !part.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// polygons returns the single polygon geofences making up the geofence, which is just
+// the geofence itself unless it was built by NewMultiGeofence.
+func (geofence *Geofence) polygons() []*Geofence {
+	if len(geofence.parts) == 0 {
+		return []*Geofence{geofence}
+	}
+	return geofence.parts
+}
+
+// template returns the first polygon of the geofence with vertices, whose options a geofence
+// built from it takes, or the geofence itself when they are all empty.
+func (geofence *Geofence) template() *Geofence {
+	for _, part := range geofence.polygons() {
+		if !part.empty() {
+			return part
+		}
+	}
+	return geofence
+}
@@ -0,0 +1,75 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiGeofence(t *testing.T) {
+	regionA := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	regionB := []*Point{NewPoint(20, 20), NewPoint(20, 30), NewPoint(25, 35), NewPoint(30, 20)}
+	geofence := NewMultiGeofence([][]*Point{regionA, regionB})
+
+	inB := NewPoint(25, 25)
+	assert.False(t, NewGeofence(regionA).Inside(inB))
+	assert.True(t, geofence.Inside(inB))
+	assert.True(t, geofence.Inside(NewPoint(5, 5)))
+	assert.False(t, geofence.Inside(NewPoint(15, 15)))
+	assert.False(t, geofence.Inside(NewPoint(-1, 5)))
+
+	min, max := geofence.Bounds()
+	assert.Equal(t, NewPoint(0, 0), min)
+	assert.Equal(t, NewPoint(30, 35), max)
+	assert.InDelta(t, 5, geofence.DistanceToBoundary(NewPoint(15, 5)), 1e-9)
+	assert.InDelta(t, -2, geofence.DistanceToBoundary(NewPoint(22, 25)), 1e-9)
+
+	data, err := geofence.ToGeoJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[10,0],[10,10],[0,10],[0,0]]],
+		[[[20,20],[30,20],[35,25],[20,30],[20,20]]]]}`, string(data))
+
+	data, err = geofence.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.Inside(inB))
+	assert.False(t, decoded.Inside(NewPoint(15, 15)))
+
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{geofence}, nil)
+	assert.Equal(t, map[int]bool{1: true}, group.GetValidKeys(inB))
+	assert.Empty(t, group.GetValidKeys(NewPoint(15, 15)))
+}
+
+func TestMultiGeofenceIndex(t *testing.T) {
+	// An archipelago of 400 small islands
+	var islands [][]*Point
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 20; j++ {
+			lat, lng := float64(i)*5, float64(j)*5
+			islands = append(islands, []*Point{NewPoint(lat, lng), NewPoint(lat, lng+2), NewPoint(lat+3, lng+2.5), NewPoint(lat+2, lng)})
+		}
+	}
+	geofence := NewMultiGeofence(islands)
+	assert.NotNil(t, geofence.partTiles)
+	for _, parts := range geofence.partTiles {
+		assert.LessOrEqual(t, len(parts), 4)
+	}
+
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(-5, 105, -5, 105, 1)
+		expected := false
+		for _, part := range geofence.parts {
+			expected = expected || part.Inside(point)
+		}
+		assert.Equal(t, expected, geofence.Inside(point))
+	}
+
+	// Antimeridian parts are checked in turn without the index
+	wrapped := NewMultiGeofence([][]*Point{islands[0], {NewPoint(0, 170), NewPoint(0, -170), NewPoint(10, -170), NewPoint(10, 170)}})
+	assert.Nil(t, wrapped.partTiles)
+	assert.True(t, wrapped.Inside(NewPoint(5, 179)))
+	assert.True(t, wrapped.Inside(NewPoint(1, 1)))
+}
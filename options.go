@@ -0,0 +1,254 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+)
+
+// Option configures a Geofence during construction, e.g. NewGeofence(points, WithGranularity(40)).
+type Option func(geofence *Geofence) error
+
+// WithGranularity sets the number of tiles along each axis of the tile grid, 20 by default.
+// Higher granularities cost more memory and construction time but answer more points
+// without the exact point in polygon check.
+func WithGranularity(granularity int) Option {
+	return WithGranularityXY(granularity, granularity)
+}
+
+// WithGranularityXY sets the number of tiles along each axis separately, x dividing the
+// latitude range and y the longitude range, matching Point's Lat as X and Lng as Y. A long
+// thin geofence, such as a river, is better served by about square tiles than by a square grid.
+func WithGranularityXY(x, y int) Option {
+	return func(geofence *Geofence) error {
+		for _, granularity := range []int{x, y} {
+			if granularity <= 0 {
+				return fmt.Errorf("granularity must be positive, got %d", granularity)
+			}
+		}
+		geofence.granularityX, geofence.granularityY = int64(x), int64(y)
+		geofence.autoGrid = false
+		geofence.tileMeters = 0
+		return nil
+	}
+}
+
+// WithAutoGranularity picks the granularities from the geofence's shape, aiming for about a quarter
+// of the tiles to be crossed by an edge: few tiles for a rectangle and many for a long, winding
+// boundary. The tiles are about square in degrees, so a long thin geofence is divided along its
+// length. The choice is capped so that building the tiles of a geofence with many vertices
+// stays quick. It replaces WithGranularity and WithTileSizeMeters, whichever comes last wins.
+func WithAutoGranularity() Option {
+	return func(geofence *Geofence) error {
+		geofence.autoGrid = true
+		geofence.tileMeters = 0
+		return nil
+	}
+}
+
+// WithTileSizeMeters sizes the tiles at about the given number of meters on each side, instead
+// of dividing the bounding box into a fixed number of tiles. Degrees are converted to meters at
+// the middle latitude of the geofence, so the tiles are roughly square on the ground rather than
+// in degrees. At most 1024 tiles are used along each side of the bounding box, larger tiles are
+// used beyond that. It replaces WithGranularity and WithAutoGranularity, whichever comes last wins.
+func WithTileSizeMeters(meters float64) Option {
+	return func(geofence *Geofence) error {
+		if !(meters > 0) || math.IsInf(meters, 1) {
+			return fmt.Errorf("tile size must be a positive number of meters, got %v", meters)
+		}
+		geofence.tileMeters = meters
+		geofence.autoGrid = false
+		return nil
+	}
+}
+
+// maxRefinement is the deepest WithRefinement allows, splitting a tile into 4^12 parts.
+const maxRefinement = 12
+
+// WithRefinement splits each tile crossed by an edge into quarters, and those crossed by an
+// edge into quarters again, up to depth times. This classifies most points near the boundary
+// without the exact check, at the cost of memory for the boundary tiles only. The default is 0.
+// Geodesic geofences are not refined.
+func WithRefinement(depth int) Option {
+	return func(geofence *Geofence) error {
+		if depth < 0 || depth > maxRefinement {
+			return fmt.Errorf("refinement depth must be between 0 and %d, got %d", maxRefinement, depth)
+		}
+		geofence.refinement = depth
+		return nil
+	}
+}
+
+// WithGeodesic treats each edge as the great circle arc between its vertices, see NewGeodesicGeofence.
+// It replaces WithPlanar, whichever comes last wins.
+func WithGeodesic() Option {
+	return func(geofence *Geofence) error {
+		geofence.geodesic = true
+		geofence.planar = false
+		geofence.projection, geofence.localProj = nil, false
+		return nil
+	}
+}
+
+// WithPlanar declares the coordinates to be X and Y on a flat local grid, e.g. meters across a
+// warehouse or mine site, built with NewPointXY. Nothing geographic applies: a fence spanning
+// more than 180 units is never taken to cross the antimeridian, WithDensify and
+// WithCoordinateOrder have no effect, WithTileSizeMeters takes the tile size in coordinate
+// units, and distances are euclidean. It replaces WithGeodesic and WithProjection, whichever
+// comes last wins.
+func WithPlanar() Option {
+	return func(geofence *Geofence) error {
+		geofence.planar = true
+		geofence.geodesic = false
+		geofence.projection, geofence.localProj = nil, false
+		return nil
+	}
+}
+
+// WithProjection projects the vertices and every query point onto a plane before tiling and
+// testing them, so the fence's straight edges are straight on that plane rather than in lat/lng.
+// Far from the equator a degree of lng is much shorter than a degree of lat, which a projection
+// such as TransverseMercator evens out. Methods still take and return lat/lng points, although
+// DistanceToBoundary returns meters on the plane and Bounds the corners of the projected
+// bounding box. Fences crossing the antimeridian are not supported. It replaces WithGeodesic
+// and WithPlanar, whichever comes last wins.
+func WithProjection(projection Projection) Option {
+	return func(geofence *Geofence) error {
+		if projection == nil {
+			return fmt.Errorf("projection must not be nil")
+		}
+		geofence.projection, geofence.localProj = projection, false
+		geofence.geodesic, geofence.planar = false, false
+		return nil
+	}
+}
+
+// WithLocalProjection is WithProjection(TransverseMercator{}) about the meridian through the
+// middle of the geofence's vertices.
+func WithLocalProjection() Option {
+	return func(geofence *Geofence) error {
+		geofence.projection, geofence.localProj = nil, true
+		geofence.geodesic, geofence.planar = false, false
+		return nil
+	}
+}
+
+// WithDensify adds vertices along each edge of a planar geofence so that no two consecutive
+// vertices are more than maxSegmentMeters apart, on the great circle arc between the original
+// ones. A long edge far from the equator then bends towards the pole as it does on a globe,
+// rather than following the parallel, while Inside keeps the speed of a planar geofence.
+// Vertices and Holes return the densified rings. WithGeodesic follows the arcs exactly and
+// takes precedence.
+func WithDensify(maxSegmentMeters float64) Option {
+	return func(geofence *Geofence) error {
+		if !(maxSegmentMeters > 0) || math.IsInf(maxSegmentMeters, 1) {
+			return fmt.Errorf("densify segment must be a positive number of meters, got %v", maxSegmentMeters)
+		}
+		geofence.densifyStep = maxSegmentMeters / (EARTH_RADIUS * 1000)
+		return nil
+	}
+}
+
+// WithSimplify simplifies the rings as the geofence is built, as Simplify does, keeping only
+// the vertices needed to stay within toleranceMeters of the given edges. Rings are simplified
+// before WithDensify adds vertices back along the arcs.
+func WithSimplify(toleranceMeters float64) Option {
+	return func(geofence *Geofence) error {
+		if !(toleranceMeters > 0) || math.IsInf(toleranceMeters, 1) {
+			return fmt.Errorf("simplify tolerance must be a positive number of meters, got %v", toleranceMeters)
+		}
+		geofence.simplifyTolerance = toleranceMeters
+		return nil
+	}
+}
+
+// WithBoundary decides whether points exactly on an edge or vertex are inside, Inclusive
+// by default. Tiles touching an edge always run the exact check, so the setting also
+// applies to points that fall on the corner of a tile. Two geofences sharing an edge,
+// one Inclusive and one Exclusive, never both contain a point on that edge.
+func WithBoundary(boundary Boundary) Option {
+	return func(geofence *Geofence) error {
+		if boundary != Inclusive && boundary != Exclusive {
+			return fmt.Errorf("unknown boundary %d", boundary)
+		}
+		geofence.boundary = boundary
+		return nil
+	}
+}
+
+// WithContainment sets the rule for rings that cross or overlap themselves, RayCasting by
+// default. Geodesic geofences sum the angles their edges subtend, which already counts an
+// overlap as inside, as WindingNumber does.
+func WithContainment(containment Containment) Option {
+	return func(geofence *Geofence) error {
+		if containment != RayCasting && containment != WindingNumber {
+			return fmt.Errorf("unknown containment %d", containment)
+		}
+		geofence.containment = containment
+		return nil
+	}
+}
+
+// WithExactPredicates decides which side of an edge a point is on in exact arithmetic, rather
+// than trusting float64 near vertices and nearly collinear edges, for fences where accuracy
+// matters more than speed. Clear cases are still decided in float64, but points in a tile
+// crossed by an edge are checked against every edge, and the tiles next to those are checked
+// too in case rounding misplaced an edge. Geodesic geofences are not affected.
+func WithExactPredicates() Option {
+	return func(geofence *Geofence) error {
+		geofence.exact = true
+		return nil
+	}
+}
+
+// WithBoundaryInclusive is WithBoundary(Inclusive) when inclusive and WithBoundary(Exclusive) otherwise.
+func WithBoundaryInclusive(inclusive bool) Option {
+	if inclusive {
+		return WithBoundary(Inclusive)
+	}
+	return WithBoundary(Exclusive)
+}
+
+// WithHoles adds holes to the geofence, as for NewGeofenceWithHoles. With NewMultiGeofence
+// every polygon gets the holes.
+func WithHoles(holes ...[]*Point) Option {
+	return func(geofence *Geofence) error {
+		geofence.holes = append(geofence.holes, holes...)
+		return nil
+	}
+}
+
+// WithCopyPoints copies the points and slices given to the constructor, so changing them once
+// the geofence is built, e.g. reusing a slice for the next fence, cannot corrupt it. Without
+// it the geofence keeps the caller's slices, see Clone.
+func WithCopyPoints() Option {
+	return func(geofence *Geofence) error {
+		geofence.copyPoints = true
+		return nil
+	}
+}
+
+// WithAltitudeRange limits the geofence to altitudes from minMeters to maxMeters, in whatever
+// datum the points' altitudes use, e.g. a drone no-fly zone up to 120m or one floor of a site.
+// Inside rejects a point built by NewPointWithAltitude above or below the range, and checks a
+// point without an altitude against the outline alone. The bounds follow WithBoundary.
+func WithAltitudeRange(minMeters, maxMeters float64) Option {
+	return func(geofence *Geofence) error {
+		if math.IsNaN(minMeters) || math.IsNaN(maxMeters) || minMeters > maxMeters {
+			return fmt.Errorf("altitude range must run from a minimum to a maximum, got %v to %v", minMeters, maxMeters)
+		}
+		geofence.altitudeRange, geofence.minAltitude, geofence.maxAltitude = true, minMeters, maxMeters
+		return nil
+	}
+}
+
+// applyOptions applies every option in turn. An option that fails leaves the geofence
+// unchanged, and the first failure is returned once all options have been applied.
+func (geofence *Geofence) applyOptions(opts []Option) error {
+	var firstErr error
+	for _, opt := range opts {
+		if err := opt(geofence); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,78 @@
+package geofence
+
+import (
+	"fmt"
+)
+
+// CoordinateOrder declares the order of the two numbers in the vertices given to a geofence.
+// Geofences use Lat as X and Lng as Y throughout, so vertices given the other way round build a
+// fence mirrored across the diagonal that still answers Inside, just wrongly.
+type CoordinateOrder int
+
+const (
+	// LatLng vertices were built as NewPoint(lat, lng), the order NewPoint documents.
+	LatLng CoordinateOrder = iota + 1
+	// LngLat vertices were built as NewPoint(lng, lat), e.g. straight from [x, y] data.
+	LngLat
+)
+
+// WithCoordinateOrder declares the geofence's vertices to be geographic coordinates in the
+// given order. LngLat vertices and holes are swapped into lat, lng as the geofence is built,
+// query points are always NewPoint(lat, lng), see NewPointLngLat. Either way a vertex with
+// a lat outside ±90° or a lng outside ±180° is an error for NewGeofenceChecked and the other
+// constructors returning errors.
+func WithCoordinateOrder(order CoordinateOrder) Option {
+	return func(geofence *Geofence) error {
+		if order != LatLng && order != LngLat {
+			return fmt.Errorf("unknown coordinate order %d", order)
+		}
+		geofence.order = order
+		return nil
+	}
+}
+
+// NewPointLngLat returns a new Point for the passed in longitude (lng) and latitude (lat), the
+// order of GeoJSON positions and of x, y.
+func NewPointLngLat(lng float64, lat float64) *Point {
+	return NewPoint(lat, lng)
+}
+
+// orderRings returns the rings in lat, lng order.
+func (geofence *Geofence) orderRings(outer []*Point, holes [][]*Point) ([]*Point, [][]*Point) {
+	if geofence.order != LngLat {
+		return outer, holes
+	}
+	swap := func(ring []*Point) []*Point {
+		swapped := make([]*Point, len(ring))
+		for i, point := range ring {
+			swapped[i] = NewPoint(point.Lng(), point.Lat())
+		}
+		return swapped
+	}
+	for i, hole := range holes {
+		holes[i] = swap(hole)
+	}
+	return swap(outer), holes
+}
+
+// validateGeographic returns an error wrapping ErrOutOfRange for the first vertex outside the
+// range of latitudes and longitudes.
+func validateGeographic(outer []*Point, holes [][]*Point) error {
+	inRange := func(ring []*Point) error {
+		for i, point := range ring {
+			if point.Lat() < -90 || point.Lat() > 90 || point.Lng() < -180 || point.Lng() > 180 {
+				return fmt.Errorf("vertex %d (%v, %v): %w", i, point.Lat(), point.Lng(), ErrOutOfRange)
+			}
+		}
+		return nil
+	}
+	if err := inRange(outer); err != nil {
+		return err
+	}
+	for i, hole := range holes {
+		if err := inRange(hole); err != nil {
+			return fmt.Errorf("hole %d: %w", i, err)
+		}
+	}
+	return nil
+}
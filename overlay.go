@@ -0,0 +1,429 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// An overlay finds the boundary of a region of the plane given as a predicate, when the boundary
+// is known to be made of pieces of some set of segments, e.g. the edges of two geofences for
+// their union. The segments are split where they cross, and each piece between the regions
+// either side of it, as told by testing a point just off each side, is kept. The kept pieces,
+// directed with the region on their left, are then traced into rings.
+
+// planeSegment is a segment on the plane from a to b.
+type planeSegment struct {
+	ax, ay, bx, by float64
+}
+
+// overlayGraph holds the vertices of the split segments, merging those closer than tolerance.
+type overlayGraph struct {
+	xs, ys    []float64
+	tolerance float64
+	cells     map[[2]int64][]int
+}
+
+// overlayRings returns the boundary of the region where inside is true as rings on the plane,
+// counterclockwise around the region and clockwise around its holes. The region's boundary must
+// lie along the segments.
+func overlayRings(segments []planeSegment, inside func(x, y float64) bool) [][]*Point {
+	if len(segments) == 0 {
+		return nil
+	}
+	minX, maxX, minY, maxY := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	shortest, magnitude := math.Inf(1), 0.0
+	for _, segment := range segments {
+		minX, maxX = math.Min(minX, math.Min(segment.ax, segment.bx)), math.Max(maxX, math.Max(segment.ax, segment.bx))
+		minY, maxY = math.Min(minY, math.Min(segment.ay, segment.by)), math.Max(maxY, math.Max(segment.ay, segment.by))
+		if length := math.Hypot(segment.bx-segment.ax, segment.by-segment.ay); length > 0 {
+			shortest = math.Min(shortest, length)
+		}
+		magnitude = math.Max(magnitude, math.Max(math.Max(math.Abs(segment.ax), math.Abs(segment.bx)), math.Max(math.Abs(segment.ay), math.Abs(segment.by))))
+	}
+	size := math.Max(maxX-minX, maxY-minY)
+	if size == 0 {
+		return nil
+	}
+	// The tolerance is scaled to the shortest segment rather than the whole overlay, as pieces of
+	// nearly parallel segments, e.g. the rectangles of the densified edges of a large buffered
+	// geofence, bound slivers far narrower than the overlay is wide. It stays above the rounding
+	// of the coordinates, so shared vertices still merge.
+	tolerance := math.Max(shortest*1e-9, magnitude*1e-14)
+	graph := &overlayGraph{tolerance: tolerance, cells: make(map[[2]int64][]int)}
+
+	// Split the segments where they cross and join the pieces at shared vertices
+	edges := make(map[delaunayEdge]bool)
+	for i, splits := range splitSegments(segments, minX, minY, size) {
+		segment := segments[i]
+		sort.Slice(splits, func(i, j int) bool {
+			return splits[i].t < splits[j].t
+		})
+		previous := graph.vertex(segment.ax, segment.ay)
+		for _, split := range append(splits, segmentSplit{1, segment.bx, segment.by}) {
+			next := graph.vertex(split.x, split.y)
+			if next != previous {
+				edges[delaunayEdge{minInt(previous, next), maxInt(previous, next)}] = true
+			}
+			previous = next
+		}
+	}
+
+	// Keep the pieces with the region on one side only, directed with it on their left. The
+	// point off each side is nearer for shorter pieces, as a sliver between nearly parallel
+	// segments narrows towards the ends of the pieces along it.
+	var boundary []delaunayEdge
+	for edge := range edges {
+		ax, ay, bx, by := graph.xs[edge.a], graph.ys[edge.a], graph.xs[edge.b], graph.ys[edge.b]
+		length := math.Hypot(bx-ax, by-ay)
+		offset := math.Max(length*1e-6, tolerance)
+		midX, midY := (ax+bx)/2, (ay+by)/2
+		normalX, normalY := -(by-ay)/length*offset, (bx-ax)/length*offset
+		left, right := inside(midX+normalX, midY+normalY), inside(midX-normalX, midY-normalY)
+		switch {
+		case left && !right:
+			boundary = append(boundary, edge)
+		case right && !left:
+			boundary = append(boundary, delaunayEdge{edge.b, edge.a})
+		}
+	}
+	// Trace in a fixed order, so the same inputs give the same rings
+	sort.Slice(boundary, func(i, j int) bool {
+		if boundary[i].a != boundary[j].a {
+			return boundary[i].a < boundary[j].a
+		}
+		return boundary[i].b < boundary[j].b
+	})
+
+	var rings [][]*Point
+	for _, ring := range traceRings(graph.xs, graph.ys, boundary) {
+		if ring = removeCollinear(ring, graph.xs, graph.ys, graph.tolerance); len(ring) < 3 {
+			continue
+		}
+		points := make([]*Point, len(ring))
+		for i, index := range ring {
+			points[i] = NewPointXY(graph.xs[index], graph.ys[index])
+		}
+		rings = append(rings, points)
+	}
+	return rings
+}
+
+// segmentSplit is a point where a segment is split, at the fraction t along it.
+type segmentSplit struct {
+	t, x, y float64
+}
+
+// splitSegments returns, for each segment, the points along it where other segments cross or
+// touch it. Segments are put in the cells of a grid over their bounding boxes, so only segments
+// sharing a cell are tested against each other.
+func splitSegments(segments []planeSegment, minX, minY, size float64) [][]segmentSplit {
+	cellsPerSide := math.Max(1, math.Min(1024, math.Ceil(math.Sqrt(float64(len(segments))))))
+	cellSize := size / cellsPerSide * (1 + 1e-9)
+	cellOf := func(x, y float64) (int64, int64) {
+		return int64((x - minX) / cellSize), int64((y - minY) / cellSize)
+	}
+	grid := make(map[[2]int64][]int)
+	for i, segment := range segments {
+		minCellX, minCellY := cellOf(math.Min(segment.ax, segment.bx), math.Min(segment.ay, segment.by))
+		maxCellX, maxCellY := cellOf(math.Max(segment.ax, segment.bx), math.Max(segment.ay, segment.by))
+		for cellX := minCellX; cellX <= maxCellX; cellX++ {
+			for cellY := minCellY; cellY <= maxCellY; cellY++ {
+				grid[[2]int64{cellX, cellY}] = append(grid[[2]int64{cellX, cellY}], i)
+			}
+		}
+	}
+
+	splits := make([][]segmentSplit, len(segments))
+	for cell, indices := range grid {
+		for m, i := range indices {
+			for _, j := range indices[m+1:] {
+				s, o := segments[i], segments[j]
+				// Test each pair once, in the cell holding the corner of their boxes' overlap
+				cellX, cellY := cellOf(math.Max(math.Min(s.ax, s.bx), math.Min(o.ax, o.bx)), math.Max(math.Min(s.ay, s.by), math.Min(o.ay, o.by)))
+				if cellX != cell[0] || cellY != cell[1] {
+					continue
+				}
+				t, u := segmentCrossings(s, o)
+				splits[i], splits[j] = append(splits[i], t...), append(splits[j], u...)
+			}
+		}
+	}
+	return splits
+}
+
+// segmentCrossings returns the points along s and o, strictly between their ends, where the
+// other segment crosses it or touches it, including the ends of collinear overlaps. Both
+// segments are split at the same point where they cross, and at the other's end where it
+// touches them, as for nearly parallel segments the crossing found along each can be far apart.
+func segmentCrossings(s, o planeSegment) (t, u []segmentSplit) {
+	rX, rY := s.bx-s.ax, s.by-s.ay
+	qX, qY := o.bx-o.ax, o.by-o.ay
+	dX, dY := o.ax-s.ax, o.ay-s.ay
+	denominator := rX*qY - rY*qX
+	lengths := math.Hypot(rX, rY) * math.Hypot(qX, qY)
+	if lengths == 0 {
+		return nil, nil
+	}
+	inside := func(f float64) bool { return f > 0 && f < 1 }
+
+	if math.Abs(denominator) > 1e-12*lengths {
+		a, b := (dX*qY-dY*qX)/denominator, (dX*rY-dY*rX)/denominator
+		if a < -1e-12 || a > 1+1e-12 || b < -1e-12 || b > 1+1e-12 {
+			return nil, nil
+		}
+		x, y := s.ax+a*rX, s.ay+a*rY
+		switch {
+		case inside(a) && !inside(b):
+			x, y = o.ax, o.ay
+			if b > 0.5 {
+				x, y = o.bx, o.by
+			}
+		case inside(b) && !inside(a):
+			x, y = s.ax, s.ay
+			if a > 0.5 {
+				x, y = s.bx, s.by
+			}
+		}
+		if inside(a) {
+			t = append(t, segmentSplit{a, x, y})
+		}
+		if inside(b) {
+			u = append(u, segmentSplit{b, x, y})
+		}
+		return t, u
+	}
+
+	// Parallel, so only collinear segments meet, at the ends of each inside the other
+	if math.Abs(dX*rY-dY*rX) > 1e-12*lengths {
+		return nil, nil
+	}
+	rr, qq := rX*rX+rY*rY, qX*qX+qY*qY
+	if f := (dX*rX + dY*rY) / rr; inside(f) {
+		t = append(t, segmentSplit{f, o.ax, o.ay})
+	}
+	if f := ((o.bx-s.ax)*rX + (o.by-s.ay)*rY) / rr; inside(f) {
+		t = append(t, segmentSplit{f, o.bx, o.by})
+	}
+	if f := (-dX*qX - dY*qY) / qq; inside(f) {
+		u = append(u, segmentSplit{f, s.ax, s.ay})
+	}
+	if f := ((s.bx-o.ax)*qX + (s.by-o.ay)*qY) / qq; inside(f) {
+		u = append(u, segmentSplit{f, s.bx, s.by})
+	}
+	return t, u
+}
+
+// vertex returns the index of the vertex at x, y, adding it unless one is within the tolerance.
+func (graph *overlayGraph) vertex(x, y float64) int {
+	cellX, cellY := int64(math.Floor(x/graph.tolerance/4)), int64(math.Floor(y/graph.tolerance/4))
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, index := range graph.cells[[2]int64{cellX + dx, cellY + dy}] {
+				if math.Abs(graph.xs[index]-x) <= graph.tolerance && math.Abs(graph.ys[index]-y) <= graph.tolerance {
+					return index
+				}
+			}
+		}
+	}
+	graph.xs, graph.ys = append(graph.xs, x), append(graph.ys, y)
+	graph.cells[[2]int64{cellX, cellY}] = append(graph.cells[[2]int64{cellX, cellY}], len(graph.xs)-1)
+	return len(graph.xs) - 1
+}
+
+// traceRings joins the directed edges into rings of vertex indices, each edge leading on to an
+// edge leaving its end, until the ring closes.
+func traceRings(xs, ys []float64, edges []delaunayEdge) [][]int {
+	outgoing := make(map[int][]int)
+	for _, edge := range edges {
+		outgoing[edge.a] = append(outgoing[edge.a], edge.b)
+	}
+
+	var rings [][]int
+	for _, edge := range edges {
+		if !containsInt(outgoing[edge.a], edge.b) {
+			continue
+		}
+		ring := []int{edge.a}
+		from, at := edge.a, edge.b
+		removeInt(outgoing, from, at)
+		for at != edge.a {
+			ring = append(ring, at)
+			next := nextBoundaryVertex(xs, ys, from, at, outgoing[at])
+			if next < 0 {
+				break
+			}
+			removeInt(outgoing, at, next)
+			from, at = at, next
+		}
+		if len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// removeCollinear returns the ring without the vertices on the line between their neighbors.
+func removeCollinear(ring []int, xs, ys []float64, tolerance float64) []int {
+	for changed := true; changed && len(ring) >= 3; {
+		changed = false
+		kept := ring[:0:0]
+		for i, index := range ring {
+			previous, next := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+			if len(kept) > 0 {
+				previous = kept[len(kept)-1]
+			}
+			aX, aY := xs[index]-xs[previous], ys[index]-ys[previous]
+			bX, bY := xs[next]-xs[index], ys[next]-ys[index]
+			length := math.Hypot(aX, aY) + math.Hypot(bX, bY)
+			if math.Abs(aX*bY-aY*bX) <= tolerance*length && aX*bX+aY*bY >= 0 {
+				changed = true
+				continue
+			}
+			kept = append(kept, index)
+		}
+		ring = kept
+	}
+	return ring
+}
+
+// groupRings sorts rings on the plane into outlines, counterclockwise, and holes, clockwise, and
+// returns the holes of each outline: the smallest outline around the region just off the hole.
+func groupRings(rings [][]*Point) (outlines []int, holes map[int][]int) {
+	holes = make(map[int][]int)
+	areas := make([]float64, len(rings))
+	for i, ring := range rings {
+		if areas[i] = ringArea(ring); areas[i] > 0 {
+			outlines = append(outlines, i)
+		}
+	}
+	for i, ring := range rings {
+		if areas[i] > 0 {
+			continue
+		}
+		// The outline's region is just to the left of the hole's first edge
+		a, b := ring[0], ring[1]
+		length := math.Hypot(b.X()-a.X(), b.Y()-a.Y())
+		offset := 1e-6 * length
+		probe := NewPointXY((a.X()+b.X())/2-(b.Y()-a.Y())/length*offset, (a.Y()+b.Y())/2+(b.X()-a.X())/length*offset)
+		best := -1
+		for _, outline := range outlines {
+			if (best < 0 || areas[outline] < areas[best]) && NewPolygon(rings[outline]).Contains(probe) {
+				best = outline
+			}
+		}
+		if best >= 0 {
+			holes[best] = append(holes[best], i)
+		}
+	}
+	return outlines, holes
+}
+
+// overlayPlane is the plane geofences are combined on: their own plane WithPlanar or
+// WithProjection, and otherwise a transverse Mercator plane about their middle, which keeps
+// distances true in meters near it.
+type overlayPlane struct {
+	planar     bool
+	projection Projection
+}
+
+// newOverlayPlane returns the plane for the geofence, which must not be empty.
+func newOverlayPlane(geofence *Geofence) overlayPlane {
+	template := geofence.template()
+	switch {
+	case template.planar:
+		return overlayPlane{planar: true}
+	case template.projection != nil:
+		return overlayPlane{projection: template.projection}
+	}
+	return overlayPlane{projection: TransverseMercator{CentralMeridian: geofence.Centroid().Lng()}}
+}
+
+// polygonRings returns the outer ring and holes of each polygon of the geofence on the plane.
+// Geographic edges are densified first, so the rings follow the great circle arcs of geodesic
+// geofences and the straight lat/lng edges of the others.
+func (plane overlayPlane) polygonRings(geofence *Geofence) [][][]*Point {
+	var polygons [][][]*Point
+	for _, part := range geofence.polygons() {
+		if part.empty() {
+			continue
+		}
+		var rings [][]*Point
+		for _, ring := range part.rings() {
+			if !plane.planar {
+				switch {
+				case part.projection != nil:
+					ring = part.unwrapRing(ring)
+				case part.geodesic:
+					ring = densifyGeodesic(ring, maxGeodesicStep)
+				default:
+					ring = densifyLinear(ring, maxPerimeterStep)
+				}
+				projected := make([]*Point, len(ring))
+				for i, point := range ring {
+					projected[i] = plane.projection.Project(point)
+				}
+				ring = projected
+			}
+			rings = append(rings, ring)
+		}
+		polygons = append(polygons, rings)
+	}
+	return polygons
+}
+
+// point returns the point on the plane as a geofence vertex, lat/lng unless planar.
+func (plane overlayPlane) point(point *Point) *Point {
+	if plane.planar {
+		return point
+	}
+	point = plane.projection.Unproject(point)
+	return NewPoint(point.Lat(), math.Remainder(point.Lng(), 360))
+}
+
+// geofence returns a geofence with the options of the template from rings on the plane, as
+// given by overlayRings.
+func (plane overlayPlane) geofence(rings [][]*Point, template *Geofence) *Geofence {
+	opts := template.rebuildOptions()
+	outlines, holes := groupRings(rings)
+	parts := make([]*Geofence, len(outlines))
+	for i, outline := range outlines {
+		var polygonHoles [][]*Point
+		for _, hole := range holes[outline] {
+			polygonHoles = append(polygonHoles, plane.ring(rings[hole]))
+		}
+		parts[i] = NewGeofenceWithHoles(plane.ring(rings[outline]), polygonHoles, opts...)
+	}
+	switch len(parts) {
+	case 0:
+		return NewGeofence(nil, opts...)
+	case 1:
+		return parts[0]
+	}
+	return newMultiGeofence(parts)
+}
+
+// ring returns the ring on the plane as geofence vertices.
+func (plane overlayPlane) ring(ring []*Point) []*Point {
+	points := make([]*Point, len(ring))
+	for i, point := range ring {
+		points[i] = plane.point(point)
+	}
+	return points
+}
+
+// densifyLinear returns the ring with extra points inserted along each edge, straight in its
+// coordinates, so that no two consecutive points are more than step apart in either.
+func densifyLinear(ring []*Point, step float64) []*Point {
+	dense := make([]*Point, 0, len(ring))
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		dense = append(dense, a)
+		dX, dY := b.X()-a.X(), b.Y()-a.Y()
+		steps := int(math.Ceil(math.Max(math.Abs(dX), math.Abs(dY)) / step))
+		for s := 1; s < steps; s++ {
+			f := float64(s) / float64(steps)
+			dense = append(dense, NewPoint(a.X()+f*dX, a.Y()+f*dY))
+		}
+	}
+	return dense
+}
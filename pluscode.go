@@ -0,0 +1,155 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// plusCodeAlphabet is the base 20 alphabet of Open Location Codes, chosen to avoid spelling words.
+const plusCodeAlphabet = "23456789CFGHJMPQRVWX"
+
+// Plus Codes are 10 digits in lat, lng pairs, then up to 5 grid digits each splitting a cell into
+// 5 rows and 4 columns, with a "+" after the eighth digit. Shorter codes are padded with "0" up
+// to the "+".
+const (
+	plusCodeSeparator     = 8
+	plusCodePairLength    = 10
+	plusCodeMaxLength     = 15
+	plusCodePairPerDegree = 8000 // The last pair's cells are 1/8000th of a degree
+	plusCodeGridRows      = 5
+	plusCodeGridColumns   = 4
+)
+
+// PlusCode returns the Open Location Code of the point with the given number of digits, e.g.
+// "9C3XGV4C+XV" for central London with 10 digits, the usual length, whose cells are about 14m
+// across. Lengths below 8 must be even, and are padded, and lengths outside 2 to 15 are clamped.
+func PlusCode(point *Point, length int) string {
+	switch {
+	case length < 2:
+		length = 2
+	case length > plusCodeMaxLength:
+		length = plusCodeMaxLength
+	case length < plusCodeSeparator && length%2 == 1:
+		length++
+	}
+
+	lat := math.Min(math.Max(point.Lat(), -90), 90)
+	lng := math.Mod(point.Lng()+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	gridLatCells, gridLngCells := int64(math.Pow(plusCodeGridRows, 5)), int64(math.Pow(plusCodeGridColumns, 5))
+	latValue := int64(math.Floor((lat + 90) * plusCodePairPerDegree * float64(gridLatCells)))
+	lngValue := int64(math.Floor(lng * plusCodePairPerDegree * float64(gridLngCells)))
+	// The north pole is in the cells below it
+	if maxLat := 180 * plusCodePairPerDegree * gridLatCells; latValue >= maxLat {
+		latValue = maxLat - 1
+	}
+
+	digits := make([]byte, plusCodeMaxLength)
+	for i := plusCodeMaxLength - 1; i >= plusCodePairLength; i-- {
+		digits[i] = plusCodeAlphabet[latValue%plusCodeGridRows*plusCodeGridColumns+lngValue%plusCodeGridColumns]
+		latValue /= plusCodeGridRows
+		lngValue /= plusCodeGridColumns
+	}
+	for i := plusCodePairLength - 2; i >= 0; i -= 2 {
+		digits[i], digits[i+1] = plusCodeAlphabet[latValue%20], plusCodeAlphabet[lngValue%20]
+		latValue /= 20
+		lngValue /= 20
+	}
+
+	code := string(digits[:length])
+	if length < plusCodeSeparator {
+		code += strings.Repeat("0", plusCodeSeparator-length)
+	}
+	return code[:plusCodeSeparator] + "+" + code[plusCodeSeparator:]
+}
+
+// NewPointFromPlusCode returns the center of the cell of a full Open Location Code, e.g.
+// "9C3XGV4C+XV". Short codes such as "GV4C+XV London" need a reference location to recover the
+// full code first.
+func NewPointFromPlusCode(code string) (*Point, error) {
+	minLat, minLng, maxLat, maxLng, err := plusCodeBounds(code)
+	if err != nil {
+		return nil, err
+	}
+	return NewPoint((minLat+maxLat)/2, (minLng+maxLng)/2), nil
+}
+
+// NewGeofenceFromPlusCode is the construct for a rectangular Geofence, as built by
+// NewBBoxGeofence, covering the cell of a full Open Location Code, e.g. "9C3XGV00+" for a
+// 275m square. Options are applied as for NewBBoxGeofence.
+func NewGeofenceFromPlusCode(code string, opts ...Option) (*Geofence, error) {
+	minLat, minLng, maxLat, maxLng, err := plusCodeBounds(code)
+	if err != nil {
+		return nil, err
+	}
+	return NewBBoxGeofence(minLat, minLng, maxLat, maxLng, opts...), nil
+}
+
+// plusCodeBounds returns the rectangle of a full Open Location Code's cell.
+func plusCodeBounds(code string) (minLat, minLng, maxLat, maxLng float64, err error) {
+	digits, err := plusCodeDigits(code)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid plus code %q: %v", code, err)
+	}
+
+	minLat, minLng = -90, -180
+	latSize, lngSize := 20.0*20, 20.0*20
+	for i, digit := range digits {
+		switch {
+		case i < plusCodePairLength && i%2 == 0:
+			latSize /= 20
+			minLat += float64(digit) * latSize
+		case i < plusCodePairLength:
+			lngSize /= 20
+			minLng += float64(digit) * lngSize
+		default:
+			latSize /= plusCodeGridRows
+			lngSize /= plusCodeGridColumns
+			minLat += float64(digit/plusCodeGridColumns) * latSize
+			minLng += float64(digit%plusCodeGridColumns) * lngSize
+		}
+	}
+	return minLat, minLng, math.Min(minLat+latSize, 90), minLng + lngSize, nil
+}
+
+// plusCodeDigits returns the values of a full code's digits, without the separator and padding.
+func plusCodeDigits(code string) ([]int, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	separator := strings.IndexByte(code, '+')
+	switch {
+	case separator < 0:
+		return nil, fmt.Errorf("it has no \"+\"")
+	case strings.Count(code, "+") > 1:
+		return nil, fmt.Errorf("it has more than one \"+\"")
+	case separator < plusCodeSeparator:
+		return nil, fmt.Errorf("it is a short code, which needs a reference location")
+	case separator > plusCodeSeparator:
+		return nil, fmt.Errorf("it has more than %d digits before the \"+\"", plusCodeSeparator)
+	case len(code)-separator-1 == 1:
+		return nil, fmt.Errorf("it has a single digit after the \"+\"")
+	case len(code)-1 > plusCodeMaxLength:
+		return nil, fmt.Errorf("it has more than %d digits", plusCodeMaxLength)
+	}
+
+	code = code[:separator] + code[separator+1:]
+	if padding := strings.IndexByte(code, '0'); padding >= 0 {
+		if padding == 0 || padding%2 == 1 || strings.TrimRight(code[padding:], "0") != "" || len(code) > plusCodeSeparator {
+			return nil, fmt.Errorf("its padding is invalid")
+		}
+		code = code[:padding]
+	}
+	digits := make([]int, len(code))
+	for i := 0; i < len(code); i++ {
+		if digits[i] = strings.IndexByte(plusCodeAlphabet, code[i]); digits[i] < 0 {
+			return nil, fmt.Errorf("%q is not a plus code digit", code[i])
+		}
+	}
+	// The first pair is at most 8 (lat 70..90) and 17 (lng 160..180)
+	if digits[0] > 8 || digits[1] > 17 {
+		return nil, fmt.Errorf("it is outside the range of latitude and longitude")
+	}
+	return digits, nil
+}
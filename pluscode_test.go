@@ -0,0 +1,55 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlusCode(t *testing.T) {
+	// Google's Zurich office, from the Open Location Code test data
+	zurich := NewPoint(47.365590, 8.524997)
+	assert.Equal(t, "8FVC9G8F+6X", PlusCode(zurich, 10))
+	assert.Equal(t, "8FVC9G8F+6XQQ", PlusCode(zurich, 12))
+	assert.Equal(t, "8FVC0000+", PlusCode(zurich, 4))
+	assert.Equal(t, "8FVC9G00+", PlusCode(zurich, 5))
+	assert.Equal(t, "CFX30000+", PlusCode(NewPoint(90, 1), 4))
+	assert.Equal(t, "62H20000+", PlusCode(NewPoint(1, 180), 4))
+
+	point, err := NewPointFromPlusCode("8fvc9g8f+6x")
+	assert.NoError(t, err)
+	assert.InDelta(t, 47.3655625, point.Lat(), 1e-9)
+	assert.InDelta(t, 8.5249375, point.Lng(), 1e-9)
+	assert.Equal(t, "8FVC9G8F+6X", PlusCode(point, 10))
+
+	geofence, err := NewGeofenceFromPlusCode("8FVC9G00+", WithGranularity(5))
+	assert.NoError(t, err)
+	assert.True(t, geofence.rect)
+	minLat, minLng, maxLat, maxLng := geofence.BBox()
+	assert.InDelta(t, 47.35, minLat, 1e-9)
+	assert.InDelta(t, 8.5, minLng, 1e-9)
+	assert.InDelta(t, 47.4, maxLat, 1e-9)
+	assert.InDelta(t, 8.55, maxLng, 1e-9)
+	assert.True(t, geofence.Inside(zurich))
+	assert.False(t, geofence.Inside(NewPoint(47.45, 8.52)))
+
+	for _, bad := range []string{
+		"8FVC9G8F",
+		"9G8F+6X",
+		"8FVC9G8F+6",
+		"8FVC9G8F+6X+",
+		"8FVC9G8F1+6X",
+		"8FVC9G0F+",
+		"8FVC900+",
+		"8FVC0000+6X",
+		"8FVC9G8F+6XQQQQQQ",
+		"8FVC9G8A+6X",
+		"XFVC9G8F+6X",
+		"8XVC9G8F+6X",
+	} {
+		_, err := NewGeofenceFromPlusCode(bad)
+		assert.Error(t, err, bad)
+		_, err = NewPointFromPlusCode(bad)
+		assert.Error(t, err, bad)
+	}
+}
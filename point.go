@@ -11,8 +11,10 @@ import (
 
 // Represents a Physical Point in geographic notation [lat, lng].
 type Point struct {
-	lat float64
-	lng float64
+	lat    float64
+	lng    float64
+	alt    float64
+	hasAlt bool
 }
 
 const (
@@ -25,6 +27,28 @@ func NewPoint(lat float64, lng float64) *Point {
 	return &Point{lat: lat, lng: lng}
 }
 
+// Returns a new Point at the latitude, longitude and altitude, in meters, e.g. a drone's height
+// for a geofence built WithAltitudeRange.
+func NewPointWithAltitude(lat, lng, altitude float64) *Point {
+	return &Point{lat: lat, lng: lng, alt: altitude, hasAlt: true}
+}
+
+// Returns a new Point on a flat local grid populated by the passed in x and y values, for
+// geofences built WithPlanar. X is stored as the latitude and Y as the longitude.
+func NewPointXY(x float64, y float64) *Point {
+	return &Point{lat: x, lng: y}
+}
+
+// Returns Point p's x coordinate on a flat local grid, the same as Lat.
+func (p *Point) X() float64 {
+	return p.lat
+}
+
+// Returns Point p's y coordinate on a flat local grid, the same as Lng.
+func (p *Point) Y() float64 {
+	return p.lng
+}
+
 // Returns Point p's latitude.
 func (p *Point) Lat() float64 {
 	return p.lat
@@ -35,6 +59,11 @@ func (p *Point) Lng() float64 {
 	return p.lng
 }
 
+// Returns Point p's altitude in meters, and whether it has one.
+func (p *Point) Altitude() (float64, bool) {
+	return p.alt, p.hasAlt
+}
+
 // Returns a Point populated with the lat and lng coordinates
 // by transposing the origin point the passed in distance (in kilometers)
 // by the passed in compass bearing (in degrees).
@@ -84,7 +113,14 @@ func (p *Point) GreatCircleDistance(p2 *Point) float64 {
 	return EARTH_RADIUS * c
 }
 
-// Calculates the initial bearing (sometimes referred to as forward azimuth)
+// Calculates the Haversine distance between two points in meters, the same as
+// GreatCircleDistance in kilometers times 1000.
+func (p *Point) DistanceTo(p2 *Point) float64 {
+	return p.GreatCircleDistance(p2) * 1000
+}
+
+// Calculates the initial bearing (sometimes referred to as forward azimuth) in degrees
+// clockwise from north, between -180 and 180.
 // Original Implementation from: http://www.movable-type.co.uk/scripts/latlong.html
 func (p *Point) BearingTo(p2 *Point) float64 {
 
@@ -137,6 +173,13 @@ func (p *Point) MarshalBinary() ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to encode lng %v: %v", p.lng, err)
 	}
+	// The altitude, when there is one, follows as a third value
+	if p.hasAlt {
+		err = binary.Write(&buf, binary.LittleEndian, p.alt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode altitude %v: %v", p.alt, err)
+		}
+	}
 
 	return buf.Bytes(), nil
 }
@@ -156,14 +199,22 @@ func (p *Point) UnmarshalBinary(data []byte) error {
 		return fmt.Errorf("binary.Read failed: %v", err)
 	}
 
-	p.lat = lat
-	p.lng = lng
+	*p = Point{lat: lat, lng: lng}
+	if buf.Len() >= 8 {
+		if err := binary.Read(buf, binary.LittleEndian, &p.alt); err != nil {
+			return fmt.Errorf("binary.Read failed: %v", err)
+		}
+		p.hasAlt = true
+	}
 	return nil
 }
 
 // Renders the current Point to valid JSON.
 // Implements the json.Marshaller Interface.
 func (p *Point) MarshalJSON() ([]byte, error) {
+	if p.hasAlt {
+		return []byte(fmt.Sprintf(`{"lat":%v, "lng":%v, "alt":%v}`, p.lat, p.lng, p.alt)), nil
+	}
 	res := fmt.Sprintf(`{"lat":%v, "lng":%v}`, p.lat, p.lng)
 	return []byte(res), nil
 }
@@ -182,6 +233,9 @@ func (p *Point) UnmarshalJSON(data []byte) error {
 	}
 
 	*p = *NewPoint(values["lat"], values["lng"])
+	if alt, ok := values["alt"]; ok {
+		*p = *NewPointWithAltitude(values["lat"], values["lng"], alt)
+	}
 
 	return nil
 }
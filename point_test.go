@@ -0,0 +1,61 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointDistanceTo(t *testing.T) {
+	london, paris := NewPoint(51.5074, -0.1278), NewPoint(48.8566, 2.3522)
+	assert.InDelta(t, 343500, london.DistanceTo(paris), 500)
+	assert.Equal(t, london.GreatCircleDistance(paris)*1000, london.DistanceTo(paris))
+	assert.Equal(t, london.DistanceTo(paris), paris.DistanceTo(london))
+	assert.Equal(t, 0.0, london.DistanceTo(london))
+	// A degree of lat is about 111km anywhere
+	assert.InDelta(t, 111195, NewPoint(0, 0).DistanceTo(NewPoint(1, 0)), 1)
+	assert.InDelta(t, 111195, NewPoint(60, 10).DistanceTo(NewPoint(61, 10)), 1)
+}
+
+func TestPointBearingTo(t *testing.T) {
+	origin := NewPoint(0, 0)
+	assert.InDelta(t, 0, origin.BearingTo(NewPoint(1, 0)), 1e-9)
+	assert.InDelta(t, 90, origin.BearingTo(NewPoint(0, 1)), 1e-9)
+	assert.InDelta(t, 180, origin.BearingTo(NewPoint(-1, 0)), 1e-9)
+	assert.InDelta(t, -90, origin.BearingTo(NewPoint(0, -1)), 1e-9)
+
+	london, paris := NewPoint(51.5074, -0.1278), NewPoint(48.8566, 2.3522)
+	bearing := london.BearingTo(paris)
+	assert.InDelta(t, 148.1, bearing, 0.1)
+	there := london.PointAtDistanceAndBearing(london.DistanceTo(paris)/1000, bearing)
+	assert.InDelta(t, paris.Lat(), there.Lat(), 1e-6)
+	assert.InDelta(t, paris.Lng(), there.Lng(), 1e-6)
+}
+
+func TestPointAltitude(t *testing.T) {
+	flat := NewPoint(51.5, -0.1)
+	_, ok := flat.Altitude()
+	assert.False(t, ok)
+	drone := NewPointWithAltitude(51.5, -0.1, 85.5)
+	altitude, ok := drone.Altitude()
+	assert.True(t, ok)
+	assert.Equal(t, 85.5, altitude)
+
+	for _, point := range []*Point{flat, drone, NewPointWithAltitude(1, 2, 0)} {
+		data, err := point.MarshalBinary()
+		assert.NoError(t, err)
+		decoded := &Point{}
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, point, decoded)
+
+		data, err = point.MarshalJSON()
+		assert.NoError(t, err)
+		decoded = &Point{}
+		assert.NoError(t, decoded.UnmarshalJSON(data))
+		assert.Equal(t, point, decoded)
+	}
+	data, _ := flat.MarshalJSON()
+	assert.JSONEq(t, `{"lat":51.5,"lng":-0.1}`, string(data))
+	data, _ = drone.MarshalJSON()
+	assert.JSONEq(t, `{"lat":51.5,"lng":-0.1,"alt":85.5}`, string(data))
+}
@@ -0,0 +1,67 @@
+package geofence
+
+import (
+	"math"
+)
+
+// A geodesic ring around a pole, such as the parallel at 70°N, has vertices all round the globe
+// and no area in the lat/lng plane between them. The spherical check handles it as any other
+// ring, but the tiles need the planar region it bounds: the band from the ring up to the pole,
+// cut at the antimeridian so it runs from -180° to 180°.
+
+// enclosedPole returns 90 or -90 when the ring winds once round the globe, for the pole it
+// encloses, or 0 when it does not. The pole on the side of the ring's mean latitude is taken,
+// the ring then bounding the smaller cap.
+func enclosedPole(ring []*Point) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+	turn, lat := 0.0, 0.0
+	for i := 0; i < len(ring); i++ {
+		turn += math.Remainder(ring[(i+1)%len(ring)].Lng()-ring[i].Lng(), 360)
+		lat += ring[i].Lat()
+	}
+	if math.Abs(turn) < 180 {
+		return 0
+	}
+	if lat < 0 {
+		return -90
+	}
+	return 90
+}
+
+// polarRing returns the planar ring bounding the cap between the ring and the pole. The ring's
+// longitudes are unwrapped to run continuously through 360°, then cut where they pass 180°.
+func polarRing(ring []*Point, pole float64) []*Point {
+	n := len(ring)
+	lngs := make([]float64, n+1)
+	lngs[0] = ring[0].Lng()
+	for i := 1; i <= n; i++ {
+		lngs[i] = lngs[i-1] + math.Remainder(ring[i%n].Lng()-ring[i-1].Lng(), 360)
+	}
+	if lngs[n] < lngs[0] {
+		// Running west, reverse the ring so the longitudes increase
+		reversed := make([]*Point, n)
+		for i, point := range ring {
+			reversed[n-1-i] = point
+		}
+		return polarRing(reversed, pole)
+	}
+
+	// The edge from i to i+1 passes 180°, the last vertex closing the ring back to the first
+	i := 0
+	for lngs[i+1] <= 180 {
+		i++
+	}
+	next := ring[(i+1)%n]
+	crossingLat := ring[i].Lat() + (180-lngs[i])/(lngs[i+1]-lngs[i])*(next.Lat()-ring[i].Lat())
+
+	band := []*Point{NewPoint(crossingLat, -180)}
+	for j := i + 1; j < n; j++ {
+		band = append(band, NewPoint(ring[j].Lat(), lngs[j]-360))
+	}
+	for j := 0; j <= i; j++ {
+		band = append(band, NewPoint(ring[j].Lat(), lngs[j]))
+	}
+	return append(band, NewPoint(crossingLat, 180), NewPoint(pole, 180), NewPoint(pole, -180))
+}
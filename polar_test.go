@@ -0,0 +1,58 @@
+package geofence
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parallel(lat float64, vertices int, west bool) []*Point {
+	var ring []*Point
+	for i := 0; i < vertices; i++ {
+		lng := -180 + 360*float64(i)/float64(vertices) + 10
+		if west {
+			lng = -lng
+		}
+		ring = append(ring, NewPoint(lat, lng))
+	}
+	return ring
+}
+
+func TestPolarGeofence(t *testing.T) {
+	for _, west := range []bool{false, true} {
+		arctic := NewGeodesicGeofence(parallel(70, 12, west))
+		assert.Equal(t, 90.0, enclosedPole(arctic.vertices))
+		assert.True(t, arctic.Inside(NewPoint(90, 0)))
+		assert.True(t, arctic.Inside(NewPoint(85, 123)))
+		assert.True(t, arctic.Inside(NewPoint(80, -180)))
+		assert.False(t, arctic.Inside(NewPoint(60, 0)))
+		assert.False(t, arctic.Inside(NewPoint(-85, 123)))
+
+		min, max := arctic.Bounds()
+		assert.InDelta(t, 70, min.Lat(), 1e-9)
+		assert.Equal(t, 90.0, max.Lat())
+		assert.Equal(t, -180.0, min.Lng())
+		assert.Equal(t, 180.0, max.Lng())
+
+		for i := 0; i < 10000; i++ {
+			point := NewPoint(60+rand.Float64()*30, rand.Float64()*360-180)
+			assert.Equal(t, sphericalRingContains(arctic.vertices, point), arctic.Inside(point), point)
+		}
+	}
+
+	antarctic := NewGeodesicGeofence(parallel(-65, 24, false))
+	assert.Equal(t, -90.0, enclosedPole(antarctic.vertices))
+	assert.True(t, antarctic.Inside(NewPoint(-90, 0)))
+	assert.True(t, antarctic.Inside(NewPoint(-75, 45)))
+	assert.False(t, antarctic.Inside(NewPoint(-55, 45)))
+	assert.False(t, antarctic.Inside(NewPoint(75, 45)))
+
+	// A band between two parallels, the inner ring as a hole
+	band := NewGeofenceWithHoles(parallel(70, 12, false), [][]*Point{parallel(80, 12, false)}, WithGeodesic())
+	assert.True(t, band.Inside(NewPoint(76, 10)))
+	assert.False(t, band.Inside(NewPoint(88, 10)))
+
+	// Rings not around a pole are unaffected
+	assert.Equal(t, 0.0, enclosedPole([]*Point{NewPoint(-20, 170), NewPoint(-20, -170), NewPoint(-10, -170), NewPoint(-10, 170)}))
+}
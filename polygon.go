@@ -8,6 +8,7 @@ import (
 // It can thus contain holes, and can be self-intersecting.
 type Polygon struct {
 	points []*Point
+	holes  [][]*Point
 }
 
 // Creates and returns a new pointer to a Polygon
@@ -18,6 +19,14 @@ func NewPolygon(points []*Point) *Polygon {
 	return &Polygon{points: points}
 }
 
+// Creates and returns a new pointer to a Polygon
+// composed of the passed in outer points and holes.
+// A point inside a hole is not contained by the Polygon,
+// unless it is also inside a hole nested within that hole.
+func NewPolygonWithHoles(points []*Point, holes [][]*Point) *Polygon {
+	return &Polygon{points: points, holes: holes}
+}
+
 // Returns the points of the current Polygon.
 func (p *Polygon) Points() []*Point {
 	return p.points
@@ -28,10 +37,21 @@ func (p *Polygon) Add(point *Point) {
 	p.points = append(p.points, point)
 }
 
+// Returns the holes of the current Polygon.
+func (p *Polygon) Holes() [][]*Point {
+	return p.holes
+}
+
+// Appends the passed in hole to the current Polygon.
+func (p *Polygon) AddHole(hole []*Point) {
+	p.holes = append(p.holes, hole)
+}
+
 // Returns whether or not the polygon is closed.
 // TODO:  This can obviously be improved, but for now,
-//        this should be sufficient for detecting if points
-//        are contained using the raycast algorithm.
+//
+//	this should be sufficient for detecting if points
+//	are contained using the raycast algorithm.
 func (p *Polygon) IsClosed() bool {
 	if len(p.points) < 3 {
 		return false
@@ -46,13 +66,25 @@ func (p *Polygon) Contains(point *Point) bool {
 		return false
 	}
 
-	start := len(p.points) - 1
+	contains := p.ringContains(p.points, point)
+	for _, hole := range p.holes {
+		if len(hole) >= 3 && p.ringContains(hole, point) {
+			contains = !contains
+		}
+	}
+
+	return contains
+}
+
+// Returns whether or not the ring drawn by the passed in points contains the passed in Point.
+func (p *Polygon) ringContains(ring []*Point, point *Point) bool {
+	start := len(ring) - 1
 	end := 0
 
-	contains := p.intersectsWithRaycast(point, p.points[start], p.points[end])
+	contains := p.intersectsWithRaycast(point, ring[start], ring[end])
 
-	for i := 1; i < len(p.points); i++ {
-		if p.intersectsWithRaycast(point, p.points[i-1], p.points[i]) {
+	for i := 1; i < len(ring); i++ {
+		if p.intersectsWithRaycast(point, ring[i-1], ring[i]) {
 			contains = !contains
 		}
 	}
@@ -75,36 +107,36 @@ func (p *Polygon) intersectsWithRaycast(point *Point, start *Point, end *Point)
 
 	// Move the point's y coordinate
 	// outside of the bounds of the testing region
-	// so we can start drawing a ray
-	for point.lng == start.lng || point.lng == end.lng {
-		newLng := math.Nextafter(point.lng, math.Inf(1))
-		point = NewPoint(point.lat, newLng)
+	// so we can start drawing a ray.
+	// A local copy is nudged rather than allocating a new Point
+	lat, lng := point.lat, point.lng
+	for lng == start.lng || lng == end.lng {
+		lng = math.Nextafter(lng, math.Inf(1))
 	}
 
 	// If we are outside of the polygon, indicate so.
-	if point.lng < start.lng || point.lng > end.lng {
+	if lng < start.lng || lng > end.lng {
 		return false
 	}
 
 	if start.lat > end.lat {
-		if point.lat > start.lat {
+		if lat > start.lat {
 			return false
 		}
-		if point.lat < end.lat {
+		if lat < end.lat {
 			return true
 		}
 
 	} else {
-		if point.lat > end.lat {
+		if lat > end.lat {
 			return false
 		}
-		if point.lat < start.lat {
+		if lat < start.lat {
 			return true
 		}
 	}
 
-	raySlope := (point.lng - start.lng) / (point.lat - start.lat)
-	diagSlope := (end.lng - start.lng) / (end.lat - start.lat)
-
-	return raySlope >= diagSlope
+	// Compares the slopes of the ray and the edge from start, multiplied out so that a point
+	// level in lat with start is not divided by zero
+	return (lat-start.lat)*(end.lng-start.lng) <= (end.lat-start.lat)*(lng-start.lng)
 }
@@ -0,0 +1,106 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// polylinePrecision is the scale of coordinates in Google's encoded polyline format, 5 decimal
+// places, about a meter.
+const polylinePrecision = 1e5
+
+// NewGeofenceFromEncodedPolyline builds a corridor Geofence, as by NewCorridorGeofence, covering
+// every point within widthMeters/2 of a path in Google's encoded polyline format, e.g. the
+// overview_polyline of a Directions API route.
+func NewGeofenceFromEncodedPolyline(polyline string, widthMeters float64, opts ...Option) (*Geofence, error) {
+	if !(widthMeters > 0) {
+		return nil, fmt.Errorf("corridor width must be positive, got %v", widthMeters)
+	}
+	path, err := DecodePolyline(polyline)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("encoded polyline has no points")
+	}
+	return NewCorridorGeofence(path, widthMeters, opts...), nil
+}
+
+// EncodedPolylines returns the rings of the geofence in Google's encoded polyline format, each
+// polygon's outer ring followed by its holes, without repeating the first point at the end, e.g.
+// as the paths of a Maps JavaScript API Polygon.
+func (geofence *Geofence) EncodedPolylines() []string {
+	var polylines []string
+	if geofence.empty() {
+		return polylines
+	}
+	for _, part := range geofence.polygons() {
+		polylines = append(polylines, EncodePolyline(part.unwrapRing(part.vertices)))
+		for _, hole := range part.holes {
+			polylines = append(polylines, EncodePolyline(part.unwrapRing(hole)))
+		}
+	}
+	return polylines
+}
+
+// EncodePolyline returns the points in Google's encoded polyline format, rounded to 5 decimal
+// places.
+func EncodePolyline(points []*Point) string {
+	var builder strings.Builder
+	var lastLat, lastLng int64
+	for _, point := range points {
+		lat, lng := int64(math.Round(point.Lat()*polylinePrecision)), int64(math.Round(point.Lng()*polylinePrecision))
+		writePolylineValue(&builder, lat-lastLat)
+		writePolylineValue(&builder, lng-lastLng)
+		lastLat, lastLng = lat, lng
+	}
+	return builder.String()
+}
+
+// writePolylineValue writes a delta as 5 bit chunks, low first, each offset by 63 and with 0x20
+// set on all but the last.
+func writePolylineValue(builder *strings.Builder, value int64) {
+	encoded := uint64(value) << 1
+	if value < 0 {
+		encoded = ^encoded
+	}
+	for encoded >= 0x20 {
+		builder.WriteByte(byte(0x20|encoded&0x1f) + 63)
+		encoded >>= 5
+	}
+	builder.WriteByte(byte(encoded) + 63)
+}
+
+// DecodePolyline returns the points of a path in Google's encoded polyline format.
+func DecodePolyline(polyline string) ([]*Point, error) {
+	var points []*Point
+	var lat, lng int64
+	for pos := 0; pos < len(polyline); {
+		var deltas [2]int64
+		for i := range deltas {
+			var encoded uint64
+			for shift := uint(0); ; shift += 5 {
+				if pos >= len(polyline) {
+					return nil, fmt.Errorf("encoded polyline is truncated at point %d", len(points))
+				}
+				chunk := polyline[pos]
+				if chunk < 63 || chunk > 126 || shift > 60 {
+					return nil, fmt.Errorf("invalid encoded polyline character %q at %d", chunk, pos)
+				}
+				pos++
+				encoded |= uint64(chunk-63) & 0x1f << shift
+				if chunk-63 < 0x20 {
+					break
+				}
+			}
+			deltas[i] = int64(encoded >> 1)
+			if encoded&1 != 0 {
+				deltas[i] = ^deltas[i]
+			}
+		}
+		lat, lng = lat+deltas[0], lng+deltas[1]
+		points = append(points, NewPoint(float64(lat)/polylinePrecision, float64(lng)/polylinePrecision))
+	}
+	return points, nil
+}
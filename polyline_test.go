@@ -0,0 +1,48 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodedPolyline(t *testing.T) {
+	// The example from Google's documentation of the format
+	path := []*Point{NewPoint(38.5, -120.2), NewPoint(40.7, -120.95), NewPoint(43.252, -126.453)}
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", EncodePolyline(path))
+	decoded, err := DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	assert.NoError(t, err)
+	if assert.Len(t, decoded, 3) {
+		for i := range path {
+			assert.InDelta(t, path[i].Lat(), decoded[i].Lat(), 1e-9)
+			assert.InDelta(t, path[i].Lng(), decoded[i].Lng(), 1e-9)
+		}
+	}
+	decoded, err = DecodePolyline("")
+	assert.NoError(t, err)
+	assert.Empty(t, decoded)
+	for _, bad := range []string{"_p~iF~ps|", "_p~iF~ps|U_", "_p~iF ~ps|U", "~~~~~~~~~~~~~~~~~"} {
+		_, err := DecodePolyline(bad)
+		assert.Error(t, err, bad)
+	}
+
+	corridor, err := NewGeofenceFromEncodedPolyline(EncodePolyline([]*Point{NewPoint(51.5, -0.1), NewPoint(51.5, -0.09)}), 100, WithGranularity(5))
+	assert.NoError(t, err)
+	assert.True(t, corridor.geodesic)
+	assert.True(t, corridor.Inside(NewPoint(51.5004, -0.095)))
+	assert.False(t, corridor.Inside(NewPoint(51.5006, -0.095)))
+	_, err = NewGeofenceFromEncodedPolyline("", 100)
+	assert.Error(t, err)
+	_, err = NewGeofenceFromEncodedPolyline("_p~iF~ps|U", -1)
+	assert.Error(t, err)
+
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	polylines := NewGeofenceWithHoles(outer, [][]*Point{hole}).EncodedPolylines()
+	if assert.Len(t, polylines, 2) {
+		assert.Equal(t, EncodePolyline(outer), polylines[0])
+		assert.Equal(t, EncodePolyline(hole), polylines[1])
+	}
+	assert.Len(t, NewMultiGeofence([][]*Point{outer, {NewPoint(20, 20), NewPoint(20, 21), NewPoint(21, 21)}}).EncodedPolylines(), 2)
+	assert.Empty(t, NewGeofence(nil).EncodedPolylines())
+}
@@ -0,0 +1,97 @@
+package geofence
+
+import (
+	"math"
+	"math/big"
+)
+
+// orientationErrorBound bounds the rounding error of the float64 orientation determinant, relative
+// to the sum of the magnitudes of its two products (Shewchuk's ccwerrboundA).
+var orientationErrorBound = (3 + 16*epsilon) * epsilon
+
+// epsilon is half the gap between 1 and the next float64, the largest relative rounding error.
+const epsilon = 1.0 / (1 << 53)
+
+// orientation returns the sign of the cross product of b-a and p-a: 1 when p is to the left of
+// the line from a to b, with lat as x and lng as y, -1 when it is to the right and 0 when it is
+// exactly on the line. The float64 result is used when it is clear of its rounding error and
+// the determinant is recomputed exactly otherwise, so points on an edge are found regardless
+// of how the arithmetic rounds.
+func orientation(a, b, p *Point) int {
+	left := (b.lat - a.lat) * (p.lng - a.lng)
+	right := (b.lng - a.lng) * (p.lat - a.lat)
+	det := left - right
+	bound := orientationErrorBound * (math.Abs(left) + math.Abs(right))
+	switch {
+	case det > bound:
+		return 1
+	case det < -bound:
+		return -1
+	case math.IsNaN(det) || math.IsInf(bound, 0):
+		// Coordinates that are not finite have no exact value to fall back on
+		return sign(det)
+	}
+	return exactOrientation(a, b, p)
+}
+
+// exactOrientation computes the orientation determinant in rational arithmetic. Every finite
+// float64 is a rational number, so the result is exact.
+func exactOrientation(a, b, p *Point) int {
+	rat := func(f float64) *big.Rat { return new(big.Rat).SetFloat64(f) }
+	ax, ay := rat(a.lat), rat(a.lng)
+	dx1, dy1 := new(big.Rat).Sub(rat(b.lat), ax), new(big.Rat).Sub(rat(b.lng), ay)
+	dx2, dy2 := new(big.Rat).Sub(rat(p.lat), ax), new(big.Rat).Sub(rat(p.lng), ay)
+	left := new(big.Rat).Mul(dx1, dy2)
+	return left.Cmp(new(big.Rat).Mul(dy1, dx2))
+}
+
+// exactContains returns whether the geofence contains the point, using orientation for every
+// edge crossing the line of the point's lng, by the geofence's containment rule. Each hole
+// containing the point flips the result.
+func (geofence *Geofence) exactContains(point *Point) bool {
+	inside := geofence.exactRingContains(geofence.vertices, point)
+	for _, hole := range geofence.holes {
+		if geofence.exactRingContains(hole, point) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (geofence *Geofence) exactRingContains(ring []*Point, point *Point) bool {
+	if len(ring) < 3 {
+		return false
+	}
+	winding := exactWindingNumber(ring, point)
+	if geofence.containment == WindingNumber {
+		return winding != 0
+	}
+	// Every crossing adds or takes one, so the count of crossings is odd when the sum is
+	return winding%2 != 0
+}
+
+// exactWindingNumber is windingNumber with the side of each edge decided by orientation.
+func exactWindingNumber(ring []*Point, point *Point) int {
+	winding := 0
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		if a.lng <= point.lng {
+			if b.lng > point.lng && orientation(a, b, point) > 0 {
+				winding++
+			}
+		} else if b.lng <= point.lng && orientation(a, b, point) < 0 {
+			winding--
+		}
+	}
+	return winding
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrientation(t *testing.T) {
+	a, b := NewPoint(0, 0), NewPoint(10, 10)
+	assert.Equal(t, 1, orientation(a, b, NewPoint(0, 10)))
+	assert.Equal(t, -1, orientation(a, b, NewPoint(10, 0)))
+	assert.Equal(t, 0, orientation(a, b, NewPoint(5, 5)))
+	assert.Equal(t, 0, orientation(a, b, NewPoint(20, 20)))
+
+	// The float64 determinant rounds to zero, but the point is just off the line
+	a, b = NewPoint(41.89417856013015, -87.16143133875777), NewPoint(40.73324951843237, -87.96235657833655)
+	p := NewPoint(41.418949460810516, -87.48929201460989)
+	assert.Equal(t, 0.0, vectorCrossProduct(vectorDifference(b, a), vectorDifference(p, a)))
+	assert.Equal(t, 1, orientation(a, b, p))
+	assert.False(t, onPlanarSegment(p, a, b))
+}
+
+func TestBoundaryExact(t *testing.T) {
+	a, b := NewPoint(41.89417856013015, -87.16143133875777), NewPoint(40.73324951843237, -87.96235657833655)
+	p := NewPoint(41.418949460810516, -87.48929201460989)
+	triangle := []*Point{a, b, NewPoint(40, -87)}
+	// Off the boundary, so the boundary setting makes no difference
+	inclusive := NewGeofence(triangle, WithBoundary(Inclusive))
+	exclusive := NewGeofence(triangle, WithBoundary(Exclusive))
+	assert.Equal(t, inclusive.Inside(p), exclusive.Inside(p))
+
+	// Points exactly on the boundary follow the setting
+	for _, point := range []*Point{a, b, triangle[2]} {
+		assert.True(t, inclusive.Inside(point))
+		assert.False(t, exclusive.Inside(point))
+	}
+}
+
+func TestWithExactPredicates(t *testing.T) {
+	a, b := NewPoint(41.89417856013015, -87.16143133875777), NewPoint(40.73324951843237, -87.96235657833655)
+	p := NewPoint(41.418949460810516, -87.48929201460989)
+	for _, c := range []*Point{NewPoint(40, -87), NewPoint(42, -88)} {
+		// p is just off the edge from a to b, inside when on the same side as c
+		geofence := NewGeofence([]*Point{a, b, c}, WithExactPredicates(), WithBoundary(Exclusive))
+		assert.Equal(t, orientation(a, b, c) == orientation(a, b, p), geofence.Inside(p))
+	}
+
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithExactPredicates())
+	polygon := NewPolygonWithHoles(outer, [][]*Point{hole})
+	for i := 0; i < 10000; i++ {
+		point := randomPointCustom(0, 10, 0, 10, 1.2)
+		assert.Equal(t, polygon.Contains(point), geofence.Inside(point))
+	}
+
+	star := NewGeofence(pentagram(), WithExactPredicates())
+	assert.False(t, star.Inside(NewPoint(0, 0)))
+	assert.True(t, NewGeofence(pentagram(), WithExactPredicates(), WithContainment(WindingNumber)).Inside(NewPoint(0, 0)))
+
+	data, err := geofence.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.exact)
+}
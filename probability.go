@@ -0,0 +1,68 @@
+package geofence
+
+import (
+	"math"
+)
+
+const (
+	// probabilityRings and probabilityBearings are the samples InsideProbability takes, a
+	// point on each bearing at the middle radius of each of the rings of equal probability.
+	probabilityRings    = 8
+	probabilityBearings = 16
+	// accuracySigmas is the ratio of the radius holding 68% of fixes to the standard deviation
+	// of the error along each axis, for a circular normal error.
+	accuracySigmas = 1.5096
+)
+
+// InsideProbability estimates the probability that the true position is inside the geofence,
+// given a fix at the point with a circular normal error whose 68% radius is accuracyMeters, the
+// accuracy Android and most GPS receivers report. Alerts can then fire above, say, 0.9 rather
+// than flap on every fix near the boundary. The probability is the share of a fixed pattern of
+// 128 points around the fix that are inside, so it moves in steps of 1/128. A non-positive
+// accuracy gives 1 or 0 as for Inside. WithPlanar geofences take the accuracy in grid units.
+func (geofence *Geofence) InsideProbability(point *Point, accuracyMeters float64) float64 {
+	if !(accuracyMeters > 0) {
+		if geofence.Inside(point) {
+			return 1
+		}
+		return 0
+	}
+
+	sigma := accuracyMeters / accuracySigmas
+	radii := make([]float64, probabilityRings)
+	for i := range radii {
+		// The radius at the middle of each ring, by probability, of the Rayleigh distribution
+		radii[i] = sigma * math.Sqrt(-2*math.Log(1-(float64(i)+0.5)/probabilityRings))
+	}
+
+	// Fixes further from the boundary than every sample are decided by the fix alone
+	if distance := geofence.DistanceToBoundaryMeters(point); math.Abs(distance) > radii[len(radii)-1] {
+		if distance < 0 {
+			return 1
+		}
+		return 0
+	}
+
+	planar := false
+	for _, part := range geofence.polygons() {
+		planar = planar || part.planar
+	}
+	inside := 0
+	for i, radius := range radii {
+		for j := 0; j < probabilityBearings; j++ {
+			// Alternate rings are turned by half a step so the samples do not line up
+			bearing := (float64(j) + float64(i%2)/2) * 360 / probabilityBearings
+			var sample *Point
+			if planar {
+				angle := bearing * math.Pi / 180.0
+				sample = NewPointXY(point.X()+radius*math.Cos(angle), point.Y()+radius*math.Sin(angle))
+			} else {
+				sample = point.PointAtDistanceAndBearing(radius/1000, bearing)
+			}
+			if geofence.Inside(sample) {
+				inside++
+			}
+		}
+	}
+	return float64(inside) / (probabilityRings * probabilityBearings)
+}
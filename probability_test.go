@@ -0,0 +1,41 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsideProbability(t *testing.T) {
+	// A block about 1.1km across
+	geofence := NewGeofence([]*Point{NewPoint(51.500, -0.130), NewPoint(51.500, -0.114), NewPoint(51.510, -0.114), NewPoint(51.510, -0.130)})
+	center := NewPoint(51.505, -0.122)
+	north := NewPoint(51.510, -0.122)
+
+	assert.Equal(t, 1.0, geofence.InsideProbability(center, 50))
+	assert.Equal(t, 0.0, geofence.InsideProbability(NewPoint(51.52, -0.122), 50))
+	assert.Equal(t, 1.0, geofence.InsideProbability(center, 0))
+	assert.Equal(t, 0.0, geofence.InsideProbability(NewPoint(51.52, -0.122), 0))
+
+	// On a straight edge half the samples are inside, and the share falls off either side
+	assert.InDelta(t, 0.5, geofence.InsideProbability(north, 50), 0.05)
+	inside := geofence.InsideProbability(north.PointAtDistanceAndBearing(0.03, 180), 50)
+	outside := geofence.InsideProbability(north.PointAtDistanceAndBearing(0.03, 0), 50)
+	assert.Greater(t, inside, 0.6)
+	assert.Less(t, inside, 1.0)
+	assert.Less(t, outside, 0.4)
+	assert.Greater(t, outside, 0.0)
+	assert.InDelta(t, 1, inside+outside, 0.05)
+
+	// At a corner only about a quarter are inside
+	assert.InDelta(t, 0.25, geofence.InsideProbability(NewPoint(51.510, -0.114), 50), 0.05)
+
+	// A better accuracy gives a more confident answer
+	point := north.PointAtDistanceAndBearing(0.03, 180)
+	assert.Greater(t, geofence.InsideProbability(point, 20), geofence.InsideProbability(point, 100))
+
+	// Planar geofences take the accuracy in grid units
+	shed := NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 40), NewPointXY(0, 40)}, WithPlanar())
+	assert.InDelta(t, 0.5, shed.InsideProbability(NewPointXY(10, 20), 3), 0.05)
+	assert.Equal(t, 1.0, shed.InsideProbability(NewPointXY(5, 20), 1))
+}
@@ -0,0 +1,100 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+)
+
+// Projection maps lat/lng points onto a flat plane and back, see WithProjection. Projected
+// points hold X as their Lat and Y as their Lng, as built by NewPointXY.
+type Projection interface {
+	// Project returns the point's X and Y on the plane.
+	Project(point *Point) *Point
+	// Unproject returns the lat/lng point at X and Y on the plane.
+	Unproject(point *Point) *Point
+}
+
+// webMercatorRadius is the WGS84 equatorial radius in meters used by Web Mercator (EPSG:3857).
+const webMercatorRadius = 6378137
+
+// webMercatorMaxLat is the latitude Web Mercator maps make square, beyond which it is clamped.
+const webMercatorMaxLat = 85.05112878
+
+// WebMercator is the projection of web maps (EPSG:3857), X east and Y north in meters at the
+// equator. It keeps angles, so shapes drawn on a web map keep their shape, but stretches
+// distances by 1/cos(lat).
+type WebMercator struct{}
+
+// Project returns the point's X and Y in Web Mercator meters. Latitudes beyond about ±85° are clamped.
+func (WebMercator) Project(point *Point) *Point {
+	lat := math.Max(-webMercatorMaxLat, math.Min(webMercatorMaxLat, point.Lat())) * math.Pi / 180.0
+	return NewPointXY(webMercatorRadius*point.Lng()*math.Pi/180.0, webMercatorRadius*math.Log(math.Tan(math.Pi/4+lat/2)))
+}
+
+// Unproject returns the lat/lng point at X and Y in Web Mercator meters.
+func (WebMercator) Unproject(point *Point) *Point {
+	lat := 2*math.Atan(math.Exp(point.Y()/webMercatorRadius)) - math.Pi/2
+	return NewPoint(lat*180.0/math.Pi, point.X()/webMercatorRadius*180.0/math.Pi)
+}
+
+// TransverseMercator is the spherical transverse Mercator projection about a central meridian,
+// X east of the meridian and Y north of the equator in meters. Distances and shapes are true near
+// the meridian at any latitude, so it suits fences within a few hundred kilometers of it.
+type TransverseMercator struct {
+	CentralMeridian float64
+}
+
+// Project returns the point's X and Y in meters.
+func (projection TransverseMercator) Project(point *Point) *Point {
+	lat := point.Lat() * math.Pi / 180.0
+	dLng := (point.Lng() - projection.CentralMeridian) * math.Pi / 180.0
+	b := math.Cos(lat) * math.Sin(dLng)
+	radius := EARTH_RADIUS * 1000.0
+	return NewPointXY(radius*math.Atanh(b), radius*math.Atan2(math.Tan(lat), math.Cos(dLng)))
+}
+
+// Unproject returns the lat/lng point at X and Y in meters.
+func (projection TransverseMercator) Unproject(point *Point) *Point {
+	radius := EARTH_RADIUS * 1000.0
+	x, d := point.X()/radius, point.Y()/radius
+	lat := math.Asin(math.Sin(d) / math.Cosh(x))
+	lng := projection.CentralMeridian + math.Atan2(math.Sinh(x), math.Cos(d))*180.0/math.Pi
+	return NewPoint(lat*180.0/math.Pi, lng)
+}
+
+// projected returns whether the geofence, or any of its polygons, has its bounds and tiles
+// on a projected plane rather than in lat/lng.
+func (geofence *Geofence) projected() bool {
+	for _, part := range geofence.polygons() {
+		if part.projection != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// projectionSnapshot returns the name and central meridian MarshalBinary saves for the
+// projection, or an error for a projection other than the built in ones.
+func projectionSnapshot(projection Projection) (string, float64, error) {
+	switch projection := projection.(type) {
+	case nil:
+		return "", 0, nil
+	case WebMercator:
+		return "WebMercator", 0, nil
+	case TransverseMercator:
+		return "TransverseMercator", projection.CentralMeridian, nil
+	default:
+		return "", 0, fmt.Errorf("unable to encode geofence: projection %T is not built in", projection)
+	}
+}
+
+// restoreProjection returns the projection saved by projectionSnapshot.
+func restoreProjection(name string, centralMeridian float64) Projection {
+	switch name {
+	case "WebMercator":
+		return WebMercator{}
+	case "TransverseMercator":
+		return TransverseMercator{CentralMeridian: centralMeridian}
+	}
+	return nil
+}
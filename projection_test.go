@@ -0,0 +1,88 @@
+package geofence
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flippedProjection struct{}
+
+func (flippedProjection) Project(point *Point) *Point   { return NewPointXY(point.Lng(), point.Lat()) }
+func (flippedProjection) Unproject(point *Point) *Point { return NewPoint(point.Y(), point.X()) }
+
+func TestProjections(t *testing.T) {
+	for _, projection := range []Projection{WebMercator{}, TransverseMercator{}, TransverseMercator{CentralMeridian: 25}} {
+		for _, point := range []*Point{NewPoint(0, 0), NewPoint(60.17, 24.94), NewPoint(-33.87, 151.21), NewPoint(78.2, 15.6)} {
+			back := projection.Unproject(projection.Project(point))
+			assert.InDelta(t, point.Lat(), back.Lat(), 1e-9)
+			assert.InDelta(t, point.Lng(), back.Lng(), 1e-9)
+		}
+	}
+
+	// A degree of lng at the equator is about 111km in both
+	assert.InDelta(t, 111319, WebMercator{}.Project(NewPoint(0, 1)).X(), 1)
+	assert.InDelta(t, 111200, TransverseMercator{}.Project(NewPoint(0, 1)).X(), 1)
+	// Transverse Mercator keeps distances true near the central meridian at high latitudes
+	a, b := NewPoint(60, 24.9), NewPoint(60, 25.1)
+	projection := TransverseMercator{CentralMeridian: 25}
+	assert.InDelta(t, a.GreatCircleDistance(b)*1000, projection.Project(b).X()-projection.Project(a).X(), 1)
+}
+
+func TestWithProjection(t *testing.T) {
+	// A diagonal slab across Helsinki, whose edges are straight on the plane but not in lat/lng
+	slab := []*Point{NewPoint(60.0, 24.0), NewPoint(60.1, 24.0), NewPoint(60.4, 25.5), NewPoint(60.3, 25.5)}
+	projection := TransverseMercator{CentralMeridian: 24.75}
+	geofence := NewGeofence(slab, WithProjection(projection))
+
+	projected := make([]*Point, len(slab))
+	for i, point := range slab {
+		projected[i] = projection.Project(point)
+	}
+	polygon := NewPolygon(projected)
+	for i := 0; i < 2000; i++ {
+		point := NewPoint(59.95+rand.Float64()*0.5, 23.9+rand.Float64()*1.7)
+		assert.Equal(t, polygon.Contains(projection.Project(point)), geofence.Inside(point), point)
+	}
+	assert.True(t, geofence.Inside(NewPoint(60.2, 24.75)))
+	assert.False(t, geofence.Inside(NewPoint(60.4, 24.0)))
+
+	// Methods still take and return lat/lng
+	for i, vertex := range geofence.Vertices() {
+		assert.InDelta(t, slab[i].Lat(), vertex.Lat(), 1e-9)
+		assert.InDelta(t, slab[i].Lng(), vertex.Lng(), 1e-9)
+	}
+	assert.Greater(t, geofence.DistanceToBoundary(NewPoint(60.5, 24.0)), 10000.0)
+
+	// WithLocalProjection picks the meridian through the middle of the vertices
+	local := NewGeofence(slab, WithLocalProjection())
+	assert.Equal(t, projection, local.projection)
+	assert.True(t, local.Inside(NewPoint(60.2, 24.75)))
+
+	// The last of WithProjection, WithGeodesic and WithPlanar wins
+	assert.Nil(t, NewGeofence(slab, WithProjection(projection), WithGeodesic()).projection)
+	assert.Nil(t, NewGeofence(slab, WithLocalProjection(), WithPlanar()).projection)
+	assert.False(t, NewGeofence(slab, WithGeodesic(), WithProjection(projection)).geodesic)
+	_, err := NewGeofenceChecked(slab, WithProjection(nil))
+	assert.EqualError(t, err, "projection must not be nil")
+}
+
+func TestWithProjectionMarshalBinary(t *testing.T) {
+	slab := []*Point{NewPoint(60.0, 24.0), NewPoint(60.1, 24.0), NewPoint(60.4, 25.5), NewPoint(60.3, 25.5)}
+	for _, projection := range []Projection{WebMercator{}, TransverseMercator{CentralMeridian: 24.75}} {
+		geofence := NewGeofence(slab, WithProjection(projection))
+		data, err := geofence.MarshalBinary()
+		assert.NoError(t, err)
+		decoded := &Geofence{}
+		assert.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, projection, decoded.projection)
+		assert.True(t, decoded.Inside(NewPoint(60.2, 24.75)))
+		assert.False(t, decoded.Inside(NewPoint(60.4, 24.0)))
+	}
+
+	geofence := NewMultiGeofence([][]*Point{slab}, WithProjection(flippedProjection{}))
+	assert.True(t, geofence.Inside(NewPoint(60.2, 24.75)))
+	_, err := geofence.MarshalBinary()
+	assert.EqualError(t, err, "unable to encode geofence: projection geofence.flippedProjection is not built in")
+}
@@ -0,0 +1,476 @@
+package geofence
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The messages of geofence.proto are encoded and decoded by hand in the protobuf wire format,
+// so the package needs no protobuf runtime. Scalars at their default are left out, as protoc
+// generated code does, and unknown fields are skipped.
+
+// Protobuf wire types.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// Values of the Geofence.Mode and Geofence.Projection enums.
+const (
+	protoLatLng = iota
+	protoGeodesic
+	protoPlanar
+	protoProjected
+	protoLocalProjection
+)
+
+const (
+	protoNoProjection = iota
+	protoWebMercator
+	protoTransverseMercator
+)
+
+// ToProto renders the Geofence as a Geofence message of geofence.proto, with its polygons and
+// the options needed to build it again, as for MarshalJSON. Custom projections cannot be saved.
+func (geofence *Geofence) ToProto() ([]byte, error) {
+	encoded, err := geofence.encodedOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if !geofence.empty() {
+		for _, part := range geofence.polygons() {
+			polygon := appendProtoBytes(nil, 1, protoRing(part.unwrapRing(part.vertices)))
+			for _, hole := range part.holes {
+				polygon = appendProtoBytes(polygon, 2, protoRing(part.unwrapRing(hole)))
+			}
+			data = appendProtoBytes(data, 1, polygon)
+		}
+	}
+	if len(encoded.Granularity) == 2 {
+		data = appendProtoVarint(data, 2, uint64(encoded.Granularity[0]))
+		data = appendProtoVarint(data, 3, uint64(encoded.Granularity[1]))
+	}
+	data = appendProtoBool(data, 4, encoded.AutoGranularity)
+	data = appendProtoDouble(data, 5, encoded.TileSizeMeters)
+	data = appendProtoVarint(data, 6, uint64(int64(encoded.Refinement)))
+	mode, projection := protoLatLng, protoNoProjection
+	switch {
+	case encoded.Geodesic:
+		mode = protoGeodesic
+	case encoded.Planar:
+		mode = protoPlanar
+	case encoded.LocalProjection:
+		mode = protoLocalProjection
+	case encoded.Projection == "WebMercator":
+		mode, projection = protoProjected, protoWebMercator
+	case encoded.Projection == "TransverseMercator":
+		mode, projection = protoProjected, protoTransverseMercator
+	}
+	data = appendProtoVarint(data, 7, uint64(mode))
+	data = appendProtoVarint(data, 8, uint64(projection))
+	data = appendProtoDouble(data, 9, encoded.CentralMeridian)
+	data = appendProtoBool(data, 10, encoded.Boundary == "exclusive")
+	data = appendProtoBool(data, 11, encoded.Containment == "winding")
+	data = appendProtoBool(data, 12, encoded.ExactPredicates)
+	data = appendProtoBool(data, 13, encoded.Rectangle)
+	if encoded.AltitudeRange != nil {
+		altitudes := appendProtoDouble(nil, 1, encoded.AltitudeRange[0])
+		data = appendProtoBytes(data, 14, appendProtoDouble(altitudes, 2, encoded.AltitudeRange[1]))
+	}
+	return data, nil
+}
+
+// protoRing returns the Ring message of the points.
+func protoRing(ring []*Point) []byte {
+	var data []byte
+	for _, point := range ring {
+		var encoded []byte
+		encoded = appendProtoDouble(encoded, 1, point.Lat())
+		encoded = appendProtoDouble(encoded, 2, point.Lng())
+		data = appendProtoBytes(data, 1, encoded)
+	}
+	return data
+}
+
+// NewGeofenceFromProto builds a Geofence from a Geofence message of geofence.proto, as rendered
+// by ToProto, with the options it holds.
+func NewGeofenceFromProto(data []byte) (*Geofence, error) {
+	var encoded geofenceJSON
+	var polygons [][][]*Point
+	var granularity [2]int
+	mode, projection := 0, 0
+	err := readProto(data, func(field int, value uint64, bytes []byte) error {
+		switch field {
+		case 1:
+			polygon, err := readProtoPolygon(bytes)
+			if err != nil {
+				return fmt.Errorf("polygon %d: %v", len(polygons), err)
+			}
+			polygons = append(polygons, polygon)
+		case 2:
+			granularity[0] = int(int32(value))
+		case 3:
+			granularity[1] = int(int32(value))
+		case 4:
+			encoded.AutoGranularity = value != 0
+		case 5:
+			encoded.TileSizeMeters = math.Float64frombits(value)
+		case 6:
+			encoded.Refinement = int(int32(value))
+		case 7:
+			mode = int(value)
+		case 8:
+			projection = int(value)
+		case 9:
+			encoded.CentralMeridian = math.Float64frombits(value)
+		case 10:
+			if value != 0 {
+				encoded.Boundary = "exclusive"
+			}
+		case 11:
+			if value != 0 {
+				encoded.Containment = "winding"
+			}
+		case 12:
+			encoded.ExactPredicates = value != 0
+		case 13:
+			encoded.Rectangle = value != 0
+		case 14:
+			encoded.AltitudeRange = make([]float64, 2)
+			return readProto(bytes, func(field int, value uint64, bytes []byte) error {
+				if field == 1 || field == 2 {
+					encoded.AltitudeRange[field-1] = math.Float64frombits(value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode geofence: %v", err)
+	}
+
+	if granularity != [2]int{} {
+		encoded.Granularity = granularity[:]
+	}
+	switch mode {
+	case protoLatLng:
+	case protoGeodesic:
+		encoded.Geodesic = true
+	case protoPlanar:
+		encoded.Planar = true
+	case protoLocalProjection:
+		encoded.LocalProjection = true
+	case protoProjected:
+		switch projection {
+		case protoWebMercator:
+			encoded.Projection = "WebMercator"
+		case protoTransverseMercator:
+			encoded.Projection = "TransverseMercator"
+		default:
+			return nil, fmt.Errorf("unable to decode geofence: unknown projection %d", projection)
+		}
+	default:
+		return nil, fmt.Errorf("unable to decode geofence: unknown mode %d", mode)
+	}
+	opts, err := encoded.options()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode geofence: %v", err)
+	}
+
+	switch len(polygons) {
+	case 0:
+		return NewGeofence(nil, opts...), nil
+	case 1:
+		return encoded.restoreRectangle(NewGeofenceWithHoles(polygons[0][0], polygons[0][1:], opts...), opts), nil
+	}
+	parts := make([]*Geofence, len(polygons))
+	for i, polygon := range polygons {
+		parts[i] = NewGeofenceWithHoles(polygon[0], polygon[1:], opts...)
+	}
+	return newMultiGeofence(parts), nil
+}
+
+// FromProto builds a Geofence from a Geofence message, it is the same as NewGeofenceFromProto.
+func FromProto(data []byte) (*Geofence, error) {
+	return NewGeofenceFromProto(data)
+}
+
+// readProtoPolygon returns the outer ring followed by the holes of a Polygon message.
+func readProtoPolygon(data []byte) ([][]*Point, error) {
+	rings := [][]*Point{nil}
+	err := readProto(data, func(field int, value uint64, bytes []byte) error {
+		if field != 1 && field != 2 {
+			return nil
+		}
+		var ring []*Point
+		err := readProto(bytes, func(field int, value uint64, bytes []byte) error {
+			if field != 1 {
+				return nil
+			}
+			var lat, lng float64
+			err := readProto(bytes, func(field int, value uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					lat = math.Float64frombits(value)
+				case 2:
+					lng = math.Float64frombits(value)
+				}
+				return nil
+			})
+			ring = append(ring, NewPoint(lat, lng))
+			return err
+		})
+		if field == 1 {
+			rings[0] = ring
+		} else {
+			rings = append(rings, ring)
+		}
+		return err
+	})
+	if err == nil && len(rings[0]) == 0 {
+		err = fmt.Errorf("polygon has no outer ring")
+	}
+	return rings, err
+}
+
+// GroupToProto renders the GeofenceGroup as a Group message of geofence.proto, with its index
+// options and each key with its whitelist and blacklist geofences, sorted by key, their expiry
+// times if they were added by AddWithExpiry, and the key's priority. Geofences that have already
+// expired are left out. Metadata, which has no protobuf form, is not rendered.
+func GroupToProto(group *GeofenceGroup[string]) ([]byte, error) {
+	group.rLockUnexpired()
+	defer group.mu.RUnlock()
+
+	var data []byte
+	data = appendProtoVarint(data, 1, uint64(group.options.s2Level))
+	data = appendProtoVarint(data, 2, uint64(group.options.geohashPrecision))
+	keys := make([]string, 0, len(group.entries))
+	for key := range group.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := group.entries[key]
+		encoded := appendProtoBytes(nil, 1, []byte(key))
+		for i, list := range [][]*Geofence{entry.whitelist, entry.blacklist} {
+			for _, geofence := range list {
+				fence, err := geofence.ToProto()
+				if err != nil {
+					return nil, err
+				}
+				encoded = appendProtoBytes(encoded, 2+i, fence)
+			}
+		}
+		for i, expires := range [][]time.Time{entry.whitelistExpires, entry.blacklistExpires} {
+			if expires != nil {
+				encoded = appendProtoBytes(encoded, 4+i, protoExpiries(expires))
+			}
+		}
+		encoded = appendProtoVarint(encoded, 6, uint64(entry.priority))
+		data = appendProtoBytes(data, 3, encoded)
+	}
+	return data, nil
+}
+
+// NewGeofenceGroupFromProto builds a GeofenceGroup from a Group message of geofence.proto, as
+// rendered by GroupToProto.
+func NewGeofenceGroupFromProto(data []byte) (*GeofenceGroup[string], error) {
+	var opts []GroupOption
+	entries := make(map[string]*groupEntry)
+	err := readProto(data, func(field int, value uint64, bytes []byte) error {
+		switch field {
+		case 1:
+			if value != 0 {
+				opts = append(opts, WithS2Index(int(int32(value))))
+			}
+		case 2:
+			if value != 0 {
+				opts = append(opts, WithGeohashIndex(int(int32(value))))
+			}
+		case 3:
+			var key string
+			entry := &groupEntry{}
+			err := readProto(bytes, func(field int, value uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					key = string(bytes)
+				case 2, 3:
+					geofence, err := NewGeofenceFromProto(bytes)
+					if err != nil {
+						return err
+					}
+					if field == 2 {
+						entry.whitelist = append(entry.whitelist, geofence)
+					} else {
+						entry.blacklist = append(entry.blacklist, geofence)
+					}
+				case 6:
+					entry.priority = int(int64(value))
+				case 4, 5:
+					expires, err := readProtoExpiries(value, bytes)
+					if err != nil {
+						return err
+					}
+					if field == 4 {
+						entry.whitelistExpires = append(entry.whitelistExpires, expires...)
+					} else {
+						entry.blacklistExpires = append(entry.blacklistExpires, expires...)
+					}
+				}
+				return nil
+			})
+			if err == nil && ((entry.whitelistExpires != nil && len(entry.whitelistExpires) != len(entry.whitelist)) ||
+				(entry.blacklistExpires != nil && len(entry.blacklistExpires) != len(entry.blacklist))) {
+				err = fmt.Errorf("must have an expiry time for each geofence")
+			}
+			if err != nil {
+				return fmt.Errorf("key %q: %v", key, err)
+			}
+			if _, ok := entries[key]; ok {
+				return fmt.Errorf("key %q appears more than once", key)
+			}
+			entries[key] = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode geofence group: %v", err)
+	}
+
+	group := NewGeofenceGroup[string]()
+	for _, opt := range opts {
+		if err := opt(&group.options); err != nil {
+			return nil, fmt.Errorf("unable to decode geofence group: %v", err)
+		}
+	}
+	group.entries, group.dirty = entries, true
+	for _, entry := range entries {
+		group.scheduleExpiry(entry)
+	}
+	return group, nil
+}
+
+// protoExpiries packs the expiry times as Unix nanoseconds, 0 for never.
+func protoExpiries(expires []time.Time) []byte {
+	var packed []byte
+	for _, expiry := range expires {
+		var nanos int64
+		if !expiry.IsZero() {
+			nanos = expiry.UnixNano()
+		}
+		packed = appendUvarint(packed, uint64(nanos))
+	}
+	return packed
+}
+
+// readProtoExpiries returns the expiry times of a packed field, or of a single unpacked value
+// when bytes is nil.
+func readProtoExpiries(value uint64, bytes []byte) ([]time.Time, error) {
+	values := []uint64{value}
+	if bytes != nil {
+		values = values[:0]
+		for len(bytes) > 0 {
+			value, n := binary.Uvarint(bytes)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid protobuf varint in expiry times")
+			}
+			values, bytes = append(values, value), bytes[n:]
+		}
+	}
+	expires := make([]time.Time, len(values))
+	for i, value := range values {
+		if value != 0 {
+			expires[i] = time.Unix(0, int64(value))
+		}
+	}
+	return expires, nil
+}
+
+// readProto calls fn with each field of the message: the value of varint and fixed fields, or
+// the bytes of length delimited ones.
+func readProto(data []byte, fn func(field int, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid protobuf tag")
+		}
+		data = data[n:]
+		field := int(tag >> 3)
+		var value uint64
+		var bytes []byte
+		switch tag & 7 {
+		case protoVarint:
+			if value, n = binary.Uvarint(data); n <= 0 {
+				return fmt.Errorf("invalid protobuf varint in field %d", field)
+			}
+			data = data[n:]
+		case protoFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("protobuf field %d is truncated", field)
+			}
+			value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case protoFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("protobuf field %d is truncated", field)
+			}
+			value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case protoBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return fmt.Errorf("protobuf field %d is truncated", field)
+			}
+			bytes, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d in field %d", tag&7, field)
+		}
+		if err := fn(field, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendProtoTag(data []byte, field, wireType int) []byte {
+	return appendUvarint(data, uint64(field)<<3|uint64(wireType))
+}
+
+func appendUvarint(data []byte, value uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], value)]...)
+}
+
+func appendProtoVarint(data []byte, field int, value uint64) []byte {
+	if value == 0 {
+		return data
+	}
+	return appendUvarint(appendProtoTag(data, field, protoVarint), value)
+}
+
+func appendProtoBool(data []byte, field int, value bool) []byte {
+	if !value {
+		return data
+	}
+	return appendProtoVarint(data, field, 1)
+}
+
+func appendProtoDouble(data []byte, field int, value float64) []byte {
+	if value == 0 && !math.Signbit(value) {
+		return data
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(value))
+	return append(appendProtoTag(data, field, protoFixed64), buf[:]...)
+}
+
+// appendProtoBytes appends a length delimited field, even when empty, as embedded messages are.
+func appendProtoBytes(data []byte, field int, value []byte) []byte {
+	return append(appendUvarint(appendProtoTag(data, field, protoBytes), uint64(len(value))), value...)
+}
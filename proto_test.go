@@ -0,0 +1,119 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofenceProto(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(5, 14), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+
+	// A Ring of one Point{lat: 1, lng: 2}
+	assert.Equal(t, []byte{0x0a, 0x12, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x11, 0, 0, 0, 0, 0, 0, 0, 0x40}, protoRing([]*Point{NewPoint(1, 2)}))
+
+	for _, original := range []*Geofence{
+		NewGeofenceWithHoles(outer, [][]*Point{hole}, WithGranularityXY(40, 30), WithBoundary(Exclusive)),
+		NewGeofence(outer, WithAutoGranularity(), WithGeodesic(), WithContainment(WindingNumber)),
+		NewGeofence(outer, WithTileSizeMeters(5000), WithProjection(TransverseMercator{CentralMeridian: 5})),
+		NewGeofence(outer, WithProjection(WebMercator{})),
+		NewGeofence(outer, WithLocalProjection(), WithExactPredicates(), WithRefinement(2)),
+		NewGeofence(outer, WithPlanar(), WithGranularity(8), WithAltitudeRange(-5, 120)),
+		NewBBoxGeofence(-1, 179, 1, -179),
+		NewMultiGeofence([][]*Point{outer, {NewPoint(20, 20), NewPoint(20, 21), NewPoint(21, 21)}}, WithGranularity(10)),
+		NewGeofence(nil),
+	} {
+		data, err := original.ToProto()
+		assert.NoError(t, err)
+		decoded, err := NewGeofenceFromProto(data)
+		if assert.NoError(t, err) {
+			want, got := original.template(), decoded.template()
+			assert.Equal(t, []interface{}{want.granularityX, want.granularityY, want.autoGrid, want.tileMeters, want.refinement, want.geodesic, want.planar,
+				want.projection, want.localProj, want.boundary, want.containment, want.exact, want.rect, want.altitudeRange, want.minAltitude, want.maxAltitude},
+				[]interface{}{got.granularityX, got.granularityY, got.autoGrid, got.tileMeters, got.refinement, got.geodesic, got.planar,
+					got.projection, got.localProj, got.boundary, got.containment, got.exact, got.rect, got.altitudeRange, got.minAltitude, got.maxAltitude})
+			assert.Equal(t, len(original.polygons()), len(decoded.polygons()))
+			assert.Equal(t, original.Holes(), decoded.Holes())
+			assert.True(t, original.Equal(decoded))
+			assert.Equal(t, original.Inside(NewPoint(5, 5)), decoded.Inside(NewPoint(5, 5)))
+		}
+	}
+
+	// Unknown fields, as from a newer schema, are skipped
+	data, err := NewGeofence(outer).ToProto()
+	assert.NoError(t, err)
+	data = append(data, 0xa0, 0x06, 0x01, 0xaa, 0x06, 0x02, 0x01, 0x02, 0xad, 0x06, 1, 2, 3, 4)
+	decoded, err := FromProto(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, NewGeofence(outer).Vertices(), decoded.Vertices())
+	}
+
+	for _, bad := range [][]byte{
+		data[:len(data)-20],
+		{0x38, 0x09},
+		{0x38, 0x03},
+		{0x10, 0x05},
+		{0x0a, 0x00},
+		{0x0b},
+	} {
+		_, err := NewGeofenceFromProto(bad)
+		assert.Error(t, err, "%x", bad)
+	}
+
+	_, err = NewGeofence(outer, WithProjection(flippedProjection{})).ToProto()
+	assert.Error(t, err)
+}
+
+func TestGeofenceGroupProto(t *testing.T) {
+	square := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, WithGranularity(5))
+	inner := NewGeofence([]*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)})
+	group := NewGeofenceGroup[string](WithGeohashIndex(4))
+	group.Add("b", []*Geofence{square}, []*Geofence{inner})
+	group.Add("a", nil, []*Geofence{inner})
+
+	data, err := GroupToProto(group)
+	assert.NoError(t, err)
+	again, err := GroupToProto(group)
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	decoded, err := NewGeofenceGroupFromProto(data)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{"a", "b"}, decoded.Keys())
+		assert.Equal(t, 4, decoded.options.geohashPrecision)
+		for _, point := range []*Point{NewPoint(1, 1), NewPoint(5, 5), NewPoint(20, 20)} {
+			assert.Equal(t, group.GetValidKeys(point), decoded.GetValidKeys(point))
+		}
+	}
+
+	// Expiry times of geofences added by AddWithExpiry
+	expires := time.Now().Add(time.Hour)
+	group.AddWithExpiry("b", nil, []*Geofence{square}, expires)
+	group.AddWithExpiry("c", []*Geofence{square}, nil, time.Now().Add(-time.Second))
+	assert.NoError(t, group.SetPriority("b", -2))
+	data, err = GroupToProto(group)
+	assert.NoError(t, err)
+	decoded, err = NewGeofenceGroupFromProto(data)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{"a", "b"}, decoded.Keys())
+		if assert.Len(t, decoded.entries["b"].blacklistExpires, 2) {
+			assert.True(t, decoded.entries["b"].blacklistExpires[0].IsZero())
+			assert.True(t, expires.Equal(decoded.entries["b"].blacklistExpires[1]))
+		}
+		assert.True(t, expires.Equal(decoded.nextExpiry))
+		priority, _ := decoded.Priority("b")
+		assert.Equal(t, -2, priority)
+	}
+
+	for _, bad := range [][]byte{
+		{0x08, 0x63},
+		{0x1a, 0x05, 0x0a, 0x01, 'a', 0x20, 0x01},
+		{0x1a, 0x03, 0x0a, 0x01, 'a', 0x1a, 0x03, 0x0a, 0x01, 'a'},
+		{0x1a, 0x04, 0x0a, 0x01, 'a', 0x12},
+	} {
+		_, err := NewGeofenceGroupFromProto(bad)
+		assert.Error(t, err, "%x", bad)
+	}
+}
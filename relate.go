@@ -0,0 +1,127 @@
+package geofence
+
+import (
+	"math"
+)
+
+// relateTolerance is the share of the smaller geofence's area below which an overlap or a part
+// left uncovered is taken to be an artifact of rounding along shared edges.
+const relateTolerance = 1e-9
+
+// equalToleranceMeters is how far apart, on average along their edges, the boundaries of equal
+// geofences can be, e.g. after a round trip through a format that rounds coordinates.
+const equalToleranceMeters = 0.01
+
+// Equal returns whether the geofences cover the same points, within about a centimeter along
+// their edges, or a billionth of their size for WithPlanar geofences. The vertices need not
+// match: a ring may start at another vertex, wind the other way or have extra vertices along
+// its edges. Geofences without vertices are equal to each other.
+func (geofence *Geofence) Equal(other *Geofence) bool {
+	if geofence.empty() || other.empty() {
+		return geofence.empty() && other.empty()
+	}
+	if geofence.Area() == 0 || other.Area() == 0 {
+		return geofence.Contains(other) && other.Contains(geofence)
+	}
+	tolerance := equalToleranceMeters
+	if geofence.template().planar {
+		tolerance = 1e-9 * math.Max(math.Max(geofence.maxX-geofence.minX, geofence.maxY-geofence.minY), math.Max(other.maxX-other.minX, other.maxY-other.minY))
+	}
+	// The area inside one but not the other is about the distance between the boundaries
+	// times their length
+	difference := overlayArea([]*Geofence{geofence, other}, func(inside []bool) bool { return inside[0] != inside[1] })
+	return difference <= tolerance*(geofence.Perimeter()+other.Perimeter())/2
+}
+
+// Intersects returns whether the geofences share any point, including points on their edges, so
+// geofences that only touch intersect. Geofences are compared on a common plane as for Union.
+func (geofence *Geofence) Intersects(other *Geofence) bool {
+	if geofence.empty() || other.empty() {
+		return false
+	}
+	plane := newOverlayPlane(newMultiGeofence(append(append([]*Geofence{}, geofence.polygons()...), other.polygons()...)))
+	rings, otherRings := plane.polygonRings(geofence), plane.polygonRings(other)
+
+	// A vertex of either inside or on the edge of the other, or else edges that cross
+	fence, otherFence := planeGeofence(rings), planeGeofence(otherRings)
+	for _, polygon := range rings {
+		for _, ring := range polygon {
+			for _, point := range ring {
+				if otherFence.Inside(point) {
+					return true
+				}
+			}
+		}
+	}
+	for _, polygon := range otherRings {
+		for _, ring := range polygon {
+			for _, point := range ring {
+				if fence.Inside(point) {
+					return true
+				}
+			}
+		}
+	}
+	var segments, otherSegments []planeSegment
+	for _, polygon := range rings {
+		segments = append(segments, ringSegments(polygon)...)
+	}
+	for _, polygon := range otherRings {
+		otherSegments = append(otherSegments, ringSegments(polygon)...)
+	}
+	for _, s := range segments {
+		for _, o := range otherSegments {
+			if math.Max(s.ax, s.bx) < math.Min(o.ax, o.bx) || math.Min(s.ax, s.bx) > math.Max(o.ax, o.bx) ||
+				math.Max(s.ay, s.by) < math.Min(o.ay, o.by) || math.Min(s.ay, s.by) > math.Max(o.ay, o.by) {
+				continue
+			}
+			if t, _ := segmentCrossings(s, o); len(t) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Overlaps returns whether the geofences share some area, e.g. to keep exclusive zones apart.
+// Unlike Intersects, geofences that only touch along an edge or at a vertex do not overlap.
+func (geofence *Geofence) Overlaps(other *Geofence) bool {
+	if geofence.empty() || other.empty() {
+		return false
+	}
+	return overlayArea([]*Geofence{geofence, other}, insideAll) > relateTolerance*math.Min(geofence.Area(), other.Area())
+}
+
+// Contains returns whether every point of the other geofence is inside this one, edges included,
+// so a geofence contains itself. A geofence without vertices contains nothing.
+func (geofence *Geofence) Contains(other *Geofence) bool {
+	if geofence.empty() || other.empty() {
+		return false
+	}
+	if area := other.Area(); area > 0 {
+		return overlayArea([]*Geofence{other, geofence}, insideFirstOnly) <= relateTolerance*area
+	}
+	// Without area, only the vertices can be checked
+	for _, part := range other.polygons() {
+		for _, ring := range part.rings() {
+			for _, point := range part.unwrapRing(ring) {
+				if !geofence.Inside(point) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// overlayArea returns the area on the common plane of the points where keep is true of whether
+// each fence contains them.
+func overlayArea(fences []*Geofence, keep func(inside []bool) bool) float64 {
+	_, rings, _ := overlayFences(fences, keep)
+	area := 0.0
+	for _, ring := range rings {
+		// Holes wind clockwise, so their areas are taken away
+		area += ringArea(ring)
+	}
+	return area
+}
@@ -0,0 +1,94 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelate(t *testing.T) {
+	rectangle := func(minX, minY, maxX, maxY float64) *Geofence {
+		return NewGeofence([]*Point{NewPointXY(minX, minY), NewPointXY(maxX, minY), NewPointXY(maxX, maxY), NewPointXY(minX, maxY)}, WithPlanar())
+	}
+	a := rectangle(0, 0, 10, 10)
+	overlapping := rectangle(5, 5, 15, 15)
+	inner := rectangle(2, 2, 4, 4)
+	beside := rectangle(10, 0, 20, 10)
+	corner := rectangle(10, 10, 12, 12)
+	apart := rectangle(20, 20, 25, 25)
+	// Crossing without either having a vertex inside the other
+	cross := rectangle(-1, 4, 11, 6)
+
+	for _, other := range []*Geofence{overlapping, inner, beside, corner, cross} {
+		assert.True(t, a.Intersects(other))
+		assert.True(t, other.Intersects(a))
+	}
+	assert.False(t, a.Intersects(apart))
+	assert.False(t, a.Intersects(NewGeofence(nil)))
+
+	assert.True(t, a.Overlaps(overlapping))
+	assert.True(t, a.Overlaps(inner))
+	assert.True(t, a.Overlaps(cross))
+	assert.False(t, a.Overlaps(beside))
+	assert.False(t, a.Overlaps(corner))
+	assert.False(t, a.Overlaps(apart))
+
+	assert.True(t, a.Contains(inner))
+	assert.True(t, a.Contains(a))
+	assert.True(t, a.Contains(rectangle(0, 0, 10, 5)))
+	assert.False(t, inner.Contains(a))
+	assert.False(t, a.Contains(overlapping))
+	assert.False(t, a.Contains(cross))
+	assert.False(t, a.Contains(NewGeofence(nil)))
+
+	// A geofence in a hole is neither contained nor overlapping, but touches at the hole's edge
+	holed := NewGeofenceWithHoles([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 10), NewPointXY(0, 10)},
+		[][]*Point{{NewPointXY(1, 1), NewPointXY(5, 1), NewPointXY(5, 5), NewPointXY(1, 5)}}, WithPlanar())
+	assert.False(t, holed.Contains(inner))
+	assert.False(t, holed.Overlaps(inner))
+	assert.False(t, holed.Intersects(inner))
+	assert.False(t, holed.Contains(rectangle(0.5, 0.5, 3, 3)))
+	assert.True(t, holed.Overlaps(rectangle(0.5, 0.5, 3, 3)))
+	assert.True(t, holed.Intersects(rectangle(1, 1, 3, 3)))
+	assert.False(t, holed.Overlaps(rectangle(1, 1, 3, 3)))
+	assert.True(t, holed.Contains(rectangle(6, 6, 9, 9)))
+	assert.True(t, a.Contains(holed))
+	assert.False(t, holed.Contains(a))
+
+	// Each polygon of a multi polygon geofence counts
+	multi := NewMultiGeofence([][]*Point{inner.Vertices(), apart.Vertices()}, WithPlanar())
+	assert.True(t, multi.Intersects(a))
+	assert.False(t, a.Contains(multi))
+	assert.True(t, multi.Contains(apart))
+
+	// Geographic geofences, across the antimeridian
+	fiji := NewGeofence([]*Point{NewPoint(-19, 177), NewPoint(-19, -179), NewPoint(-15, -179), NewPoint(-15, 177)})
+	east := NewGeofence([]*Point{NewPoint(-18, -179.5), NewPoint(-18, -178), NewPoint(-16, -178), NewPoint(-16, -179.5)})
+	island := NewGeodesicCircleGeofence(NewPoint(-17, 179), 20000, 32)
+	assert.True(t, fiji.Overlaps(east))
+	assert.False(t, fiji.Contains(east))
+	assert.True(t, fiji.Contains(island))
+	assert.False(t, island.Intersects(east))
+}
+
+func TestEqual(t *testing.T) {
+	square := NewGeofence([]*Point{NewPoint(51.5, -0.1), NewPoint(51.5, -0.09), NewPoint(51.51, -0.09), NewPoint(51.51, -0.1)})
+	// Starting at another vertex, the other way round, with a vertex along an edge and rounded
+	reordered := NewGeofence([]*Point{NewPoint(51.51, -0.09), NewPoint(51.5, -0.09), NewPoint(51.5, -0.095), NewPoint(51.5, -0.1), NewPoint(51.51, -0.1)})
+	rounded := NewGeofence([]*Point{NewPoint(51.5000001, -0.1), NewPoint(51.5, -0.0899999), NewPoint(51.51, -0.09), NewPoint(51.51, -0.1000001)})
+	assert.True(t, square.Equal(square))
+	assert.True(t, square.Equal(reordered))
+	assert.True(t, reordered.Equal(square))
+	assert.True(t, square.Equal(rounded))
+	assert.True(t, square.Equal(square.Clone()))
+
+	moved := NewGeofence([]*Point{NewPoint(51.5, -0.1), NewPoint(51.5, -0.09), NewPoint(51.5101, -0.09), NewPoint(51.5101, -0.1)})
+	assert.False(t, square.Equal(moved))
+	assert.False(t, square.Equal(NewGeofenceWithHoles(square.Vertices(), [][]*Point{{NewPoint(51.504, -0.096), NewPoint(51.504, -0.094), NewPoint(51.506, -0.094)}})))
+	assert.False(t, square.Equal(NewGeofence(nil)))
+	assert.True(t, NewGeofence(nil).Equal(NewMultiGeofence(nil)))
+
+	planar := NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 10)}, WithPlanar())
+	assert.True(t, planar.Equal(NewGeofence([]*Point{NewPointXY(10, 10), NewPointXY(0, 0), NewPointXY(10, 0)}, WithPlanar())))
+	assert.False(t, planar.Equal(NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(10, 0), NewPointXY(10, 10.001)}, WithPlanar())))
+}
@@ -0,0 +1,292 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+)
+
+// S2MaxLevel is the level of the smallest S2 cells, about 1cm across.
+const S2MaxLevel = 30
+
+// The Hilbert curve tables of the S2 library. Each cell is split into four children, numbered
+// along the curve, whose orientation (swapped and/or inverted axes) depends on the position.
+var (
+	s2IJToPos          = [4][4]int{{0, 1, 3, 2}, {0, 3, 1, 2}, {2, 3, 1, 0}, {2, 1, 3, 0}}
+	s2PosToIJ          = [4][4]int{{0, 1, 3, 2}, {0, 2, 3, 1}, {3, 2, 0, 1}, {3, 1, 0, 2}}
+	s2PosToOrientation = [4]int{1, 0, 0, 3}
+)
+
+// The leaf cells at the poles, whose ancestors span every longitude.
+var (
+	s2NorthPole = s2LeafID(NewPoint(90, 0))
+	s2SouthPole = s2LeafID(NewPoint(-90, 0))
+)
+
+// s2Cell is a cell of the S2 hierarchy: a face of the cube around the Earth, its i and j
+// coordinates at the cell's level, and its orientation along the Hilbert curve.
+type s2Cell struct {
+	id          uint64
+	face        int
+	level       int
+	i, j        uint64
+	orientation int
+}
+
+// S2CellID returns the ID of the S2 cell at the level, 0 to S2MaxLevel, containing the point,
+// as used by the S2 geometry library and databases such as BigQuery and Spanner. Levels outside
+// the range are clamped.
+func S2CellID(point *Point, level int) uint64 {
+	return s2Parent(s2LeafID(point), clampLevel(level, S2MaxLevel))
+}
+
+// s2LeafID returns the ID of the leaf cell containing the point.
+func s2LeafID(point *Point) uint64 {
+	face, u, v := s2FaceUV(toVector3(point))
+	i, j := s2STToIJ(s2UVToST(u)), s2STToIJ(s2UVToST(v))
+
+	orientation := face & 1
+	pos := uint64(0)
+	for k := S2MaxLevel - 1; k >= 0; k-- {
+		p := s2IJToPos[orientation][int((i>>k)&1)<<1|int((j>>k)&1)]
+		pos = pos<<2 | uint64(p)
+		orientation ^= s2PosToOrientation[p]
+	}
+	return uint64(face)<<61 | pos<<1 | 1
+}
+
+// s2Parent returns the ID of the ancestor of the cell at the level.
+func s2Parent(id uint64, level int) uint64 {
+	lsb := uint64(1) << (2 * (S2MaxLevel - level))
+	return id&-lsb | lsb
+}
+
+// s2Level returns the level of the cell.
+func s2Level(id uint64) int {
+	level := S2MaxLevel
+	for id&1 == 0 {
+		id >>= 2
+		level--
+	}
+	return level
+}
+
+// s2FaceUV returns the face of the cube the vector points through and its u, v coordinates
+// on the face, from -1 to 1.
+func s2FaceUV(p vector3) (int, float64, float64) {
+	ax, ay, az := math.Abs(p.x), math.Abs(p.y), math.Abs(p.z)
+	switch {
+	case ax >= ay && ax >= az && p.x >= 0:
+		return 0, p.y / p.x, p.z / p.x
+	case ax >= ay && ax >= az:
+		return 3, p.z / p.x, p.y / p.x
+	case ay >= az && p.y >= 0:
+		return 1, -p.x / p.y, p.z / p.y
+	case ay >= az:
+		return 4, p.z / p.y, -p.x / p.y
+	case p.z >= 0:
+		return 2, -p.x / p.z, -p.y / p.z
+	default:
+		return 5, -p.y / p.z, -p.x / p.z
+	}
+}
+
+// s2FaceUVToVector returns the (unnormalized) vector through u, v on the face.
+func s2FaceUVToVector(face int, u, v float64) vector3 {
+	switch face {
+	case 0:
+		return vector3{1, u, v}
+	case 1:
+		return vector3{-u, 1, v}
+	case 2:
+		return vector3{-u, -v, 1}
+	case 3:
+		return vector3{-1, -v, -u}
+	case 4:
+		return vector3{v, -1, -u}
+	default:
+		return vector3{v, u, -1}
+	}
+}
+
+// s2UVToST applies S2's quadratic transform, which makes cells at a level closer in size.
+func s2UVToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+func s2STToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
+
+// s2STToIJ returns the leaf cell coordinate of s, from 0 to 2^30 - 1.
+func s2STToIJ(s float64) uint64 {
+	const size = 1 << S2MaxLevel
+	return uint64(math.Max(0, math.Min(size-1, math.Floor(s*size))))
+}
+
+// s2FaceCell returns the level 0 cell of the face.
+func s2FaceCell(face int) s2Cell {
+	return s2Cell{id: uint64(face)<<61 | 1<<60, face: face, orientation: face & 1}
+}
+
+// children returns the four cells the cell is split into, in Hilbert curve order.
+func (cell s2Cell) children() [4]s2Cell {
+	var children [4]s2Cell
+	lsb := uint64(1) << (2 * (S2MaxLevel - cell.level))
+	childLsb := lsb >> 2
+	for pos := 0; pos < 4; pos++ {
+		ij := s2PosToIJ[cell.orientation][pos]
+		children[pos] = s2Cell{
+			id:          cell.id - lsb + childLsb + uint64(pos)*2*childLsb,
+			face:        cell.face,
+			level:       cell.level + 1,
+			i:           cell.i<<1 | uint64(ij>>1),
+			j:           cell.j<<1 | uint64(ij&1),
+			orientation: cell.orientation ^ s2PosToOrientation[pos],
+		}
+	}
+	return children
+}
+
+// rects returns lat/lng rectangles covering the cell: one, or two for a cell crossing the
+// antimeridian. The cell's edges are great circle arcs, so they are sampled and the rectangles
+// widened a little, erring towards covering too much.
+func (cell s2Cell) rects() [][4]float64 {
+	// Edges of small cells are close to straight in lat/lng
+	samples := 2
+	if cell.level < 6 {
+		samples = 8
+	}
+	size := math.Ldexp(1, -cell.level)
+	s0, t0 := float64(cell.i)*size, float64(cell.j)*size
+
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	var lngs []float64
+	for k := 0; k < 4*samples; k++ {
+		// Walk round the cell's edges in s, t
+		f := float64(k%samples) / float64(samples)
+		s, t := [4]float64{f, 1, 1 - f, 0}[k/samples], [4]float64{0, f, 1, 1 - f}[k/samples]
+		point := s2FaceUVToVector(cell.face, s2STToUV(s0+s*size), s2STToUV(t0+t*size)).toPoint()
+		minLat, maxLat = math.Min(minLat, point.Lat()), math.Max(maxLat, point.Lat())
+		lngs = append(lngs, point.Lng())
+	}
+	latMargin := (maxLat-minLat)*0.1 + 1e-9
+
+	// Cells around a pole span every longitude, and reach the pole
+	if s2Parent(s2NorthPole, cell.level) == cell.id {
+		return [][4]float64{{math.Max(-90, minLat-latMargin), -180, 90, 180}}
+	}
+	if s2Parent(s2SouthPole, cell.level) == cell.id {
+		return [][4]float64{{-90, -180, math.Min(90, maxLat+latMargin), 180}}
+	}
+
+	sort.Float64s(lngs)
+	// The rectangle spans the longitudes apart from the largest gap between them
+	gap, gapIndex := lngs[0]+360-lngs[len(lngs)-1], len(lngs)-1
+	for i := 0; i+1 < len(lngs); i++ {
+		if lngs[i+1]-lngs[i] > gap {
+			gap, gapIndex = lngs[i+1]-lngs[i], i
+		}
+	}
+	minLng, maxLng := lngs[(gapIndex+1)%len(lngs)], lngs[gapIndex]
+	span := maxLng - minLng
+	if span < 0 {
+		span += 360
+	}
+	lngMargin := span*0.1 + 1e-9
+	minLat, maxLat = math.Max(-90, minLat-latMargin), math.Min(90, maxLat+latMargin)
+	minLng, maxLng = minLng-lngMargin, maxLng+lngMargin
+
+	var rects [][4]float64
+	if minLng < -180 {
+		rects = append(rects, [4]float64{minLat, minLng + 360, maxLat, 180})
+		minLng = -180
+	}
+	if maxLng > 180 {
+		rects = append(rects, [4]float64{minLat, -180, maxLat, maxLng - 360})
+		maxLng = 180
+	}
+	if minLng <= maxLng {
+		rects = append(rects, [4]float64{minLat, minLng, maxLat, maxLng})
+	} else {
+		// The cell crosses the antimeridian
+		rects = append(rects, [4]float64{minLat, minLng, maxLat, 180}, [4]float64{minLat, -180, maxLat, maxLng})
+	}
+	return rects
+}
+
+// S2Covering returns the IDs of S2 cells, no smaller than the level, that together cover the
+// geofence, in increasing order. Cells wholly inside the geofence are kept as large as possible,
+// so the covering mixes levels, as normalized S2 coverings do. Cells are tested against the
+// geofence's tiling rings, so they may include a little beyond the boundary but never miss part
+// of the geofence. A geofence without vertices returns nil.
+func (geofence *Geofence) S2Covering(level int) []uint64 {
+	return geofence.s2Covering(clampLevel(level, S2MaxLevel), 0)
+}
+
+// s2Covering returns the covering at the level, stopping short of it when maxCells is positive
+// and splitting the boundary cells any further would need more cells than that.
+func (geofence *Geofence) s2Covering(level, maxCells int) []uint64 {
+	if geofence.empty() {
+		return nil
+	}
+	classifier := newRectClassifier(geofence)
+
+	var covering []uint64
+	var frontier []s2Cell
+	for face := 0; face < 6; face++ {
+		frontier = append(frontier, s2FaceCell(face))
+	}
+	for len(frontier) > 0 {
+		var boundary []s2Cell
+		for _, cell := range frontier {
+			switch classifier.classifyCell(cell) {
+			case TILE_IN:
+				covering = append(covering, cell.id)
+			case TILE_EITHER:
+				boundary = append(boundary, cell)
+			}
+		}
+		frontier = nil
+		for _, cell := range boundary {
+			if cell.level == level || (maxCells > 0 && len(covering)+4*len(boundary) > maxCells) {
+				covering = append(covering, cell.id)
+				continue
+			}
+			children := cell.children()
+			frontier = append(frontier, children[:]...)
+		}
+	}
+	sort.Slice(covering, func(i, j int) bool { return covering[i] < covering[j] })
+	return covering
+}
+
+// classifyCell classifies the cell by the rectangles around it, which must all agree for the
+// cell to be inside or outside.
+func (classifier *rectClassifier) classifyCell(cell s2Cell) byte {
+	var state byte
+	for i, rect := range cell.rects() {
+		rectState := classifier.classify(rect[0], rect[1], rect[2], rect[3])
+		if i > 0 && rectState != state {
+			return TILE_EITHER
+		}
+		state = rectState
+	}
+	return state
+}
+
+// clampLevel returns the level within 0 to max.
+func clampLevel(level, max int) int {
+	if level < 0 {
+		return 0
+	}
+	if level > max {
+		return max
+	}
+	return level
+}
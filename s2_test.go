@@ -0,0 +1,74 @@
+package geofence
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS2CellID(t *testing.T) {
+	// IDs from the S2 geometry library
+	assert.Equal(t, uint64(0x1000000000000001), S2CellID(NewPoint(0, 0), S2MaxLevel))
+	assert.Equal(t, uint64(0x1000000000000000), S2CellID(NewPoint(0, 0), 0))
+	assert.Equal(t, uint64(0x5000000000000000), S2CellID(NewPoint(90, 0), 0))
+	assert.Equal(t, uint64(0xb000000000000000), S2CellID(NewPoint(-90, 0), 0))
+	// New York is in the cell with token 89c25
+	assert.Equal(t, uint64(0x89c2500000000000), S2CellID(NewPoint(40.7128, -74.006), 8))
+	assert.Equal(t, 8, s2Level(S2CellID(NewPoint(40.7128, -74.006), 8)))
+	assert.Equal(t, S2CellID(NewPoint(0, 0), S2MaxLevel), S2CellID(NewPoint(0, 0), 40))
+}
+
+// coveringContains returns whether the point's cell at some level is in the covering.
+func coveringContains(covering []uint64, point *Point, level int) bool {
+	for l := 0; l <= level; l++ {
+		id := S2CellID(point, l)
+		for _, cell := range covering {
+			if cell == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestS2Covering(t *testing.T) {
+	fences := []*Geofence{
+		NewGeofence([]*Point{NewPoint(51.50, -0.13), NewPoint(51.50, -0.11), NewPoint(51.51, -0.11), NewPoint(51.51, -0.13)}),
+		NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, [][]*Point{{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}}),
+		NewGeofence([]*Point{NewPoint(-20, 177), NewPoint(-20, -178), NewPoint(-15, -178), NewPoint(-15, 177)}),
+		NewGeodesicGeofence(parallel(70, 12, false)),
+		NewGeofence([]*Point{NewPoint(44, 0), NewPoint(46, 0), NewPoint(46, 1)}, WithLocalProjection()),
+	}
+	for _, geofence := range fences {
+		covering := geofence.S2Covering(10)
+		assert.NotEmpty(t, covering)
+		assert.Less(t, len(covering), 10000)
+		for i := 1; i < len(covering); i++ {
+			assert.Less(t, covering[i-1], covering[i])
+		}
+
+		min, max := geofence.Bounds()
+		for i := 0; i < 300; i++ {
+			point := NewPoint(min.Lat()+rand.Float64()*(max.Lat()-min.Lat()), min.Lng()+rand.Float64()*(max.Lng()-min.Lng()))
+			if geofence.wrapLng {
+				point = NewPoint(point.Lat(), min.Lng()+rand.Float64()*(max.Lng()+360-min.Lng()))
+			}
+			if geofence.Inside(point) {
+				assert.True(t, coveringContains(covering, point, 10), point)
+			}
+		}
+	}
+
+	// Cells inside the fence are kept whole, so the covering is far smaller than the cells at the level
+	covering := fences[1].S2Covering(12)
+	levels := map[int]int{}
+	for _, id := range covering {
+		levels[s2Level(id)]++
+	}
+	assert.Greater(t, len(levels), 3)
+	assert.False(t, coveringContains(covering, NewPoint(5, 5), 12))
+	assert.True(t, coveringContains(covering, NewPoint(2, 2), 12))
+	assert.False(t, coveringContains(covering, NewPoint(20, 20), 12))
+	assert.Nil(t, NewGeofence(nil).S2Covering(10))
+}
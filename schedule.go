@@ -0,0 +1,138 @@
+package geofence
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeWindow is a daily period during which a ScheduledGeofence is active, from Start up to but
+// not including End, both measured from midnight on the wall clock of the schedule's location. A
+// window whose End is before its Start runs overnight, e.g. 22:00 to 06:00, and belongs to the
+// day it starts on. Days limits the window to those weekdays, or every day when empty.
+type TimeWindow struct {
+	Days  []time.Weekday
+	Start time.Duration
+	End   time.Duration
+}
+
+// NewTimeWindow returns a TimeWindow between two "15:04" clock times on the days, or every day
+// when none are given, e.g. NewTimeWindow("08:00", "09:30", time.Monday, time.Friday). An End of
+// "24:00" runs to midnight.
+func NewTimeWindow(start, end string, days ...time.Weekday) (TimeWindow, error) {
+	startOffset, err := parseClock(start)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	endOffset, err := parseClock(end)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	window := TimeWindow{Days: days, Start: startOffset, End: endOffset}
+	return window, window.validate()
+}
+
+// parseClock returns the time since midnight of a "15:04" clock time, allowing "24:00".
+func parseClock(clock string) (time.Duration, error) {
+	if clock == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, expected e.g. \"08:30\"", clock)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+func (window TimeWindow) validate() error {
+	switch {
+	case window.Start < 0 || window.Start >= 24*time.Hour:
+		return fmt.Errorf("time window start must be from 0 up to 24h, got %v", window.Start)
+	case window.End < 0 || window.End > 24*time.Hour:
+		return fmt.Errorf("time window end must be from 0 to 24h, got %v", window.End)
+	case window.Start == window.End:
+		return fmt.Errorf("time window starts and ends at %v", window.Start)
+	}
+	for _, day := range window.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("invalid weekday %d", day)
+		}
+	}
+	return nil
+}
+
+func (window TimeWindow) onDay(day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, d := range window.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// active reports whether the window covers the time of day on the weekday, both on the
+// schedule's wall clock.
+func (window TimeWindow) active(day time.Weekday, offset time.Duration) bool {
+	if window.Start < window.End {
+		return window.onDay(day) && offset >= window.Start && offset < window.End
+	}
+	// An overnight window covers the late hours of its own days and the early hours of the next
+	return (window.onDay(day) && offset >= window.Start) || (window.onDay((day+6)%7) && offset < window.End)
+}
+
+// ScheduledGeofence is a Geofence that is only active during its time windows, e.g. a school zone
+// on weekday mornings and afternoons, or a delivery bay's opening hours.
+type ScheduledGeofence struct {
+	Geofence *Geofence
+	location *time.Location
+	windows  []TimeWindow
+}
+
+// NewScheduledGeofence returns the geofence active during the windows, on the wall clock of the
+// location, e.g. time.LoadLocation("Europe/London"), so the schedule follows daylight saving
+// time. A nil location is UTC.
+func NewScheduledGeofence(geofence *Geofence, location *time.Location, windows ...TimeWindow) (*ScheduledGeofence, error) {
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("schedule has no time windows")
+	}
+	for _, window := range windows {
+		if err := window.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &ScheduledGeofence{Geofence: geofence, location: location, windows: append([]TimeWindow(nil), windows...)}, nil
+}
+
+// Location returns the time zone of the schedule.
+func (scheduled *ScheduledGeofence) Location() *time.Location {
+	return scheduled.location
+}
+
+// Windows returns a copy of the schedule's time windows.
+func (scheduled *ScheduledGeofence) Windows() []TimeWindow {
+	return append([]TimeWindow(nil), scheduled.windows...)
+}
+
+// Active reports whether the timestamp falls in one of the schedule's time windows.
+func (scheduled *ScheduledGeofence) Active(timestamp time.Time) bool {
+	local := timestamp.In(scheduled.location)
+	hour, minute, second := local.Clock()
+	offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second + time.Duration(local.Nanosecond())
+	for _, window := range scheduled.windows {
+		if window.active(local.Weekday(), offset) {
+			return true
+		}
+	}
+	return false
+}
+
+// InsideAt reports whether the point is inside the geofence at the timestamp, which is never the
+// case outside the schedule's time windows.
+func (scheduled *ScheduledGeofence) InsideAt(point *Point, timestamp time.Time) bool {
+	return scheduled.Active(timestamp) && scheduled.Geofence.Inside(point)
+}
@@ -0,0 +1,62 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledGeofence(t *testing.T) {
+	morning, err := NewTimeWindow("08:00", "09:30", time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	assert.NoError(t, err)
+	night, err := NewTimeWindow("22:00", "06:00", time.Saturday)
+	assert.NoError(t, err)
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	scheduled, err := NewScheduledGeofence(square(0, 0, 10), zone, morning, night)
+	assert.NoError(t, err)
+	assert.Equal(t, zone, scheduled.Location())
+	assert.Len(t, scheduled.Windows(), 2)
+
+	inside, outside := NewPoint(5, 5), NewPoint(20, 20)
+	// Monday 2024-01-01
+	monday := func(clock string) time.Time {
+		at, err := time.ParseInLocation("2006-01-02 15:04", "2024-01-01 "+clock, zone)
+		assert.NoError(t, err)
+		return at
+	}
+	assert.True(t, scheduled.InsideAt(inside, monday("08:00")))
+	assert.True(t, scheduled.InsideAt(inside, monday("09:29")))
+	assert.False(t, scheduled.InsideAt(inside, monday("09:30")), "the end of a window is excluded")
+	assert.False(t, scheduled.InsideAt(inside, monday("07:59")))
+	assert.False(t, scheduled.InsideAt(outside, monday("08:30")))
+	// The same instant in UTC is still 08:30 in the schedule's zone
+	assert.True(t, scheduled.InsideAt(inside, monday("08:30").UTC()))
+
+	saturday, sunday := monday("23:00").AddDate(0, 0, 5), monday("05:00").AddDate(0, 0, 6)
+	assert.False(t, scheduled.Active(saturday.AddDate(0, 0, -2)), "the morning window is weekdays only")
+	assert.True(t, scheduled.Active(saturday))
+	assert.True(t, scheduled.Active(sunday), "an overnight window runs into the next day")
+	assert.False(t, scheduled.Active(sunday.Add(time.Hour)))
+	assert.False(t, scheduled.Active(saturday.Add(-2*time.Hour)), "Saturday's window starts at 22:00")
+	assert.False(t, scheduled.Active(monday("05:00")), "Sunday has no overnight window")
+
+	allDay, err := NewTimeWindow("00:00", "24:00")
+	assert.NoError(t, err)
+	always, err := NewScheduledGeofence(square(0, 0, 10), nil, allDay)
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, always.Location())
+	assert.True(t, always.Active(time.Date(2024, 1, 6, 23, 59, 59, 0, time.UTC)))
+
+	_, err = NewTimeWindow("8am", "09:00")
+	assert.Error(t, err)
+	_, err = NewTimeWindow("09:00", "09:00")
+	assert.Error(t, err)
+	_, err = NewScheduledGeofence(square(0, 0, 10), nil)
+	assert.Error(t, err)
+	_, err = NewScheduledGeofence(square(0, 0, 10), nil, TimeWindow{Start: 25 * time.Hour, End: time.Hour})
+	assert.Error(t, err)
+	_, err = NewScheduledGeofence(square(0, 0, 10), nil, TimeWindow{Days: []time.Weekday{7}, Start: 0, End: time.Hour})
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Shapefile shape types with polygons, plain and with Z or M values, which are ignored.
+const (
+	shapeNull     = 0
+	shapePolygon  = 5
+	shapePolygonZ = 15
+	shapePolygonM = 25
+)
+
+// NewGeofencesFromShapefile builds a Geofence from each polygon record of an ESRI shapefile,
+// its .shp and .dbf files, keyed by the record's keyField attribute, e.g. "DISTRICT". Field
+// names match regardless of case and values are trimmed. Clockwise rings are polygons and
+// counterclockwise rings are their holes, as the format requires, and records with several
+// polygons, or several records with the same key, make a geofence as built by
+// NewMultiGeofence. Deleted and null records are skipped. Coordinates must be longitude and
+// latitude, as for WGS 84 (EPSG:4326) data; other projections should be converted first.
+// Options are applied to each geofence as for NewGeofence.
+func NewGeofencesFromShapefile(shp, dbf io.Reader, keyField string, opts ...Option) (map[string]*Geofence, error) {
+	shapes, err := readShapefilePolygons(shp)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := readDBFColumn(dbf, keyField)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != len(shapes) {
+		return nil, fmt.Errorf("shapefile has %d records but its dbf file has %d", len(shapes), len(keys))
+	}
+
+	parts := make(map[string][]*Geofence)
+	var order []string
+	for i, rings := range shapes {
+		if rings == nil || keys[i] == nil {
+			continue
+		}
+		key := *keys[i]
+		if _, ok := parts[key]; !ok {
+			order = append(order, key)
+		}
+		outlines, holes := groupRings(rings)
+		for _, outline := range outlines {
+			var polygonHoles [][]*Point
+			for _, hole := range holes[outline] {
+				polygonHoles = append(polygonHoles, rings[hole])
+			}
+			parts[key] = append(parts[key], NewGeofenceWithHoles(rings[outline], polygonHoles, opts...))
+		}
+	}
+
+	geofences := make(map[string]*Geofence, len(order))
+	for _, key := range order {
+		switch len(parts[key]) {
+		case 0:
+		case 1:
+			geofences[key] = parts[key][0]
+		default:
+			geofences[key] = newMultiGeofence(parts[key])
+		}
+	}
+	return geofences, nil
+}
+
+// readShapefilePolygons returns the rings of each record of a .shp file, nil for null shapes.
+// The points are NewPoint(y, x), which turns the format's clockwise outlines counterclockwise.
+func readShapefilePolygons(shp io.Reader) ([][][]*Point, error) {
+	data, err := io.ReadAll(shp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read shapefile: %v", err)
+	}
+	if len(data) < 100 || binary.BigEndian.Uint32(data) != 9994 {
+		return nil, fmt.Errorf("unable to read shapefile: not a .shp file")
+	}
+	switch shapeType := binary.LittleEndian.Uint32(data[32:]); shapeType {
+	case shapeNull, shapePolygon, shapePolygonZ, shapePolygonM:
+	default:
+		return nil, fmt.Errorf("unable to read shapefile: shape type %d is not a polygon", shapeType)
+	}
+
+	var shapes [][][]*Point
+	for pos := 100; pos < len(data); {
+		if len(data)-pos < 8 {
+			return nil, fmt.Errorf("unable to read shapefile: record %d is truncated", len(shapes))
+		}
+		length := int(binary.BigEndian.Uint32(data[pos+4:])) * 2
+		if length < 4 || length > len(data)-pos-8 {
+			return nil, fmt.Errorf("unable to read shapefile: record %d is truncated", len(shapes))
+		}
+		rings, err := shapefilePolygon(data[pos+8 : pos+8+length])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read shapefile: record %d: %v", len(shapes), err)
+		}
+		shapes = append(shapes, rings)
+		pos += 8 + length
+	}
+	return shapes, nil
+}
+
+// shapefilePolygon returns the rings of a polygon record's content, without their closing points.
+func shapefilePolygon(content []byte) ([][]*Point, error) {
+	switch shapeType := binary.LittleEndian.Uint32(content); shapeType {
+	case shapeNull:
+		return nil, nil
+	case shapePolygon, shapePolygonZ, shapePolygonM:
+	default:
+		return nil, fmt.Errorf("shape type %d is not a polygon", shapeType)
+	}
+	// The shape type and bounding box come before the part and point counts
+	if len(content) < 44 {
+		return nil, fmt.Errorf("polygon is truncated")
+	}
+	numParts := int(binary.LittleEndian.Uint32(content[36:]))
+	numPoints := int(binary.LittleEndian.Uint32(content[40:]))
+	if numParts < 1 || numPoints < 0 || numParts > numPoints || len(content) < 44+4*numParts+16*numPoints {
+		return nil, fmt.Errorf("polygon is truncated")
+	}
+	points := content[44+4*numParts:]
+	rings := make([][]*Point, numParts)
+	for i := range rings {
+		start := int(binary.LittleEndian.Uint32(content[44+4*i:]))
+		end := numPoints
+		if i+1 < numParts {
+			end = int(binary.LittleEndian.Uint32(content[48+4*i:]))
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, fmt.Errorf("polygon part %d is out of range", i)
+		}
+		ring := make([]*Point, 0, end-start)
+		for j := start; j < end; j++ {
+			x := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j:]))
+			y := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j+8:]))
+			ring = append(ring, NewPoint(y, x))
+		}
+		// Shapefile rings repeat the first point at the end, Geofence rings do not
+		if len(ring) > 1 {
+			if first, last := ring[0], ring[len(ring)-1]; first.lat == last.lat && first.lng == last.lng {
+				ring = ring[:len(ring)-1]
+			}
+		}
+		if len(ring) < 3 {
+			return nil, fmt.Errorf("polygon part %d has %d points, at least 3 are required", i, len(ring))
+		}
+		rings[i] = ring
+	}
+	return rings, nil
+}
+
+// readDBFColumn returns the trimmed values of the field in each record of a .dbf file, nil for
+// deleted records.
+func readDBFColumn(dbf io.Reader, field string) ([]*string, error) {
+	data, err := io.ReadAll(dbf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read dbf file: %v", err)
+	}
+	if len(data) < 32 {
+		return nil, fmt.Errorf("unable to read dbf file: header is truncated")
+	}
+	numRecords := int(binary.LittleEndian.Uint32(data[4:]))
+	headerLength := int(binary.LittleEndian.Uint16(data[8:]))
+	recordLength := int(binary.LittleEndian.Uint16(data[10:]))
+	if headerLength > len(data) || recordLength < 1 {
+		return nil, fmt.Errorf("unable to read dbf file: header is truncated")
+	}
+
+	// Each field is described in 32 bytes, its name padded with zeros, then its type and length.
+	// A field's value follows those before it in each record, after the deletion flag.
+	offset, width := -1, 0
+	var names []string
+	for pos, start := 32, 1; pos+32 <= headerLength && data[pos] != 0x0d; pos += 32 {
+		name := string(bytes.TrimRight(data[pos:pos+11], "\x00"))
+		names = append(names, name)
+		length := int(data[pos+16])
+		if offset < 0 && strings.EqualFold(name, field) {
+			offset, width = start, length
+		}
+		start += length
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("dbf file has no field %q, it has %s", field, strings.Join(names, ", "))
+	}
+	if offset+width > recordLength || numRecords > (len(data)-headerLength)/recordLength {
+		return nil, fmt.Errorf("unable to read dbf file: records are truncated")
+	}
+
+	values := make([]*string, numRecords)
+	for i := range values {
+		record := data[headerLength+i*recordLength:]
+		if record[0] == '*' {
+			continue
+		}
+		value := strings.TrimSpace(string(record[offset : offset+width]))
+		values[i] = &value
+	}
+	return values, nil
+}
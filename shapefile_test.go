@@ -0,0 +1,141 @@
+package geofence
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testShapefile returns the .shp file of polygon records, each a list of rings of [x, y]
+// points, with a nil record as a null shape.
+func testShapefile(records [][][][2]float64) []byte {
+	le, be := binary.LittleEndian, binary.BigEndian
+	header := make([]byte, 100)
+	be.PutUint32(header, 9994)
+	le.PutUint32(header[28:], 1000)
+	le.PutUint32(header[32:], shapePolygon)
+	data := header
+	for i, rings := range records {
+		content := make([]byte, 4)
+		if rings != nil {
+			content = make([]byte, 44)
+			le.PutUint32(content, shapePolygon)
+			le.PutUint32(content[36:], uint32(len(rings)))
+			var points []byte
+			count := 0
+			for _, ring := range rings {
+				content = append(content, 0, 0, 0, 0)
+				le.PutUint32(content[len(content)-4:], uint32(count))
+				for _, point := range append(ring, ring[0]) {
+					points = append(points, make([]byte, 16)...)
+					le.PutUint64(points[len(points)-16:], math.Float64bits(point[0]))
+					le.PutUint64(points[len(points)-8:], math.Float64bits(point[1]))
+					count++
+				}
+			}
+			le.PutUint32(content[40:], uint32(count))
+			content = append(content, points...)
+		}
+		record := make([]byte, 8)
+		be.PutUint32(record, uint32(i+1))
+		be.PutUint32(record[4:], uint32(len(content)/2))
+		data = append(append(data, record...), content...)
+	}
+	be.PutUint32(data[24:], uint32(len(data)/2))
+	return data
+}
+
+// testDBF returns the .dbf file of records of character fields, with a leading "*" marking a
+// deleted record's first value.
+func testDBF(fields []string, width int, records [][]string) []byte {
+	header := make([]byte, 32)
+	header[0] = 3
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(header[8:], uint16(33+32*len(fields)))
+	binary.LittleEndian.PutUint16(header[10:], uint16(1+width*len(fields)))
+	data := header
+	for _, field := range fields {
+		descriptor := make([]byte, 32)
+		copy(descriptor, field)
+		descriptor[11], descriptor[16] = 'C', byte(width)
+		data = append(data, descriptor...)
+	}
+	data = append(data, 0x0d)
+	for _, record := range records {
+		flag := " "
+		if record[0][0] == '*' {
+			flag, record = "*", append([]string{record[0][1:]}, record[1:]...)
+		}
+		data = append(data, flag...)
+		for _, value := range record {
+			data = append(data, bytes.Repeat([]byte(" "), width)...)
+			copy(data[len(data)-width:], value)
+		}
+	}
+	return append(data, 0x1a)
+}
+
+func TestNewGeofencesFromShapefile(t *testing.T) {
+	square := func(min, max float64) [][2]float64 {
+		// Clockwise, as shapefile outlines are
+		return [][2]float64{{min, min}, {min, max}, {max, max}, {max, min}}
+	}
+	reversed := func(ring [][2]float64) [][2]float64 {
+		out := make([][2]float64, len(ring))
+		for i, point := range ring {
+			out[len(ring)-1-i] = point
+		}
+		return out
+	}
+	shp := testShapefile([][][][2]float64{
+		{square(0, 10), reversed(square(4, 6))},
+		{square(20, 21), square(30, 31)},
+		nil,
+		{square(40, 41)},
+		{square(50, 51)},
+		{square(60, 61)},
+	})
+	dbf := testDBF([]string{"NAME", "DISTRICT"}, 10, [][]string{
+		{"Yard", "north"},
+		{"Sites", "south"},
+		{"Nothing", "east"},
+		{"Depot", "south "},
+		{"*Old", "west"},
+		{"Field", "west"},
+	})
+
+	geofences, err := NewGeofencesFromShapefile(bytes.NewReader(shp), bytes.NewReader(dbf), "district", WithGranularity(5))
+	assert.NoError(t, err)
+	assert.Len(t, geofences, 3)
+	if north := geofences["north"]; assert.NotNil(t, north) {
+		assert.Equal(t, NewPoint(0, 0), north.Vertices()[0])
+		assert.Len(t, north.Holes(), 1)
+		assert.True(t, north.Inside(NewPoint(2, 5)))
+		assert.False(t, north.Inside(NewPoint(5, 5)))
+		assert.Equal(t, int64(5), north.granularityX)
+	}
+	if south := geofences["south"]; assert.NotNil(t, south) {
+		assert.Len(t, south.polygons(), 3)
+		for _, point := range []*Point{NewPoint(20.5, 20.5), NewPoint(30.5, 30.5), NewPoint(40.5, 40.5)} {
+			assert.True(t, south.Inside(point))
+		}
+	}
+	if west := geofences["west"]; assert.NotNil(t, west) {
+		assert.False(t, west.Inside(NewPoint(50.5, 50.5)))
+		assert.True(t, west.Inside(NewPoint(60.5, 60.5)))
+	}
+
+	_, err = NewGeofencesFromShapefile(bytes.NewReader(shp), bytes.NewReader(dbf), "ZONE")
+	assert.Error(t, err)
+	_, err = NewGeofencesFromShapefile(bytes.NewReader(shp[:len(shp)-8]), bytes.NewReader(dbf), "NAME")
+	assert.Error(t, err)
+	_, err = NewGeofencesFromShapefile(bytes.NewReader(shp), bytes.NewReader(dbf[:40]), "NAME")
+	assert.Error(t, err)
+	_, err = NewGeofencesFromShapefile(bytes.NewReader(dbf), bytes.NewReader(dbf), "NAME")
+	assert.Error(t, err)
+	_, err = NewGeofencesFromShapefile(bytes.NewReader(testShapefile([][][][2]float64{{square(0, 1)}})), bytes.NewReader(dbf), "NAME")
+	assert.Error(t, err)
+}
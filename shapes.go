@@ -0,0 +1,183 @@
+package geofence
+
+import (
+	"math"
+)
+
+const defaultCircleSegments = 32
+
+// corridorCapSegments is the number of segments in each rounded end of a corridor segment.
+const corridorCapSegments = 16
+
+// NewCircleGeofence is the construct for a Geofence approximating a circle with a regular
+// polygon of the given number of segments, 32 when segments is not positive.
+// With WithGeodesic the radius is in meters along the Earth's surface, so the circle
+// stays round on the ground at any latitude. Otherwise the radius is in coordinate units.
+func NewCircleGeofence(center *Point, radius float64, segments int, opts ...Option) *Geofence {
+	return NewGeofence(circlePoints(center, radius, segments, optionsGeodesic(opts)), opts...)
+}
+
+// NewAnnulusGeofence is the construct for a Geofence between two circles around the center,
+// e.g. an approach warning ring around a port. The radii are in meters with WithGeodesic and in
+// coordinate units otherwise, as for NewCircleGeofence, and each circle has 32 segments. Points
+// within innerRadius are outside the geofence, and an innerRadius that is not positive gives a
+// circle. The radii are not checked, so an innerRadius at or beyond outerRadius gives a hole
+// that covers the whole geofence, see NewAnnulusGeofenceChecked.
+func NewAnnulusGeofence(center *Point, innerRadius, outerRadius float64, opts ...Option) *Geofence {
+	outer, holes := annulusRings(center, innerRadius, outerRadius, opts)
+	return NewGeofenceWithHoles(outer, holes, opts...)
+}
+
+// annulusRings returns the outer circle of an annulus and its inner circle as a hole, if any.
+func annulusRings(center *Point, innerRadius, outerRadius float64, opts []Option) ([]*Point, [][]*Point) {
+	geodesic := optionsGeodesic(opts)
+	outer := circlePoints(center, outerRadius, defaultCircleSegments, geodesic)
+	if innerRadius <= 0 {
+		return outer, nil
+	}
+	return outer, [][]*Point{circlePoints(center, innerRadius, defaultCircleSegments, geodesic)}
+}
+
+// circlePoints returns the vertices of a regular polygon inscribed in the circle, clockwise
+// from north, with the radius in meters when geodesic and in coordinate units otherwise.
+func circlePoints(center *Point, radius float64, segments int, geodesic bool) []*Point {
+	if segments <= 0 {
+		segments = defaultCircleSegments
+	}
+
+	points := make([]*Point, segments)
+	for i := 0; i < segments; i++ {
+		points[i] = circlePoint(center, radius, 360.0*float64(i)/float64(segments), geodesic)
+	}
+	return points
+}
+
+// NewSectorGeofence is the construct for a pie slice Geofence, e.g. the coverage of a directional
+// antenna, running clockwise from startBearing to endBearing (in degrees from north) around the
+// center. The radius is in meters with WithGeodesic and in coordinate units otherwise, as for
+// NewCircleGeofence. Equal bearings give a full circle.
+func NewSectorGeofence(center *Point, radius, startBearing, endBearing float64, opts ...Option) *Geofence {
+	sweep := math.Mod(endBearing-startBearing, 360)
+	if sweep < 0 {
+		sweep += 360
+	}
+	if sweep == 0 {
+		return NewCircleGeofence(center, radius, defaultCircleSegments, opts...)
+	}
+
+	// The arc gets the same spacing as a circle's segments
+	geodesic := optionsGeodesic(opts)
+	segments := int(math.Ceil(sweep / 360 * defaultCircleSegments))
+	points := []*Point{center}
+	for i := 0; i <= segments; i++ {
+		points = append(points, circlePoint(center, radius, startBearing+sweep*float64(i)/float64(segments), geodesic))
+	}
+	return NewGeofence(points, opts...)
+}
+
+// circlePoint returns the point radius from the center along the bearing.
+func circlePoint(center *Point, radius, bearing float64, geodesic bool) *Point {
+	if geodesic {
+		return center.PointAtDistanceAndBearing(radius/1000, bearing)
+	}
+	angle := bearing * math.Pi / 180.0
+	return NewPoint(center.Lat()+radius*math.Cos(angle), center.Lng()+radius*math.Sin(angle))
+}
+
+// NewGeodesicCircleGeofence is the construct for a geodesic Geofence approximating a circle of
+// radiusMeters around the center, e.g. "within 500 m of a depot". It is shorthand for
+// NewCircleGeofence(center, radiusMeters, segments, WithGeodesic()).
+func NewGeodesicCircleGeofence(center *Point, radiusMeters float64, segments int, opts ...Option) *Geofence {
+	return NewCircleGeofence(center, radiusMeters, segments, append([]Option{WithGeodesic()}, opts...)...)
+}
+
+// NewCorridorGeofence is the construct for a geodesic Geofence covering every point within
+// widthMeters/2 of the path, e.g. to detect a vehicle deviating from its route. Each segment
+// of the path becomes a polygon with rounded ends, combined as by NewMultiGeofence, so bends
+// in the path are rounded too. A path of a single point gives a circle.
+func NewCorridorGeofence(path []*Point, widthMeters float64, opts ...Option) *Geofence {
+	opts = append([]Option{WithGeodesic()}, opts...)
+	// Repeated points have no bearing between them, unlike a route ending where it started
+	deduped := make([]*Point, 0, len(path))
+	for _, point := range path {
+		if len(deduped) == 0 || !samePoint(deduped[len(deduped)-1], point) {
+			deduped = append(deduped, point)
+		}
+	}
+	path = deduped
+	if len(path) == 1 {
+		return newMultiGeofence([]*Geofence{NewGeodesicCircleGeofence(path[0], widthMeters/2, defaultCircleSegments, opts...)})
+	}
+
+	halfWidth := widthMeters / 2 / 1000
+	parts := make([]*Geofence, 0, len(path))
+	for i := 0; i+1 < len(path); i++ {
+		start, end := path[i], path[i+1]
+		startBearing := start.BearingTo(end)
+		endBearing := end.BearingTo(start) + 180
+
+		// Half circles behind the start and ahead of the end, joined by the sides
+		points := make([]*Point, 0, 2*corridorCapSegments+2)
+		for j := 0; j <= corridorCapSegments; j++ {
+			points = append(points, start.PointAtDistanceAndBearing(halfWidth, startBearing+90+180*float64(j)/corridorCapSegments))
+		}
+		for j := 0; j <= corridorCapSegments; j++ {
+			points = append(points, end.PointAtDistanceAndBearing(halfWidth, endBearing-90+180*float64(j)/corridorCapSegments))
+		}
+		parts = append(parts, NewGeofence(points, opts...))
+	}
+	return newMultiGeofence(parts)
+}
+
+// NewBBoxGeofence is the construct for a rectangular Geofence between the given parallels and
+// meridians. Inside only compares the point with the bounds, so no tiles are computed. A minLng
+// greater than maxLng gives a rectangle running east from minLng across the antimeridian to
+// maxLng, e.g. 10° to 5° covers all but 5° to 10°. The edges always follow the parallels and
+// meridians, so WithGeodesic and WithProjection are ignored. As with NewGeofence, an invalid
+// option is ignored, and the bounds are not checked, so a minLat greater than maxLat gives a
+// geofence nothing is inside, see NewBBoxGeofenceChecked.
+func NewBBoxGeofence(minLat, minLng, maxLat, maxLng float64, opts ...Option) *Geofence {
+	geofence, _ := newBBoxGeofence(minLat, minLng, maxLat, maxLng, opts)
+	return geofence
+}
+
+// newBBoxGeofence builds the rectangle and returns it along with the first option error, if any.
+func newBBoxGeofence(minLat, minLng, maxLat, maxLng float64, opts []Option) (*Geofence, error) {
+	geofence := &Geofence{granularityX: defaultGranularity, granularityY: defaultGranularity, rect: true, tiles: make(map[int64]byte)}
+	err := geofence.applyOptions(opts)
+	corners := []*Point{NewPoint(minLat, minLng), NewPoint(minLat, maxLng), NewPoint(maxLat, maxLng), NewPoint(maxLat, minLng)}
+	if len(geofence.holes) > 0 {
+		// Holes need the tiles after all
+		return newGeofence(corners, nil, append(opts, func(geofence *Geofence) error {
+			geofence.geodesic = false
+			geofence.projection, geofence.localProj = nil, false
+			return nil
+		}))
+	}
+	geofence.geodesic = false
+	geofence.projection, geofence.localProj = nil, false
+	geofence.wrapLng = minLng > maxLng
+	geofence.minX, geofence.maxX = minLat, maxLat
+	geofence.minY, geofence.maxY = minLng, maxLng
+	if geofence.wrapLng {
+		// The rectangle runs east from minLng, so its longitudes start there rather than at 0°
+		geofence.maxY += 360
+	}
+	geofence.vertices = geofence.wrapRing(corners)
+	geofence.polygon = NewPolygon(geofence.vertices)
+	return geofence, err
+}
+
+// optionsGeodesic returns whether the options select geodesic mode.
+func optionsGeodesic(opts []Option) bool {
+	probe := &Geofence{}
+	probe.applyOptions(opts)
+	return probe.geodesic
+}
+
+// optionsPlanar returns whether the options select planar mode.
+func optionsPlanar(opts []Option) bool {
+	probe := &Geofence{}
+	probe.applyOptions(opts)
+	return probe.planar
+}
@@ -0,0 +1,161 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircleGeofence(t *testing.T) {
+	center := NewPoint(50, 50)
+	geofence := NewCircleGeofence(center, 10, 0)
+	assert.Len(t, geofence.vertices, defaultCircleSegments)
+
+	assert.True(t, geofence.Inside(NewPoint(59, 50)))
+	assert.True(t, geofence.Inside(NewPoint(50-9*0.7071, 50+9*0.7071)))
+	assert.False(t, geofence.Inside(NewPoint(61, 50)))
+	assert.False(t, geofence.Inside(NewPoint(50-11*0.7071, 50+11*0.7071)))
+}
+
+func TestCircleGeofenceGeodesic(t *testing.T) {
+	center := NewPoint(60, 10)
+	geofence := NewCircleGeofence(center, 500, 64, WithGeodesic())
+	assert.Len(t, geofence.vertices, 64)
+
+	for bearing := 0.0; bearing < 360; bearing += 10 {
+		assert.True(t, geofence.Inside(center.PointAtDistanceAndBearing(0.45, bearing)), "bearing %v", bearing)
+		assert.False(t, geofence.Inside(center.PointAtDistanceAndBearing(0.55, bearing)), "bearing %v", bearing)
+	}
+}
+
+func TestGeodesicCircleGeofenceHighLatitude(t *testing.T) {
+	// At 80N a degree of longitude is under 20km, a planar circle would be a thin ellipse
+	center := NewPoint(80, 20)
+	geofence := NewGeodesicCircleGeofence(center, 200000, 0)
+	assert.True(t, geofence.geodesic)
+	assert.Len(t, geofence.vertices, defaultCircleSegments)
+	for _, vertex := range geofence.vertices {
+		assert.InDelta(t, 200, center.GreatCircleDistance(vertex), 1e-6)
+	}
+
+	for bearing := 0.0; bearing < 360; bearing += 15 {
+		assert.True(t, geofence.Inside(center.PointAtDistanceAndBearing(190, bearing)), "bearing %v", bearing)
+		assert.False(t, geofence.Inside(center.PointAtDistanceAndBearing(210, bearing)), "bearing %v", bearing)
+	}
+}
+
+func TestCorridorGeofence(t *testing.T) {
+	// A route east along the equator then north
+	path := []*Point{NewPoint(0, 0), NewPoint(0, 0.1), NewPoint(0.1, 0.1)}
+	geofence := NewCorridorGeofence(path, 1000)
+	assert.Len(t, geofence.parts, 2)
+
+	for _, segment := range [][2]*Point{{path[0], path[1]}, {path[1], path[2]}} {
+		middle := segment[0].MidpointTo(segment[1])
+		bearing := segment[0].BearingTo(segment[1])
+		for _, side := range []float64{-90, 90} {
+			assert.True(t, geofence.Inside(middle.PointAtDistanceAndBearing(0.45, bearing+side)))
+			assert.False(t, geofence.Inside(middle.PointAtDistanceAndBearing(0.55, bearing+side)))
+		}
+	}
+
+	// Rounded ends and the outside of the bend
+	assert.True(t, geofence.Inside(path[0].PointAtDistanceAndBearing(0.45, 270)))
+	assert.False(t, geofence.Inside(path[0].PointAtDistanceAndBearing(0.55, 270)))
+	assert.True(t, geofence.Inside(path[1].PointAtDistanceAndBearing(0.45, 135)))
+	assert.False(t, geofence.Inside(path[1].PointAtDistanceAndBearing(0.55, 135)))
+	assert.True(t, geofence.Inside(path[2].PointAtDistanceAndBearing(0.45, 0)))
+
+	loop := NewCorridorGeofence(append(path, path[0]), 1000)
+	assert.Len(t, loop.parts, 3)
+	assert.True(t, loop.Inside(NewPoint(0.05, 0.05)))
+
+	single := NewCorridorGeofence([]*Point{NewPoint(10, 10), NewPoint(10, 10)}, 1000)
+	assert.True(t, single.Inside(NewPoint(10, 10).PointAtDistanceAndBearing(0.45, 45)))
+	assert.False(t, single.Inside(NewPoint(10, 10).PointAtDistanceAndBearing(0.55, 45)))
+}
+
+func TestBBoxGeofence(t *testing.T) {
+	geofence := NewBBoxGeofence(10, 20, 30, 40)
+	assert.Empty(t, geofence.tiles)
+	assert.True(t, geofence.Inside(NewPoint(15, 25)))
+	assert.True(t, geofence.Inside(NewPoint(10, 30)))
+	assert.False(t, geofence.Inside(NewPoint(5, 25)))
+	assert.False(t, geofence.Inside(NewPoint(15, 41)))
+	min, max := geofence.Bounds()
+	assert.Equal(t, NewPoint(10, 20), min)
+	assert.Equal(t, NewPoint(30, 40), max)
+	assert.InDelta(t, -5, geofence.DistanceToBoundary(NewPoint(15, 25)), 1e-9)
+
+	exclusive := NewBBoxGeofence(10, 20, 30, 40, WithBoundary(Exclusive), WithGeodesic())
+	assert.False(t, exclusive.geodesic)
+	assert.False(t, exclusive.Inside(NewPoint(10, 30)))
+	assert.True(t, exclusive.Inside(NewPoint(10.001, 30)))
+
+	antimeridian := NewBBoxGeofence(-10, 170, 10, -170)
+	assert.True(t, antimeridian.Inside(NewPoint(0, 175)))
+	assert.True(t, antimeridian.Inside(NewPoint(0, -175)))
+	assert.False(t, antimeridian.Inside(NewPoint(0, 0)))
+
+	// Longitudes of the same sign go the long way round, across 0° and the antimeridian
+	east := NewBBoxGeofence(0, 10, 5, 5)
+	for _, lng := range []float64{20, 180, -170, 0, 3} {
+		assert.True(t, east.Inside(NewPoint(2, lng)), "lng %v", lng)
+	}
+	assert.False(t, east.Inside(NewPoint(2, 7)))
+	min, max = east.Bounds()
+	assert.Equal(t, NewPoint(0, 10), min)
+	assert.Equal(t, NewPoint(5, 5), max)
+	assert.True(t, coveringContains(east.S2Covering(10), NewPoint(2, 3), 10))
+
+	data, err := geofence.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := &Geofence{}
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.rect)
+	assert.True(t, decoded.Inside(NewPoint(15, 25)))
+}
+
+func TestAnnulusGeofence(t *testing.T) {
+	center := NewPoint(51.9, 4.1)
+	geofence := NewAnnulusGeofence(center, 2000, 5000, WithGeodesic())
+	assert.Len(t, geofence.holes, 1)
+	for bearing := 0.0; bearing < 360; bearing += 20 {
+		assert.False(t, geofence.Inside(center.PointAtDistanceAndBearing(1.5, bearing)), "bearing %v", bearing)
+		assert.True(t, geofence.Inside(center.PointAtDistanceAndBearing(3.5, bearing)), "bearing %v", bearing)
+		assert.False(t, geofence.Inside(center.PointAtDistanceAndBearing(5.5, bearing)), "bearing %v", bearing)
+	}
+	assert.False(t, geofence.Inside(center))
+
+	planar := NewAnnulusGeofence(NewPoint(0, 0), 2, 5)
+	assert.False(t, planar.Inside(NewPoint(1, 1)))
+	assert.True(t, planar.Inside(NewPoint(3, 0)))
+	assert.False(t, planar.Inside(NewPoint(0, 6)))
+
+	disc := NewAnnulusGeofence(NewPoint(0, 0), 0, 5)
+	assert.Empty(t, disc.holes)
+	assert.True(t, disc.Inside(NewPoint(0, 0)))
+}
+
+func TestSectorGeofence(t *testing.T) {
+	// A quarter circle from north east to south east
+	center := NewPoint(0, 0)
+	geofence := NewSectorGeofence(center, 10, 45, 135)
+	assert.Len(t, geofence.vertices, 10)
+	assert.True(t, geofence.Inside(NewPoint(0, 9)))
+	assert.True(t, geofence.Inside(NewPoint(1, 5)))
+	assert.False(t, geofence.Inside(NewPoint(6, 5)))
+	assert.False(t, geofence.Inside(NewPoint(0, -5)))
+	assert.False(t, geofence.Inside(NewPoint(0, 11)))
+
+	// Wrapping through north, a runway approach cone
+	runway := NewPoint(52.3, 4.75)
+	cone := NewSectorGeofence(runway, 5000, 350, 10, WithGeodesic())
+	assert.True(t, cone.Inside(runway.PointAtDistanceAndBearing(4, 0)))
+	assert.True(t, cone.Inside(runway.PointAtDistanceAndBearing(4, 355)))
+	assert.False(t, cone.Inside(runway.PointAtDistanceAndBearing(4, 20)))
+	assert.False(t, cone.Inside(runway.PointAtDistanceAndBearing(4, 180)))
+	assert.False(t, cone.Inside(runway.PointAtDistanceAndBearing(6, 0)))
+
+	assert.Len(t, NewSectorGeofence(center, 10, 90, 90).vertices, defaultCircleSegments)
+}
@@ -0,0 +1,162 @@
+package geofence
+
+// Simplify returns a geofence with the same options, whose rings keep only the vertices needed
+// to stay within toleranceMeters of the original edges, by the Douglas-Peucker algorithm. An
+// imported boundary with tens of thousands of vertices tiles much faster and answers points near
+// its edges sooner. Holes narrower than the tolerance are dropped. WithPlanar geofences take the
+// tolerance in grid units. Simplification can make a narrow part of a ring cross itself, so the
+// tolerance should be well below the smallest width that matters. A NewBBoxGeofence, or a
+// tolerance that is not positive, returns the geofence itself.
+func (geofence *Geofence) Simplify(toleranceMeters float64) *Geofence {
+	if !(toleranceMeters > 0) || geofence.rect || geofence.empty() {
+		return geofence
+	}
+	if len(geofence.parts) > 0 {
+		parts := make([]*Geofence, len(geofence.parts))
+		for i, part := range geofence.parts {
+			parts[i] = part.Simplify(toleranceMeters)
+		}
+		return newMultiGeofence(parts)
+	}
+	return NewGeofenceWithHoles(geofence.Vertices(), geofence.Holes(), append(geofence.rebuildOptions(), WithSimplify(toleranceMeters))...)
+}
+
+// simplifyRings simplifies the rings given to newGeofence WithSimplify, dropping the holes that
+// collapse.
+func (geofence *Geofence) simplifyRings(outer []*Point, holes [][]*Point) ([]*Point, [][]*Point) {
+	outer = geofence.simplifyRing(outer, true)
+	var kept [][]*Point
+	for _, hole := range holes {
+		if hole = geofence.simplifyRing(hole, false); len(hole) >= 3 {
+			kept = append(kept, hole)
+		}
+	}
+	return outer, kept
+}
+
+// simplifyRing returns the vertices of the ring further than the tolerance from the simplified
+// edges around them. The outer ring keeps at least a triangle when it has any area.
+func (geofence *Geofence) simplifyRing(ring []*Point, outer bool) []*Point {
+	if len(ring) > 1 && samePoint(ring[0], ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+	n := len(ring)
+	if n < 4 {
+		return ring
+	}
+	shifted := !geofence.planar && crossesAntimeridian(ring)
+	if shifted {
+		// Measure across the antimeridian in continuous longitudes
+		wrapped := make([]*Point, n)
+		for i, point := range ring {
+			wrapped[i] = point
+			if point.Lng() < 0 {
+				wrapped[i] = NewPoint(point.Lat(), point.Lng()+360)
+			}
+		}
+		ring = wrapped
+	}
+
+	// Split the ring at the first vertex and the vertex furthest from it
+	far, farthest := 0, 0.0
+	for i := 1; i < n; i++ {
+		if distance := geofence.simplifyDistance(ring[i], ring[0], ring[0]); distance > farthest {
+			far, farthest = i, distance
+		}
+	}
+	keep := make([]bool, n)
+	keep[0], keep[far] = true, true
+	spans := [][2]int{{0, far}, {far, n}}
+	for len(spans) > 0 {
+		span := spans[len(spans)-1]
+		spans = spans[:len(spans)-1]
+		start, end := ring[span[0]], ring[span[1]%n]
+		best, bestDistance := -1, -1.0
+		for i := span[0] + 1; i < span[1]; i++ {
+			if distance := geofence.simplifyDistance(ring[i], start, end); distance > bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		if bestDistance > geofence.simplifyTolerance {
+			keep[best] = true
+			spans = append(spans, [2]int{span[0], best}, [2]int{best, span[1]})
+		}
+	}
+	if outer && countKept(keep) < 3 {
+		// The whole ring is within the tolerance of a line, keep its widest triangle
+		best, bestDistance := -1, 0.0
+		for i := range ring {
+			if distance := geofence.simplifyDistance(ring[i], ring[0], ring[far]); distance > bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best >= 0 {
+			keep[best] = true
+		}
+	}
+
+	simplified := make([]*Point, 0, countKept(keep))
+	for i, point := range ring {
+		if keep[i] {
+			if shifted && point.Lng() > 180 {
+				point = NewPoint(point.Lat(), point.Lng()-360)
+			}
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// simplifyDistance returns the distance from p to the edge a-b, in meters or grid units.
+func (geofence *Geofence) simplifyDistance(p, a, b *Point) float64 {
+	switch {
+	case geofence.planar:
+		return planarSegmentDistance(p, a, b)
+	case geofence.geodesic:
+		return geodesicSegmentDistance(p, a, b)
+	}
+	return localSegmentDistance(p, a, b)
+}
+
+func countKept(keep []bool) int {
+	count := 0
+	for _, kept := range keep {
+		if kept {
+			count++
+		}
+	}
+	return count
+}
+
+// rebuildOptions returns options that give a new geofence the tile grid, mode and boundary rules
+// of this one. Vertices are rebuilt from Vertices and Holes, which are already densified and in
+// lat, lng order, so WithDensify and WithCoordinateOrder are left out.
+func (geofence *Geofence) rebuildOptions() []Option {
+	opts := []Option{WithBoundary(geofence.boundary), WithContainment(geofence.containment), WithRefinement(geofence.refinement)}
+	switch {
+	case geofence.autoGrid:
+		opts = append(opts, WithAutoGranularity())
+	case geofence.tileMeters > 0:
+		opts = append(opts, WithTileSizeMeters(geofence.tileMeters))
+	default:
+		opts = append(opts, WithGranularityXY(int(geofence.granularityX), int(geofence.granularityY)))
+	}
+	switch {
+	case geofence.geodesic:
+		opts = append(opts, WithGeodesic())
+	case geofence.planar:
+		opts = append(opts, WithPlanar())
+	case geofence.projection != nil:
+		opts = append(opts, WithProjection(geofence.projection))
+	}
+	if geofence.exact {
+		opts = append(opts, WithExactPredicates())
+	}
+	if geofence.altitudeRange {
+		opts = append(opts, WithAltitudeRange(geofence.minAltitude, geofence.maxAltitude))
+	}
+	return opts
+}
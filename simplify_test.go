@@ -0,0 +1,54 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplify(t *testing.T) {
+	// A fine circle needs few vertices to stay within 10m of its edge
+	circle := NewGeodesicCircleGeofence(NewPoint(51.5, -0.1), 5000, 3600)
+	simplified := circle.Simplify(10)
+	assert.Less(t, len(simplified.Vertices()), 150)
+	assert.Greater(t, len(simplified.Vertices()), 10)
+	assert.True(t, simplified.geodesic)
+	for _, vertex := range circle.Vertices() {
+		assert.LessOrEqual(t, simplified.DistanceToBoundaryMeters(vertex), 10.0)
+	}
+	assert.True(t, simplified.Inside(NewPoint(51.5, -0.1)))
+
+	// Collinear vertices go, across the antimeridian and along with a hole narrower than the tolerance
+	var outer []*Point
+	for lng := 170.0; lng <= 190; lng++ {
+		outer = append(outer, NewPoint(0, lng-float64(int(lng/180))*360))
+	}
+	outer = append(outer, NewPoint(10, -170), NewPoint(10, 170))
+	hole := []*Point{NewPoint(5, 179), NewPoint(5, 179.0001), NewPoint(5.0001, 179.0001), NewPoint(5.0001, 179)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole}, WithGranularity(40)).Simplify(100)
+	assert.Equal(t, []*Point{NewPoint(0, 170), NewPoint(0, -170), NewPoint(10, -170), NewPoint(10, 170)}, geofence.Vertices())
+	assert.Nil(t, geofence.Holes())
+	assert.Equal(t, int64(40), geofence.granularityX)
+	assert.True(t, geofence.Inside(NewPoint(5, 180)))
+
+	// A sliver keeps a triangle, and planar geofences take grid units
+	sliver := NewGeofence([]*Point{NewPointXY(0, 0), NewPointXY(5, 0.1), NewPointXY(10, 0), NewPointXY(5, -0.1)}, WithPlanar())
+	assert.Len(t, sliver.Simplify(1).Vertices(), 3)
+	assert.Len(t, sliver.Simplify(0.01).Vertices(), 4)
+
+	// Multi geofences simplify each polygon and bounding boxes are already simple
+	multi := NewMultiGeofence([][]*Point{circle.Vertices(), outer}).Simplify(100)
+	assert.Len(t, multi.parts[1].Vertices(), 4)
+	bbox := NewBBoxGeofence(0, 0, 1, 1)
+	assert.Same(t, bbox, bbox.Simplify(100))
+	assert.Same(t, circle, circle.Simplify(0))
+}
+
+func TestWithSimplify(t *testing.T) {
+	circle := NewGeodesicCircleGeofence(NewPoint(51.5, -0.1), 5000, 3600)
+	geofence := NewGeodesicCircleGeofence(NewPoint(51.5, -0.1), 5000, 3600, WithSimplify(10))
+	assert.Equal(t, circle.Simplify(10).Vertices(), geofence.Vertices())
+
+	_, err := NewGeofenceChecked(circle.Vertices(), WithSimplify(-1))
+	assert.EqualError(t, err, "simplify tolerance must be a positive number of meters, got -1")
+}
@@ -0,0 +1,80 @@
+package geofence
+
+import (
+	"math"
+)
+
+// maxGeodesicStep is the largest angle (in radians) between consecutive points when a
+// great circle edge is densified for tiling, about 64km on the Earth's surface.
+const maxGeodesicStep = 0.01
+
+// vector3 is a point on the unit sphere in earth-centered cartesian coordinates.
+type vector3 struct {
+	x, y, z float64
+}
+
+func toVector3(point *Point) vector3 {
+	lat := point.Lat() * math.Pi / 180.0
+	lng := point.Lng() * math.Pi / 180.0
+	return vector3{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+func (v vector3) toPoint() *Point {
+	lat := math.Atan2(v.z, math.Hypot(v.x, v.y))
+	lng := math.Atan2(v.y, v.x)
+	return NewPoint(lat*180.0/math.Pi, lng*180.0/math.Pi)
+}
+
+func (v vector3) dot(w vector3) float64 {
+	return v.x*w.x + v.y*w.y + v.z*w.z
+}
+
+func (v vector3) cross(w vector3) vector3 {
+	return vector3{v.y*w.z - v.z*w.y, v.z*w.x - v.x*w.z, v.x*w.y - v.y*w.x}
+}
+
+// sphericalRingContains returns whether the ring, whose edges are great circle arcs,
+// contains the point. It sums the signed angles the edges subtend at the point, which
+// is ±2π for points inside and 0 for points outside. Rings must be smaller than a hemisphere.
+func sphericalRingContains(ring []*Point, point *Point) bool {
+	if len(ring) < 3 {
+		return false
+	}
+
+	p := toVector3(point)
+	sum := 0.0
+	a := toVector3(ring[len(ring)-1])
+	for i := 0; i < len(ring); i++ {
+		b := toVector3(ring[i])
+		sum += math.Atan2(p.dot(a.cross(b)), a.dot(b)-p.dot(a)*p.dot(b))
+		a = b
+	}
+	return math.Abs(sum) > math.Pi
+}
+
+// densifyGeodesic returns the ring with extra points inserted along each great circle
+// edge, so that straight lines between consecutive points closely follow the arcs. No two
+// consecutive points are more than step radians apart.
+func densifyGeodesic(ring []*Point, step float64) []*Point {
+	if len(ring) < 2 {
+		return ring
+	}
+
+	dense := make([]*Point, 0, len(ring))
+	for i := 0; i < len(ring); i++ {
+		start, end := ring[i], ring[(i+1)%len(ring)]
+		dense = append(dense, start)
+
+		a, b := toVector3(start), toVector3(end)
+		angle := math.Atan2(math.Sqrt(a.cross(b).dot(a.cross(b))), a.dot(b))
+		steps := int(math.Ceil(angle / step))
+		for step := 1; step < steps; step++ {
+			// Spherical linear interpolation between the edge end points
+			f := float64(step) / float64(steps)
+			wa := math.Sin((1-f)*angle) / math.Sin(angle)
+			wb := math.Sin(f*angle) / math.Sin(angle)
+			dense = append(dense, vector3{wa*a.x + wb*b.x, wa*a.y + wb*b.y, wa*a.z + wb*b.z}.toPoint())
+		}
+	}
+	return dense
+}
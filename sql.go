@@ -0,0 +1,71 @@
+package geofence
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Value renders the Geofence as well-known binary, as for WKB, so it can be written through
+// database/sql, e.g. db.Exec("INSERT INTO zones (area) VALUES (ST_GeomFromWKB($1, 4326))", fence).
+// A nil geofence is NULL.
+// Implements the driver.Valuer Interface.
+func (geofence *Geofence) Value() (driver.Value, error) {
+	if geofence == nil {
+		return nil, nil
+	}
+	return geofence.WKB(), nil
+}
+
+// Scan decodes a Polygon or MultiPolygon read from a spatial column: well-known binary or
+// PostGIS EWKB, as raw bytes or the hex text PostGIS drivers return, MySQL's internal format of
+// an SRID followed by WKB, or well-known text. The geofence is built with the default options.
+// NULL is an error.
+// Implements the sql.Scanner Interface.
+func (geofence *Geofence) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		return fmt.Errorf("unable to scan NULL into a geofence")
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("unable to scan %T into a geofence", src)
+	}
+
+	decoded, err := scanGeometry(data)
+	if err != nil {
+		return fmt.Errorf("unable to scan geofence: %v", err)
+	}
+	*geofence = *decoded
+	return nil
+}
+
+// scanGeometry decodes the geometry in whichever of the formats Scan accepts it is in.
+func scanGeometry(data []byte) (*Geofence, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("geometry is empty")
+	}
+	if data[0] <= 1 {
+		geofence, err := NewGeofenceFromWKB(data)
+		if err != nil && len(data) > 4 && data[4] <= 1 {
+			// A MySQL SRID of 0 or 1 also starts with a byte order marker
+			if mysql, mysqlErr := NewGeofenceFromWKB(data[4:]); mysqlErr == nil {
+				return mysql, nil
+			}
+		}
+		return geofence, err
+	}
+	if len(data) > 4 && data[4] <= 1 {
+		// MySQL prefixes the WKB with a four byte SRID
+		return NewGeofenceFromWKB(data[4:])
+	}
+	text := strings.TrimSpace(string(data))
+	if binary, err := hex.DecodeString(text); err == nil {
+		return NewGeofenceFromWKB(binary)
+	}
+	return NewGeofenceFromWKT(text)
+}
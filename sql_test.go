@@ -0,0 +1,47 @@
+package geofence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofenceValueScan(t *testing.T) {
+	var _ driver.Valuer = &Geofence{}
+	var _ sql.Scanner = &Geofence{}
+
+	geofence := NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)},
+		[][]*Point{{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}})
+	value, err := geofence.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, geofence.WKB(), value)
+	value, err = (*Geofence)(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	mysql := append([]byte{0xe6, 0x10, 0, 0}, geofence.WKB()...)
+	for _, src := range []interface{}{
+		geofence.WKB(),
+		hex.EncodeToString(geofence.WKB()),
+		[]byte(hex.EncodeToString(geofence.WKB())),
+		mysql,
+		append([]byte{0, 0, 0, 0}, geofence.WKB()...),
+		geofence.WKT(),
+		"SRID=4326;" + geofence.WKT(),
+	} {
+		scanned := &Geofence{}
+		if assert.NoError(t, scanned.Scan(src), "%v", src) {
+			assert.Equal(t, geofence.Vertices(), scanned.Vertices())
+			assert.Equal(t, geofence.Holes(), scanned.Holes())
+			assert.False(t, scanned.Inside(NewPoint(5, 5)))
+			assert.True(t, scanned.Inside(NewPoint(2, 2)))
+		}
+	}
+
+	for _, src := range []interface{}{nil, 42, []byte{}, "LINESTRING (0 0, 1 1)", []byte{1, 2, 3}} {
+		assert.Error(t, (&Geofence{}).Scan(src), "%v", src)
+	}
+}
@@ -0,0 +1,131 @@
+package geofence
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Fix is a position report of a moving entity, e.g. from a GPS receiver: the Point and the Time
+// it was taken, with the speed, heading and accuracy when the receiver gives them.
+type Fix struct {
+	Point *Point
+	Time  time.Time
+
+	speed, heading, accuracy          float64
+	hasSpeed, hasHeading, hasAccuracy bool
+}
+
+// NewFix returns a Fix at the point and timestamp, without a speed, heading or accuracy.
+func NewFix(point *Point, timestamp time.Time) Fix {
+	return Fix{Point: point, Time: timestamp}
+}
+
+// WithSpeed returns a copy of the fix with the speed, in meters per second.
+func (fix Fix) WithSpeed(metersPerSecond float64) Fix {
+	fix.speed, fix.hasSpeed = metersPerSecond, true
+	return fix
+}
+
+// WithHeading returns a copy of the fix with the heading, in degrees clockwise from north. The
+// heading is normalised to 0 up to 360.
+func (fix Fix) WithHeading(degrees float64) Fix {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	fix.heading, fix.hasHeading = degrees, true
+	return fix
+}
+
+// WithAccuracy returns a copy of the fix with the radius of its horizontal uncertainty, in meters.
+func (fix Fix) WithAccuracy(meters float64) Fix {
+	fix.accuracy, fix.hasAccuracy = meters, true
+	return fix
+}
+
+// Speed returns the fix's speed in meters per second, and whether it has one.
+func (fix Fix) Speed() (float64, bool) {
+	return fix.speed, fix.hasSpeed
+}
+
+// Heading returns the fix's heading in degrees clockwise from north, and whether it has one.
+func (fix Fix) Heading() (float64, bool) {
+	return fix.heading, fix.hasHeading
+}
+
+// Accuracy returns the radius of the fix's horizontal uncertainty in meters, and whether it has one.
+func (fix Fix) Accuracy() (float64, bool) {
+	return fix.accuracy, fix.hasAccuracy
+}
+
+// Track is the fixes of one entity in time order, e.g. a vehicle's trip.
+type Track []Fix
+
+// NewTrack returns a Track of the fixes sorted by time, keeping the order of fixes with the same
+// timestamp. The fixes are copied, so the slice passed in is not reordered.
+func NewTrack(fixes ...Fix) Track {
+	track := make(Track, len(fixes))
+	copy(track, fixes)
+	sort.SliceStable(track, func(i, j int) bool {
+		return track[i].Time.Before(track[j].Time)
+	})
+	return track
+}
+
+// Start returns the time of the track's first fix, or the zero time for an empty track.
+func (track Track) Start() time.Time {
+	if len(track) == 0 {
+		return time.Time{}
+	}
+	return track[0].Time
+}
+
+// End returns the time of the track's last fix, or the zero time for an empty track.
+func (track Track) End() time.Time {
+	if len(track) == 0 {
+		return time.Time{}
+	}
+	return track[len(track)-1].Time
+}
+
+// Duration returns the time between the track's first and last fixes.
+func (track Track) Duration() time.Duration {
+	return track.End().Sub(track.Start())
+}
+
+// Length returns the distance along the track in meters, as the sum of the great circle
+// distances between consecutive fixes.
+func (track Track) Length() float64 {
+	length := 0.0
+	for i := 1; i < len(track); i++ {
+		length += track[i-1].Point.DistanceTo(track[i].Point)
+	}
+	return length
+}
+
+// Points returns the points of the track's fixes, e.g. to build a corridor with
+// NewCorridorGeofence.
+func (track Track) Points() []*Point {
+	points := make([]*Point, len(track))
+	for i, fix := range track {
+		points[i] = fix.Point
+	}
+	return points
+}
+
+// BBox returns the minimum and maximum Lat and Lng of the track's fixes. Unlike a geofence's
+// BBox, a track crossing the antimeridian spans the longitudes between, so minLng is never
+// greater than maxLng. An empty track returns zeros.
+func (track Track) BBox() (minLat, minLng, maxLat, maxLng float64) {
+	if len(track) == 0 {
+		return 0, 0, 0, 0
+	}
+	minLat, minLng = math.Inf(1), math.Inf(1)
+	maxLat, maxLng = math.Inf(-1), math.Inf(-1)
+	for _, fix := range track {
+		minLat, maxLat = math.Min(minLat, fix.Point.Lat()), math.Max(maxLat, fix.Point.Lat())
+		minLng, maxLng = math.Min(minLng, fix.Point.Lng()), math.Max(maxLng, fix.Point.Lng())
+	}
+	return minLat, minLng, maxLat, maxLng
+}
@@ -0,0 +1,66 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFix(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	fix := NewFix(NewPoint(51.5, -0.12), start)
+	_, ok := fix.Speed()
+	assert.False(t, ok)
+	_, ok = fix.Heading()
+	assert.False(t, ok)
+	_, ok = fix.Accuracy()
+	assert.False(t, ok)
+
+	moving := fix.WithSpeed(12.5).WithHeading(-90).WithAccuracy(8)
+	speed, ok := moving.Speed()
+	assert.True(t, ok)
+	assert.Equal(t, 12.5, speed)
+	heading, ok := moving.Heading()
+	assert.True(t, ok)
+	assert.Equal(t, 270.0, heading)
+	accuracy, ok := moving.Accuracy()
+	assert.True(t, ok)
+	assert.Equal(t, 8.0, accuracy)
+
+	// A stationary fix still has a speed
+	speed, ok = fix.WithSpeed(0).Speed()
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, speed)
+	_, ok = fix.Speed()
+	assert.False(t, ok, "WithSpeed must not change the original fix")
+}
+
+func TestTrack(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	a, b, c := NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)
+	fixes := []Fix{NewFix(c, start.Add(20*time.Minute)), NewFix(a, start), NewFix(b, start.Add(5*time.Minute))}
+	track := NewTrack(fixes...)
+
+	assert.Equal(t, []*Point{a, b, c}, track.Points())
+	assert.Equal(t, c, fixes[0].Point, "NewTrack must not reorder the fixes passed in")
+	assert.Equal(t, start, track.Start())
+	assert.Equal(t, start.Add(20*time.Minute), track.End())
+	assert.Equal(t, 20*time.Minute, track.Duration())
+	assert.InDelta(t, a.DistanceTo(b)+b.DistanceTo(c), track.Length(), 1e-6)
+	assert.InDelta(t, 222390, track.Length(), 10)
+
+	minLat, minLng, maxLat, maxLng := track.BBox()
+	assert.Equal(t, []float64{0, 0, 1, 1}, []float64{minLat, minLng, maxLat, maxLng})
+
+	var empty Track
+	assert.Zero(t, empty.Duration())
+	assert.Zero(t, empty.Length())
+	assert.True(t, empty.Start().IsZero())
+	minLat, minLng, maxLat, maxLng = empty.BBox()
+	assert.Equal(t, []float64{0, 0, 0, 0}, []float64{minLat, minLng, maxLat, maxLng})
+
+	single := NewTrack(NewFix(a, start))
+	assert.Zero(t, single.Duration())
+	assert.Zero(t, single.Length())
+}
@@ -0,0 +1,234 @@
+package geofence
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change a Tracker reports for an entity and a group key.
+type EventType int
+
+const (
+	// Enter is reported when a key becomes valid for an entity's position.
+	Enter EventType = iota + 1
+	// Exit is reported when a key stops being valid for an entity's position.
+	Exit
+	// Dwell is reported once per visit when an entity has stayed inside a key for the
+	// duration set by WithDwell.
+	Dwell
+	// Drag is reported when an entity watched by WatchAnchor leaves its swing circle.
+	Drag
+)
+
+// Returns the name of the event type, e.g. "ENTER".
+func (eventType EventType) String() string {
+	switch eventType {
+	case Enter:
+		return "ENTER"
+	case Exit:
+		return "EXIT"
+	case Dwell:
+		return "DWELL"
+	case Drag:
+		return "DRAG"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Event is a change in the keys that are valid for an entity, or a dragged anchor, see Tracker.
+type Event[K comparable] struct {
+	Type   EventType
+	Entity string
+	Key    K
+	Point  *Point
+	Time   time.Time
+	// Duration is how long the entity had been inside the key, for Exit and Dwell events
+	Duration time.Duration
+}
+
+// TrackerOption configures a Tracker, e.g. NewTracker(group, handler, WithDwell(15*time.Minute)).
+type TrackerOption func(options *trackerOptions) error
+
+type trackerOptions struct {
+	dwell   time.Duration
+	samples int
+}
+
+// WithDwell reports a Dwell event when an entity has been inside a key for at least the
+// duration, e.g. a truck parked at a depot. The duration is measured between update
+// timestamps, so the event comes with the first update after it has passed.
+func WithDwell(duration time.Duration) TrackerOption {
+	return func(options *trackerOptions) error {
+		if duration <= 0 {
+			return fmt.Errorf("dwell duration must be positive, got %v", duration)
+		}
+		options.dwell = duration
+		return nil
+	}
+}
+
+// WithHysteresis requires a key to be valid, or invalid, for the given number of consecutive
+// updates of an entity before reporting Enter, or Exit, so GPS noise near an edge does not
+// flip the state back and forth. The default of 1 reports every change straight away. The
+// visit is then taken to have started, or ended, at the first update of the run.
+func WithHysteresis(samples int) TrackerOption {
+	return func(options *trackerOptions) error {
+		if samples < 1 {
+			return fmt.Errorf("hysteresis samples must be at least 1, got %d", samples)
+		}
+		options.samples = samples
+		return nil
+	}
+}
+
+// Tracker follows the positions of entities, e.g. vehicles, through a GeofenceGroup and reports
+// an Enter event when a key becomes valid for an entity and an Exit event when it stops being
+// valid. Entities start outside every key, so the first update of an entity reports an Enter
+// for each key valid at its position. A Tracker is safe for concurrent use.
+type Tracker[K comparable] struct {
+	mu       sync.Mutex
+	group    *GeofenceGroup[K]
+	handler  func(Event[K])
+	options  trackerOptions
+	entities map[string]*trackedEntity[K]
+}
+
+type trackedEntity[K comparable] struct {
+	time   time.Time
+	inside map[K]*visit
+	// pending holds the keys whose validity has changed for fewer updates than WithHysteresis requires
+	pending map[K]*streak
+	// anchor is set by WatchAnchor
+	anchor *anchorState
+}
+
+// streak is a run of consecutive updates that disagree with the entity's state for a key.
+type streak struct {
+	count int
+	since time.Time
+}
+
+// visit is an entity's stay inside a key.
+type visit struct {
+	entered time.Time
+	dwelled bool
+}
+
+// NewTracker returns a Tracker over the group. The handler, if not nil, is called with every
+// event of an Update, in order, before Update returns, e.g. to send it on a channel. It is called
+// without the Tracker locked, so a handler that blocks only holds up its own Update and may call
+// the Tracker, though the events of concurrent Updates reach it in no particular order. As with
+// NewGeofence, an invalid option is ignored.
+func NewTracker[K comparable](group *GeofenceGroup[K], handler func(Event[K]), opts ...TrackerOption) *Tracker[K] {
+	tracker := &Tracker[K]{group: group, handler: handler, options: trackerOptions{samples: 1}, entities: make(map[string]*trackedEntity[K])}
+	for _, opt := range opts {
+		opt(&tracker.options)
+	}
+	return tracker
+}
+
+// Update moves the entity to the point at the timestamp and returns the resulting events,
+// exits before enters, dwells and drags. Updates older than the entity's last update are ignored,
+// since the entity has already moved on.
+func (tracker *Tracker[K]) Update(entity string, point *Point, timestamp time.Time) []Event[K] {
+	events := tracker.update(entity, point, timestamp)
+	if tracker.handler != nil {
+		for _, event := range events {
+			tracker.handler(event)
+		}
+	}
+	return events
+}
+
+// update moves the entity with the Tracker locked and returns the events for Update.
+func (tracker *Tracker[K]) update(entity string, point *Point, timestamp time.Time) []Event[K] {
+	validKeys := tracker.group.GetValidKeys(point)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	state, ok := tracker.entities[entity]
+	if !ok {
+		state = &trackedEntity[K]{inside: make(map[K]*visit), pending: make(map[K]*streak)}
+		tracker.entities[entity] = state
+	} else if timestamp.Before(state.time) {
+		return nil
+	}
+	state.time = timestamp
+
+	var events []Event[K]
+	for key, visit := range state.inside {
+		if validKeys[key] {
+			delete(state.pending, key)
+		} else if since, ok := state.settle(key, timestamp, tracker.options.samples); ok {
+			delete(state.inside, key)
+			events = append(events, Event[K]{Type: Exit, Entity: entity, Key: key, Point: point, Time: timestamp, Duration: since.Sub(visit.entered)})
+		}
+	}
+	for key := range validKeys {
+		if _, ok := state.inside[key]; ok {
+			continue
+		}
+		if since, ok := state.settle(key, timestamp, tracker.options.samples); ok {
+			state.inside[key] = &visit{entered: since}
+			events = append(events, Event[K]{Type: Enter, Entity: entity, Key: key, Point: point, Time: timestamp})
+		}
+	}
+	// An entry run is broken by any update outside the key
+	for key := range state.pending {
+		if _, ok := state.inside[key]; !ok && !validKeys[key] {
+			delete(state.pending, key)
+		}
+	}
+	if tracker.options.dwell > 0 {
+		for key, visit := range state.inside {
+			if duration := timestamp.Sub(visit.entered); !visit.dwelled && duration >= tracker.options.dwell {
+				visit.dwelled = true
+				events = append(events, Event[K]{Type: Dwell, Entity: entity, Key: key, Point: point, Time: timestamp, Duration: duration})
+			}
+		}
+	}
+	return append(events, tracker.checkAnchor(entity, state, point, timestamp)...)
+}
+
+// settle counts the update towards the key's streak, returning the time the streak started
+// and whether it is now long enough to change the state.
+func (state *trackedEntity[K]) settle(key K, timestamp time.Time, samples int) (time.Time, bool) {
+	run, ok := state.pending[key]
+	if !ok {
+		run = &streak{since: timestamp}
+		state.pending[key] = run
+	}
+	run.count++
+	if run.count < samples {
+		return time.Time{}, false
+	}
+	delete(state.pending, key)
+	return run.since, true
+}
+
+// Inside returns the keys the entity is currently inside, nil for an unknown entity.
+func (tracker *Tracker[K]) Inside(entity string) map[K]bool {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	state, ok := tracker.entities[entity]
+	if !ok {
+		return nil
+	}
+	inside := make(map[K]bool, len(state.inside))
+	for key := range state.inside {
+		inside[key] = true
+	}
+	return inside
+}
+
+// Forget drops the entity's state without reporting any events, e.g. once a vehicle is retired.
+func (tracker *Tracker[K]) Forget(entity string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	delete(tracker.entities, entity)
+}
@@ -0,0 +1,145 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("depot", []*Geofence{square(0, 0, 10)}, nil)
+	group.Add("yard", []*Geofence{square(5, 5, 10)}, nil)
+
+	var handled []Event[string]
+	tracker := NewTracker(group, func(event Event[string]) {
+		handled = append(handled, event)
+	})
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Empty(t, tracker.Update("truck", NewPoint(20, 20), start))
+	assert.Empty(t, tracker.Inside("truck"))
+	assert.Nil(t, tracker.Inside("van"))
+
+	point := NewPoint(2, 2)
+	events := tracker.Update("truck", point, start.Add(time.Minute))
+	assert.Equal(t, []Event[string]{{Type: Enter, Entity: "truck", Key: "depot", Point: point, Time: start.Add(time.Minute)}}, events)
+	assert.Empty(t, tracker.Update("truck", NewPoint(3, 3), start.Add(2*time.Minute)))
+
+	events = tracker.Update("truck", NewPoint(12, 12), start.Add(3*time.Minute))
+	assert.Len(t, events, 2)
+	assert.Equal(t, Exit, events[0].Type)
+	assert.Equal(t, "depot", events[0].Key)
+	assert.Equal(t, Enter, events[1].Type)
+	assert.Equal(t, "yard", events[1].Key)
+	assert.Equal(t, map[string]bool{"yard": true}, tracker.Inside("truck"))
+
+	// A late fix from before the last update is ignored
+	assert.Empty(t, tracker.Update("truck", NewPoint(2, 2), start.Add(90*time.Second)))
+
+	// Entities are independent
+	events = tracker.Update("van", NewPoint(7, 7), start)
+	assert.Len(t, events, 2)
+	assert.Equal(t, map[string]bool{"depot": true, "yard": true}, tracker.Inside("van"))
+
+	assert.Len(t, handled, 5)
+	assert.Equal(t, "ENTER", handled[0].Type.String())
+	assert.Equal(t, "EXIT", handled[1].Type.String())
+
+	tracker.Forget("van")
+	assert.Nil(t, tracker.Inside("van"))
+}
+
+func TestTrackerHandlerUnlocked(t *testing.T) {
+	group := NewGeofenceGroup[string]()
+	group.Add("depot", []*Geofence{square(0, 0, 10)}, nil)
+
+	// The handler calls back into the Tracker and blocks on an unbuffered channel
+	events, waiting := make(chan Event[string]), make(chan bool)
+	var tracker *Tracker[string]
+	tracker = NewTracker(group, func(event Event[string]) {
+		assert.True(t, tracker.Inside(event.Entity)[event.Key])
+		waiting <- true
+		events <- event
+	})
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	go tracker.Update("truck", NewPoint(2, 2), start)
+	<-waiting
+
+	// Other entities are tracked while the handler waits
+	done := make(chan []Event[string])
+	go func() { done <- tracker.Update("van", NewPoint(20, 20), start) }()
+	select {
+	case updated := <-done:
+		assert.Empty(t, updated)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update blocked by another Update's handler")
+	}
+	assert.Equal(t, "truck", (<-events).Entity)
+}
+
+func TestTrackerDwell(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	tracker := NewTracker(group, nil, WithDwell(15*time.Minute), WithDwell(0))
+	assert.Equal(t, 15*time.Minute, tracker.options.dwell)
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	inside := NewPoint(5, 5)
+	assert.Len(t, tracker.Update("truck", inside, start), 1)
+	assert.Empty(t, tracker.Update("truck", inside, start.Add(10*time.Minute)))
+	events := tracker.Update("truck", inside, start.Add(16*time.Minute))
+	assert.Equal(t, []Event[int]{{Type: Dwell, Entity: "truck", Key: 1, Point: inside, Time: start.Add(16 * time.Minute), Duration: 16 * time.Minute}}, events)
+	assert.Equal(t, "DWELL", events[0].Type.String())
+	assert.Empty(t, tracker.Update("truck", inside, start.Add(30*time.Minute)))
+
+	events = tracker.Update("truck", NewPoint(20, 20), start.Add(40*time.Minute))
+	assert.Equal(t, Exit, events[0].Type)
+	assert.Equal(t, 40*time.Minute, events[0].Duration)
+
+	// A new visit can dwell again, and a short visit never does
+	assert.Len(t, tracker.Update("truck", inside, start.Add(50*time.Minute)), 1)
+	assert.Len(t, tracker.Update("truck", NewPoint(20, 20), start.Add(55*time.Minute)), 1)
+	assert.Len(t, tracker.Update("truck", inside, start.Add(60*time.Minute)), 1)
+	assert.Equal(t, Dwell, tracker.Update("truck", inside, start.Add(75*time.Minute))[0].Type)
+}
+
+func TestTrackerHysteresis(t *testing.T) {
+	group := NewGeofenceGroup[int]()
+	group.Add(1, []*Geofence{square(0, 0, 10)}, nil)
+	tracker := NewTracker(group, nil, WithHysteresis(3), WithHysteresis(0))
+	assert.Equal(t, 3, tracker.options.samples)
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	inside, outside := NewPoint(9.9, 5), NewPoint(10.1, 5)
+
+	// Jitter across the edge never settles
+	for i := 0; i < 9; i++ {
+		point := inside
+		if i%3 == 2 {
+			point = outside
+		}
+		assert.Empty(t, tracker.Update("truck", point, at(i)))
+	}
+
+	assert.Empty(t, tracker.Update("truck", inside, at(10)))
+	assert.Empty(t, tracker.Update("truck", inside, at(11)))
+	events := tracker.Update("truck", inside, at(12))
+	assert.Len(t, events, 1)
+	assert.Equal(t, Enter, events[0].Type)
+	assert.Equal(t, map[int]bool{1: true}, tracker.Inside("truck"))
+
+	assert.Empty(t, tracker.Update("truck", outside, at(13)))
+	assert.Empty(t, tracker.Update("truck", outside, at(14)))
+	assert.Empty(t, tracker.Update("truck", inside, at(15)))
+	assert.Empty(t, tracker.Update("truck", outside, at(16)))
+	assert.Empty(t, tracker.Update("truck", outside, at(17)))
+	events = tracker.Update("truck", outside, at(18))
+	assert.Len(t, events, 1)
+	assert.Equal(t, Exit, events[0].Type)
+	// The visit ran from the first of the three inside updates to the first of the three outside updates
+	assert.Equal(t, 6*time.Minute, events[0].Duration)
+}
@@ -41,7 +41,7 @@ func segmentsIntersect(s1p1 *Point, s1p2 *Point, s2p1 *Point, s2p2 *Point) bool
 
 	if rCrossS == 0 {
 		if vectorCrossProduct(qMinusP, r) == 0 {
-			return true
+			return collinearSegmentsOverlap(p, r, q, s)
 		} else {
 			return false
 		}
@@ -52,6 +52,24 @@ func segmentsIntersect(s1p1 *Point, s1p2 *Point, s2p1 *Point, s2p2 *Point) bool
 	return t >= 0 && t <= 1 && u >= 0 && u <= 1
 }
 
+// collinearSegmentsOverlap checks whether the collinear segments p+r and q+s share a point.
+func collinearSegmentsOverlap(p *Point, r *Point, q *Point, s *Point) bool {
+	rDotR := vectorDotProduct(r, r)
+	if rDotR == 0 {
+		sDotS := vectorDotProduct(s, s)
+		if sDotS == 0 {
+			return p.Lat() == q.Lat() && p.Lng() == q.Lng()
+		}
+		t := vectorDotProduct(vectorDifference(p, q), s) / sDotS
+		return t >= 0 && t <= 1
+	}
+
+	// Position of q and q+s along p+r, where 0 is p and 1 is p+r
+	t0 := vectorDotProduct(vectorDifference(q, p), r) / rDotR
+	t1 := t0 + vectorDotProduct(s, r)/rDotR
+	return math.Max(t0, t1) >= 0 && math.Min(t0, t1) <= 1
+}
+
 // here we temporarily use point struct to store vector
 func vectorDifference(p1 *Point, p2 *Point) *Point {
 	return NewPoint(p1.Lat()-p2.Lat(), p1.Lng()-p2.Lng())
@@ -60,3 +78,22 @@ func vectorDifference(p1 *Point, p2 *Point) *Point {
 func vectorCrossProduct(p1 *Point, p2 *Point) float64 {
 	return p1.Lat()*p2.Lng() - p1.Lng()*p2.Lat()
 }
+
+func vectorDotProduct(p1 *Point, p2 *Point) float64 {
+	return p1.Lat()*p2.Lat() + p1.Lng()*p2.Lng()
+}
+
+// closeRing returns the ring with its first point appended when it is not already closed,
+// so that edge iteration over consecutive points also covers the closing edge.
+func closeRing(ring []*Point) []*Point {
+	if len(ring) == 0 {
+		return ring
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first.Lat() == last.Lat() && first.Lng() == last.Lng() {
+		return ring
+	}
+	closed := make([]*Point, len(ring), len(ring)+1)
+	copy(closed, ring)
+	return append(closed, first)
+}
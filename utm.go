@@ -0,0 +1,271 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UTM coordinates are on the WGS 84 ellipsoid, unlike the rest of the package, which takes the
+// Earth as a sphere, as grid references are meant to be exact to the meter. The projection uses
+// Krüger's series to the sixth order (Karney, "Transverse Mercator with an accuracy of a few
+// nanometers", 2011), good to well under a millimeter across each zone.
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0 // For the southern hemisphere
+	wgs84Radius      = 6378137.0
+	wgs84Flattening  = 1 / 298.257223563
+)
+
+// utmBands are the latitude bands of UTM zones, 8 degrees each from 80°S, X being 12 degrees to
+// 84°N. Bands C to M are south of the equator.
+const utmBands = "CDEFGHJKLMNPQRSTUVWX"
+
+var utmSeries = newUTMSeries()
+
+// utmSeriesCoefficients holds the rectifying radius and the coefficients of the forward (alpha)
+// and inverse (beta) series.
+type utmSeriesCoefficients struct {
+	radius        float64
+	eccentricity  float64
+	alpha, beta   [6]float64
+	eccentricity2 float64
+}
+
+func newUTMSeries() utmSeriesCoefficients {
+	f := wgs84Flattening
+	n := f / (2 - f)
+	n2, n3, n4, n5, n6 := n*n, n*n*n, n*n*n*n, n*n*n*n*n, n*n*n*n*n*n
+	return utmSeriesCoefficients{
+		radius:        wgs84Radius / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		eccentricity:  math.Sqrt(f * (2 - f)),
+		eccentricity2: f * (2 - f),
+		alpha: [6]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [6]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+	}
+}
+
+// NewPointFromUTM returns the point at the easting and northing, in meters, of a UTM zone given
+// with its latitude band, e.g. NewPointFromUTM("31U", 448251, 5411952) for the Eiffel Tower.
+// Bands C to M are south of the equator and N to X north of it, so a zone named by hemisphere
+// only, e.g. "33N", is read correctly in the north, but a southern zone must be given a band
+// from C to M rather than "S". Polar UPS coordinates are not supported.
+func NewPointFromUTM(zone string, easting, northing float64) (*Point, error) {
+	number, band, err := parseUTMZone(zone)
+	if err != nil {
+		return nil, err
+	}
+	if !(easting > 0 && easting < 1000000) || !(northing >= 0 && northing <= utmFalseNorthing) {
+		return nil, fmt.Errorf("UTM easting %v and northing %v are outside zone %s", easting, northing, zone)
+	}
+	return utmToPoint(number, band >= 'N', easting, northing), nil
+}
+
+// parseUTMZone returns the number and band letter of a UTM zone such as "31U".
+func parseUTMZone(zone string) (int, byte, error) {
+	zone = strings.ToUpper(strings.TrimSpace(zone))
+	if len(zone) < 2 {
+		return 0, 0, fmt.Errorf("invalid UTM zone %q", zone)
+	}
+	number, err := strconv.Atoi(zone[:len(zone)-1])
+	band := zone[len(zone)-1]
+	if err != nil || number < 1 || number > 60 || strings.IndexByte(utmBands, band) < 0 {
+		return 0, 0, fmt.Errorf("invalid UTM zone %q, expected a number from 1 to 60 and a latitude band from C to X", zone)
+	}
+	return number, band, nil
+}
+
+// UTM returns the zone, with its latitude band, and the easting and northing in meters of the
+// point on the UTM grid, e.g. "31U", 448251, 5411952 for the Eiffel Tower. The zones of southern
+// Norway and Svalbard are widened as the standard defines. Latitudes beyond 80°S and 84°N, the
+// UPS polar areas, are given in the nearest band.
+func (p *Point) UTM() (zone string, easting, northing float64) {
+	lat, lng := math.Max(-80, math.Min(p.Lat(), 84)), math.Remainder(p.Lng(), 360)
+	number := int(math.Floor((lng+180)/6)) + 1
+	if number > 60 {
+		number = 1
+	}
+	switch {
+	case lat >= 56 && lat < 64 && lng >= 3 && lng < 12:
+		number = 32
+	case lat >= 72 && lng >= 0 && lng < 42:
+		number = 31 + 2*int(math.Floor((lng+3)/12))
+	}
+	band := int(math.Floor((lat + 80) / 8))
+	if band >= len(utmBands) {
+		band = len(utmBands) - 1
+	}
+	easting, northing = pointToUTM(number, p.Lat(), lng)
+	return strconv.Itoa(number) + string(utmBands[band]), easting, northing
+}
+
+// utmCentralMeridian returns the central meridian of the zone in degrees.
+func utmCentralMeridian(zone int) float64 {
+	return float64(zone*6 - 183)
+}
+
+// pointToUTM projects the lat/lng onto the zone. Zones east or west of the point's own still
+// give true coordinates, as the series holds far outside the zone's 6 degrees.
+func pointToUTM(zone int, lat, lng float64) (easting, northing float64) {
+	series := utmSeries
+	phi := lat * math.Pi / 180
+	lambda := math.Remainder(lng-utmCentralMeridian(zone), 360) * math.Pi / 180
+	sinPhi := math.Sin(phi)
+	tau := math.Sinh(math.Atanh(sinPhi) - series.eccentricity*math.Atanh(series.eccentricity*sinPhi))
+	xi0 := math.Atan2(tau, math.Cos(lambda))
+	eta0 := math.Atanh(math.Sin(lambda) / math.Sqrt(1+tau*tau))
+	xi, eta := xi0, eta0
+	for j, alpha := range series.alpha {
+		k := 2 * float64(j+1)
+		xi += alpha * math.Sin(k*xi0) * math.Cosh(k*eta0)
+		eta += alpha * math.Cos(k*xi0) * math.Sinh(k*eta0)
+	}
+	easting = utmFalseEasting + utmScale*series.radius*eta
+	northing = utmScale * series.radius * xi
+	if lat < 0 {
+		northing += utmFalseNorthing
+	}
+	return easting, northing
+}
+
+// utmToPoint returns the lat/lng at the easting and northing of the zone.
+func utmToPoint(zone int, north bool, easting, northing float64) *Point {
+	series := utmSeries
+	if !north {
+		northing -= utmFalseNorthing
+	}
+	xi := northing / (utmScale * series.radius)
+	eta := (easting - utmFalseEasting) / (utmScale * series.radius)
+	xi0, eta0 := xi, eta
+	for j, beta := range series.beta {
+		k := 2 * float64(j+1)
+		xi0 -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta0 -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	sinhEta, cosXi := math.Sinh(eta0), math.Cos(xi0)
+	conformal := math.Sin(xi0) / math.Sqrt(sinhEta*sinhEta+cosXi*cosXi)
+	lambda := math.Atan2(sinhEta, cosXi)
+
+	// Newton's method for the tangent of the latitude from that of the conformal latitude
+	e, e2 := series.eccentricity, series.eccentricity2
+	tau := conformal
+	for i := 0; i < 10; i++ {
+		sigma := math.Sinh(e * math.Atanh(e*tau/math.Sqrt(1+tau*tau)))
+		estimate := tau*math.Sqrt(1+sigma*sigma) - sigma*math.Sqrt(1+tau*tau)
+		delta := (conformal - estimate) / math.Sqrt(1+estimate*estimate) * (1 + (1-e2)*tau*tau) / ((1 - e2) * math.Sqrt(1+tau*tau))
+		tau += delta
+		if math.Abs(delta) < 1e-14 {
+			break
+		}
+	}
+	lat := math.Atan(tau) * 180 / math.Pi
+	lng := math.Remainder(utmCentralMeridian(zone)+lambda*180/math.Pi, 360)
+	return NewPoint(lat, lng)
+}
+
+// MGRS 100km squares are named by a column letter, from sets of 8 that cycle every 3 zones, and a
+// row letter, cycling every 2,000km and offset by 5 rows in even zones. I and O are not used.
+const (
+	mgrsColumns = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	mgrsRows    = "ABCDEFGHJKLMNPQRSTUV"
+)
+
+// NewPointFromMGRS returns the point at the southwest corner of an MGRS grid reference, e.g.
+// "31U DQ 48250 11951" for the Eiffel Tower, with or without spaces, to a precision from 10km
+// ("31UDQ41") to 1m. A reference of the square alone, e.g. "31UDQ", gives its corner. The polar
+// areas, bands A, B, Y and Z, are not supported.
+func NewPointFromMGRS(reference string) (*Point, error) {
+	compact := strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, reference))
+	digits := 0
+	for digits < len(compact) && compact[digits] >= '0' && compact[digits] <= '9' {
+		digits++
+	}
+	if digits < 1 || digits > 2 || len(compact) < digits+3 {
+		return nil, fmt.Errorf("invalid MGRS reference %q", reference)
+	}
+	zone, band, err := parseUTMZone(compact[:digits+1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid MGRS reference %q: %v", reference, err)
+	}
+	column := strings.IndexByte(mgrsColumns, compact[digits+1])
+	row := strings.IndexByte(mgrsRows, compact[digits+2])
+	numbers := compact[digits+3:]
+	// Each zone uses the 8 column letters of its set
+	set := (zone - 1) % 3
+	if column < 8*set || column >= 8*set+8 || row < 0 {
+		return nil, fmt.Errorf("invalid MGRS reference %q: unknown 100km square %q in zone %d", reference, compact[digits+1:digits+3], zone)
+	}
+	if len(numbers)%2 == 1 || len(numbers) > 10 || strings.Trim(numbers, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid MGRS reference %q: expected an even number of digits, up to 10", reference)
+	}
+
+	precision := len(numbers) / 2
+	easting, northing := 0.0, 0.0
+	if precision > 0 {
+		e, _ := strconv.Atoi(numbers[:precision])
+		n, _ := strconv.Atoi(numbers[precision:])
+		scale := math.Pow(10, float64(5-precision))
+		easting, northing = float64(e)*scale, float64(n)*scale
+	}
+	easting += float64(column-8*set+1) * 100000
+	if zone%2 == 0 {
+		row = (row + len(mgrsRows) - 5) % len(mgrsRows)
+	}
+	northing += float64(row) * 100000
+
+	// The row letters repeat every 2,000km, so the band picks the cycle; bands are under 1,400km
+	// tall, which leaves room for grid north to stray from the band's parallels
+	bandIndex := strings.IndexByte(utmBands, band)
+	_, bandNorthing := pointToUTM(zone, -80+8*float64(bandIndex), utmCentralMeridian(zone))
+	for northing < bandNorthing-300000 {
+		northing += 2000000
+	}
+	return utmToPoint(zone, band >= 'N', easting, northing), nil
+}
+
+// MGRS returns the MGRS grid reference of the point, to the given number of digits for each of
+// easting and northing from 0 (100km) to 5 (1m), e.g. "31UDQ4825011951" for the Eiffel Tower at
+// precision 5. Precisions outside the range are clamped.
+func (p *Point) MGRS(precision int) string {
+	precision = clampLevel(precision, 5)
+	zone, easting, northing := p.UTM()
+	number, _ := strconv.Atoi(zone[:len(zone)-1])
+	// The corners NewPointFromMGRS returns come back a rounding error short of the grid lines
+	easting, northing = easting+1e-6, northing+1e-6
+	column := int(math.Max(0, math.Min(math.Floor(easting/100000)-1, 7)))
+	row := int(math.Floor(northing/100000)) % len(mgrsRows)
+	if number%2 == 0 {
+		row = (row + 5) % len(mgrsRows)
+	}
+	set := (number - 1) % 3
+	reference := zone + string(mgrsColumns[8*set+column]) + string(mgrsRows[row])
+	if precision == 0 {
+		return reference
+	}
+	scale := math.Pow(10, float64(5-precision))
+	e := int(math.Floor(math.Mod(easting, 100000) / scale))
+	n := int(math.Floor(math.Mod(northing, 100000) / scale))
+	return fmt.Sprintf("%s%0*d%0*d", reference, precision, e, precision, n)
+}
@@ -0,0 +1,78 @@
+package geofence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUTM(t *testing.T) {
+	zone, easting, northing := NewPoint(51.2, 7.5).UTM()
+	assert.Equal(t, "32U", zone)
+	assert.InDelta(t, 395201.310, easting, 1e-3)
+	assert.InDelta(t, 5673135.241, northing, 1e-3)
+
+	for _, point := range []*Point{NewPoint(48.858370, 2.294481), NewPoint(-33.8568, 151.2153), NewPoint(0.5, -78.2), NewPoint(-0.5, -78.2),
+		NewPoint(60.39, 5.32), NewPoint(78.22, 15.65), NewPoint(83.9, -30), NewPoint(-79.9, 179.9)} {
+		zone, easting, northing := point.UTM()
+		decoded, err := NewPointFromUTM(zone, easting, northing)
+		if assert.NoError(t, err, point) {
+			assert.InDelta(t, point.Lat(), decoded.Lat(), 1e-9, point)
+			assert.InDelta(t, point.Lng(), decoded.Lng(), 1e-9, point)
+		}
+	}
+	// Bergen and Longyearbyen are in the widened zones
+	zone, _, _ = NewPoint(60.39, 5.32).UTM()
+	assert.Equal(t, "32V", zone)
+	zone, _, _ = NewPoint(78.22, 15.65).UTM()
+	assert.Equal(t, "33X", zone)
+	zone, _, _ = NewPoint(-33.8568, 151.2153).UTM()
+	assert.Equal(t, "56H", zone)
+
+	point, err := NewPointFromUTM("32n", 395201.310, 5673135.241)
+	assert.NoError(t, err)
+	assert.InDelta(t, 51.2, point.Lat(), 1e-8)
+	assert.InDelta(t, 7.5, point.Lng(), 1e-8)
+	for _, bad := range []string{"", "U", "0U", "61U", "32A", "32I", "3 2U"} {
+		_, err := NewPointFromUTM(bad, 395201, 5673135)
+		assert.Error(t, err, bad)
+	}
+	_, err = NewPointFromUTM("32U", -1, 5673135)
+	assert.Error(t, err)
+	_, err = NewPointFromUTM("32U", 395201, 10000001)
+	assert.Error(t, err)
+}
+
+func TestMGRS(t *testing.T) {
+	eiffel := NewPoint(48.858370, 2.294481)
+	assert.Equal(t, "31UDQ4825011951", eiffel.MGRS(5))
+	assert.Equal(t, "31UDQ4811", eiffel.MGRS(2))
+	assert.Equal(t, "31UDQ", eiffel.MGRS(0))
+	assert.Equal(t, "32ULB9520173135", NewPoint(51.2, 7.5).MGRS(5))
+
+	point, err := NewPointFromMGRS("31u dq 48250 11951")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, point.DistanceTo(eiffel), 2)
+	corner, err := NewPointFromMGRS("31UDQ41")
+	assert.NoError(t, err)
+	zone, easting, northing := corner.UTM()
+	assert.Equal(t, "31U", zone)
+	assert.InDelta(t, 440000, easting, 1e-6)
+	assert.InDelta(t, 5410000, northing, 1e-6)
+
+	// Every square comes back to the same reference, north and south, in odd and even zones
+	for _, point := range []*Point{NewPoint(-33.8568, 151.2153), NewPoint(-54.8, -68.3), NewPoint(0.1, 30.1), NewPoint(-0.1, 30.1),
+		NewPoint(64.1, -21.9), NewPoint(78.22, 15.65), NewPoint(35.7, 139.7), NewPoint(-79.5, 10), NewPoint(83.5, 10)} {
+		reference := point.MGRS(5)
+		decoded, err := NewPointFromMGRS(reference)
+		if assert.NoError(t, err, reference) {
+			assert.Equal(t, reference, decoded.MGRS(5))
+			assert.InDelta(t, 0, decoded.DistanceTo(point), 2, reference)
+		}
+	}
+
+	for _, bad := range []string{"", "31", "31U", "31UD", "31UDQ4", "31UDQ123", "31UIQ12", "31UJQ12", "31UDQ12345678901", "31UDQ12a4", "A31UDQ", "61UDQ12"} {
+		_, err := NewPointFromMGRS(bad)
+		assert.Error(t, err, bad)
+	}
+}
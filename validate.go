@@ -0,0 +1,189 @@
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInvalidCoordinate is returned for vertices with a NaN or infinite Lat or Lng.
+	ErrInvalidCoordinate = errors.New("geofence vertex coordinates must be finite")
+	// ErrTooFewVertices is returned for rings with fewer than three distinct vertices.
+	ErrTooFewVertices = errors.New("geofence needs at least three distinct vertices")
+	// ErrZeroArea is returned for rings whose vertices are all collinear.
+	ErrZeroArea = errors.New("geofence has zero area")
+	// ErrSelfIntersecting is returned for rings whose edges cross each other.
+	ErrSelfIntersecting = errors.New("geofence edges intersect each other")
+	// ErrOutOfRange is returned, with WithCoordinateOrder, for vertices outside ±90° lat or ±180° lng.
+	ErrOutOfRange = errors.New("geofence vertex is outside the range of latitudes and longitudes")
+	// ErrHoleOutside is returned for holes that are not strictly inside the outer ring.
+	ErrHoleOutside = errors.New("geofence hole is not inside the outer ring")
+)
+
+// NewGeofenceChecked is the construct for Geofence that rejects polygons Inside cannot
+// answer sensibly: NaN or infinite coordinates, fewer than three distinct vertices, zero
+// area or self-intersecting edges. Unlike NewGeofence it also returns an error for invalid
+// options. The errors can be tested for with errors.Is, e.g. errors.Is(err, ErrZeroArea).
+func NewGeofenceChecked(points []*Point, opts ...Option) (*Geofence, error) {
+	if err := validateRing(points); err != nil {
+		return nil, err
+	}
+	geofence, err := newGeofence(points, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geofence, nil
+}
+
+// NewGeofenceWithHolesChecked is the construct for a Geofence with holes that validates the
+// outer ring and every hole as NewGeofenceChecked does, and also rejects holes that are not
+// strictly inside the outer ring or whose edges cross another hole. Holes may nest.
+func NewGeofenceWithHolesChecked(outer []*Point, holes [][]*Point, opts ...Option) (*Geofence, error) {
+	if err := validateRing(outer); err != nil {
+		return nil, err
+	}
+	outerRing := closeRing(outer)
+	outerPolygon := NewPolygon(outer)
+	for i, hole := range holes {
+		if err := validateRing(hole); err != nil {
+			return nil, fmt.Errorf("hole %d: %w", i, err)
+		}
+		holeRing := closeRing(hole)
+		if haveIntersectingEdges(holeRing, outerRing) || !outerPolygon.Contains(hole[0]) {
+			return nil, fmt.Errorf("hole %d: %w", i, ErrHoleOutside)
+		}
+		for j := 0; j < i; j++ {
+			if haveIntersectingEdges(holeRing, closeRing(holes[j])) {
+				return nil, fmt.Errorf("holes %d and %d: %w", j, i, ErrSelfIntersecting)
+			}
+		}
+	}
+	geofence, err := newGeofence(outer, holes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geofence, nil
+}
+
+// NewAnnulusGeofenceChecked is the construct for a Geofence between two circles, as built by
+// NewAnnulusGeofence, that returns an error when the center is not finite, either radius is not
+// positive or innerRadius is not less than outerRadius, or for invalid options.
+func NewAnnulusGeofenceChecked(center *Point, innerRadius, outerRadius float64, opts ...Option) (*Geofence, error) {
+	if math.IsNaN(center.Lat()) || math.IsNaN(center.Lng()) || math.IsInf(center.Lat(), 0) || math.IsInf(center.Lng(), 0) {
+		return nil, fmt.Errorf("annulus center: %w", ErrInvalidCoordinate)
+	}
+	switch {
+	case !(innerRadius > 0) || math.IsInf(innerRadius, 0):
+		return nil, fmt.Errorf("annulus inner radius must be positive, got %v", innerRadius)
+	case !(outerRadius > innerRadius) || math.IsInf(outerRadius, 0):
+		return nil, fmt.Errorf("annulus outer radius must be greater than the inner radius %v, got %v", innerRadius, outerRadius)
+	}
+	outer, holes := annulusRings(center, innerRadius, outerRadius, opts)
+	geofence, err := newGeofence(outer, holes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geofence, nil
+}
+
+// NewBBoxGeofenceChecked is the construct for a rectangular Geofence, as built by
+// NewBBoxGeofence, that returns an error for bounds that are not finite, latitudes beyond ±90°,
+// a minLat not less than maxLat or equal longitudes, or for invalid options. A minLng greater
+// than maxLng still crosses the antimeridian.
+func NewBBoxGeofenceChecked(minLat, minLng, maxLat, maxLng float64, opts ...Option) (*Geofence, error) {
+	for _, bound := range []float64{minLat, minLng, maxLat, maxLng} {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return nil, fmt.Errorf("bounding box: %w", ErrInvalidCoordinate)
+		}
+	}
+	switch {
+	case minLat < -90 || maxLat > 90:
+		return nil, fmt.Errorf("bounding box latitudes must be from -90 to 90, got %v to %v: %w", minLat, maxLat, ErrOutOfRange)
+	case minLat >= maxLat:
+		return nil, fmt.Errorf("bounding box minLat must be less than maxLat %v, got %v", maxLat, minLat)
+	case minLng == maxLng:
+		return nil, fmt.Errorf("bounding box from longitude %v to %v: %w", minLng, maxLng, ErrZeroArea)
+	}
+	geofence, err := newBBoxGeofence(minLat, minLng, maxLat, maxLng, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geofence, nil
+}
+
+func validateRing(points []*Point) error {
+	for i, point := range points {
+		if math.IsNaN(point.Lat()) || math.IsNaN(point.Lng()) || math.IsInf(point.Lat(), 0) || math.IsInf(point.Lng(), 0) {
+			return fmt.Errorf("vertex %d: %w", i, ErrInvalidCoordinate)
+		}
+	}
+	ring := dedupeRing(points)
+	if countDistinct(ring) < 3 {
+		return ErrTooFewVertices
+	}
+	if ringSelfIntersects(ring) {
+		return ErrSelfIntersecting
+	}
+	if ringArea(ring) == 0 {
+		return ErrZeroArea
+	}
+	return nil
+}
+
+// dedupeRing returns the ring without repeated consecutive vertices or a closing vertex.
+func dedupeRing(points []*Point) []*Point {
+	ring := make([]*Point, 0, len(points))
+	for _, point := range points {
+		if len(ring) > 0 && samePoint(ring[len(ring)-1], point) {
+			continue
+		}
+		ring = append(ring, point)
+	}
+	for len(ring) > 1 && samePoint(ring[0], ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+	return ring
+}
+
+func countDistinct(points []*Point) int {
+	distinct := make(map[[2]float64]bool, len(points))
+	for _, point := range points {
+		distinct[[2]float64{point.Lat(), point.Lng()}] = true
+	}
+	return len(distinct)
+}
+
+func samePoint(p1 *Point, p2 *Point) bool {
+	return p1.Lat() == p2.Lat() && p1.Lng() == p2.Lng()
+}
+
+// ringArea returns the signed planar area of the ring using the shoelace formula,
+// positive when the vertices run counterclockwise with Lat as x and Lng as y.
+func ringArea(ring []*Point) float64 {
+	area := 0.0
+	for i := 0; i < len(ring); i++ {
+		area += vectorCrossProduct(ring[i], ring[(i+1)%len(ring)])
+	}
+	return area / 2
+}
+
+// ringSelfIntersects checks every edge against the edges that do not share a vertex with it.
+func ringSelfIntersects(ring []*Point) bool {
+	closed := closeRing(ring)
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		// The closing edge is adjacent to the first edge, so leave it out of the chain
+		chainEnd := n + 1
+		if i == 0 {
+			chainEnd = n
+		}
+		if i+2 > chainEnd-2 {
+			continue
+		}
+		if haveIntersectingEdges(closed[i:i+2], closed[i+2:chainEnd]) {
+			return true
+		}
+	}
+	return false
+}
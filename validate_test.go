@@ -0,0 +1,181 @@
+package geofence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGeofenceChecked(t *testing.T) {
+	geofence, err := NewGeofenceChecked([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0)})
+	assert.NoError(t, err)
+	assert.True(t, geofence.Inside(NewPoint(5, 5)))
+
+	// Collinear but separate edges are not an intersection
+	comb := []*Point{NewPoint(0, 0), NewPoint(0, 3), NewPoint(2, 3), NewPoint(2, 2), NewPoint(1, 2), NewPoint(1, 1), NewPoint(2, 1), NewPoint(2, 0)}
+	_, err = NewGeofenceChecked(comb)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		points []*Point
+		err    error
+	}{
+		{"empty", nil, ErrTooFewVertices},
+		{"two vertices", []*Point{NewPoint(0, 0), NewPoint(1, 1)}, ErrTooFewVertices},
+		{"duplicates", []*Point{NewPoint(0, 0), NewPoint(1, 1), NewPoint(1, 1), NewPoint(0, 0)}, ErrTooFewVertices},
+		{"back and forth", []*Point{NewPoint(0, 0), NewPoint(1, 1), NewPoint(0, 0), NewPoint(1, 1)}, ErrTooFewVertices},
+		{"collinear", []*Point{NewPoint(0, 0), NewPoint(1, 1), NewPoint(2, 2)}, ErrZeroArea},
+		{"bow tie", []*Point{NewPoint(0, 0), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 10)}, ErrSelfIntersecting},
+		{"touching", []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(5, 0), NewPoint(10, 10), NewPoint(10, 0), NewPoint(5, 0)}, ErrSelfIntersecting},
+	}
+	for _, test := range tests {
+		geofence, err := NewGeofenceChecked(test.points)
+		assert.ErrorIs(t, err, test.err, test.name)
+		assert.Nil(t, geofence, test.name)
+	}
+
+	_, err = NewGeofenceChecked(comb, WithGranularity(0))
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+}
+
+func TestNewGeofenceCheckedCoordinates(t *testing.T) {
+	geofence, err := NewGeofenceChecked([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10)})
+	assert.NoError(t, err)
+	assert.True(t, geofence.Inside(NewPoint(1, 5)))
+
+	_, err = NewGeofenceChecked([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(math.NaN(), 10)})
+	assert.ErrorIs(t, err, ErrInvalidCoordinate)
+	assert.EqualError(t, err, "vertex 2: geofence vertex coordinates must be finite")
+	_, err = NewGeofenceChecked([]*Point{NewPoint(0, math.Inf(-1)), NewPoint(0, 10), NewPoint(10, 10)})
+	assert.EqualError(t, err, "vertex 0: geofence vertex coordinates must be finite")
+
+	_, err = NewGeofenceChecked([]*Point{})
+	assert.ErrorIs(t, err, ErrTooFewVertices)
+	_, err = NewGeofenceChecked([]*Point{NewPoint(0, 0), NewPoint(0, 0), NewPoint(0, 0)})
+	assert.ErrorIs(t, err, ErrTooFewVertices)
+
+	_, err = NewGeofenceWithHolesChecked([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10)}, [][]*Point{{NewPoint(1, 5), NewPoint(math.NaN(), 6), NewPoint(2, 6)}})
+	assert.EqualError(t, err, "hole 0: vertex 1: geofence vertex coordinates must be finite")
+}
+
+func TestNewGeofenceWithHolesChecked(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 8), NewPoint(8, 8), NewPoint(8, 2)}
+	island := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence, err := NewGeofenceWithHolesChecked(outer, [][]*Point{hole, island})
+	assert.NoError(t, err)
+	assert.Equal(t, outer, geofence.Vertices())
+	assert.Equal(t, [][]*Point{hole, island}, geofence.Holes())
+	assert.False(t, geofence.Inside(NewPoint(3, 3)))
+
+	tests := []struct {
+		name    string
+		holes   [][]*Point
+		err     error
+		message string
+	}{
+		{"bow tie", [][]*Point{{NewPoint(2, 2), NewPoint(8, 8), NewPoint(8, 2), NewPoint(2, 8)}}, ErrSelfIntersecting, "hole 0: geofence edges intersect each other"},
+		{"outside", [][]*Point{{NewPoint(12, 2), NewPoint(12, 8), NewPoint(18, 8)}}, ErrHoleOutside, "hole 0: geofence hole is not inside the outer ring"},
+		{"crossing outer", [][]*Point{hole, {NewPoint(5, 5), NewPoint(5, 15), NewPoint(6, 15)}}, ErrHoleOutside, "hole 1: geofence hole is not inside the outer ring"},
+		{"crossing hole", [][]*Point{hole, {NewPoint(5, 5), NewPoint(5, 9), NewPoint(6, 9)}}, ErrSelfIntersecting, "holes 0 and 1: geofence edges intersect each other"},
+	}
+	for _, test := range tests {
+		geofence, err := NewGeofenceWithHolesChecked(outer, test.holes)
+		assert.ErrorIs(t, err, test.err, test.name)
+		assert.EqualError(t, err, test.message, test.name)
+		assert.Nil(t, geofence, test.name)
+	}
+
+	assert.Nil(t, NewGeofence(outer).Holes())
+	wrapped := []*Point{NewPoint(0, 170), NewPoint(0, -170), NewPoint(10, -170), NewPoint(10, 170)}
+	assert.Equal(t, wrapped, NewGeofence(wrapped).Vertices())
+}
+
+func TestWithCoordinateOrder(t *testing.T) {
+	// Chicago, given as [lng, lat] pairs
+	lngLat := []*Point{NewPoint(-87.9, 41.6), NewPoint(-87.9, 42.1), NewPoint(-87.4, 42.1), NewPoint(-87.4, 41.6)}
+	geofence, err := NewGeofenceChecked(lngLat, WithCoordinateOrder(LngLat))
+	assert.NoError(t, err)
+	assert.True(t, geofence.Inside(NewPoint(41.8, -87.6)))
+	assert.True(t, geofence.Inside(NewPointLngLat(-87.6, 41.8)))
+	assert.Equal(t, NewPoint(41.6, -87.9), geofence.Vertices()[0])
+
+	_, err = NewGeofenceChecked(lngLat, WithCoordinateOrder(LatLng))
+	assert.NoError(t, err)
+
+	// Sydney given as [lng, lat] but declared lat, lng has a lat beyond 90°
+	sydney := []*Point{NewPoint(151.1, -33.9), NewPoint(151.3, -33.9), NewPoint(151.3, -33.7)}
+	_, err = NewGeofenceChecked(sydney, WithCoordinateOrder(LatLng))
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.EqualError(t, err, "vertex 0 (151.1, -33.9): geofence vertex is outside the range of latitudes and longitudes")
+	_, err = NewGeofenceChecked(sydney, WithCoordinateOrder(LngLat))
+	assert.NoError(t, err)
+	// Without a declared order coordinates are not range checked
+	_, err = NewGeofenceChecked(sydney)
+	assert.NoError(t, err)
+
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(2, 2), NewPoint(2, 200), NewPoint(8, 8)}
+	_, err = NewGeofenceWithHolesChecked(outer, nil, WithCoordinateOrder(LatLng), WithHoles(hole))
+	assert.EqualError(t, err, "hole 0: vertex 1 (2, 200): geofence vertex is outside the range of latitudes and longitudes")
+
+	_, err = NewGeofenceChecked(outer, WithCoordinateOrder(CoordinateOrder(0)))
+	assert.EqualError(t, err, "unknown coordinate order 0")
+}
+
+func TestNewAnnulusGeofenceChecked(t *testing.T) {
+	center := NewPoint(50, -1)
+	annulus, err := NewAnnulusGeofenceChecked(center, 100, 500, WithGeodesic())
+	assert.NoError(t, err)
+	assert.False(t, annulus.Inside(center))
+	assert.True(t, annulus.Inside(center.PointAtDistanceAndBearing(0.3, 90)))
+
+	_, err = NewAnnulusGeofenceChecked(center, 500, 500, WithGeodesic())
+	assert.EqualError(t, err, "annulus outer radius must be greater than the inner radius 500, got 500")
+	_, err = NewAnnulusGeofenceChecked(center, 600, 500)
+	assert.EqualError(t, err, "annulus outer radius must be greater than the inner radius 600, got 500")
+	_, err = NewAnnulusGeofenceChecked(center, 0, 500)
+	assert.EqualError(t, err, "annulus inner radius must be positive, got 0")
+	_, err = NewAnnulusGeofenceChecked(center, math.NaN(), 500)
+	assert.Error(t, err)
+	_, err = NewAnnulusGeofenceChecked(center, 1, math.Inf(1))
+	assert.Error(t, err)
+	_, err = NewAnnulusGeofenceChecked(NewPoint(math.NaN(), 0), 1, 2)
+	assert.ErrorIs(t, err, ErrInvalidCoordinate)
+	_, err = NewAnnulusGeofenceChecked(center, 1, 2, WithGranularity(0))
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+
+	// Unchecked, the inner circle covers the whole geofence
+	assert.False(t, NewAnnulusGeofence(center, 600, 500).Inside(NewPoint(50, 1000)))
+}
+
+func TestNewBBoxGeofenceChecked(t *testing.T) {
+	bbox, err := NewBBoxGeofenceChecked(0, 170, 10, -170)
+	assert.NoError(t, err)
+	assert.True(t, bbox.Inside(NewPoint(5, 180)))
+	west, err := NewBBoxGeofenceChecked(0, -170, 5, -175)
+	assert.NoError(t, err)
+	for _, lng := range []float64{-170, 0, 180, -178} {
+		assert.True(t, west.Inside(NewPoint(2, lng)), "lng %v", lng)
+	}
+	assert.False(t, west.Inside(NewPoint(2, -172)))
+
+	_, err = NewBBoxGeofenceChecked(10, 0, 0, 10)
+	assert.EqualError(t, err, "bounding box minLat must be less than maxLat 0, got 10")
+	_, err = NewBBoxGeofenceChecked(0, 5, 10, 5)
+	assert.ErrorIs(t, err, ErrZeroArea)
+	_, err = NewBBoxGeofenceChecked(-91, 0, 10, 10)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = NewBBoxGeofenceChecked(0, math.NaN(), 10, 10)
+	assert.ErrorIs(t, err, ErrInvalidCoordinate)
+	_, err = NewBBoxGeofenceChecked(0, 0, 10, 10, WithGranularity(0))
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+	_, err = NewBBoxGeofenceChecked(0, 0, 10, 10, WithHoles([]*Point{NewPoint(2, 2), NewPoint(2, 4), NewPoint(4, 4)}), WithGranularity(0))
+	assert.EqualError(t, err, "granularity must be positive, got 0")
+
+	// Unchecked, invalid options are ignored and inverted latitudes contain nothing
+	assert.True(t, NewBBoxGeofence(0, 0, 10, 10, WithGranularity(0)).Inside(NewPoint(5, 5)))
+	assert.False(t, NewBBoxGeofence(10, 0, 0, 10).Inside(NewPoint(5, 5)))
+}
@@ -0,0 +1,228 @@
+package geofence
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// OGC well-known binary geometry types.
+const (
+	wkbPolygon      = 3
+	wkbMultiPolygon = 6
+)
+
+// EWKB flags, as written by PostGIS, in the high bits of the geometry type.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// NewGeofenceFromWKB builds a Geofence from a well-known binary Polygon or MultiPolygon, e.g.
+// from PostGIS ST_AsBinary, in either byte order. PostGIS EWKB, with an SRID or Z and M flags,
+// and ISO Z, M and ZM geometry types are accepted, the SRID and extra values being ignored. In
+// each polygon the first ring is the outer boundary and any further rings are holes. Options
+// are applied as for NewGeofence.
+func NewGeofenceFromWKB(data []byte, opts ...Option) (*Geofence, error) {
+	reader := &wkbReader{data: data}
+	geometryType, dimensions, err := reader.header()
+	if err != nil {
+		return nil, err
+	}
+
+	var geofence *Geofence
+	switch geometryType {
+	case wkbPolygon:
+		rings, err := reader.polygon(dimensions)
+		if err != nil {
+			return nil, err
+		}
+		geofence = NewGeofenceWithHoles(rings[0], rings[1:], opts...)
+	case wkbMultiPolygon:
+		count, err := reader.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("WKB multipolygon is empty")
+		}
+		var parts []*Geofence
+		for i := uint32(0); i < count; i++ {
+			partType, partDimensions, err := reader.header()
+			if err != nil {
+				return nil, err
+			}
+			if partType != wkbPolygon {
+				return nil, fmt.Errorf("WKB multipolygon holds geometry type %d, not a polygon", partType)
+			}
+			rings, err := reader.polygon(partDimensions)
+			if err != nil {
+				return nil, fmt.Errorf("WKB polygon %d: %v", i, err)
+			}
+			parts = append(parts, NewGeofenceWithHoles(rings[0], rings[1:], opts...))
+		}
+		geofence = newMultiGeofence(parts)
+	default:
+		return nil, fmt.Errorf("unsupported WKB geometry type %d", geometryType)
+	}
+
+	if rest := len(reader.data) - reader.pos; rest > 0 {
+		return nil, fmt.Errorf("unexpected %d bytes after WKB geometry", rest)
+	}
+	return geofence, nil
+}
+
+// wkbReader reads well-known binary, in the byte order of the geometry being read.
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// header reads a byte order marker and geometry type, returning the base type and the number
+// of values in each point.
+func (reader *wkbReader) header() (uint32, int, error) {
+	if reader.pos >= len(reader.data) {
+		return 0, 0, fmt.Errorf("WKB geometry is truncated")
+	}
+	switch reader.data[reader.pos] {
+	case 0:
+		reader.order = binary.BigEndian
+	case 1:
+		reader.order = binary.LittleEndian
+	default:
+		return 0, 0, fmt.Errorf("invalid WKB byte order %d", reader.data[reader.pos])
+	}
+	reader.pos++
+	geometryType, err := reader.uint32()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dimensions := 2
+	if geometryType&ewkbZ != 0 {
+		dimensions++
+	}
+	if geometryType&ewkbM != 0 {
+		dimensions++
+	}
+	if geometryType&ewkbSRID != 0 {
+		if _, err := reader.uint32(); err != nil {
+			return 0, 0, err
+		}
+	}
+	geometryType &^= ewkbZ | ewkbM | ewkbSRID
+	// ISO types add 1000 for Z, 2000 for M and 3000 for ZM
+	switch geometryType / 1000 {
+	case 1, 2:
+		dimensions++
+	case 3:
+		dimensions += 2
+	}
+	return geometryType % 1000, dimensions, nil
+}
+
+// polygon reads the rings of a polygon, dropping the closing point of each.
+func (reader *wkbReader) polygon(dimensions int) ([][]*Point, error) {
+	count, err := reader.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("WKB polygon is empty")
+	}
+	rings := make([][]*Point, count)
+	for i := range rings {
+		points, err := reader.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if points < 4 {
+			return nil, fmt.Errorf("WKB ring %d has %d points, at least 4 are required", i, points)
+		}
+		if int(points) > (len(reader.data)-reader.pos)/(8*dimensions) {
+			return nil, fmt.Errorf("WKB geometry is truncated")
+		}
+		ring := make([]*Point, 0, points)
+		for j := uint32(0); j < points; j++ {
+			x, y := reader.float64(), reader.float64()
+			for extra := 2; extra < dimensions; extra++ {
+				reader.float64()
+			}
+			ring = append(ring, NewPoint(y, x))
+		}
+		if first, last := ring[0], ring[len(ring)-1]; first.lat == last.lat && first.lng == last.lng {
+			ring = ring[:len(ring)-1]
+		}
+		rings[i] = ring
+	}
+	return rings, nil
+}
+
+func (reader *wkbReader) uint32() (uint32, error) {
+	if len(reader.data)-reader.pos < 4 {
+		return 0, fmt.Errorf("WKB geometry is truncated")
+	}
+	value := reader.order.Uint32(reader.data[reader.pos:])
+	reader.pos += 4
+	return value, nil
+}
+
+// float64 reads a value whose bytes the caller has checked are there.
+func (reader *wkbReader) float64() float64 {
+	value := math.Float64frombits(reader.order.Uint64(reader.data[reader.pos:]))
+	reader.pos += 8
+	return value
+}
+
+// WKB renders the Geofence as little-endian well-known binary, a Polygon with the holes as
+// interior rings or a MultiPolygon for a NewMultiGeofence, as accepted by PostGIS ST_GeomFromWKB.
+// A geofence without vertices is a Polygon with no rings.
+func (geofence *Geofence) WKB() []byte {
+	if len(geofence.parts) > 0 {
+		data := appendWKBHeader(nil, wkbMultiPolygon)
+		data = appendUint32(data, uint32(len(geofence.parts)))
+		for _, part := range geofence.parts {
+			data = part.appendWKBPolygon(data)
+		}
+		return data
+	}
+	return geofence.appendWKBPolygon(nil)
+}
+
+func (geofence *Geofence) appendWKBPolygon(data []byte) []byte {
+	data = appendWKBHeader(data, wkbPolygon)
+	if len(geofence.vertices) == 0 {
+		return appendUint32(data, 0)
+	}
+
+	rings := geofence.rings()
+	data = appendUint32(data, uint32(len(rings)))
+	for _, ring := range rings {
+		ring = closeRing(geofence.unwrapRing(ring))
+		data = appendUint32(data, uint32(len(ring)))
+		for _, point := range ring {
+			data = appendFloat64(data, point.Lng())
+			data = appendFloat64(data, point.Lat())
+		}
+	}
+	return data
+}
+
+// appendWKBHeader appends the little-endian byte order marker and the geometry type.
+func appendWKBHeader(data []byte, geometryType uint32) []byte {
+	return appendUint32(append(data, 1), geometryType)
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], value)
+	return append(data, buf[:]...)
+}
+
+func appendFloat64(data []byte, value float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(value))
+	return append(data, buf[:]...)
+}
@@ -0,0 +1,221 @@
+package geofence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Like GeoJSON, WKT orders coordinates "x y", that is "lng lat", the opposite of NewPoint(lat, lng).
+
+// NewGeofenceFromWKT builds a Geofence from POLYGON or MULTIPOLYGON well-known text, e.g.
+// "POLYGON ((-87.9 41.6, -87.4 41.6, -87.4 42.1, -87.9 41.6))". In each polygon the first ring
+// is the outer boundary and any further rings are holes. Z and M values and an EWKT
+// "SRID=4326;" prefix are accepted and ignored. Options are applied as for NewGeofence.
+func NewGeofenceFromWKT(wkt string, opts ...Option) (*Geofence, error) {
+	if i := strings.Index(wkt, ";"); i >= 0 && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(wkt)), "SRID=") {
+		wkt = wkt[i+1:]
+	}
+	parser := &wktParser{input: wkt}
+
+	geometryType := parser.word()
+	switch dimension := strings.ToUpper(parser.peekWord()); dimension {
+	case "Z", "M", "ZM":
+		parser.word()
+	}
+	if strings.EqualFold(parser.peekWord(), "EMPTY") {
+		return nil, fmt.Errorf("WKT %s is empty", strings.ToUpper(geometryType))
+	}
+
+	var geofence *Geofence
+	switch strings.ToUpper(geometryType) {
+	case "POLYGON":
+		rings, err := parser.polygon()
+		if err != nil {
+			return nil, err
+		}
+		geofence = NewGeofenceWithHoles(rings[0], rings[1:], opts...)
+	case "MULTIPOLYGON":
+		var parts []*Geofence
+		err := parser.list(func() error {
+			rings, err := parser.polygon()
+			if err != nil {
+				return err
+			}
+			parts = append(parts, NewGeofenceWithHoles(rings[0], rings[1:], opts...))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		geofence = newMultiGeofence(parts)
+	default:
+		return nil, fmt.Errorf("unsupported WKT geometry type %q", geometryType)
+	}
+
+	if rest := strings.TrimSpace(parser.input[parser.pos:]); rest != "" {
+		return nil, fmt.Errorf("unexpected %q after WKT geometry", rest)
+	}
+	return geofence, nil
+}
+
+// WKT renders the Geofence as POLYGON well-known text with the holes as interior rings, or as
+// MULTIPOLYGON for a NewMultiGeofence. A geofence without vertices is "POLYGON EMPTY".
+func (geofence *Geofence) WKT() string {
+	if geofence.empty() {
+		return "POLYGON EMPTY"
+	}
+	var builder strings.Builder
+	if len(geofence.parts) > 0 {
+		builder.WriteString("MULTIPOLYGON (")
+		written := 0
+		for _, part := range geofence.parts {
+			if part.empty() {
+				continue
+			}
+			if written > 0 {
+				builder.WriteString(", ")
+			}
+			part.writeWKTPolygon(&builder)
+			written++
+		}
+		builder.WriteString(")")
+		return builder.String()
+	}
+	builder.WriteString("POLYGON ")
+	geofence.writeWKTPolygon(&builder)
+	return builder.String()
+}
+
+func (geofence *Geofence) writeWKTPolygon(builder *strings.Builder) {
+	builder.WriteString("(")
+	for i, ring := range geofence.rings() {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString("(")
+		for j, point := range closeRing(geofence.unwrapRing(ring)) {
+			if j > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(strconv.FormatFloat(point.Lng(), 'f', -1, 64))
+			builder.WriteString(" ")
+			builder.WriteString(strconv.FormatFloat(point.Lat(), 'f', -1, 64))
+		}
+		builder.WriteString(")")
+	}
+	builder.WriteString(")")
+}
+
+type wktParser struct {
+	input string
+	pos   int
+}
+
+func (parser *wktParser) skipSpace() {
+	for parser.pos < len(parser.input) && unicode.IsSpace(rune(parser.input[parser.pos])) {
+		parser.pos++
+	}
+}
+
+// word consumes and returns the next run of letters.
+func (parser *wktParser) word() string {
+	parser.skipSpace()
+	start := parser.pos
+	for parser.pos < len(parser.input) && unicode.IsLetter(rune(parser.input[parser.pos])) {
+		parser.pos++
+	}
+	return parser.input[start:parser.pos]
+}
+
+func (parser *wktParser) peekWord() string {
+	pos := parser.pos
+	word := parser.word()
+	parser.pos = pos
+	return word
+}
+
+func (parser *wktParser) expect(char byte) error {
+	parser.skipSpace()
+	if parser.pos >= len(parser.input) {
+		return fmt.Errorf("expected %q but WKT ended", char)
+	}
+	if parser.input[parser.pos] != char {
+		return fmt.Errorf("expected %q at offset %d of WKT", char, parser.pos)
+	}
+	parser.pos++
+	return nil
+}
+
+// list parses a parenthesised, comma separated list, calling item for each entry.
+func (parser *wktParser) list(item func() error) error {
+	if err := parser.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		parser.skipSpace()
+		if parser.pos < len(parser.input) && parser.input[parser.pos] == ',' {
+			parser.pos++
+			continue
+		}
+		return parser.expect(')')
+	}
+}
+
+func (parser *wktParser) polygon() ([][]*Point, error) {
+	var rings [][]*Point
+	err := parser.list(func() error {
+		ring, err := parser.ring(len(rings))
+		rings = append(rings, ring)
+		return err
+	})
+	return rings, err
+}
+
+func (parser *wktParser) ring(index int) ([]*Point, error) {
+	var ring []*Point
+	err := parser.list(func() error {
+		point, err := parser.point()
+		ring = append(ring, point)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ring) < 4 {
+		return nil, fmt.Errorf("WKT ring %d has %d points, at least 4 are required", index, len(ring))
+	}
+	// WKT rings repeat the first point at the end, Geofence rings do not
+	if first, last := ring[0], ring[len(ring)-1]; first.lat == last.lat && first.lng == last.lng {
+		ring = ring[:len(ring)-1]
+	}
+	return ring, nil
+}
+
+// point parses "x y" with optional z and m values, returning Point(y, x).
+func (parser *wktParser) point() (*Point, error) {
+	var coordinates []float64
+	for {
+		parser.skipSpace()
+		start := parser.pos
+		for parser.pos < len(parser.input) && strings.IndexByte("+-.0123456789eE", parser.input[parser.pos]) >= 0 {
+			parser.pos++
+		}
+		if start == parser.pos {
+			break
+		}
+		value, err := strconv.ParseFloat(parser.input[start:parser.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WKT coordinate %q", parser.input[start:parser.pos])
+		}
+		coordinates = append(coordinates, value)
+	}
+	if len(coordinates) < 2 || len(coordinates) > 4 {
+		return nil, fmt.Errorf("WKT point at offset %d needs 2 to 4 coordinates, got %d", parser.pos, len(coordinates))
+	}
+	return NewPoint(coordinates[1], coordinates[0]), nil
+}
@@ -0,0 +1,125 @@
+package geofence
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGeofenceFromWKT(t *testing.T) {
+	geofence, err := NewGeofenceFromWKT("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 8 2, 8 8, 2 8, 2 2))")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, geofence.vertices)
+	assert.Len(t, geofence.holes, 1)
+	assert.True(t, geofence.Inside(NewPoint(1, 1)))
+	assert.False(t, geofence.Inside(NewPoint(5, 5)))
+
+	geofence, err = NewGeofenceFromWKT("SRID=4326;MultiPolygon Z(((-87.9 41.6 1,-87.4 41.6 1,-87.4 42.1 1,-87.9 41.6 1)),((0 0 0,1 0 0,1 1 0,0 0 0)))")
+	assert.NoError(t, err)
+	assert.Len(t, geofence.parts, 2)
+	assert.True(t, geofence.Inside(NewPoint(41.7, -87.5)))
+	assert.True(t, geofence.Inside(NewPoint(0.2, 0.8)))
+	assert.False(t, geofence.Inside(NewPoint(0.8, 0.2)))
+
+	tests := map[string]string{
+		"LINESTRING (0 0, 1 1)":                 `unsupported WKT geometry type "LINESTRING"`,
+		"POLYGON EMPTY":                         "WKT POLYGON is empty",
+		"POLYGON ((0 0, 1 0, 0 0))":             "WKT ring 0 has 3 points, at least 4 are required",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0)":         `expected ')' but WKT ended`,
+		"POLYGON ((0 0, 1 0, 1 1, 0 0)) extra":  `unexpected "extra" after WKT geometry`,
+		"POLYGON ((0 0, 1, 1 1, 0 0))":          "WKT point at offset 16 needs 2 to 4 coordinates, got 1",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0), (0 0))": "WKT ring 1 has 1 points, at least 4 are required",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0) (0 0))":  `expected ')' at offset 30 of WKT`,
+		"POLYGON ((0 0, 1-- 0, 1 1, 0 0))":      `invalid WKT coordinate "1--"`,
+	}
+	for wkt, message := range tests {
+		_, err := NewGeofenceFromWKT(wkt)
+		assert.EqualError(t, err, message, wkt)
+	}
+}
+
+func TestGeofenceWKT(t *testing.T) {
+	geofence := NewGeofenceWithHoles([]*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}, [][]*Point{{NewPoint(2, 2), NewPoint(2, 8.5), NewPoint(8, 8.5), NewPoint(8, 2)}})
+	assert.Equal(t, "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 8.5 2, 8.5 8, 2 8, 2 2))", geofence.WKT())
+
+	multi := NewMultiGeofence([][]*Point{{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)}, {NewPoint(5, 170), NewPoint(5, -170), NewPoint(6, -170)}})
+	assert.Equal(t, "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((170 5, -170 5, -170 6, 170 5)))", multi.WKT())
+
+	parsed, err := NewGeofenceFromWKT(multi.WKT())
+	assert.NoError(t, err)
+	assert.Equal(t, multi.WKT(), parsed.WKT())
+
+	assert.Equal(t, "POLYGON EMPTY", NewGeofence(nil).WKT())
+}
+
+func TestGeofenceWKB(t *testing.T) {
+	geofence := NewGeofence([]*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)})
+	assert.Equal(t, []byte{
+		1, 3, 0, 0, 0, // little-endian Polygon
+		1, 0, 0, 0, // rings
+		4, 0, 0, 0, // points
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}, geofence.WKB())
+
+	multi := NewMultiGeofence([][]*Point{{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)}, {NewPoint(5, 5), NewPoint(5, 6), NewPoint(6, 6)}})
+	data := multi.WKB()
+	assert.Equal(t, []byte{1, 6, 0, 0, 0, 2, 0, 0, 0}, data[:9])
+	assert.Equal(t, geofence.WKB(), data[9:9+len(geofence.WKB())])
+	assert.Len(t, data, 9+2*len(geofence.WKB()))
+
+	assert.Equal(t, []byte{1, 3, 0, 0, 0, 0, 0, 0, 0}, NewGeofence(nil).WKB())
+}
+
+func TestNewGeofenceFromWKB(t *testing.T) {
+	outer := []*Point{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0)}
+	hole := []*Point{NewPoint(4, 4), NewPoint(4, 6), NewPoint(6, 6), NewPoint(6, 4)}
+	geofence := NewGeofenceWithHoles(outer, [][]*Point{hole})
+	parsed, err := NewGeofenceFromWKB(geofence.WKB(), WithGranularity(5))
+	assert.NoError(t, err)
+	assert.Equal(t, geofence.Vertices(), parsed.Vertices())
+	assert.Equal(t, geofence.Holes(), parsed.Holes())
+	assert.Equal(t, int64(5), parsed.granularityX)
+
+	multi := NewMultiGeofence([][]*Point{outer, {NewPoint(20, 20), NewPoint(20, 21), NewPoint(21, 21)}})
+	parsed, err = NewGeofenceFromWKB(multi.WKB())
+	assert.NoError(t, err)
+	assert.Equal(t, multi.WKT(), parsed.WKT())
+
+	// Big-endian, PostGIS EWKB with an SRID and Z values, and ISO ZM
+	bigEndian := []byte{0, 0, 0, 0, 3, 0, 0, 0, 1, 0, 0, 0, 4}
+	ewkb := []byte{1, 3, 0, 0, 0xa0, 0xe6, 0x10, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0}
+	iso := []byte{1, 0xbb, 0x0b, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0}
+	triangle := [][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}
+	for _, position := range triangle {
+		for _, value := range position {
+			bigEndian = append(bigEndian, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.BigEndian.PutUint64(bigEndian[len(bigEndian)-8:], math.Float64bits(value))
+		}
+		ewkb = appendFloat64(appendFloat64(appendFloat64(ewkb, position[0]), position[1]), 99)
+		iso = appendFloat64(appendFloat64(appendFloat64(appendFloat64(iso, position[0]), position[1]), 99), 42)
+	}
+	for _, data := range [][]byte{bigEndian, ewkb, iso} {
+		parsed, err := NewGeofenceFromWKB(data)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []*Point{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 1)}, parsed.Vertices())
+		}
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		{2, 3, 0, 0, 0},
+		{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		NewGeofence(nil).WKB(),
+		geofence.WKB()[:40],
+		append(geofence.WKB(), 0),
+		{1, 6, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		_, err := NewGeofenceFromWKB(data)
+		assert.Error(t, err, "%v", data)
+	}
+}